@@ -0,0 +1,165 @@
+package indieauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/random"
+)
+
+// AuthorizationRequest describes the authorization request in progress. It
+// must be stored by the application between the redirect to URL and the
+// callback, such as in the encrypted cookie of the user agent.
+type AuthorizationRequest struct {
+	Metadata Metadata `json:"metadata"`
+	// URL is the authorization endpoint URL which the user agent must be
+	// redirected to.
+	URL string `json:"url"`
+	// Me is the canonical profile URL entered by the user, empty if the
+	// user chooses the profile on the authorization server.
+	Me           string `json:"me,omitempty"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// stateLength and verifierLength are the lengths of random state and PKCE
+// verifier, see RFC 7636 section 4.1.
+const (
+	stateLength    uint8 = 32
+	verifierLength uint8 = 64
+)
+
+var (
+	ErrState error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"state does not match the authorization request",
+		"https://indieauth.net/source/#authorization-response",
+	)
+	ErrIssuer error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"iss does not match the authorization server",
+		"https://indieauth.net/source/#authorization-response",
+	)
+	ErrCode error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"authorization code is missing",
+		"https://indieauth.net/source/#authorization-response",
+	)
+)
+
+// Authorize discovers the authorization server of the user and creates a new
+// authorization request for provided scopes.
+func (c *Client) Authorize(ctx context.Context, me string, scopes ...string) (*AuthorizationRequest, error) {
+	canonical, metadata, err := c.Discover(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewAuthorizationRequest(*metadata, canonical, scopes...)
+}
+
+// NewAuthorizationRequest creates a new authorization request with random
+// state and S256 PKCE challenge to the known authorization server. Me is
+// optional.
+func (c *Client) NewAuthorizationRequest(metadata Metadata, me string, scopes ...string,
+) (*AuthorizationRequest, error) {
+	u, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil || metadata.AuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("%w: invalid authorization endpoint", ErrDiscovery)
+	}
+
+	state, err := random.String(stateLength, random.Alphanumeric)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate state: %w", err)
+	}
+
+	verifier, err := random.String(verifierLength, random.Alphanumeric)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate code verifier: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+
+	// NOTE(toby3d): authorization endpoint can contain its own query.
+	q := u.Query()
+	for key, val := range map[string]string{
+		"client_id":             c.clientID,
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(hash[:]),
+		"code_challenge_method": domain.CodeChallengeMethodS256.String(),
+		"redirect_uri":          c.redirectURI,
+		"response_type":         domain.ResponseTypeCode.String(),
+		"state":                 state,
+	} {
+		q.Set(key, val)
+	}
+
+	if len(scopes) > 0 {
+		q.Set("scope", strings.Join(scopes, " "))
+	}
+
+	if me != "" {
+		q.Set("me", me)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return &AuthorizationRequest{
+		Metadata:     metadata,
+		URL:          u.String(),
+		Me:           me,
+		State:        state,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// Callback validates the query of the authorization response sent to
+// redirect_uri, exchanges the code and verifies the returned profile URL.
+func (c *Client) Callback(ctx context.Context, req AuthorizationRequest, query url.Values) (*Token, error) {
+	if code := query.Get("error"); code != "" {
+		return nil, &Error{
+			Code:        code,
+			Description: query.Get("error_description"),
+			URI:         query.Get("error_uri"),
+		}
+	}
+
+	if req.State == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(query.Get("state"))) != 1 {
+		return nil, ErrState
+	}
+
+	// NOTE(toby3d): RFC 9207 section 2.4: iss protects from the mix-up
+	// attacks, it must be checked if provided or advertised.
+	if iss := query.Get("iss"); iss != "" || req.Metadata.Issuer != "" {
+		if iss != req.Metadata.Issuer {
+			return nil, ErrIssuer
+		}
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, ErrCode
+	}
+
+	tkn, err := c.Exchange(ctx, req.Metadata, code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(toby3d): the entered profile URL is already verified by
+	// discovery.
+	if tkn.Me == req.Me {
+		return tkn, nil
+	}
+
+	if tkn.Me, err = c.VerifyMe(ctx, req.Metadata, tkn.Me); err != nil {
+		return nil, err
+	}
+
+	return tkn, nil
+}
@@ -0,0 +1,90 @@
+package indieauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+var (
+	ErrMe error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"invalid profile URL",
+		"https://indieauth.net/source/#user-profile-url",
+	)
+	ErrDiscovery error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"profile URL does not declare authorization and token endpoints",
+		"https://indieauth.net/source/#discovery-by-clients",
+	)
+	ErrMeMismatch error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"returned profile URL is not served by the same authorization server",
+		"https://indieauth.net/source/#authorization-server-confirmation",
+	)
+)
+
+// Discover fetches the profile URL of the user and returns its canonical form
+// along with endpoints of the authorization server, taken from the
+// indieauth-metadata document or legacy rel links.
+func (c *Client) Discover(ctx context.Context, me string) (string, *Metadata, error) {
+	parsed, err := normalizeMe(me)
+	if err != nil {
+		return "", nil, err
+	}
+
+	u, err := c.users.Get(ctx, *parsed)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	if u.AuthorizationEndpoint == nil || u.TokenEndpoint == nil {
+		return "", nil, ErrDiscovery
+	}
+
+	base := u.Me.URL()
+	out := new(Metadata)
+
+	for src, dst := range map[**url.URL]*string{
+		&u.Issuer:                &out.Issuer,
+		&u.AuthorizationEndpoint: &out.AuthorizationEndpoint,
+		&u.TokenEndpoint:         &out.TokenEndpoint,
+		&u.IntrospectionEndpoint: &out.IntrospectionEndpoint,
+		&u.RevocationEndpoint:    &out.RevocationEndpoint,
+	} {
+		// NOTE(toby3d): endpoints of Link header can be relative to the
+		// profile URL.
+		if *src != nil {
+			*dst = base.ResolveReference(*src).String()
+		}
+	}
+
+	return u.Me.String(), out, nil
+}
+
+// VerifyMe checks that the profile URL returned by the token endpoint
+// declares the same authorization server, so one server cannot issue tokens
+// for the users of another, see IndieAuth section 5.4.
+func (c *Client) VerifyMe(ctx context.Context, metadata Metadata, me string) (string, error) {
+	canonical, actual, err := c.Discover(ctx, me)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrMeMismatch, err)
+	}
+
+	if metadata.Issuer != "" && actual.Issuer != "" {
+		if metadata.Issuer != actual.Issuer {
+			return "", fmt.Errorf("%w: issuer %s, want %s", ErrMeMismatch, actual.Issuer, metadata.Issuer)
+		}
+
+		return canonical, nil
+	}
+
+	if metadata.AuthorizationEndpoint != actual.AuthorizationEndpoint {
+		return "", fmt.Errorf("%w: authorization endpoint %s, want %s", ErrMeMismatch,
+			actual.AuthorizationEndpoint, metadata.AuthorizationEndpoint)
+	}
+
+	return canonical, nil
+}
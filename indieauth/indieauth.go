@@ -0,0 +1,144 @@
+// Package indieauth implements the relying party side of IndieAuth: discovery
+// of the authorization server of the user, authorization request with state
+// and PKCE, callback validation and token requests.
+//
+//	client, err := indieauth.NewClient(indieauth.ClientOptions{
+//		ClientID:    "https://app.example.com/",
+//		RedirectURI: "https://app.example.com/callback",
+//	})
+//
+//	// NOTE: store req in the session of the user agent.
+//	req, err := client.Authorize(ctx, "example.com", "profile", "create")
+//	http.Redirect(w, r, req.URL, http.StatusFound)
+//
+//	// On redirect_uri:
+//	tkn, err := client.Callback(ctx, *req, r.URL.Query())
+package indieauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/user"
+	userhttprepo "source.toby3d.me/toby3d/auth/internal/user/repository/http"
+)
+
+type (
+	ClientOptions struct {
+		// Client makes requests to the user profile and the
+		// authorization server. By default http.DefaultClient is used.
+		Client *http.Client
+
+		// ClientID is the URL of the application home page.
+		ClientID string
+
+		// RedirectURI is the URL which the user is returned to after
+		// authorization.
+		RedirectURI string
+	}
+
+	// Client is the IndieAuth relying party.
+	Client struct {
+		client      *http.Client
+		users       user.Repository
+		clientID    string
+		redirectURI string
+	}
+
+	// Metadata describes endpoints of the authorization server of the user.
+	Metadata struct {
+		Issuer                string `json:"issuer,omitempty"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		IntrospectionEndpoint string `json:"introspection_endpoint,omitempty"`
+		RevocationEndpoint    string `json:"revocation_endpoint,omitempty"`
+	}
+
+	// Token describes the successful response of the token endpoint.
+	Token struct {
+		Expiry       time.Time `json:"expiry,omitempty"`
+		Profile      *Profile  `json:"profile,omitempty"`
+		AccessToken  string    `json:"access_token"`
+		TokenType    string    `json:"token_type"`
+		RefreshToken string    `json:"refresh_token,omitempty"`
+		// Me is the canonical profile URL of the user, verified to be
+		// served by the same authorization server.
+		Me    string   `json:"me"`
+		Scope []string `json:"scope,omitempty"`
+	}
+
+	Profile struct {
+		Name  string `json:"name,omitempty"`
+		URL   string `json:"url,omitempty"`
+		Photo string `json:"photo,omitempty"`
+		Email string `json:"email,omitempty"`
+	}
+
+	// Error is the error response of the authorization server.
+	Error struct {
+		Code        string `json:"error"`
+		Description string `json:"error_description,omitempty"`
+		URI         string `json:"error_uri,omitempty"`
+	}
+)
+
+// NewClient creates a new relying party identified by provided client_id.
+func NewClient(opts ClientOptions) (*Client, error) {
+	if _, err := url.Parse(opts.ClientID); err != nil || opts.ClientID == "" {
+		return nil, fmt.Errorf("invalid client_id %q", opts.ClientID)
+	}
+
+	if u, err := url.Parse(opts.RedirectURI); err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("invalid redirect_uri %q", opts.RedirectURI)
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return &Client{
+		client:      opts.Client,
+		clientID:    opts.ClientID,
+		redirectURI: opts.RedirectURI,
+		users:       userhttprepo.NewHTTPUserRepository(opts.Client),
+	}, nil
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Description
+}
+
+// Is reports whether target is the error with the same code, so responses can
+// be matched by errors.Is(err, &indieauth.Error{Code: "invalid_grant"}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code
+}
+
+// normalizeMe parses the profile URL entered by the user, adding the missing
+// scheme and path, see IndieAuth section 3.4.
+func normalizeMe(raw string) (*domain.Me, error) {
+	u, err := url.Parse(raw)
+	if err == nil && u.Scheme == "" {
+		u, err = url.Parse("https://" + raw)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMe, err)
+	}
+
+	me, err := domain.ParseMe(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMe, err)
+	}
+
+	return me, nil
+}
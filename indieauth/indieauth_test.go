@@ -0,0 +1,405 @@
+package indieauth_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/indieauth"
+	"source.toby3d.me/toby3d/auth/internal/auth"
+	authucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
+	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	metadatahttpdelivery "source.toby3d.me/toby3d/auth/internal/metadata/delivery/http"
+	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	replayrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
+	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/token"
+	tokenhttpdelivery "source.toby3d.me/toby3d/auth/internal/token/delivery/http"
+	tokenrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
+)
+
+type Dependencies struct {
+	auth   auth.UseCase
+	tokens token.UseCase
+	client *indieauth.Client
+}
+
+const (
+	testClientID    string = "http://localhost/"
+	testRedirectURI string = "http://localhost/callback"
+	testIssuer      string = "http://auth.example.com/"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req, err := deps.client.Authorize(context.Background(), "http://user.example.net", "profile", "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Me != "http://user.example.net/" || req.Metadata.Issuer != testIssuer ||
+		req.Metadata.TokenEndpoint != testIssuer+"token" {
+		t.Errorf("Authorize() = %+v, want request to %s for http://user.example.net/", req, testIssuer)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := u.Query()
+
+	for key, expect := range map[string]string{
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURI,
+		"response_type":         "code",
+		"code_challenge_method": "S256",
+		"scope":                 "profile create",
+		"me":                    "http://user.example.net/",
+		"state":                 req.State,
+	} {
+		if actual := q.Get(key); actual != expect {
+			t.Errorf("Authorize() returns URL with %s = %q, want %q", key, actual, expect)
+		}
+	}
+
+	if !domain.CodeChallengeMethodS256.Validate(q.Get("code_challenge"), req.CodeVerifier) {
+		t.Errorf("Authorize() returns code_challenge which does not match code_verifier")
+	}
+}
+
+func TestCallback(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		entered  string
+		me       string
+		query    url.Values
+		expError error
+	}{
+		"entered":          {entered: "http://user.example.net", me: "http://user.example.net/"},
+		"chosen on server": {me: "http://user.example.net/"},
+		"permanent redirect": {
+			entered: "http://user.example.net/moved",
+			me:      "http://user.example.net/",
+		},
+		"other server": {me: "http://other.example.org/", expError: indieauth.ErrMeMismatch},
+		"invalid state": {
+			me:       "http://user.example.net/",
+			query:    url.Values{"state": []string{"hackme"}},
+			expError: indieauth.ErrState,
+		},
+		"mix-up": {
+			me:       "http://user.example.net/",
+			query:    url.Values{"iss": []string{"http://evil.example.com/"}},
+			expError: indieauth.ErrIssuer,
+		},
+		"denied": {
+			me:       "http://user.example.net/",
+			query:    url.Values{"error": []string{"access_denied"}},
+			expError: &indieauth.Error{Code: "access_denied"},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deps := NewDependencies(t)
+
+			var (
+				req *indieauth.AuthorizationRequest
+				err error
+			)
+
+			if tc.entered != "" {
+				req, err = deps.client.Authorize(context.Background(), tc.entered, "profile")
+			} else {
+				_, metadata, discoveryErr := deps.client.Discover(context.Background(),
+					"http://user.example.net/")
+				if discoveryErr != nil {
+					t.Fatal(discoveryErr)
+				}
+
+				req, err = deps.client.NewAuthorizationRequest(*metadata, "", "profile")
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query := Consent(t, deps, *req, tc.me)
+			for key := range tc.query {
+				query.Set(key, tc.query.Get(key))
+			}
+
+			result, err := deps.client.Callback(context.Background(), *req, query)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Callback() = %v, want %v", err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Me != tc.me || result.AccessToken == "" || len(result.Scope) != 1 {
+				t.Errorf("Callback() = %+v, want token for %s", result, tc.me)
+			}
+
+			if _, _, err = deps.tokens.Verify(context.Background(), result.AccessToken); err != nil {
+				t.Errorf("Callback() returns invalid token: %s", err)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req, err := deps.client.Authorize(context.Background(), "http://user.example.net", "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := deps.client.Callback(context.Background(), *req, Consent(t, deps, *req, req.Me))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deps.client.Revoke(context.Background(), req.Metadata, tkn.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = deps.tokens.Verify(context.Background(), tkn.AccessToken); err == nil {
+		t.Error("Revoke() does not revoke token")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req, err := deps.client.Authorize(context.Background(), "http://user.example.net", "create", "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := deps.client.Callback(context.Background(), *req, Consent(t, deps, *req, req.Me))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tkn.RefreshToken == "" {
+		t.Fatalf("Callback() = %+v, want refresh token", tkn)
+	}
+
+	result, err := deps.client.Refresh(context.Background(), req.Metadata, tkn.RefreshToken, "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Me != tkn.Me || len(result.Scope) != 1 || result.Scope[0] != "create" ||
+		result.RefreshToken == "" || result.RefreshToken == tkn.RefreshToken {
+		t.Errorf("Refresh() = %+v, want rotated token with create scope", result)
+	}
+
+	if _, _, err = deps.tokens.Verify(context.Background(), result.AccessToken); err != nil {
+		t.Errorf("Refresh() returns invalid token: %s", err)
+	}
+
+	// NOTE(toby3d): rotated refresh token cannot be used again.
+	target := new(indieauth.Error)
+	if _, err = deps.client.Refresh(context.Background(), req.Metadata, tkn.RefreshToken); !errors.As(err, &target) ||
+		target.Code != "invalid_grant" {
+		t.Errorf("Refresh() = %v, want invalid_grant error response", err)
+	}
+}
+
+func TestRevoke_RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req, err := deps.client.Authorize(context.Background(), "http://user.example.net", "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := deps.client.Callback(context.Background(), *req, Consent(t, deps, *req, req.Me))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deps.client.Revoke(context.Background(), req.Metadata, tkn.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = deps.client.Refresh(context.Background(), req.Metadata, tkn.RefreshToken); err == nil {
+		t.Error("Revoke() does not revoke refresh token")
+	}
+
+	if _, _, err = deps.tokens.Verify(context.Background(), tkn.AccessToken); err == nil {
+		t.Error("Revoke() does not revoke access token of the same grant")
+	}
+}
+
+// Consent simulates the owner who approves the authorization request on the
+// server and returns query of the redirect back to the client.
+func Consent(tb testing.TB, deps Dependencies, req indieauth.AuthorizationRequest, me string) url.Values {
+	tb.Helper()
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	q := u.Query()
+
+	cid, err := domain.ParseClientID(q.Get("client_id"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	scope := make(domain.Scopes, 0)
+	if err = scope.UnmarshalForm([]byte(q.Get("scope"))); err != nil {
+		tb.Fatal(err)
+	}
+
+	code, err := deps.auth.Generate(context.Background(), auth.GenerateOptions{
+		ClientID:            *cid,
+		Me:                  *domain.TestMe(tb, me),
+		RedirectURI:         redirectURI,
+		CodeChallengeMethod: domain.CodeChallengeMethodS256,
+		CodeChallenge:       q.Get("code_challenge"),
+		Scope:               scope,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return url.Values{
+		"code":  []string{code},
+		"state": []string{q.Get("state")},
+		"iss":   []string{testIssuer},
+	}
+}
+
+// NewDependencies starts the authorization server in-process along with the
+// profile pages of its users. All hosts are served by the same listener.
+func NewDependencies(tb testing.TB) Dependencies {
+	tb.Helper()
+
+	config := domain.TestConfig(tb)
+	config.Server.RootURL = testIssuer
+
+	profiles := profilerepo.NewMemoryProfileRepository()
+	sessions := sessionrepo.NewMemorySessionRepository(*config)
+	tokens := tokenucase.NewTokenUseCase(tokenucase.Config{
+		Config:   *config,
+		Profiles: profiles,
+		Sessions: sessions,
+		Tokens:   tokenrepo.NewMemoryTokenRepository(),
+	})
+	authService := authucase.NewAuthUseCase(sessions, profiles, nil, nil, *config)
+
+	cid, err := domain.ParseClientID(testClientID)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	clients := clientrepo.NewMemoryClientRepository()
+	if err = clients.Create(context.Background(), domain.Client{
+		ID:          *cid,
+		RedirectURI: []*url.URL{{Scheme: "http", Host: "localhost", Path: "/callback"}},
+	}); err != nil {
+		tb.Fatal(err)
+	}
+
+	root, _ := url.Parse(testIssuer)
+	metadata := metadatahttpdelivery.NewHandler(&domain.Metadata{
+		Issuer:                        root,
+		AuthorizationEndpoint:         root.JoinPath("authorize"),
+		TokenEndpoint:                 root.JoinPath("token"),
+		IntrospectionEndpoint:         root.JoinPath("introspect"),
+		RevocationEndpoint:            root.JoinPath("revocation"),
+		UserinfoEndpoint:              root.JoinPath("userinfo"),
+		ServiceDocumentation:          root,
+		CodeChallengeMethodsSupported: []domain.CodeChallengeMethod{domain.CodeChallengeMethodS256},
+		AuthorizationResponseIssParameterSupported: true,
+	})
+	tokenHandler := tokenhttpdelivery.NewHandler(tokens, authService,
+		clientucase.NewClientUseCase(clients, clientrepo.NewMemoryClientRepository(), nil, nil, nil, nil), nil,
+		scopeucase.NewScopeUseCase(domain.ScopePolicyReject), replayrepo.NewMemoryReplayRepository(), *config)
+
+	profile := func(metadataURL string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
+			_, _ = w.Write([]byte(`<link rel="indieauth-metadata" href="` + metadataURL + `">`))
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		default:
+			http.NotFound(w, r)
+		case "user.example.net/":
+			profile(testIssuer+".well-known/oauth-authorization-server")(w, r)
+		case "user.example.net/moved":
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		case "other.example.org/":
+			profile("http://evil.example.com/.well-known/oauth-authorization-server")(w, r)
+		case "auth.example.com/.well-known/oauth-authorization-server":
+			metadata.ServeHTTP(w, r)
+		case "evil.example.com/.well-known/oauth-authorization-server":
+			_, _ = w.Write([]byte(`{"issuer":"http://evil.example.com/",` +
+				`"authorization_endpoint":"http://evil.example.com/authorize",` +
+				`"token_endpoint":"http://evil.example.com/token"}`))
+		case "auth.example.com/token", "auth.example.com/revocation", "auth.example.com/introspect":
+			tokenHandler.ServeHTTP(w, r)
+		}
+	}))
+	tb.Cleanup(srv.Close)
+
+	client, err := indieauth.NewClient(indieauth.ClientOptions{
+		Client: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, network, srv.Listener.Addr().String())
+			},
+		}},
+		ClientID:    testClientID,
+		RedirectURI: testRedirectURI,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return Dependencies{
+		auth:   authService,
+		client: client,
+		tokens: tokens,
+	}
+}
@@ -0,0 +1,156 @@
+package indieauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+//nolint:tagliatelle // IndieAuth section 5.3.3
+type tokenResponse struct {
+	Profile      *Profile `json:"profile,omitempty"`
+	AccessToken  string   `json:"access_token"`
+	TokenType    string   `json:"token_type"`
+	Scope        string   `json:"scope"`
+	Me           string   `json:"me"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpiresIn    int64    `json:"expires_in"`
+}
+
+// Exchange redeems the authorization code with PKCE verifier at the token
+// endpoint. Callback must be preferred, as it also validates the
+// authorization response and the returned profile URL.
+func (c *Client) Exchange(ctx context.Context, metadata Metadata, code, verifier string) (*Token, error) {
+	return c.token(ctx, metadata, url.Values{
+		"grant_type":    []string{domain.GrantTypeAuthorizationCode.String()},
+		"code":          []string{code},
+		"client_id":     []string{c.clientID},
+		"redirect_uri":  []string{c.redirectURI},
+		"code_verifier": []string{verifier},
+	})
+}
+
+// Refresh requests a new access token by the refresh token. Scopes are
+// optional and can only narrow the originally granted ones.
+func (c *Client) Refresh(ctx context.Context, metadata Metadata, refreshToken string, scopes ...string,
+) (*Token, error) {
+	form := url.Values{
+		"grant_type":    []string{domain.GrantTypeRefreshToken.String()},
+		"refresh_token": []string{refreshToken},
+		"client_id":     []string{c.clientID},
+	}
+
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+
+	return c.token(ctx, metadata, form)
+}
+
+// Revoke revokes the access or refresh token by the revocation endpoint, or by
+// the legacy action of the token endpoint if the server does not provide one.
+func (c *Client) Revoke(ctx context.Context, metadata Metadata, token string) error {
+	endpoint, form := metadata.RevocationEndpoint, url.Values{
+		"token":     []string{token},
+		"client_id": []string{c.clientID},
+	}
+
+	if endpoint == "" {
+		endpoint = metadata.TokenEndpoint
+		form.Set("action", domain.ActionRevoke.String())
+	}
+
+	resp, err := c.post(ctx, endpoint, form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	return nil
+}
+
+func (c *Client) token(ctx context.Context, metadata Metadata, form url.Values) (*Token, error) {
+	resp, err := c.post(ctx, metadata.TokenEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeError(resp)
+	}
+
+	in := new(tokenResponse)
+	if err = json.NewDecoder(resp.Body).Decode(in); err != nil {
+		return nil, fmt.Errorf("cannot decode token response: %w", err)
+	}
+
+	if in.AccessToken == "" || in.Me == "" {
+		return nil, errors.New("token response does not contain access_token or me")
+	}
+
+	out := &Token{
+		Profile:      in.Profile,
+		AccessToken:  in.AccessToken,
+		TokenType:    in.TokenType,
+		RefreshToken: in.RefreshToken,
+		Me:           in.Me,
+		Scope:        strings.Fields(in.Scope),
+	}
+
+	if in.ExpiresIn > 0 {
+		out.Expiry = time.Now().UTC().Add(time.Duration(in.ExpiresIn) * time.Second)
+	}
+
+	return out, nil
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("%w: endpoint is not provided", ErrDiscovery)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot build request: %w", err)
+	}
+
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request %s: %w", endpoint, err)
+	}
+
+	return resp, nil
+}
+
+// decodeError returns error described by the response of the authorization
+// server, see RFC 6749 section 5.2.
+func decodeError(resp *http.Response) error {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16)) //nolint:gomnd // 64 KiB is enough for error
+	if err != nil {
+		return fmt.Errorf("cannot read error response: %w", err)
+	}
+
+	out := new(Error)
+	if err = json.Unmarshal(body, out); err != nil || out.Code == "" {
+		return fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return out
+}
@@ -0,0 +1,27 @@
+package account
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	Create(ctx context.Context, account domain.Account) error
+	Get(ctx context.Context, username string) (*domain.Account, error)
+	Update(ctx context.Context, account domain.Account) error
+}
+
+var (
+	ErrExist error = domain.NewError(
+		domain.ErrorCodeServerError,
+		"account already exists",
+		"",
+	)
+
+	ErrNotExist error = domain.NewError(
+		domain.ErrorCodeServerError,
+		"account does not exist",
+		"",
+	)
+)
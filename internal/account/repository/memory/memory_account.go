@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"source.toby3d.me/toby3d/auth/internal/account"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type memoryAccountRepository struct {
+	mutex    *sync.RWMutex
+	accounts map[string]domain.Account
+}
+
+func NewMemoryAccountRepository() account.Repository {
+	return &memoryAccountRepository{
+		mutex:    new(sync.RWMutex),
+		accounts: make(map[string]domain.Account),
+	}
+}
+
+func (repo *memoryAccountRepository) Create(_ context.Context, a domain.Account) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.accounts[a.Username]; ok {
+		return account.ErrExist
+	}
+
+	repo.accounts[a.Username] = copyAccount(a)
+
+	return nil
+}
+
+func (repo *memoryAccountRepository) Get(_ context.Context, username string) (*domain.Account, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	a, ok := repo.accounts[username]
+	if !ok {
+		return nil, account.ErrNotExist
+	}
+
+	out := copyAccount(a)
+
+	return &out, nil
+}
+
+func (repo *memoryAccountRepository) Update(_ context.Context, a domain.Account) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.accounts[a.Username]; !ok {
+		return account.ErrNotExist
+	}
+
+	repo.accounts[a.Username] = copyAccount(a)
+
+	return nil
+}
+
+// copyAccount returns copy of account which identities slice can be modified
+// without a lock.
+func copyAccount(a domain.Account) domain.Account {
+	a.Identities = append(make([]*domain.Me, 0, len(a.Identities)), a.Identities...)
+
+	return a
+}
@@ -0,0 +1,28 @@
+package account
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type UseCase interface {
+	// Get returns account by username.
+	Get(ctx context.Context, username string) (*domain.Account, error)
+
+	// AddIdentity verifies that provided profile URL delegates
+	// authorization to this server and adds its canonical form to the
+	// account identities.
+	AddIdentity(ctx context.Context, username string, me domain.Me) (*domain.Me, error)
+
+	// Choose resolves identity by the profile URL hint provided by client.
+	// It returns the owned identity the hint resolves to, or nil if owner
+	// must choose one of the account identities.
+	Choose(ctx context.Context, username string, hint *domain.Me) (*domain.Me, error)
+}
+
+var ErrIdentity error = domain.NewError(
+	domain.ErrorCodeAccessDenied,
+	"profile URL is not owned by account",
+	"https://indieauth.net/source/#authorization-request",
+)
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"source.toby3d.me/toby3d/auth/internal/account"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/user"
+)
+
+type accountUseCase struct {
+	accounts account.Repository
+	users    user.UseCase
+	config   domain.Config
+}
+
+// NewAccountUseCase creates a new accounts use case which verifies identities
+// by the user profiles discovery.
+func NewAccountUseCase(accounts account.Repository, users user.UseCase, config domain.Config) account.UseCase {
+	return &accountUseCase{
+		accounts: accounts,
+		config:   config,
+		users:    users,
+	}
+}
+
+func (uc *accountUseCase) Get(ctx context.Context, username string) (*domain.Account, error) {
+	out, err := uc.accounts.Get(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find account: %w", err)
+	}
+
+	return out, nil
+}
+
+func (uc *accountUseCase) AddIdentity(ctx context.Context, username string, me domain.Me) (*domain.Me, error) {
+	a, err := uc.Get(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := uc.verify(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+
+	a.AddIdentity(*canonical)
+
+	if err = uc.accounts.Update(ctx, *a); err != nil {
+		return nil, fmt.Errorf("cannot update account: %w", err)
+	}
+
+	return canonical, nil
+}
+
+func (uc *accountUseCase) Choose(ctx context.Context, username string, hint *domain.Me) (*domain.Me, error) {
+	a, err := uc.Get(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(toby3d): account without identities can use any profile URL
+	// which delegates authorization to this server.
+	if len(a.Identities) == 0 {
+		if hint == nil || hint.URL() == nil {
+			return nil, domain.NewError(domain.ErrorCodeInvalidRequest, "me is required",
+				"https://indieauth.net/source/#authorization-request")
+		}
+
+		return uc.verify(ctx, *hint)
+	}
+
+	if hint == nil || hint.URL() == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	if a.HasIdentity(*hint) {
+		return hint, nil
+	}
+
+	// NOTE(toby3d): hint may redirect to one of the owned identities, like
+	// an apex domain to www one. Any other hint is not an error, owner
+	// chooses the identity on the consent page.
+	canonical, err := uc.verify(ctx, *hint)
+	if err != nil || !a.HasIdentity(*canonical) {
+		return nil, nil //nolint:nilnil
+	}
+
+	return canonical, nil
+}
+
+// verify checks that profile URL delegates authorization to this server and
+// returns its canonical form.
+func (uc *accountUseCase) verify(ctx context.Context, me domain.Me) (*domain.Me, error) {
+	issuer, err := url.Parse(uc.config.Server.GetRootURL())
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse issuer URL: %w", err)
+	}
+
+	u, err := uc.users.Verify(ctx, user.VerifyOptions{
+		Me:                    me,
+		Issuer:                issuer,
+		AuthorizationEndpoint: issuer.JoinPath("authorize"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify profile URL: %w", err)
+	}
+
+	return u.Me, nil
+}
@@ -0,0 +1,147 @@
+package usecase_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/account"
+	repository "source.toby3d.me/toby3d/auth/internal/account/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/account/usecase"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	userrepo "source.toby3d.me/toby3d/auth/internal/user/repository/memory"
+	userucase "source.toby3d.me/toby3d/auth/internal/user/usecase"
+)
+
+type Dependencies struct {
+	accounts       account.Repository
+	accountService account.UseCase
+	config         *domain.Config
+}
+
+func TestAddIdentity(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t, "https://user.example.net/", "https://www.user.example.net/")
+
+	if _, err := deps.accountService.AddIdentity(context.Background(), deps.config.IndieAuth.Username,
+		*domain.TestMe(t, "https://user.example.net/")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := deps.accountService.AddIdentity(context.Background(), deps.config.IndieAuth.Username,
+		*domain.TestMe(t, "https://foreign.example.net/")); err == nil {
+		t.Error("AddIdentity(https://foreign.example.net/) = nil, want error")
+	}
+
+	result, err := deps.accounts.Get(context.Background(), deps.config.IndieAuth.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Identities) != 1 || result.Identities[0].String() != "https://user.example.net/" {
+		t.Errorf("Identities = %v, want [https://user.example.net/]", result.Identities)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t, "https://user.example.net/", "https://photos.example.net/")
+
+	for _, raw := range []string{"https://user.example.net/", "https://photos.example.net/"} {
+		if _, err := deps.accountService.AddIdentity(context.Background(), deps.config.IndieAuth.Username,
+			*domain.TestMe(t, raw)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		hint  *domain.Me
+		expMe string
+	}{
+		"omitted": {hint: nil, expMe: ""},
+		"owned":   {hint: domain.TestMe(t, "https://photos.example.net/"), expMe: "https://photos.example.net/"},
+		"foreign": {hint: domain.TestMe(t, "https://foreign.example.net/"), expMe: ""},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := deps.accountService.Choose(context.Background(), deps.config.IndieAuth.Username,
+				tc.hint)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := ""
+			if result != nil {
+				out = result.String()
+			}
+
+			if out != tc.expMe {
+				t.Errorf("Choose(%v) = %s, want %s", tc.hint, out, tc.expMe)
+			}
+		})
+	}
+}
+
+func TestChoose_WithoutIdentities(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t, "https://user.example.net/")
+
+	if _, err := deps.accountService.Choose(context.Background(), deps.config.IndieAuth.Username,
+		nil); err == nil {
+		t.Error("Choose(nil) = nil, want error")
+	}
+
+	result, err := deps.accountService.Choose(context.Background(), deps.config.IndieAuth.Username,
+		domain.TestMe(t, "https://user.example.net/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.String() != "https://user.example.net/" {
+		t.Errorf("Choose(https://user.example.net/) = %s, want %s", result, "https://user.example.net/")
+	}
+}
+
+// NewDependencies creates account use case with empty owner account and users
+// which delegates authorization to the test server.
+func NewDependencies(tb testing.TB, delegated ...string) Dependencies {
+	tb.Helper()
+
+	config := domain.TestConfig(tb)
+	issuer, _ := url.Parse(config.Server.GetRootURL())
+	users := userrepo.NewMemoryUserRepository()
+
+	for _, raw := range append(delegated, "https://foreign.example.net/") {
+		user := domain.TestUser(tb)
+		user.Me = domain.TestMe(tb, raw)
+		user.Issuer = issuer
+
+		if raw == "https://foreign.example.net/" {
+			user.Issuer = &url.URL{Scheme: "https", Host: "auth.example.org", Path: "/"}
+		}
+
+		if err := users.Create(context.Background(), *user); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	accounts := repository.NewMemoryAccountRepository()
+	if err := accounts.Create(context.Background(), domain.Account{
+		Username:   config.IndieAuth.Username,
+		Identities: make([]*domain.Me, 0),
+	}); err != nil {
+		tb.Fatal(err)
+	}
+
+	return Dependencies{
+		accounts:       accounts,
+		accountService: usecase.NewAccountUseCase(accounts, userucase.NewUserUseCase(users), *config),
+		config:         config,
+	}
+}
@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Create records the event.
+	Create(ctx context.Context, event domain.AuditEvent) error
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/audit"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type loggerAuditRepository struct {
+	logger *log.Logger
+}
+
+// NewLoggerAuditRepository creates a new audit repository which writes every
+// event as a single JSON line into the provided logger.
+func NewLoggerAuditRepository(logger *log.Logger) audit.Repository {
+	return &loggerAuditRepository{
+		logger: logger,
+	}
+}
+
+func (repo *loggerAuditRepository) Create(_ context.Context, event domain.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("cannot encode audit event: %w", err)
+	}
+
+	repo.logger.Printf("audit: %s", data)
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/audit/repository/logger"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	event := domain.AuditEvent{
+		CreatedAt: time.Now().UTC(),
+		ClientID:  *domain.TestClientID(t),
+		Me:        *domain.TestMe(t, "https://user.example.net/"),
+		Type:      domain.AuditEventCodeReplay,
+		TokenIDs:  []string{"abc123"},
+	}
+
+	if err := logger.NewLoggerAuditRepository(log.New(buf, "", 0)).
+		Create(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"type":"code_replay"`, `"token_ids":["abc123"]`, `"me":"https://user.example.net/"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Create(%+v) = %s, want contains %s", event, buf.String(), want)
+		}
+	}
+}
@@ -2,46 +2,133 @@ package http
 
 import (
 	"crypto/subtle"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
+	"source.toby3d.me/toby3d/auth/internal/account"
 	"source.toby3d.me/toby3d/auth/internal/auth"
 	"source.toby3d.me/toby3d/auth/internal/client"
 	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/consent"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
 	"source.toby3d.me/toby3d/auth/internal/middleware"
+	"source.toby3d.me/toby3d/auth/internal/policy"
 	"source.toby3d.me/toby3d/auth/internal/profile"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+	"source.toby3d.me/toby3d/auth/internal/totp"
 	"source.toby3d.me/toby3d/auth/internal/urlutil"
 	"source.toby3d.me/toby3d/auth/web"
 )
 
 type (
 	NewHandlerOptions struct {
+		Accounts account.UseCase
 		Auth     auth.UseCase
 		Clients  client.UseCase
+		Consents consent.UseCase
+		Images   imageproxy.UseCase
 		Matcher  language.Matcher
+		Policies policy.UseCase
 		Profiles profile.UseCase
+		Scopes   scope.UseCase
+		// SigningKey is the optional private key published in the key
+		// set, which signs JWT-secured authorization responses.
+		SigningKey jwk.Key
+		// Clock provides the current time. System clock is used if
+		// nil.
+		Clock domain.Clock
+		// Renderer writes pages. Built-in templates are used if nil.
+		Renderer web.Renderer
 		Config   domain.Config
 	}
 
+	// authorizationRedirect contains validated parameters of the
+	// authorization request used to return the response to the client.
+	authorizationRedirect struct {
+		RedirectURI  *url.URL
+		ClientID     domain.ClientID
+		ResponseMode domain.ResponseMode
+		State        string
+	}
+
+	// consentRequest contains parameters of the authorization request
+	// signed on the consent page.
+	consentRequest struct {
+		// RequestURI of the pushed authorization request, empty if it
+		// was not pushed.
+		RequestURI     string
+		Scope          domain.Scopes
+		Reauthenticate bool
+	}
+
 	Handler struct {
-		clients client.UseCase
-		matcher language.Matcher
-		useCase auth.UseCase
-		config  domain.Config
+		accounts account.UseCase
+		clients  client.UseCase
+		consents consent.UseCase
+		images   imageproxy.UseCase
+		matcher  language.Matcher
+		policies policy.UseCase
+		scopes   scope.UseCase
+		useCase  auth.UseCase
+		key      jwk.Key
+		clock    domain.Clock
+		renderer web.Renderer
+		config   domain.Config
 	}
 )
 
+const (
+	// ResponseExpiry is the lifetime of the JWT-secured authorization
+	// response.
+	ResponseExpiry time.Duration = 10 * time.Minute
+
+	// SessionCookieName is the name of cookie which remembers
+	// authentication of the owner.
+	SessionCookieName string = "__Secure-session"
+
+	// StepUpCookieName is the name of cookie which remembers confirmation
+	// of the second factor by the owner.
+	StepUpCookieName string = "__Secure-step-up"
+
+	// ConsentExpiry is the lifetime of the consent page, after which it
+	// cannot be submitted anymore.
+	ConsentExpiry time.Duration = time.Hour
+)
+
 func NewHandler(opts NewHandlerOptions) *Handler {
+	if opts.Clock == nil {
+		opts.Clock = domain.SystemClock
+	}
+
+	if opts.Renderer == nil {
+		opts.Renderer = web.DefaultRenderer
+	}
+
 	return &Handler{
-		clients: opts.Clients,
-		config:  opts.Config,
-		matcher: opts.Matcher,
-		useCase: opts.Auth,
+		accounts: opts.Accounts,
+		clock:    opts.Clock,
+		renderer: opts.Renderer,
+		clients:  opts.Clients,
+		consents: opts.Consents,
+		config:   opts.Config,
+		images:   opts.Images,
+		key:      opts.SigningKey,
+		matcher:  opts.Matcher,
+		policies: opts.Policies,
+		scopes:   opts.Scopes,
+		useCase:  opts.Auth,
 	}
 }
 
@@ -56,9 +143,9 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			CookieMaxAge:   0,
 			CookieSameSite: http.SameSiteStrictMode,
 			ContextKey:     "csrf",
-			CookieDomain:   h.config.Server.Domain,
+			CookieDomain:   h.config.Server.GetHostname(),
 			CookieName:     "__Secure-csrf",
-			CookiePath:     "/authorize",
+			CookiePath:     h.config.Server.GetPath("/authorize"),
 			TokenLookup:    "param:_csrf",
 			TokenLength:    0,
 			CookieSecure:   true,
@@ -117,63 +204,251 @@ func (h *Handler) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
 
-	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get(common.HeaderAcceptLanguage))
-	tag, _, _ := h.matcher.Match(tags...)
-	baseOf := web.BaseOf{
-		Config:   &h.config,
-		Language: tag,
-		Printer:  message.NewPrinter(tag),
+	req := NewAuthAuthorizationRequest()
+	bindErr := h.bindAuthorization(r, req)
+
+	// NOTE(toby3d): client and redirect URI are decoded first, so they
+	// can be validated even if the rest of the request is invalid.
+	if req.ClientID.String() == "" || req.RedirectURI.URL == nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, nil, bindErr)
+
+		return
 	}
 
-	req := NewAuthAuthorizationRequest()
-	if err := req.bind(r); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		web.WriteTemplate(w, &web.ErrorPage{
-			BaseOf: baseOf,
-			Error:  err,
-		})
+	profile := h.config.Security.GetProfile()
+
+	report, err := h.consents.Assess(r.Context(), consent.AssessOptions{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI.URL,
+		Exact:       profile.RequireExactRedirectURI(),
+	})
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, nil, err)
 
 		return
 	}
 
-	client, err := h.clients.Discovery(r.Context(), req.ClientID)
+	target := &authorizationRedirect{
+		RedirectURI:  req.RedirectURI.URL,
+		ClientID:     req.ClientID,
+		ResponseMode: req.ResponseMode,
+		State:        req.State,
+	}
+
+	if bindErr != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, bindErr)
+
+		return
+	}
+
+	// NOTE(toby3d): JWT-secured responses are verified by the published
+	// key set, which exists only if OpenID Connect is enabled.
+	if req.ResponseMode.IsJWT() && h.key == nil {
+		target.ResponseMode = req.ResponseMode.Transport()
+
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, domain.NewError(
+			domain.ErrorCodeInvalidRequest, "JWT-secured authorization responses are not supported",
+			"https://openid.net/specs/oauth-v2-jarm.html#section-2.3"))
+
+		return
+	}
+
+	if err = profile.ValidateResponseType(req.ResponseType); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	if err = profile.ValidateCodeChallenge(req.CodeChallengeMethod, req.CodeChallenge); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	// NOTE(toby3d): backwards-compatible support.
+	// See: https://aaronparecki.com/2020/12/03/1/indieauth-2020#response-type
+	if req.ResponseType == domain.ResponseTypeID {
+		req.ResponseType = domain.ResponseTypeCode
+	}
+
+	if req.Scope, err = h.scopes.Resolve(r.Context(), req.Scope); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	// NOTE(toby3d): me is just a hint, owner can choose any of their
+	// identities on the consent page if hint is not owned outright.
+	me, err := h.accounts.Choose(r.Context(), h.config.IndieAuth.Username, req.hint())
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		web.WriteTemplate(w, &web.ErrorPage{
-			BaseOf: baseOf,
-			Error:  err,
-		})
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
 
 		return
 	}
 
-	if !client.ValidateRedirectURI(req.RedirectURI.URL) {
-		w.WriteHeader(http.StatusBadRequest)
-		web.WriteTemplate(w, &web.ErrorPage{
-			BaseOf: baseOf,
-			Error: domain.NewError(domain.ErrorCodeInvalidClient, "requested redirect_uri is not"+
-				" registered on client_id side", ""),
-		})
+	// NOTE(toby3d): policy is evaluated again with the identity chosen on
+	// the consent page, so rules matched by me are enforced anyway.
+	decision, status, err := h.evaluate(r, domain.PolicyRequest{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI.URL,
+		Me:          me,
+		Scope:       req.Scope,
+	}, req.CodeChallenge)
+	if err != nil {
+		h.writeAuthorizationError(w, r, status, target, err)
+
+		return
+	}
+
+	req.Scope = decision.Scope
+	sensitive, steppedUp := h.stepUp(r, req.Scope)
+
+	if req.Prompt.Has(domain.PromptNone) {
+		h.handleSilentAuthorize(w, r, req, report, me, decision, target)
+
+		return
+	}
+
+	owner, err := h.accounts.Get(r.Context(), h.config.IndieAuth.Username)
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
+
+		return
+	}
+
+	// NOTE(toby3d): browsers remember Basic credentials, so the owner
+	// enters the password on the consent page again if the client requires
+	// the active authentication.
+	reauthenticate := h.reauthenticate(r, req)
+
+	consentToken, err := h.consentToken(req.ClientID, req.RedirectURI.URL, consentRequest{
+		RequestURI:     req.RequestURI,
+		Scope:          req.Scope,
+		Reauthenticate: reauthenticate,
+	})
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
 
 		return
 	}
 
+	// NOTE(toby3d): sensitive scopes are shown in the distinct section,
+	// so the owner cannot overlook them among the harmless ones.
+	scopes := make([]domain.ScopeDefinition, 0, len(req.Scope))
+	sensitiveScopes := make([]domain.ScopeDefinition, 0, len(sensitive))
+
+	for _, def := range h.scopes.Describe(r.Context(), req.Scope) {
+		if sensitive.Has(def.Scope) {
+			sensitiveScopes = append(sensitiveScopes, def)
+		} else {
+			scopes = append(scopes, def)
+		}
+	}
+
 	csrf, _ := r.Context().Value(middleware.DefaultCSRFConfig.ContextKey).([]byte)
-	web.WriteTemplate(w, &web.AuthorizePage{
-		BaseOf:              baseOf,
+	h.renderer.Render(w, &web.AuthorizePage{
+		BaseOf:              h.baseOf(r),
 		CSRF:                csrf,
-		Scope:               req.Scope,
-		Client:              client,
-		Me:                  &req.Me,
+		ConsentToken:        consentToken,
+		Scope:               scopes,
+		Sensitive:           sensitiveScopes,
+		Client:              report.Client,
+		Warnings:            report.Warnings,
+		Me:                  me,
+		Identities:          owner.Identities,
 		RedirectURI:         &req.RedirectURI,
 		CodeChallengeMethod: req.CodeChallengeMethod,
 		ResponseType:        req.ResponseType,
+		ResponseMode:        req.ResponseMode,
 		CodeChallenge:       req.CodeChallenge,
 		State:               req.State,
+		Nonce:               req.Nonce,
+		Resource:            req.Resource,
+		SecondFactor:        decision.RequireSecondFactor(),
+		StepUp:              len(sensitive) > 0 && !steppedUp,
+		Reauthenticate:      reauthenticate,
 		Providers:           make([]*domain.Provider, 0), // TODO(toby3d)
 	})
 }
 
+// bindAuthorization binds parameters of the authorization request from the
+// query, from the request object or, if request_uri of the pushed
+// authorization request is provided, from the request pushed before.
+func (h *Handler) bindAuthorization(r *http.Request, req *AuthAuthorizationRequest) error {
+	query := r.URL.Query()
+
+	if !strings.HasPrefix(query.Get("request_uri"), auth.RequestURIPrefix) {
+		if h.config.PAR.Required {
+			return auth.ErrPushRequired
+		}
+
+		if query.Has("request") || query.Has("request_uri") {
+			return h.bindRequestObject(r, req)
+		}
+
+		return req.bind(r)
+	}
+
+	ref := new(AuthPushedAuthorizationRequest)
+	if err := ref.bind(r); err != nil {
+		return err
+	}
+
+	pushed, err := h.useCase.Pull(r.Context(), ref.ClientID, ref.RequestURI)
+	if err != nil {
+		return fmt.Errorf("cannot pull pushed authorization request: %w", err)
+	}
+
+	req.populate(pushed)
+	req.RequestURI = ref.RequestURI
+
+	return nil
+}
+
+// bindRequestObject binds parameters of the authorization request from the
+// signed request object. Signed parameters take precedence over the query
+// ones, see RFC 9101 section 6.3.
+func (h *Handler) bindRequestObject(r *http.Request, req *AuthAuthorizationRequest) error {
+	ref := new(AuthRequestObjectRequest)
+	if err := ref.bind(r); err != nil {
+		return err
+	}
+
+	opts := client.RequestObjectOptions{
+		ClientID:   ref.ClientID,
+		Request:    ref.Request,
+		RequestURI: nil,
+		Audience: []string{
+			h.config.Server.GetRootURL(),
+			strings.TrimSuffix(h.config.Server.GetRootURL(), "/"),
+		},
+	}
+
+	if ref.Request == "" {
+		u, err := url.Parse(ref.RequestURI)
+		if err != nil {
+			return fmt.Errorf("%w: %w", client.ErrRequestObjectNotExist, err)
+		}
+
+		opts.RequestURI = u
+	}
+
+	claims, err := h.clients.RequestObject(r.Context(), opts)
+	if err != nil {
+		return fmt.Errorf("cannot verify request object: %w", err)
+	}
+
+	query := r.URL.Query()
+	query.Del("request")
+	query.Del("request_uri")
+
+	for key, val := range claims {
+		query[key] = requestObjectValues(val)
+	}
+
+	return req.bindValues(query)
+}
+
 func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
@@ -181,57 +456,652 @@ func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set(common.HeaderAccessControlAllowOrigin, h.config.Server.Domain)
-	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
-
-	encoder := json.NewEncoder(w)
+	w.Header().Set(common.HeaderAccessControlAllowOrigin, h.config.Server.GetOrigin())
+	w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
 
 	req := NewAuthVerifyRequest()
-	if err := req.bind(r); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	bindErr := req.bind(r)
 
-		_ = encoder.Encode(err)
+	if req.ClientID.String() == "" || req.RedirectURI.URL == nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, nil, bindErr)
+
+		return
+	}
+
+	// NOTE(toby3d): verification form can be crafted by anyone, so
+	// redirect URI must be validated again before any redirect.
+	profile := h.config.Security.GetProfile()
+
+	if _, err := h.consents.Assess(r.Context(), consent.AssessOptions{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI.URL,
+		Exact:       profile.RequireExactRedirectURI(),
+	}); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, nil, err)
+
+		return
+	}
+
+	target := &authorizationRedirect{
+		RedirectURI:  req.RedirectURI.URL,
+		ClientID:     req.ClientID,
+		ResponseMode: req.ResponseMode,
+		State:        req.State,
+	}
+
+	if bindErr != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, bindErr)
 
 		return
 	}
 
 	if strings.EqualFold(req.Authorize, "deny") {
-		domain.NewError(domain.ErrorCodeAccessDenied, "user deny authorization request", "", req.State).
-			SetReirectURI(req.RedirectURI.URL)
-		http.Redirect(w, r, req.RedirectURI.String(), http.StatusFound)
+		h.writeAuthorizationError(w, r, http.StatusForbidden, target,
+			domain.NewError(domain.ErrorCodeAccessDenied, "user deny authorization request", ""))
+
+		return
+	}
+
+	if err := profile.ValidateCodeChallenge(req.CodeChallengeMethod, req.CodeChallenge); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	// NOTE(toby3d): owner can uncheck any of requested scopes, but not
+	// the scopes implied by the checked ones.
+	requested, err := h.requested(req)
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	if requested.Reauthenticate && subtle.ConstantTimeCompare([]byte(req.Password),
+		[]byte(h.config.IndieAuth.Password)) != 1 {
+		h.writeAuthorizationError(w, r, http.StatusUnauthorized, target, auth.ErrLoginRequired)
+
+		return
+	}
+
+	for _, s := range req.Scope {
+		if requested.Scope.Has(s) {
+			continue
+		}
+
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, domain.NewError(
+			domain.ErrorCodeInvalidScope, "scope is not requested by the client: "+s.String(),
+			"https://indieauth.net/source/#authorization-request"))
+
+		return
+	}
+
+	scopes, err := h.scopes.Resolve(r.Context(), req.Scope)
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	me, err := h.accounts.Choose(r.Context(), h.config.IndieAuth.Username, &req.Me)
+	if err == nil && me == nil {
+		err = account.ErrIdentity
+	}
+
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, err)
+
+		return
+	}
+
+	decision, status, err := h.evaluate(r, domain.PolicyRequest{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI.URL,
+		Me:          me,
+		Scope:       scopes,
+	}, req.CodeChallenge)
+	if err != nil {
+		h.writeAuthorizationError(w, r, status, target, err)
+
+		return
+	}
+
+	authTime := h.clock.Now().Truncate(time.Second)
+	acr, amr := auth.ACRPassword, []string{auth.AMRPassword}
+	sensitive, steppedUp := h.stepUp(r, decision.Scope)
+
+	switch {
+	case decision.RequireSecondFactor(), len(sensitive) > 0 && !steppedUp:
+		if !totp.Validate(h.config.IndieAuth.TOTPSecret, strings.TrimSpace(req.OTP), authTime) {
+			err = auth.ErrStepUpRequired
+			if decision.RequireSecondFactor() {
+				err = policy.ErrSecondFactorRequired
+			}
+
+			h.writeAuthorizationError(w, r, http.StatusForbidden, target, err)
+
+			return
+		}
+
+		if err = h.setSteppedUp(w, authTime); err != nil {
+			h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
+
+			return
+		}
+
+		acr, amr = auth.ACRSecondFactor, []string{auth.AMRPassword, auth.AMROneTimePassword}
+	case steppedUp:
+		// NOTE(toby3d): second factor is confirmed recently, so it is
+		// not asked again until step-up authentication is expired.
+		acr, amr = auth.ACRSecondFactor, []string{auth.AMRPassword, auth.AMROneTimePassword}
+	}
+
+	code, err := h.useCase.Generate(r.Context(), auth.GenerateOptions{
+		AuthTime:            authTime,
+		ClientID:            req.ClientID,
+		Me:                  *me,
+		RedirectURI:         req.RedirectURI.URL,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Scope:               decision.Scope,
+		Resource:            req.Resource,
+		CodeChallenge:       req.CodeChallenge,
+		Nonce:               req.Nonce,
+		ACR:                 acr,
+		AMR:                 amr,
+		GrantExpiry:         req.GrantExpiry,
+		RequestURI:          requested.RequestURI,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidRequestURI) {
+			status = http.StatusBadRequest
+		}
+
+		h.writeAuthorizationError(w, r, status, target, err)
+
+		return
+	}
+
+	if err = h.consents.Grant(r.Context(), domain.Consent{
+		CreatedAt: h.clock.Now(),
+		ClientID:  req.ClientID,
+		Me:        *me,
+		Scope:     decision.Scope,
+	}); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
+
+		return
+	}
+
+	// NOTE(toby3d): owner is authenticated by password, so next requests
+	// with prompt=none can be authorized without interaction.
+	if err = h.setAuthenticated(w, authTime); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
+
+		return
+	}
+
+	if err = h.respond(w, r, *target, map[string]string{"code": code}); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// handleSilentAuthorize responds to the authorization request with prompt=none
+// without any interaction with the owner: code is issued immediately only if
+// the owner is already authenticated and has authorized the client before.
+func (h *Handler) handleSilentAuthorize(w http.ResponseWriter, r *http.Request, req *AuthAuthorizationRequest,
+	report *domain.ConsentReport, me *domain.Me, decision *domain.PolicyDecision, target *authorizationRedirect,
+) {
+	authTime, ok := h.authenticated(r)
+	if maxAge, hasMaxAge, _ := req.maxAge(); ok && hasMaxAge && h.clock.Now().Sub(authTime) > maxAge {
+		ok = false
+	}
+
+	if !ok {
+		h.writeAuthorizationError(w, r, http.StatusUnauthorized, target, domain.NewError(
+			domain.ErrorCodeLoginRequired, "owner is not authenticated",
+			"https://openid.net/specs/openid-connect-core-1_0.html#AuthError"))
+
+		return
+	}
+
+	if me == nil {
+		h.writeAuthorizationError(w, r, http.StatusBadRequest, target, domain.NewError(
+			domain.ErrorCodeInteractionRequired, "owner must choose one of identities",
+			"https://openid.net/specs/openid-connect-core-1_0.html#AuthError"))
+
+		return
+	}
+
+	// NOTE(toby3d): any risk of the request must be shown to the owner on
+	// the consent page, including the client never authorized before.
+	if len(report.Warnings) > 0 {
+		h.writeAuthorizationError(w, r, http.StatusForbidden, target, domain.NewError(
+			domain.ErrorCodeConsentRequired, "client must be authorized by owner on the consent page",
+			"https://openid.net/specs/openid-connect-core-1_0.html#AuthError"))
+
+		return
+	}
+
+	// NOTE(toby3d): client cannot silently get more than the owner has
+	// granted to it on behalf of the same identity before.
+	covered, err := h.consents.Covers(r.Context(), req.ClientID, *me, req.Scope)
+	if err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
+
+		return
+	}
+
+	if !covered {
+		h.writeAuthorizationError(w, r, http.StatusForbidden, target, domain.NewError(
+			domain.ErrorCodeConsentRequired, "requested scopes or identity must be authorized by owner on the "+
+				"consent page", "https://openid.net/specs/openid-connect-core-1_0.html#AuthError"))
+
+		return
+	}
+
+	if decision.RequireSecondFactor() {
+		h.writeAuthorizationError(w, r, http.StatusForbidden, target, policy.ErrSecondFactorRequired)
+
+		return
+	}
+
+	acr, amr := auth.ACRPassword, []string{auth.AMRPassword}
+
+	if sensitive, steppedUp := h.stepUp(r, req.Scope); steppedUp {
+		acr, amr = auth.ACRSecondFactor, []string{auth.AMRPassword, auth.AMROneTimePassword}
+	} else if len(sensitive) > 0 {
+		h.writeAuthorizationError(w, r, http.StatusForbidden, target, auth.ErrStepUpRequired)
 
 		return
 	}
 
 	code, err := h.useCase.Generate(r.Context(), auth.GenerateOptions{
+		AuthTime:            authTime,
 		ClientID:            req.ClientID,
-		Me:                  req.Me,
+		Me:                  *me,
 		RedirectURI:         req.RedirectURI.URL,
 		CodeChallengeMethod: req.CodeChallengeMethod,
 		Scope:               req.Scope,
+		Resource:            req.Resource,
 		CodeChallenge:       req.CodeChallenge,
+		Nonce:               req.Nonce,
+		ACR:                 acr,
+		AMR:                 amr,
+		RequestURI:          req.RequestURI,
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidRequestURI) {
+			status = http.StatusBadRequest
+		}
 
-		_ = encoder.Encode(err)
+		h.writeAuthorizationError(w, r, status, target, err)
+
+		return
+	}
+
+	if err = h.setAuthenticated(w, authTime); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, target, err)
 
 		return
 	}
 
-	q := req.RedirectURI.Query()
+	if err = h.respond(w, r, *target, map[string]string{"code": code}); err != nil {
+		h.writeAuthorizationError(w, r, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// evaluate evaluates policy for the authorization request and returns
+// decision or error with the HTTP status code of the response.
+func (h *Handler) evaluate(r *http.Request, req domain.PolicyRequest, codeChallenge string,
+) (*domain.PolicyDecision, int, error) {
+	decision, err := h.policies.Evaluate(r.Context(), req)
+	if err != nil {
+		return nil, http.StatusForbidden, fmt.Errorf("cannot evaluate policy: %w", err)
+	}
+
+	if decision.RequirePKCE() && codeChallenge == "" {
+		return nil, http.StatusBadRequest, policy.ErrPKCERequired
+	}
+
+	return decision, http.StatusOK, nil
+}
+
+// writeAuthorizationError responds with the error of the authorization
+// request. Error is redirected back to the client only if the redirect URI is
+// validated, otherwise it is rendered on the page to the owner, see RFC 6749
+// section 4.1.2.1.
+func (h *Handler) writeAuthorizationError(w http.ResponseWriter, r *http.Request, status int,
+	target *authorizationRedirect, err error,
+) {
+	if target != nil {
+		var e *domain.Error
+		if !errors.As(err, &e) {
+			// NOTE(toby3d): do not expose internal errors to the
+			// client.
+			e = domain.NewError(domain.ErrorCodeServerError, "", "")
+		}
+
+		if err = h.respond(w, r, *target, e.Params()); err == nil {
+			return
+		}
+
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
+	w.WriteHeader(status)
+	h.renderer.Render(w, &web.ErrorPage{
+		BaseOf: h.baseOf(r),
+		Error:  err,
+	})
+}
+
+// respond returns parameters of the authorization response to the client by
+// requested response mode. State of the request and issuer identifier are
+// always included, see RFC 9207 section 2.
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, target authorizationRedirect,
+	params map[string]string,
+) error {
+	params["iss"] = h.config.Server.GetRootURL()
+
+	if target.State != "" {
+		params["state"] = target.State
+	}
+
+	if target.ResponseMode.IsJWT() {
+		response, err := h.signResponse(target.ClientID, params)
+		if err != nil {
+			return err
+		}
+
+		params = map[string]string{"response": response}
+	}
+
+	u := *target.RedirectURI
+
+	switch target.ResponseMode.Transport() {
+	default:
+		q := u.Query()
+
+		for key, val := range params {
+			q.Set(key, val)
+		}
+
+		u.RawQuery = q.Encode()
+
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	case domain.ResponseModeFragment:
+		f := make(url.Values)
 
-	for key, val := range map[string]string{
-		"code":  code,
-		"iss":   h.config.Server.GetRootURL(),
-		"state": req.State,
+		for key, val := range params {
+			f.Set(key, val)
+		}
+
+		u.Fragment, u.RawFragment = "", ""
+
+		http.Redirect(w, r, u.String()+"#"+f.Encode(), http.StatusFound)
+	case domain.ResponseModeFormPost:
+		w.Header().Set(common.HeaderCacheControl, "no-store")
+		w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
+		h.renderer.Render(w, &web.FormPostPage{
+			BaseOf: h.baseOf(r),
+			Action: u.String(),
+			Params: params,
+		})
+	}
+
+	return nil
+}
+
+// signResponse wraps parameters of the authorization response in the JWT
+// signed by the server key, see JARM section 2.1.
+func (h *Handler) signResponse(cid domain.ClientID, params map[string]string) (string, error) {
+	now := h.clock.Now().Truncate(time.Second)
+	tkn := jwt.New()
+
+	for key, val := range params {
+		if err := tkn.Set(key, val); err != nil {
+			return "", fmt.Errorf("cannot set authorization response parameter: %w", err)
+		}
+	}
+
+	for key, val := range map[string]any{
+		jwt.AudienceKey:   cid.String(),
+		jwt.ExpirationKey: now.Add(ResponseExpiry),
+		jwt.IssuedAtKey:   now,
+		jwt.IssuerKey:     h.config.Server.GetRootURL(),
+	} {
+		if err := tkn.Set(key, val); err != nil {
+			return "", fmt.Errorf("cannot set authorization response claim: %w", err)
+		}
+	}
+
+	if h.key == nil {
+		return "", domain.NewError(domain.ErrorCodeServerError, "authorization response signing key is not set", "")
+	}
+
+	response, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(h.key.Algorithm().String()), h.key))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign authorization response: %w", err)
+	}
+
+	return string(response), nil
+}
+
+// setAuthenticated remembers the time when the owner was authenticated in the
+// signed cookie, so the following requests can be authorized silently.
+func (h *Handler) setAuthenticated(w http.ResponseWriter, authTime time.Time) error {
+	return h.setAuthenticationCookie(w, SessionCookieName, authTime, h.config.IndieAuth.SessionExpiry, nil)
+}
+
+// setSteppedUp remembers the time when the owner has confirmed the second
+// factor in the signed cookie, so sensitive scopes can be granted without
+// confirmation until step-up authentication is expired.
+func (h *Handler) setSteppedUp(w http.ResponseWriter, authTime time.Time) error {
+	return h.setAuthenticationCookie(w, StepUpCookieName, authTime, h.config.StepUp.MaxAge,
+		map[string]any{"acr": auth.ACRSecondFactor})
+}
+
+func (h *Handler) setAuthenticationCookie(w http.ResponseWriter, name string, authTime time.Time,
+	expiry time.Duration, claims map[string]any,
+) error {
+	tkn := jwt.New()
+
+	for key, val := range map[string]any{
+		jwt.ExpirationKey: authTime.Add(expiry),
+		jwt.IssuedAtKey:   authTime,
+		jwt.IssuerKey:     h.config.Server.GetRootURL(),
+		jwt.SubjectKey:    h.config.IndieAuth.Username,
 	} {
-		q.Set(key, val)
+		if err := tkn.Set(key, val); err != nil {
+			return fmt.Errorf("cannot set authentication claim: %w", err)
+		}
+	}
+
+	for key, val := range claims {
+		if err := tkn.Set(key, val); err != nil {
+			return fmt.Errorf("cannot set authentication claim: %w", err)
+		}
+	}
+
+	session, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(h.config.JWT.Algorithm),
+		[]byte(h.config.JWT.Secret)))
+	if err != nil {
+		return fmt.Errorf("cannot sign authentication: %w", err)
+	}
+
+	//nolint:exhaustivestruct
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    string(session),
+		Path:     h.config.Server.GetPath("/authorize"),
+		Domain:   h.config.Server.GetHostname(),
+		MaxAge:   int(expiry.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		// NOTE(toby3d): cookie must be sent with the authorization
+		// request navigated from the client site.
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// consentToken signs the authorization request shown on the consent page, so
+// its verification cannot grant more than it was requested and cannot skip
+// the required reauthentication.
+func (h *Handler) consentToken(clientID domain.ClientID, redirectURI *url.URL, req consentRequest,
+) (string, error) {
+	now := h.clock.Now()
+	tkn := jwt.New()
+
+	for key, val := range map[string]any{
+		jwt.AudienceKey:   h.config.Server.GetRootURL() + "authorize/verify",
+		jwt.ExpirationKey: now.Add(ConsentExpiry),
+		jwt.IssuedAtKey:   now,
+		jwt.IssuerKey:     h.config.Server.GetRootURL(),
+		jwt.SubjectKey:    clientID.String(),
+		"redirect_uri":    redirectURI.String(),
+		"scope":           req.Scope.String(),
+		"reauthenticate":  req.Reauthenticate,
+		"request_uri":     req.RequestURI,
+	} {
+		if err := tkn.Set(key, val); err != nil {
+			return "", fmt.Errorf("cannot set consent claim: %w", err)
+		}
+	}
+
+	out, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(h.config.JWT.Algorithm),
+		[]byte(h.config.JWT.Secret)))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign consent: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// requested returns the original authorization request signed on the consent
+// page.
+func (h *Handler) requested(req *AuthVerifyRequest) (*consentRequest, error) {
+	errConsent := domain.NewError(domain.ErrorCodeInvalidRequest, "consent page is expired or forged",
+		"https://indieauth.net/source/#authorization-request")
+
+	tkn, err := jwt.ParseString(req.ConsentToken, jwt.WithKey(jwa.SignatureAlgorithm(h.config.JWT.Algorithm),
+		[]byte(h.config.JWT.Secret)), jwt.WithValidate(true), jwt.WithIssuer(h.config.Server.GetRootURL()),
+		jwt.WithAudience(h.config.Server.GetRootURL()+"authorize/verify"),
+		jwt.WithSubject(req.ClientID.String()), jwt.WithClock(h.clock))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConsent, err)
+	}
+
+	if redirectURI, _ := tkn.PrivateClaims()["redirect_uri"].(string); redirectURI != req.RedirectURI.String() {
+		return nil, errConsent
+	}
+
+	out := &consentRequest{Scope: make(domain.Scopes, 0)}
+	out.Reauthenticate, _ = tkn.PrivateClaims()["reauthenticate"].(bool)
+	out.RequestURI, _ = tkn.PrivateClaims()["request_uri"].(string)
+
+	// NOTE(toby3d): scope claim is decoded as is only if it's not
+	// registered as a custom field by the token use case.
+	switch scope := tkn.PrivateClaims()["scope"].(type) {
+	case domain.Scopes:
+		out.Scope = scope
+	case string:
+		if err = out.Scope.UnmarshalForm([]byte(scope)); err != nil {
+			return nil, fmt.Errorf("%w: %w", errConsent, err)
+		}
+	}
+
+	return out, nil
+}
+
+// reauthenticate reports whether the owner must be actively authenticated
+// again by the authorization request: login prompt is requested or the last
+// authentication is older than requested maximum age.
+func (h *Handler) reauthenticate(r *http.Request, req *AuthAuthorizationRequest) bool {
+	if req.Prompt.Has(domain.PromptLogin) {
+		return true
+	}
+
+	maxAge, hasMaxAge, _ := req.maxAge()
+	if !hasMaxAge {
+		return false
+	}
+
+	authTime, ok := h.authenticated(r)
+
+	return !ok || h.clock.Now().Sub(authTime) > maxAge
+}
+
+// authenticated returns the time when the owner was authenticated, if the
+// authentication is not expired yet.
+func (h *Handler) authenticated(r *http.Request) (time.Time, bool) {
+	return h.authenticationCookie(r, SessionCookieName)
+}
+
+// steppedUp returns the time when the owner has confirmed the second factor,
+// if the step-up authentication is not expired yet.
+func (h *Handler) steppedUp(r *http.Request) (time.Time, bool) {
+	// NOTE(toby3d): both cookies are signed by the same key, so the
+	// session one must not be accepted as the step-up one.
+	return h.authenticationCookie(r, StepUpCookieName, jwt.WithClaimValue("acr", auth.ACRSecondFactor))
+}
+
+func (h *Handler) authenticationCookie(r *http.Request, name string, opts ...jwt.ParseOption) (time.Time, bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return time.Time{}, false
 	}
 
-	req.RedirectURI.RawQuery = q.Encode()
+	tkn, err := jwt.ParseString(cookie.Value, append([]jwt.ParseOption{
+		jwt.WithKey(jwa.SignatureAlgorithm(h.config.JWT.Algorithm), []byte(h.config.JWT.Secret)),
+		jwt.WithValidate(true),
+		jwt.WithClock(h.clock),
+		jwt.WithIssuer(h.config.Server.GetRootURL()),
+		jwt.WithSubject(h.config.IndieAuth.Username),
+	}, opts...)...)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return tkn.IssuedAt(), true
+}
+
+// stepUp returns provided scopes which require step-up authentication and
+// reports whether the owner has confirmed the second factor recently enough to
+// grant them without confirmation.
+func (h *Handler) stepUp(r *http.Request, scopes domain.Scopes) (domain.Scopes, bool) {
+	_, ok := h.steppedUp(r)
+	listed := h.config.StepUp.Sensitive(scopes)
+	out := make(domain.Scopes, 0, len(listed))
 
-	http.Redirect(w, r, req.RedirectURI.String(), http.StatusFound)
+	// NOTE(toby3d): scopes which can destroy the content of the owner
+	// require step-up by their definition, if the owner has the second
+	// factor at all.
+	for _, def := range h.scopes.Describe(r.Context(), scopes) {
+		if listed.Has(def.Scope) || (h.config.IndieAuth.TOTPSecret != "" &&
+			def.Sensitivity.IsAtLeast(domain.ScopeSensitivityHigh)) {
+			out = append(out, def.Scope)
+		}
+	}
+
+	return out, ok
+}
+
+// baseOf returns base of the pages localized by request preferences.
+func (h *Handler) baseOf(r *http.Request) web.BaseOf {
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get(common.HeaderAcceptLanguage))
+	tag, _, _ := h.matcher.Match(tags...)
+
+	return web.BaseOf{
+		Config:   &h.config,
+		Images:   h.images,
+		Language: tag,
+		Printer:  message.NewPrinter(tag),
+	}
 }
 
 func (h *Handler) handleExchange(w http.ResponseWriter, r *http.Request) {
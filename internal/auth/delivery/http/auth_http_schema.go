@@ -3,7 +3,12 @@ package http
 import (
 	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/form"
@@ -18,8 +23,9 @@ type (
 		// redirected to after approving the request.
 		RedirectURI domain.URL `form:"redirect_uri"`
 
-		// The URL that the user entered.
-		Me domain.Me `form:"me"`
+		// The URL that the user entered. It's optional and used as a
+		// hint of identity which owner wants to use.
+		Me domain.Me `form:"me,omitempty"`
 
 		// The hashing method used to calculate the code challenge.
 		CodeChallengeMethod domain.CodeChallengeMethod `form:"code_challenge_method,omitempty"`
@@ -28,6 +34,10 @@ type (
 		// code should be returned as the response.
 		ResponseType domain.ResponseType `form:"response_type"` // code
 
+		// The mechanism to be used for returning parameters of the
+		// authorization response. Query is used if omitted.
+		ResponseMode domain.ResponseMode `form:"response_mode,omitempty"`
+
 		// A parameter set by the client which will be included when the
 		// user is redirected back to the client. This is used to
 		// prevent CSRF attacks. The authorization server MUST return
@@ -37,12 +47,60 @@ type (
 		// The code challenge as previously described.
 		CodeChallenge string `form:"code_challenge,omitempty"`
 
+		// A value set by the OpenID Connect client which will be
+		// included in the ID Token to mitigate replay attacks.
+		Nonce string `form:"nonce,omitempty"`
+
+		// Hint about the identity which owner wants to use, like me,
+		// but in terms of OpenID Connect.
+		LoginHint string `form:"login_hint"`
+
+		// Allowable elapsed time in seconds since the last time the
+		// owner was actively authenticated.
+		MaxAge string `form:"max_age"`
+
+		// A space-separated list of values that specifies whether the
+		// owner is prompted for reauthentication and consent.
+		Prompt domain.Prompts `form:"prompt"`
+
 		// A space-separated list of scopes the client is requesting,
 		// e.g. "profile", or "profile create". If the client omits this
 		// value, the authorization server MUST NOT issue an access
 		// token for this authorization code. Only the user's profile
 		// URL may be returned without any scope requested.
 		Scope domain.Scopes `form:"scope,omitempty"`
+
+		// URLs of the protected resources where requested access token
+		// is intended to be used, see RFC 8707.
+		Resource []string `form:"resource,omitempty"`
+
+		// The request URI of the pushed authorization request which
+		// parameters are used, consumed by the issued code.
+		RequestURI string `form:"-"`
+	}
+
+	// AuthRequestObjectRequest contains request object passed by value or
+	// by reference, see RFC 9101 section 5.
+	AuthRequestObjectRequest struct {
+		// The client URL.
+		ClientID domain.ClientID `form:"client_id"`
+
+		// The signed request object.
+		Request string `form:"request,omitempty"`
+
+		// The URL of the signed request object.
+		RequestURI string `form:"request_uri,omitempty"`
+	}
+
+	// AuthPushedAuthorizationRequest references parameters of the
+	// authorization request pushed by the client before.
+	AuthPushedAuthorizationRequest struct {
+		// The client URL.
+		ClientID domain.ClientID `form:"client_id"`
+
+		// The request URI returned by the pushed authorization request
+		// endpoint.
+		RequestURI string `form:"request_uri"`
 	}
 
 	AuthVerifyRequest struct {
@@ -51,11 +109,23 @@ type (
 		RedirectURI         domain.URL                 `form:"redirect_uri"`
 		CodeChallengeMethod domain.CodeChallengeMethod `form:"code_challenge_method,omitempty"`
 		ResponseType        domain.ResponseType        `form:"response_type"`
+		ResponseMode        domain.ResponseMode        `form:"response_mode,omitempty"`
 		Authorize           string                     `form:"authorize"`
 		CodeChallenge       string                     `form:"code_challenge,omitempty"`
 		State               string                     `form:"state"`
+		Nonce               string                     `form:"nonce,omitempty"`
 		Provider            string                     `form:"provider"`
 		Scope               domain.Scopes              `form:"scope[],omitempty"`
+		Resource            []string                   `form:"resource,omitempty"`
+		// ConsentToken contains the original authorization request
+		// signed on the consent page, so owner can only narrow it.
+		ConsentToken string             `form:"consent_token"`
+		GrantExpiry  domain.GrantExpiry `form:"grant_expiry"`
+		// OTP is the one-time password of the second factor.
+		OTP string `form:"otp,omitempty"`
+		// Password is entered again on the consent page if the client
+		// requires the active authentication of the owner.
+		Password string `form:"password,omitempty"`
 	}
 
 	AuthExchangeRequest struct {
@@ -97,18 +167,31 @@ func NewAuthAuthorizationRequest() *AuthAuthorizationRequest {
 		CodeChallenge:       "",
 		CodeChallengeMethod: domain.CodeChallengeMethodUnd,
 		Me:                  domain.Me{},
+		Nonce:               "",
+		LoginHint:           "",
+		MaxAge:              "",
+		Prompt:              make(domain.Prompts, 0),
 		RedirectURI:         domain.URL{},
+		Resource:            nil,
+		ResponseMode:        domain.ResponseModeUnd,
 		ResponseType:        domain.ResponseTypeUnd,
 		Scope:               make(domain.Scopes, 0),
 		State:               "",
 	}
 }
 
-//nolint:cyclop
 func (r *AuthAuthorizationRequest) bind(req *http.Request) error {
+	return r.bindValues(req.URL.Query())
+}
+
+// bindValues binds parameters of the authorization request from values of
+// the query or the request object.
+//
+//nolint:cyclop
+func (r *AuthAuthorizationRequest) bindValues(src url.Values) error {
 	indieAuthError := new(domain.Error)
 
-	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+	if err := form.Unmarshal([]byte(src.Encode()), r); err != nil {
 		if errors.As(err, indieAuthError) {
 			return indieAuthError
 		}
@@ -117,8 +200,131 @@ func (r *AuthAuthorizationRequest) bind(req *http.Request) error {
 			"https://indieauth.net/source/#authorization-request")
 	}
 
-	if r.ResponseType == domain.ResponseTypeID {
-		r.ResponseType = domain.ResponseTypeCode
+	if err := domain.ValidateResources(r.Resource); err != nil {
+		return err //nolint:wrapcheck // domain error
+	}
+
+	if _, _, err := r.maxAge(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// maxAge returns maximum authentication age requested by the client, if any.
+func (r *AuthAuthorizationRequest) maxAge() (time.Duration, bool, error) {
+	if r.MaxAge == "" {
+		return 0, false, nil
+	}
+
+	seconds, err := strconv.ParseUint(r.MaxAge, 10, 32)
+	if err != nil {
+		return 0, false, domain.NewError(domain.ErrorCodeInvalidRequest, "max_age must be a number of seconds",
+			"https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest")
+	}
+
+	return time.Duration(seconds) * time.Second, true, nil
+}
+
+// hint returns identity which owner wants to use, if any.
+func (r *AuthAuthorizationRequest) hint() *domain.Me {
+	if r.Me.URL() != nil || r.LoginHint == "" {
+		return &r.Me
+	}
+
+	// NOTE(toby3d): login_hint is not always a profile URL, like an email
+	// address, so any other value is ignored.
+	me, err := domain.ParseMe(r.LoginHint)
+	if err != nil {
+		return &r.Me
+	}
+
+	return me
+}
+
+// populate fills request by parameters of the pushed authorization request.
+func (r *AuthAuthorizationRequest) populate(src *domain.Session) {
+	r.ClientID = src.ClientID
+	r.RedirectURI = domain.URL{URL: src.RedirectURI}
+	r.Me = src.Me
+	r.CodeChallengeMethod = src.CodeChallengeMethod
+	r.CodeChallenge = src.CodeChallenge
+	r.ResponseType = domain.ResponseTypeCode
+	r.ResponseMode = src.ResponseMode
+	r.State = src.State
+	r.Nonce = src.Nonce
+	r.Scope = src.Scope
+	r.Resource = src.Resource
+	r.Prompt = src.Prompt
+	r.MaxAge = src.MaxAge
+	r.LoginHint = src.LoginHint
+}
+
+func (r *AuthRequestObjectRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc9101#section-5")
+	}
+
+	if (r.Request == "") == (r.RequestURI == "") {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "one of request or request_uri is required",
+			"https://www.rfc-editor.org/rfc/rfc9101#section-5")
+	}
+
+	return nil
+}
+
+// requestObjectValues converts claim of the request object into values of the
+// authorization request parameter.
+func requestObjectValues(src any) []string {
+	switch val := src.(type) {
+	case string:
+		return []string{val}
+	case bool:
+		return []string{strconv.FormatBool(val)}
+	case float64:
+		return []string{strconv.FormatFloat(val, 'f', -1, 64)}
+	case []string:
+		return val
+	case []any:
+		out := make([]string, 0, len(val))
+		for i := range val {
+			out = append(out, requestObjectValues(val[i])...)
+		}
+
+		return out
+	default:
+		// NOTE(toby3d): JSON objects, such as claims, are passed as is.
+		out, err := json.Marshal(val)
+		if err != nil {
+			return nil
+		}
+
+		return []string{string(out)}
+	}
+}
+
+func (r *AuthPushedAuthorizationRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc9126#section-4")
+	}
+
+	if r.RequestURI == "" {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "request_uri is required",
+			"https://www.rfc-editor.org/rfc/rfc9126#section-4")
 	}
 
 	return nil
@@ -130,9 +336,16 @@ func NewAuthVerifyRequest() *AuthVerifyRequest {
 		ClientID:            domain.ClientID{},
 		CodeChallenge:       "",
 		CodeChallengeMethod: domain.CodeChallengeMethodUnd,
+		ConsentToken:        "",
+		GrantExpiry:         domain.GrantExpiryUnd,
 		Me:                  domain.Me{},
+		Nonce:               "",
+		OTP:                 "",
+		Password:            "",
 		Provider:            "",
 		RedirectURI:         domain.URL{},
+		Resource:            nil,
+		ResponseMode:        domain.ResponseModeUnd,
 		ResponseType:        domain.ResponseTypeUnd,
 		Scope:               make(domain.Scopes, 0),
 		State:               "",
@@ -157,6 +370,13 @@ func (r *AuthVerifyRequest) bind(req *http.Request) error {
 			"https://indieauth.net/source/#authorization-request")
 	}
 
+	// NOTE(toby3d): each checked scope is submitted as a separate value,
+	// but form decodes only the first value of the key.
+	r.Scope = make(domain.Scopes, 0)
+	if err := r.Scope.UnmarshalForm([]byte(strings.Join(req.PostForm["scope[]"], " "))); err != nil {
+		return err //nolint:wrapcheck // domain error
+	}
+
 	// NOTE(toby3d): backwards-compatible support.
 	// See: https://aaronparecki.com/2020/12/03/1/indieauth-2020#response-type
 	if r.ResponseType == domain.ResponseTypeID {
@@ -165,6 +385,10 @@ func (r *AuthVerifyRequest) bind(req *http.Request) error {
 
 	r.Provider = strings.ToLower(r.Provider)
 
+	if err := domain.ValidateResources(r.Resource); err != nil {
+		return err //nolint:wrapcheck // domain error
+	}
+
 	if !strings.EqualFold(r.Authorize, "allow") && !strings.EqualFold(r.Authorize, "deny") {
 		return domain.NewError(domain.ErrorCodeInvalidRequest, "cannot validate verification request",
 			"https://indieauth.net/source/#authorization-request")
@@ -2,40 +2,70 @@ package http_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
+	"source.toby3d.me/toby3d/auth/internal/account"
+	accountrepo "source.toby3d.me/toby3d/auth/internal/account/repository/memory"
+	accountucase "source.toby3d.me/toby3d/auth/internal/account/usecase"
 	"source.toby3d.me/toby3d/auth/internal/auth"
 	delivery "source.toby3d.me/toby3d/auth/internal/auth/delivery/http"
 	ucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
 	"source.toby3d.me/toby3d/auth/internal/client"
+	clienthttprepo "source.toby3d.me/toby3d/auth/internal/client/repository/http"
 	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
 	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	consentrepo "source.toby3d.me/toby3d/auth/internal/consent/repository/memory"
+	consentucase "source.toby3d.me/toby3d/auth/internal/consent/usecase"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+	policyucase "source.toby3d.me/toby3d/auth/internal/policy/usecase"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
 	"source.toby3d.me/toby3d/auth/internal/session"
 	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/totp"
 	"source.toby3d.me/toby3d/auth/internal/user"
 	userrepo "source.toby3d.me/toby3d/auth/internal/user/repository/memory"
+	userucase "source.toby3d.me/toby3d/auth/internal/user/usecase"
 )
 
 type Dependencies struct {
-	authService   auth.UseCase
-	clients       client.Repository
-	clientService client.UseCase
-	matcher       language.Matcher
-	profiles      profile.Repository
-	sessions      session.Repository
-	users         user.Repository
-	config        *domain.Config
+	accounts       account.Repository
+	accountService account.UseCase
+	authService    auth.UseCase
+	clients        client.Repository
+	clientService  client.UseCase
+	consentService consent.UseCase
+	matcher        language.Matcher
+	profiles       profile.Repository
+	policyService  policy.UseCase
+	scopeService   scope.UseCase
+	sessions       session.Repository
+	users          user.Repository
+	config         *domain.Config
 }
 
 //nolint:funlen
@@ -45,6 +75,7 @@ func TestAuthorize(t *testing.T) {
 	deps := NewDependencies(t)
 	me := domain.TestMe(t, "https://user.example.net/")
 	user := domain.TestUser(t)
+	user.Issuer, _ = url.Parse(deps.config.Server.GetRootURL())
 	client := domain.TestClient(t)
 
 	if err := deps.clients.Create(context.Background(), *client); err != nil {
@@ -82,10 +113,13 @@ func TestAuthorize(t *testing.T) {
 
 	//nolint:exhaustivestruct
 	delivery.NewHandler(delivery.NewHandlerOptions{
-		Auth:    deps.authService,
-		Clients: deps.clientService,
-		Config:  *deps.config,
-		Matcher: deps.matcher,
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
 	}).ServeHTTP(w, req)
 
 	resp := w.Result()
@@ -99,9 +133,2105 @@ func TestAuthorize(t *testing.T) {
 		t.Errorf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, http.StatusOK)
 	}
 
-	expResult := `Authorize ` + client.Name
-	if result := string(body); !strings.Contains(result, expResult) {
-		t.Errorf("%s %s = %s, want %s", req.Method, u.String(), result, expResult)
+	for _, expResult := range []string{
+		`Authorize ` + client.Name,
+		`This client has never been authorized before.`,
+		`View your email address.`,
+		// NOTE(toby3d): URL of the image is emitted as is, without any
+		// surrounding whitespace.
+		`src="` + client.Logo.String() + `"`,
+	} {
+		if result := string(body); !strings.Contains(result, expResult) {
+			t.Errorf("%s %s = %s, want %s", req.Method, u.String(), result, expResult)
+		}
+	}
+}
+
+func TestAuthorize_Pushed(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.PAR.Required = true
+	me := domain.TestMe(t, "https://user.example.net/")
+	user := domain.TestUser(t)
+	user.Issuer, _ = url.Parse(deps.config.Server.GetRootURL())
+	client := domain.TestClient(t)
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.users.Create(context.Background(), *user); err != nil {
+		t.Fatal(err)
+	}
+
+	requestURI, err := deps.authService.Push(context.Background(), domain.Session{
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURI[0],
+		Me:                  *me,
+		CodeChallengeMethod: domain.CodeChallengeMethodS256,
+		CodeChallenge:       "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo",
+		State:               "1234567890",
+		Scope:               domain.Scopes{domain.ScopeProfile},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE(toby3d): prompt of the pushed request is applied as well as the
+	// one of the query.
+	loginURI, err := deps.authService.Push(context.Background(), domain.Session{
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURI[0],
+		Me:                  *me,
+		CodeChallengeMethod: domain.CodeChallengeMethodS256,
+		CodeChallenge:       "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo",
+		State:               "1234567890",
+		Scope:               domain.Scopes{domain.ScopeProfile},
+		Prompt:              domain.Prompts{domain.PromptLogin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		query       url.Values
+		expStatus   int
+		expPassword bool
+	}{
+		"pushed": {
+			query:     url.Values{"client_id": {client.ID.String()}, "request_uri": {requestURI}},
+			expStatus: http.StatusOK,
+		},
+		"pushed login prompt": {
+			query:       url.Values{"client_id": {client.ID.String()}, "request_uri": {loginURI}},
+			expStatus:   http.StatusOK,
+			expPassword: true,
+		},
+		"another client": {
+			query: url.Values{
+				"client_id":   {"https://another.example.org/"},
+				"request_uri": {requestURI},
+			},
+			expStatus: http.StatusBadRequest,
+		},
+		"not pushed": {
+			query: url.Values{
+				"client_id":     {client.ID.String()},
+				"me":            {me.String()},
+				"redirect_uri":  {client.RedirectURI[0].String()},
+				"response_type": {domain.ResponseTypeCode.String()},
+				"state":         {"1234567890"},
+			},
+			expStatus: http.StatusBadRequest,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/", RawQuery: tc.query.Encode()}
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, tc.expStatus)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := strings.Contains(string(body), `name="password"`); result != tc.expPassword {
+				t.Errorf("%s %s asks password = %t, want %t", req.Method, u.String(), result, tc.expPassword)
+			}
+		})
+	}
+}
+
+func TestAuthorize_PushedOnce(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	requestURI, err := deps.authService.Push(context.Background(), domain.Session{
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURI[0],
+		Me:                  *account.Identities[0],
+		CodeChallengeMethod: domain.CodeChallengeMethodS256,
+		CodeChallenge:       "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo",
+		State:               "1234567890",
+		Scope:               domain.Scopes{domain.ScopeProfile},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:exhaustivestruct
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
+	})
+
+	u := &url.URL{Scheme: "https", Host: "example.com", Path: "/", RawQuery: url.Values{
+		"client_id":   {client.ID.String()},
+		"request_uri": {requestURI},
+	}.Encode()}
+	req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, err := io.ReadAll(w.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := regexp.MustCompile(`name="consent_token"\s+value="([^"]+)"`).FindStringSubmatch(string(body))
+	if match == nil {
+		t.Fatalf("%s %s = %s, want consent token", req.Method, u, body)
+	}
+
+	form := url.Values{
+		"authorize":             {"allow"},
+		"client_id":             {client.ID.String()},
+		"code_challenge":        {"OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo"},
+		"code_challenge_method": {domain.CodeChallengeMethodS256.String()},
+		"consent_token":         {match[1]},
+		"me":                    {account.Identities[0].String()},
+		"provider":              {"direct"},
+		"redirect_uri":          {client.RedirectURI[0].String()},
+		"response_type":         {domain.ResponseTypeCode.String()},
+		"scope[]":               {"profile"},
+		"state":                 {"1234567890"},
+	}
+
+	// NOTE(toby3d): the second verification of the same consent page must
+	// not issue another code by the consumed request_uri.
+	for i, expError := range []string{"", domain.ErrorCodeInvalidRequestURI.String()} {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+			strings.NewReader(form.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		location, err := w.Result().Location()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := location.Query().Get("error"); result != expError {
+			t.Errorf("#%d %s %s redirects with error = %q, want %q", i, req.Method, req.RequestURI, result,
+				expError)
+		}
+	}
+
+	if _, err = deps.authService.Pull(context.Background(), client.ID, requestURI); !errors.Is(err,
+		auth.ErrInvalidRequestURI) {
+		t.Errorf("Pull(%s) = %v, want %v", requestURI, err, auth.ErrInvalidRequestURI)
+	}
+}
+
+func TestAuthorize_NotDelegated(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	user := domain.TestUser(t)
+	client := domain.TestClient(t)
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.users.Create(context.Background(), *user); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	q := u.Query()
+
+	for key, val := range map[string]string{
+		"client_id":             client.ID.String(),
+		"code_challenge":        "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo",
+		"code_challenge_method": domain.CodeChallengeMethodS256.String(),
+		"me":                    user.Me.String(),
+		"redirect_uri":          client.RedirectURI[0].String(),
+		"response_type":         domain.ResponseTypeCode.String(),
+		"state":                 "1234567890",
+	} {
+		q.Set(key, val)
+	}
+
+	u.RawQuery = q.Encode()
+
+	req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+	w := httptest.NewRecorder()
+
+	//nolint:exhaustivestruct
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
+	}).ServeHTTP(w, req)
+
+	// NOTE(toby3d): redirect URI is valid, so error is returned to the
+	// client instead of the owner.
+	resp := w.Result()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, http.StatusFound)
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, val := range map[string]string{
+		"error": domain.ErrorCodeAccessDenied.String(),
+		"iss":   deps.config.Server.GetRootURL(),
+		"state": "1234567890",
+	} {
+		if result := location.Query().Get(key); result != val {
+			t.Errorf("%s %s redirects with %s = %s, want %s", req.Method, u.String(), key, result, val)
+		}
+	}
+}
+
+func TestAuthorize_IdentityChooser(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		me         string
+		loginHint  string
+		expChecked int
+	}{
+		"omitted":    {me: "", expChecked: 0},
+		"foreign":    {me: "https://evil.example.com/", expChecked: 0},
+		"owned":      {me: account.Identities[1].String(), expChecked: 1},
+		"malformed":  {me: "not a profile URL", expChecked: 0},
+		"login hint": {loginHint: account.Identities[1].String(), expChecked: 1},
+		"email hint": {loginHint: "user@example.net", expChecked: 0},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     client.ID.String(),
+				"me":            tc.me,
+				"login_hint":    tc.loginHint,
+				"redirect_uri":  client.RedirectURI[0].String(),
+				"response_type": domain.ResponseTypeCode.String(),
+				"state":         "1234567890",
+			} {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, http.StatusOK)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := string(body)
+
+			for _, identity := range account.Identities {
+				if !strings.Contains(result, `value="`+identity.String()+`"`) {
+					t.Errorf("%s %s does not contain %s identity", req.Method, u.String(), identity)
+				}
+			}
+
+			// NOTE(toby3d): no scopes requested, so only identities
+			// can be checked.
+			if checked := strings.Count(result, "checked>"); checked != tc.expChecked {
+				t.Errorf("%s %s contains %d checked identities, want %d", req.Method, u.String(), checked,
+					tc.expChecked)
+			}
+		})
+	}
+}
+
+func TestAuthorize_Scopes(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		scope     string
+		expStatus int
+		expResult string
+	}{
+		"implied": {scope: "email", expStatus: http.StatusOK, expResult: `value="profile"`},
+		"unknown": {scope: "profile gallery", expStatus: http.StatusFound, expResult: "invalid_scope"},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     client.ID.String(),
+				"me":            account.Identities[0].String(),
+				"redirect_uri":  client.RedirectURI[0].String(),
+				"response_type": domain.ResponseTypeCode.String(),
+				"scope":         tc.scope,
+				"state":         "1234567890",
+			} {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, tc.expStatus)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := string(body) + resp.Header.Get(common.HeaderLocation)
+			if !strings.Contains(result, tc.expResult) {
+				t.Errorf("%s %s = %s, want %s", req.Method, u.String(), result, tc.expResult)
+			}
+		})
+	}
+}
+
+//nolint:funlen
+func TestAuthorize_PromptNone(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.StepUp.Scopes = []string{"delete"}
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+	client := domain.TestClient(t)
+	client.ID = *domain.TestClientID(t, "https://localhost/")
+	client.RedirectURI = []*url.URL{{Scheme: "https", Host: "localhost", Path: "/redirect"}}
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE(toby3d): another client has never been authorized before.
+	another := domain.TestClient(t)
+	another.RedirectURI = []*url.URL{{Scheme: "https", Host: "127.0.0.1", Path: "/callback"}}
+
+	if err := deps.clients.Create(context.Background(), *another); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.consentService.Grant(context.Background(), domain.Consent{
+		CreatedAt: time.Now().UTC(),
+		ClientID:  client.ID,
+		Me:        *account.Identities[0],
+		Scope:     domain.Scopes{domain.ScopeProfile, domain.ScopeDelete},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recently := time.Now().UTC().Add(-time.Minute)
+
+	for name, tc := range map[string]struct {
+		client   *domain.Client
+		me       *domain.Me
+		authTime *time.Time
+		stepUp   *time.Time
+		maxAge   string
+		scope    string
+		expError string
+	}{
+		"authorized":     {client: client, authTime: &recently, scope: "profile"},
+		"fresh enough":   {client: client, authTime: &recently, maxAge: "3600"},
+		"anonymous":      {client: client, expError: domain.ErrorCodeLoginRequired.String()},
+		"too old":        {client: client, authTime: &recently, maxAge: "30", expError: domain.ErrorCodeLoginRequired.String()},
+		"new client":     {client: another, authTime: &recently, expError: domain.ErrorCodeConsentRequired.String()},
+		"more scopes":    {client: client, authTime: &recently, scope: "profile create", expError: domain.ErrorCodeConsentRequired.String()},
+		"other identity": {client: client, me: account.Identities[1], authTime: &recently, expError: domain.ErrorCodeConsentRequired.String()},
+		"sensitive":      {client: client, authTime: &recently, scope: "delete", expError: domain.ErrorCodeInteractionRequired.String()},
+		"stepped up":     {client: client, authTime: &recently, stepUp: &recently, scope: "delete"},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			me := account.Identities[0]
+			if tc.me != nil {
+				me = tc.me
+			}
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     tc.client.ID.String(),
+				"me":            me.String(),
+				"redirect_uri":  tc.client.RedirectURI[0].String(),
+				"response_type": domain.ResponseTypeCode.String(),
+				"state":         "1234567890",
+				"prompt":        domain.PromptNone.String(),
+				"max_age":       tc.maxAge,
+				"scope":         tc.scope,
+			} {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			if tc.authTime != nil {
+				req.AddCookie(NewSessionCookie(t, deps.config, *tc.authTime))
+			}
+
+			if tc.stepUp != nil {
+				req.AddCookie(NewStepUpCookie(t, deps.config, *tc.stepUp))
+			}
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != http.StatusFound {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, http.StatusFound)
+			}
+
+			location, err := resp.Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Fatalf("%s %s redirects with error = %q, want %q", req.Method, u.String(), result,
+					tc.expError)
+			}
+
+			if tc.expError != "" {
+				return
+			}
+
+			code := location.Query().Get("code")
+
+			session, err := deps.sessions.GetAndDelete(context.Background(), code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !session.AuthTime.Equal(tc.authTime.Truncate(time.Second)) {
+				t.Errorf("%s %s issues code with auth_time %s, want %s", req.Method, u.String(),
+					session.AuthTime, *tc.authTime)
+			}
+		})
+	}
+}
+
+//nolint:funlen
+func TestAuthorize_RequestObject(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwk.FromRaw(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jwk.NewSet()
+	_ = set.AddKey(publicKey)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := domain.TestClient(t)
+	client.JWKSURI, _ = url.Parse(srv.URL + "/jwks.json")
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err = deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := jwt.NewBuilder().
+		Issuer(client.ID.String()).
+		Audience([]string{deps.config.Server.GetRootURL()}).
+		Expiration(time.Now().Add(time.Minute)).
+		Claim("client_id", client.ID.String()).
+		Claim("redirect_uri", client.RedirectURI[0].String()).
+		Claim("response_type", domain.ResponseTypeCode.String()).
+		Claim("state", "signed-state").
+		Claim("code_challenge", "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo").
+		Claim("code_challenge_method", domain.CodeChallengeMethodS256.String()).
+		Claim("resource", []string{"https://api.example.com/"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := jwt.Sign(tkn, jwt.WithKey(jwa.ES256, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		request   string
+		expStatus int
+	}{
+		"signed":   {request: string(signed), expStatus: http.StatusOK},
+		"tampered": {request: string(signed[:len(signed)-4]) + "AAAA", expStatus: http.StatusBadRequest},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     client.ID.String(),
+				"request":       tc.request,
+				"response_type": domain.ResponseTypeCode.String(),
+				"state":         "query-state",
+			} {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Clients: clientucase.NewClientUseCase(deps.clients, deps.clients,
+					clienthttprepo.NewHTTPKeySetRepository(srv.Client()), nil, nil, nil),
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u.String(), resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expStatus != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := string(body)
+
+			for _, expResult := range []string{
+				`value="signed-state"`,
+				`name="resource"`,
+				`value="https://api.example.com/"`,
+				`value="OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo"`,
+			} {
+				if !strings.Contains(result, expResult) {
+					t.Errorf("%s %s does not contain %s", req.Method, u.String(), expResult)
+				}
+			}
+
+			if strings.Contains(result, "query-state") {
+				t.Errorf("%s %s contains state from the query instead of the signed one", req.Method,
+					u.String())
+			}
+		})
+	}
+}
+
+//nolint:funlen
+func TestVerify_ResponseMode(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	key := domain.TestSigningKey(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		result    func(resp *http.Response, body string) url.Values
+		mode      string
+		expStatus int
+		expJWT    bool
+	}{
+		"default": {
+			expStatus: http.StatusFound,
+			result: func(resp *http.Response, _ string) url.Values {
+				u, _ := resp.Location()
+
+				return u.Query()
+			},
+		},
+		"fragment": {
+			mode:      domain.ResponseModeFragment.String(),
+			expStatus: http.StatusFound,
+			result: func(resp *http.Response, _ string) url.Values {
+				u, _ := resp.Location()
+				v, _ := url.ParseQuery(u.Fragment)
+
+				return v
+			},
+		},
+		"form_post": {
+			mode:      domain.ResponseModeFormPost.String(),
+			expStatus: http.StatusOK,
+			result: func(_ *http.Response, body string) url.Values {
+				v := make(url.Values)
+
+				for _, key := range []string{"code", "state", "iss", "response"} {
+					if strings.Contains(body, `name="`+key+`"`) {
+						v.Set(key, key)
+					}
+				}
+
+				return v
+			},
+		},
+		"query.jwt": {
+			mode:      domain.ResponseModeQueryJWT.String(),
+			expStatus: http.StatusFound,
+			expJWT:    true,
+			result: func(resp *http.Response, _ string) url.Values {
+				u, _ := resp.Location()
+
+				return u.Query()
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := make(url.Values)
+
+			for key, val := range map[string]string{
+				"authorize":     "allow",
+				"client_id":     client.ID.String(),
+				"consent_token": NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0], ""),
+				"me":            account.Identities[0].String(),
+				"provider":      "direct",
+				"redirect_uri":  client.RedirectURI[0].String(),
+				"response_mode": tc.mode,
+				"response_type": domain.ResponseTypeCode.String(),
+				"state":         "1234567890",
+			} {
+				if val != "" {
+					form.Set(key, val)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts:   deps.accountService,
+				Auth:       deps.authService,
+				Consents:   deps.consentService,
+				Config:     *deps.config,
+				Matcher:    deps.matcher,
+				Policies:   deps.policyService,
+				Scopes:     deps.scopeService,
+				SigningKey: key,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := tc.result(resp, string(body))
+
+			if !tc.expJWT {
+				for _, key := range []string{"code", "state", "iss"} {
+					if !result.Has(key) {
+						t.Errorf("%s %s response does not contain %s", req.Method, req.RequestURI, key)
+					}
+				}
+
+				return
+			}
+
+			if result.Has("code") {
+				t.Errorf("%s %s response contains code outside of the JWT", req.Method, req.RequestURI)
+			}
+
+			public, err := key.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// NOTE(toby3d): client verifies the response by the
+			// published key set of the server.
+			response, err := jwt.ParseString(result.Get("response"), jwt.WithKey(key.Algorithm(), public),
+				jwt.WithAudience(client.ID.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if state, _ := response.Get("state"); state != "1234567890" {
+				t.Errorf("%s %s response state = %v, want %s", req.Method, req.RequestURI, state,
+					"1234567890")
+			}
+
+			if code, _ := response.Get("code"); code == nil {
+				t.Errorf("%s %s response does not contain code", req.Method, req.RequestURI)
+			}
+		})
+	}
+}
+
+func TestAuthorize_ResponseModeJWT(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	u.RawQuery = url.Values{
+		"client_id":     []string{client.ID.String()},
+		"redirect_uri":  []string{client.RedirectURI[0].String()},
+		"response_mode": []string{domain.ResponseModeQueryJWT.String()},
+		"response_type": []string{domain.ResponseTypeCode.String()},
+		"state":         []string{"1234567890"},
+	}.Encode()
+
+	req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+	w := httptest.NewRecorder()
+
+	// NOTE(toby3d): without the published signing key the response
+	// cannot be verified by the client, so it's never wrapped in JWT.
+	//nolint:exhaustivestruct
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Clients:  deps.clientService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
+	}).ServeHTTP(w, req)
+
+	location, err := w.Result().Location()
+	if err != nil {
+		t.Fatalf("%s %s = %d, want redirect", req.Method, u, w.Result().StatusCode)
+	}
+
+	if q := location.Query(); q.Get("error") != domain.ErrorCodeInvalidRequest.String() || q.Has("response") {
+		t.Errorf("%s %s redirects to %s, want %s error", req.Method, u, location,
+			domain.ErrorCodeInvalidRequest)
+	}
+}
+
+//nolint:funlen
+func TestVerify_Scopes(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	consentToken := NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0], "create profile email")
+
+	for name, tc := range map[string]struct {
+		consentToken   string
+		scope          []string
+		grantExpiry    string
+		expError       string
+		expScope       domain.Scopes
+		expGrantExpiry domain.GrantExpiry
+	}{
+		"narrowed": {
+			consentToken: consentToken,
+			scope:        []string{"create"},
+			expScope:     domain.Scopes{domain.ScopeCreate},
+		},
+		"implied": {
+			consentToken: consentToken,
+			scope:        []string{"email"},
+			expScope:     domain.Scopes{domain.ScopeEmail, domain.ScopeProfile},
+		},
+		"forever": {
+			consentToken:   consentToken,
+			scope:          []string{"create"},
+			grantExpiry:    domain.GrantExpiryForever.String(),
+			expScope:       domain.Scopes{domain.ScopeCreate},
+			expGrantExpiry: domain.GrantExpiryForever,
+		},
+		"widened": {
+			consentToken: consentToken,
+			scope:        []string{"create", "delete"},
+			expError:     domain.ErrorCodeInvalidScope.String(),
+		},
+		"forged": {
+			consentToken: "not.a.token",
+			scope:        []string{"create"},
+			expError:     domain.ErrorCodeInvalidRequest.String(),
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := url.Values{
+				"authorize":     []string{"allow"},
+				"client_id":     []string{client.ID.String()},
+				"consent_token": []string{tc.consentToken},
+				"grant_expiry":  []string{tc.grantExpiry},
+				"me":            []string{account.Identities[0].String()},
+				"provider":      []string{"direct"},
+				"redirect_uri":  []string{client.RedirectURI[0].String()},
+				"response_type": []string{domain.ResponseTypeCode.String()},
+				"scope[]":       tc.scope,
+				"state":         []string{"1234567890"},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			location, err := w.Result().Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Fatalf("%s %s redirects with error = %q, want %q", req.Method, req.RequestURI, result,
+					tc.expError)
+			}
+
+			if tc.expError != "" {
+				return
+			}
+
+			session, err := deps.sessions.GetAndDelete(context.Background(), location.Query().Get("code"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(session.Scope, tc.expScope) {
+				t.Errorf("%s %s issues code with scope %s, want %s", req.Method, req.RequestURI,
+					session.Scope, tc.expScope)
+			}
+
+			if session.GrantExpiry != tc.expGrantExpiry {
+				t.Errorf("%s %s issues code with grant expiry %s, want %s", req.Method, req.RequestURI,
+					session.GrantExpiry, tc.expGrantExpiry)
+			}
+		})
+	}
+}
+
+func TestVerify_Session(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+	client := domain.TestClient(t)
+	client.ID = *domain.TestClientID(t, "https://localhost/")
+	client.RedirectURI = []*url.URL{{Scheme: "https", Host: "localhost", Path: "/redirect"}}
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:exhaustivestruct
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
+	})
+
+	form := url.Values{
+		"authorize":     []string{"allow"},
+		"client_id":     []string{client.ID.String()},
+		"consent_token": []string{NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0], "create")},
+		"me":            []string{account.Identities[0].String()},
+		"provider":      []string{"direct"},
+		"redirect_uri":  []string{client.RedirectURI[0].String()},
+		"response_type": []string{domain.ResponseTypeCode.String()},
+		"scope[]":       []string{"create"},
+		"state":         []string{"1234567890"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/verify", strings.NewReader(form.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var session *http.Cookie
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == delivery.SessionCookieName {
+			session = cookie
+		}
+	}
+
+	if session == nil {
+		t.Fatalf("%s %s = %d, want %s cookie", req.Method, req.RequestURI, w.Result().StatusCode,
+			delivery.SessionCookieName)
+	}
+
+	// NOTE(toby3d): the next request of the same client is authorized
+	// without interaction by the cookie of the consent.
+	u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	u.RawQuery = url.Values{
+		"client_id":     []string{client.ID.String()},
+		"me":            []string{account.Identities[0].String()},
+		"redirect_uri":  []string{client.RedirectURI[0].String()},
+		"response_type": []string{domain.ResponseTypeCode.String()},
+		"state":         []string{"1234567890"},
+		"prompt":        []string{domain.PromptNone.String()},
+		"scope":         []string{"create"},
+	}.Encode()
+
+	req = httptest.NewRequest(http.MethodGet, u.String(), nil)
+	req.AddCookie(&http.Cookie{Name: session.Name, Value: session.Value})
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	location, err := w.Result().Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if location.Query().Get("code") == "" {
+		t.Errorf("%s %s redirects to %s, want code", req.Method, u.String(), location)
+	}
+}
+
+func TestVerify_Policy(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.IndieAuth.TOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	deps.policyService = policyucase.NewPolicyUseCase(
+		domain.PolicyRule{
+			Name:   "block",
+			Action: domain.PolicyActionDeny,
+			Match:  domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeDelete}},
+		},
+		domain.PolicyRule{
+			Name:   "no-media",
+			Action: domain.PolicyActionAllow,
+			Match:  domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeMedia}},
+			Strip:  domain.Scopes{domain.ScopeMedia},
+		},
+		domain.PolicyRule{
+			Name:        "pkce",
+			Action:      domain.PolicyActionAllow,
+			Match:       domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeUpdate}},
+			RequirePKCE: true,
+		},
+		domain.PolicyRule{
+			Name:                "second-factor",
+			Action:              domain.PolicyActionAllow,
+			Match:               domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeCreate}},
+			RequireSecondFactor: true,
+		},
+	)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	consentToken := NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0],
+		"create delete media profile update")
+
+	otp, err := totp.Generate(deps.config.IndieAuth.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		otp      string
+		expError string
+		expACR   string
+		scope    []string
+		expScope domain.Scopes
+	}{
+		"denied": {
+			scope:    []string{"delete"},
+			expError: domain.ErrorCodeAccessDenied.String(),
+		},
+		"stripped": {
+			scope:    []string{"media", "profile"},
+			expScope: domain.Scopes{domain.ScopeProfile},
+			expACR:   auth.ACRPassword,
+		},
+		"without pkce": {
+			scope:    []string{"update"},
+			expError: domain.ErrorCodeInvalidRequest.String(),
+		},
+		"without second factor": {
+			scope:    []string{"create"},
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"invalid second factor": {
+			scope:    []string{"create"},
+			otp:      "000000",
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"second factor": {
+			scope:    []string{"create"},
+			otp:      otp,
+			expScope: domain.Scopes{domain.ScopeCreate},
+			expACR:   auth.ACRSecondFactor,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := url.Values{
+				"authorize":     []string{"allow"},
+				"client_id":     []string{client.ID.String()},
+				"consent_token": []string{consentToken},
+				"me":            []string{account.Identities[0].String()},
+				"otp":           []string{tc.otp},
+				"provider":      []string{"direct"},
+				"redirect_uri":  []string{client.RedirectURI[0].String()},
+				"response_type": []string{domain.ResponseTypeCode.String()},
+				"scope[]":       tc.scope,
+				"state":         []string{"1234567890"},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			location, err := w.Result().Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Fatalf("%s %s redirects with error = %q, want %q", req.Method, req.RequestURI, result,
+					tc.expError)
+			}
+
+			if tc.expError != "" {
+				return
+			}
+
+			session, err := deps.sessions.GetAndDelete(context.Background(), location.Query().Get("code"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(session.Scope, tc.expScope) {
+				t.Errorf("%s %s issues code with scope %s, want %s", req.Method, req.RequestURI,
+					session.Scope, tc.expScope)
+			}
+
+			if session.ACR != tc.expACR {
+				t.Errorf("%s %s issues code with acr %s, want %s", req.Method, req.RequestURI,
+					session.ACR, tc.expACR)
+			}
+		})
+	}
+}
+
+// TestVerify_StepUp checks that sensitive scopes are granted only after
+// recent confirmation of the second factor.
+//
+//nolint:funlen
+func TestVerify_StepUp(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.IndieAuth.TOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	// NOTE(toby3d): delete scope requires step-up by its high
+	// sensitivity, update one only by the configuration.
+	deps.config.StepUp.Scopes = []string{"update"}
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	consentToken := NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0], "delete update profile")
+
+	otp, err := totp.Generate(deps.config.IndieAuth.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	strong := []string{auth.AMRPassword, auth.AMROneTimePassword}
+
+	for name, tc := range map[string]struct {
+		cookie   *http.Cookie
+		otp      string
+		expError string
+		expACR   string
+		scope    []string
+		expAMR   []string
+		expSet   bool
+	}{
+		"harmless": {
+			scope:  []string{"profile"},
+			expACR: auth.ACRPassword,
+			expAMR: []string{auth.AMRPassword},
+		},
+		"without second factor": {
+			scope:    []string{"delete"},
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"configured": {
+			scope:    []string{"update"},
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"invalid second factor": {
+			scope:    []string{"delete"},
+			otp:      "000000",
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"second factor": {
+			scope:  []string{"delete"},
+			otp:    otp,
+			expACR: auth.ACRSecondFactor,
+			expAMR: strong,
+			expSet: true,
+		},
+		"stepped up recently": {
+			scope:  []string{"delete"},
+			cookie: NewStepUpCookie(t, deps.config, now.Add(-time.Minute)),
+			expACR: auth.ACRSecondFactor,
+			expAMR: strong,
+		},
+		"stepped up long ago": {
+			scope:    []string{"delete"},
+			cookie:   NewStepUpCookie(t, deps.config, now.Add(-time.Hour)),
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+		"session as step-up": {
+			scope: []string{"delete"},
+			cookie: &http.Cookie{
+				Name:  delivery.StepUpCookieName,
+				Value: NewSessionCookie(t, deps.config, now).Value,
+			},
+			expError: domain.ErrorCodeInteractionRequired.String(),
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := url.Values{
+				"authorize":     []string{"allow"},
+				"client_id":     []string{client.ID.String()},
+				"consent_token": []string{consentToken},
+				"me":            []string{account.Identities[0].String()},
+				"otp":           []string{tc.otp},
+				"provider":      []string{"direct"},
+				"redirect_uri":  []string{client.RedirectURI[0].String()},
+				"response_type": []string{domain.ResponseTypeCode.String()},
+				"scope[]":       tc.scope,
+				"state":         []string{"1234567890"},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+			if tc.cookie != nil {
+				req.AddCookie(tc.cookie)
+			}
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			location, err := resp.Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Fatalf("%s %s redirects with error = %q, want %q", req.Method, req.RequestURI, result,
+					tc.expError)
+			}
+
+			if tc.expError != "" {
+				return
+			}
+
+			session, err := deps.sessions.GetAndDelete(context.Background(), location.Query().Get("code"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if session.ACR != tc.expACR || !reflect.DeepEqual(session.AMR, tc.expAMR) {
+				t.Errorf("%s %s issues code with acr %s and amr %v, want %s and %v", req.Method,
+					req.RequestURI, session.ACR, session.AMR, tc.expACR, tc.expAMR)
+			}
+
+			var set bool
+
+			for _, cookie := range resp.Cookies() {
+				set = set || cookie.Name == delivery.StepUpCookieName
+			}
+
+			if set != tc.expSet {
+				t.Errorf("%s %s sets step-up cookie = %t, want %t", req.Method, req.RequestURI, set,
+					tc.expSet)
+			}
+		})
+	}
+}
+
+// TestAuthorize_StepUp checks that sensitive scopes are shown in the distinct
+// section of the consent page.
+func TestAuthorize_StepUp(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.IndieAuth.TOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	deps.config.StepUp.Scopes = []string{"delete"}
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		cookie    *http.Cookie
+		expResult []string
+		expOTP    bool
+	}{
+		"anonymous": {
+			expResult: []string{`Sensitive scopes`, `Delete posts on your site.`, `name="otp"`},
+			expOTP:    true,
+		},
+		"stepped up recently": {
+			cookie:    NewStepUpCookie(t, deps.config, time.Now().UTC().Add(-time.Minute)),
+			expResult: []string{`Sensitive scopes`, `Delete posts on your site.`},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     client.ID.String(),
+				"me":            account.Identities[0].String(),
+				"redirect_uri":  client.RedirectURI[0].String(),
+				"response_type": domain.ResponseTypeCode.String(),
+				"scope":         "profile delete",
+				"state":         "1234567890",
+			} {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			if tc.cookie != nil {
+				req.AddCookie(tc.cookie)
+			}
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u, resp.StatusCode, http.StatusOK)
+			}
+
+			for _, expResult := range tc.expResult {
+				if !strings.Contains(string(body), expResult) {
+					t.Errorf("%s %s = %s, want %s", req.Method, u, body, expResult)
+				}
+			}
+
+			if result := strings.Contains(string(body), `name="otp"`); result != tc.expOTP {
+				t.Errorf("%s %s asks one-time password = %t, want %t", req.Method, u, result, tc.expOTP)
+			}
+		})
+	}
+}
+
+// TestAuthorize_Reauthenticate checks that the owner enters the password on the
+// consent page again if the client requires the active authentication.
+//
+//nolint:funlen
+func TestAuthorize_Reauthenticate(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:exhaustivestruct
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Accounts: deps.accountService,
+		Auth:     deps.authService,
+		Consents: deps.consentService,
+		Config:   *deps.config,
+		Matcher:  deps.matcher,
+		Policies: deps.policyService,
+		Scopes:   deps.scopeService,
+	})
+	consentToken := regexp.MustCompile(`name="consent_token"\s+value="([^"]+)"`)
+	recently := NewSessionCookie(t, deps.config, time.Now().UTC().Add(-time.Minute))
+
+	for name, tc := range map[string]struct {
+		cookie    *http.Cookie
+		params    map[string]string
+		expResult bool
+	}{
+		"login prompt":  {cookie: recently, params: map[string]string{"prompt": "login"}, expResult: true},
+		"anonymous":     {params: map[string]string{"max_age": "3600"}, expResult: true},
+		"too old":       {cookie: recently, params: map[string]string{"max_age": "30"}, expResult: true},
+		"fresh enough":  {cookie: recently, params: map[string]string{"max_age": "3600"}},
+		"not requested": {params: map[string]string{}},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":     client.ID.String(),
+				"me":            account.Identities[0].String(),
+				"redirect_uri":  client.RedirectURI[0].String(),
+				"response_type": domain.ResponseTypeCode.String(),
+				"scope":         "profile",
+				"state":         "1234567890",
+			} {
+				q.Set(key, val)
+			}
+
+			for key, val := range tc.params {
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			if tc.cookie != nil {
+				req.AddCookie(tc.cookie)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			body, err := io.ReadAll(w.Result().Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := strings.Contains(string(body), `name="password"`); result != tc.expResult {
+				t.Errorf("%s %s asks password = %t, want %t", req.Method, u, result, tc.expResult)
+			}
+
+			match := consentToken.FindStringSubmatch(string(body))
+			if match == nil {
+				t.Fatalf("%s %s = %s, want consent token", req.Method, u, body)
+			}
+
+			for _, password := range []string{"", deps.config.IndieAuth.Password} {
+				form := url.Values{
+					"authorize":     []string{"allow"},
+					"client_id":     []string{client.ID.String()},
+					"consent_token": []string{match[1]},
+					"me":            []string{account.Identities[0].String()},
+					"password":      []string{password},
+					"provider":      []string{"direct"},
+					"redirect_uri":  []string{client.RedirectURI[0].String()},
+					"response_type": []string{domain.ResponseTypeCode.String()},
+					"scope[]":       []string{"profile"},
+					"state":         []string{"1234567890"},
+				}
+
+				req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+					strings.NewReader(form.Encode()))
+				req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+				req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				location, err := w.Result().Location()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expError := ""
+				if tc.expResult && password == "" {
+					expError = domain.ErrorCodeLoginRequired.String()
+				}
+
+				if result := location.Query().Get("error"); result != expError {
+					t.Errorf("%s %s with password %q redirects with error = %q, want %q", req.Method,
+						req.RequestURI, password, result, expError)
+				}
+			}
+		})
+	}
+}
+
+// TestAuthorize_Strict checks the authorization endpoint rules of the strict
+// security profile.
+//
+//nolint:funlen
+func TestAuthorize_Strict(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.Security.Profile = domain.SecurityProfileStrict.String()
+	me := domain.TestMe(t, "https://user.example.net/")
+	user := domain.TestUser(t)
+	user.Issuer, _ = url.Parse(deps.config.Server.GetRootURL())
+	client := domain.TestClient(t)
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.users.Create(context.Background(), *user); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.profiles.Create(context.Background(), *me, *user.Profile); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		params    map[string]string
+		expError  string
+		expStatus int
+	}{
+		"conforming": {
+			params:    map[string]string{},
+			expStatus: http.StatusOK,
+		},
+		"legacy response type": {
+			params:    map[string]string{"response_type": domain.ResponseTypeID.String()},
+			expStatus: http.StatusFound,
+			expError:  domain.ErrorCodeUnsupportedResponseType.String(),
+		},
+		"plain pkce": {
+			params:    map[string]string{"code_challenge_method": domain.CodeChallengeMethodPLAIN.String()},
+			expStatus: http.StatusFound,
+			expError:  domain.ErrorCodeInvalidRequest.String(),
+		},
+		"without pkce": {
+			params:    map[string]string{"code_challenge": "", "code_challenge_method": ""},
+			expStatus: http.StatusFound,
+			expError:  domain.ErrorCodeInvalidRequest.String(),
+		},
+		"same host redirect": {
+			params:    map[string]string{"redirect_uri": client.ID.URL().JoinPath("callback").String()},
+			expStatus: http.StatusBadRequest,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+			q := u.Query()
+
+			for key, val := range map[string]string{
+				"client_id":             client.ID.String(),
+				"code_challenge":        "OfYAxt8zU2dAPDWQxTAUIteRzMsoj9QBdMIVEDOErUo",
+				"code_challenge_method": domain.CodeChallengeMethodS256.String(),
+				"me":                    me.String(),
+				"redirect_uri":          client.RedirectURI[0].String(),
+				"response_type":         domain.ResponseTypeCode.String(),
+				"scope":                 "profile",
+				"state":                 "1234567890",
+			} {
+				q.Set(key, val)
+			}
+
+			for key, val := range tc.params {
+				if val == "" {
+					q.Del(key)
+
+					continue
+				}
+
+				q.Set(key, val)
+			}
+
+			u.RawQuery = q.Encode()
+
+			req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Fatalf("%s %s = %d, want %d", req.Method, u, resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expError == "" {
+				return
+			}
+
+			location, err := resp.Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Errorf("%s %s redirects with error = %q, want %q", req.Method, u, result, tc.expError)
+			}
+		})
+	}
+}
+
+// TestVerify_Strict checks that the strict security profile issues
+// short-lived codes bound to the S256 challenge only.
+//
+//nolint:funlen
+func TestVerify_Strict(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.Security.Profile = domain.SecurityProfileStrict.String()
+	deps.authService = ucase.NewAuthUseCase(deps.sessions, deps.profiles, nil, nil, *deps.config)
+	client := domain.TestClient(t)
+	account := domain.TestAccount(t)
+	account.Username = deps.config.IndieAuth.Username
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deps.accounts.Update(context.Background(), *account); err != nil {
+		t.Fatal(err)
+	}
+
+	consentToken := NewConsentToken(t, deps.config, client.ID, client.RedirectURI[0], "profile")
+
+	for name, tc := range map[string]struct {
+		method   string
+		expError string
+	}{
+		"s256": {method: domain.CodeChallengeMethodS256.String()},
+		"plain": {
+			method:   domain.CodeChallengeMethodPLAIN.String(),
+			expError: domain.ErrorCodeInvalidRequest.String(),
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := url.Values{
+				"authorize":             []string{"allow"},
+				"client_id":             []string{client.ID.String()},
+				"code_challenge":        []string{"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+				"code_challenge_method": []string{tc.method},
+				"consent_token":         []string{consentToken},
+				"me":                    []string{account.Identities[0].String()},
+				"provider":              []string{"direct"},
+				"redirect_uri":          []string{client.RedirectURI[0].String()},
+				"response_type":         []string{domain.ResponseTypeCode.String()},
+				"scope[]":               []string{"profile"},
+				"state":                 []string{"1234567890"},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.config.IndieAuth.Username, deps.config.IndieAuth.Password)
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			location, err := w.Result().Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := location.Query().Get("error"); result != tc.expError {
+				t.Fatalf("%s %s redirects with error = %q, want %q", req.Method, req.RequestURI, result,
+					tc.expError)
+			}
+
+			if tc.expError != "" {
+				return
+			}
+
+			session, err := deps.sessions.Get(context.Background(), location.Query().Get("code"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ttl := time.Until(session.Expiry); ttl <= 0 || ttl > domain.StrictCodeExpiry {
+				t.Errorf("%s %s issues code which expires in %s, want at most %s", req.Method,
+					req.RequestURI, ttl, domain.StrictCodeExpiry)
+			}
+		})
+	}
+}
+
+func TestVerify_Deny(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	client := domain.TestClient(t)
+
+	if err := deps.clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		redirectURI string
+		expStatus   int
+	}{
+		"registered": {redirectURI: client.RedirectURI[0].String(), expStatus: http.StatusFound},
+		"foreign":    {redirectURI: "https://evil.example.com/callback", expStatus: http.StatusBadRequest},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			form := make(url.Values)
+
+			for key, val := range map[string]string{
+				"authorize":     "deny",
+				"client_id":     client.ID.String(),
+				"me":            "https://user.example.net/",
+				"provider":      "direct",
+				"redirect_uri":  tc.redirectURI,
+				"response_type": domain.ResponseTypeCode.String(),
+				"state":         "1234567890",
+			} {
+				form.Set(key, val)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/verify",
+				strings.NewReader(form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+
+			w := httptest.NewRecorder()
+
+			//nolint:exhaustivestruct
+			delivery.NewHandler(delivery.NewHandlerOptions{
+				Accounts: deps.accountService,
+				Auth:     deps.authService,
+				Consents: deps.consentService,
+				Config:   *deps.config,
+				Matcher:  deps.matcher,
+				Policies: deps.policyService,
+				Scopes:   deps.scopeService,
+			}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Fatalf("%s %s = %d, want %d", req.Method, tc.redirectURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expStatus != http.StatusFound {
+				return
+			}
+
+			location, err := resp.Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, val := range map[string]string{
+				"error": domain.ErrorCodeAccessDenied.String(),
+				"iss":   deps.config.Server.GetRootURL(),
+				"state": "1234567890",
+			} {
+				if result := location.Query().Get(key); result != val {
+					t.Errorf("%s %s redirects with %s = %s, want %s", req.Method, tc.redirectURI, key,
+						result, val)
+				}
+			}
+		})
+	}
+}
+
+// NewConsentToken returns signed authorization request of the consent page.
+func NewConsentToken(tb testing.TB, config *domain.Config, clientID domain.ClientID, redirectURI *url.URL,
+	scope string,
+) string {
+	tb.Helper()
+
+	tkn := jwt.New()
+
+	for key, val := range map[string]any{
+		jwt.AudienceKey:   config.Server.GetRootURL() + "authorize/verify",
+		jwt.ExpirationKey: time.Now().UTC().Add(delivery.ConsentExpiry),
+		jwt.IssuerKey:     config.Server.GetRootURL(),
+		jwt.SubjectKey:    clientID.String(),
+		"redirect_uri":    redirectURI.String(),
+		"scope":           scope,
+	} {
+		if err := tkn.Set(key, val); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	out, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(config.JWT.Algorithm), []byte(config.JWT.Secret)))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return string(out)
+}
+
+// NewSessionCookie returns cookie which remembers authentication of the owner
+// at provided time.
+func NewSessionCookie(tb testing.TB, config *domain.Config, authTime time.Time) *http.Cookie {
+	tb.Helper()
+
+	return newAuthenticationCookie(tb, config, delivery.SessionCookieName, map[string]any{
+		jwt.ExpirationKey: authTime.Add(config.IndieAuth.SessionExpiry),
+		jwt.IssuedAtKey:   authTime,
+	})
+}
+
+// NewStepUpCookie returns cookie which remembers confirmation of the second
+// factor by the owner at provided time.
+func NewStepUpCookie(tb testing.TB, config *domain.Config, authTime time.Time) *http.Cookie {
+	tb.Helper()
+
+	return newAuthenticationCookie(tb, config, delivery.StepUpCookieName, map[string]any{
+		jwt.ExpirationKey: authTime.Add(config.StepUp.MaxAge),
+		jwt.IssuedAtKey:   authTime,
+		"acr":             auth.ACRSecondFactor,
+	})
+}
+
+func newAuthenticationCookie(tb testing.TB, config *domain.Config, name string, claims map[string]any,
+) *http.Cookie {
+	tb.Helper()
+
+	tkn := jwt.New()
+	claims[jwt.IssuerKey] = config.Server.GetRootURL()
+	claims[jwt.SubjectKey] = config.IndieAuth.Username
+
+	for key, val := range claims {
+		if err := tkn.Set(key, val); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	session, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(config.JWT.Algorithm),
+		[]byte(config.JWT.Secret)))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	//nolint:exhaustivestruct
+	return &http.Cookie{
+		Name:  name,
+		Value: string(session),
 	}
 }
 
@@ -114,17 +2244,34 @@ func NewDependencies(tb testing.TB) Dependencies {
 	users := userrepo.NewMemoryUserRepository()
 	sessions := sessionrepo.NewMemorySessionRepository(*config)
 	profiles := profilerepo.NewMemoryProfileRepository()
-	authService := ucase.NewAuthUseCase(sessions, profiles, *config)
-	clientService := clientucase.NewClientUseCase(clients)
+	authService := ucase.NewAuthUseCase(sessions, profiles, nil, nil, *config)
+	clientService := clientucase.NewClientUseCase(clients, clients, nil, nil, nil, nil)
+	consentService := consentucase.NewConsentUseCase(clientService,
+		consentrepo.NewMemoryConsentRepository(), nil)
+	accounts := accountrepo.NewMemoryAccountRepository()
+	accountService := accountucase.NewAccountUseCase(accounts, userucase.NewUserUseCase(users), *config)
+
+	if err := accounts.Create(context.Background(), domain.Account{
+		CreatedAt:  time.Now().UTC(),
+		Username:   config.IndieAuth.Username,
+		Identities: make([]*domain.Me, 0),
+	}); err != nil {
+		tb.Fatal(err)
+	}
 
 	return Dependencies{
-		users:         users,
-		authService:   authService,
-		clients:       clients,
-		clientService: clientService,
-		config:        config,
-		matcher:       matcher,
-		sessions:      sessions,
-		profiles:      profiles,
+		accounts:       accounts,
+		accountService: accountService,
+		users:          users,
+		authService:    authService,
+		clients:        clients,
+		clientService:  clientService,
+		consentService: consentService,
+		config:         config,
+		matcher:        matcher,
+		policyService:  policyucase.NewPolicyUseCase(),
+		scopeService:   scopeucase.NewScopeUseCase(domain.ScopePolicyReject),
+		sessions:       sessions,
+		profiles:       profiles,
 	}
 }
@@ -3,18 +3,31 @@ package auth
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 )
 
 type (
 	GenerateOptions struct {
+		// AuthTime is the time when the owner was authenticated.
+		AuthTime            time.Time
 		ClientID            domain.ClientID
 		Me                  domain.Me
 		RedirectURI         *url.URL
 		CodeChallengeMethod domain.CodeChallengeMethod
 		CodeChallenge       string
+		Nonce               string
+		ACR                 string
+		AMR                 []string
 		Scope               domain.Scopes
+		Resource            []string
+		// GrantExpiry is how long the owner grants access to the
+		// client.
+		GrantExpiry domain.GrantExpiry
+		// RequestURI of the pushed authorization request which is
+		// consumed by the issued code, empty if it was not pushed.
+		RequestURI string
 	}
 
 	ExchangeOptions struct {
@@ -27,9 +40,38 @@ type (
 	UseCase interface {
 		Generate(ctx context.Context, opts GenerateOptions) (string, error)
 		Exchange(ctx context.Context, opts ExchangeOptions) (*domain.Me, *domain.Profile, error)
+
+		// Push stores parameters of the authorization request pushed
+		// by the client and returns request_uri which references them.
+		Push(ctx context.Context, request domain.Session) (string, error)
+
+		// Pull returns parameters of the authorization request pushed
+		// by the client with provided request_uri. Request can be
+		// pulled until it expires or is consumed by the issued code.
+		Pull(ctx context.Context, clientID domain.ClientID, requestURI string) (*domain.Session, error)
 	}
 )
 
+// ACRPassword is the authentication context class reference of the owner
+// authenticated by the password only.
+const ACRPassword string = "1"
+
+// ACRSecondFactor is the authentication context class reference of the owner
+// authenticated by the password and confirmed by the second factor.
+const ACRSecondFactor string = "2"
+
+// AMRPassword is the authentication method reference of the password, see
+// RFC 8176 section 2.
+const AMRPassword string = "pwd"
+
+// AMROneTimePassword is the authentication method reference of the
+// time-based one-time password, see RFC 8176 section 2.
+const AMROneTimePassword string = "otp"
+
+// RequestURIPrefix is the prefix of the request_uri values of the pushed
+// authorization requests.
+const RequestURIPrefix string = "urn:ietf:params:oauth:request_uri:"
+
 var (
 	ErrMismatchClientID error = domain.NewError(
 		domain.ErrorCodeInvalidRequest,
@@ -47,4 +89,24 @@ var (
 			" authorization request",
 		"https://indieauth.net/source/#request",
 	)
+	ErrInvalidRequestURI error = domain.NewError(
+		domain.ErrorCodeInvalidRequestURI,
+		"request_uri is unknown, expired, used or was pushed by another client",
+		"https://www.rfc-editor.org/rfc/rfc9126#section-4",
+	)
+	ErrStepUpRequired error = domain.NewError(
+		domain.ErrorCodeInteractionRequired,
+		"sensitive scopes must be confirmed by the second factor",
+		"https://www.rfc-editor.org/rfc/rfc9470",
+	)
+	ErrLoginRequired error = domain.NewError(
+		domain.ErrorCodeLoginRequired,
+		"owner must enter the password again to be authenticated actively",
+		"https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest",
+	)
+	ErrPushRequired error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"authorization request parameters must be pushed to the pushed authorization request endpoint first",
+		"https://www.rfc-editor.org/rfc/rfc9126#section-5",
+	)
 )
@@ -3,23 +3,37 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"source.toby3d.me/toby3d/auth/internal/audit"
 	"source.toby3d.me/toby3d/auth/internal/auth"
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	"source.toby3d.me/toby3d/auth/internal/random"
 	"source.toby3d.me/toby3d/auth/internal/session"
+	sessionucase "source.toby3d.me/toby3d/auth/internal/session/usecase"
 )
 
 type authUseCase struct {
+	clock    domain.Clock
+	replays  session.UseCase
 	sessions session.Repository
 	profiles profile.Repository
 	config   domain.Config
 }
 
-// NewAuthUseCase creates a new authentication use case.
-func NewAuthUseCase(sessions session.Repository, profiles profile.Repository, config domain.Config) auth.UseCase {
+// NewAuthUseCase creates a new authentication use case. System clock is used
+// if clock is nil.
+func NewAuthUseCase(sessions session.Repository, profiles profile.Repository, audits audit.Repository,
+	clock domain.Clock, config domain.Config,
+) auth.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
 	return &authUseCase{
+		clock:    clock,
+		replays:  sessionucase.NewSessionUseCase(sessions, audits, clock),
 		config:   config,
 		sessions: sessions,
 		profiles: profiles,
@@ -27,6 +41,16 @@ func NewAuthUseCase(sessions session.Repository, profiles profile.Repository, co
 }
 
 func (uc *authUseCase) Generate(ctx context.Context, opts auth.GenerateOptions) (string, error) {
+	// NOTE(toby3d): RFC 9126 section 4: request_uri is one-time use, so the
+	// pushed request is consumed by the first issued code.
+	if opts.RequestURI != "" {
+		request, err := uc.sessions.GetAndDelete(ctx, session.PushedPrefix+opts.RequestURI)
+		if err != nil || !request.Pushed || uc.clock.Now().After(request.Expiry) ||
+			!request.ClientID.IsEqual(opts.ClientID) {
+			return "", auth.ErrInvalidRequestURI
+		}
+	}
+
 	code, err := random.String(uc.config.Code.Length)
 	if err != nil {
 		return "", fmt.Errorf("cannot generate random code: %w", err)
@@ -46,6 +70,7 @@ func (uc *authUseCase) Generate(ctx context.Context, opts auth.GenerateOptions)
 	}
 
 	if err = uc.sessions.Create(ctx, domain.Session{
+		Expiry:              uc.clock.Now().Add(uc.config.Security.GetProfile().CodeExpiry(uc.config.Code.Expiry)),
 		ClientID:            opts.ClientID,
 		Code:                code,
 		CodeChallenge:       opts.CodeChallenge,
@@ -53,7 +78,13 @@ func (uc *authUseCase) Generate(ctx context.Context, opts auth.GenerateOptions)
 		Me:                  opts.Me,
 		Profile:             userInfo,
 		RedirectURI:         opts.RedirectURI,
+		Resource:            opts.Resource,
 		Scope:               opts.Scope,
+		AuthTime:            opts.AuthTime,
+		Nonce:               opts.Nonce,
+		ACR:                 opts.ACR,
+		AMR:                 opts.AMR,
+		GrantExpiry:         opts.GrantExpiry,
 	}); err != nil {
 		return "", fmt.Errorf("cannot save session in store: %w", err)
 	}
@@ -62,24 +93,94 @@ func (uc *authUseCase) Generate(ctx context.Context, opts auth.GenerateOptions)
 }
 
 func (uc *authUseCase) Exchange(ctx context.Context, opts auth.ExchangeOptions) (*domain.Me, *domain.Profile, error) {
-	session, err := uc.sessions.GetAndDelete(ctx, opts.Code)
+	if strings.HasPrefix(opts.Code, session.PushedPrefix) {
+		return nil, nil, fmt.Errorf("cannot find session in store: %w", session.ErrNotExist)
+	}
+
+	s, err := uc.sessions.GetAndDelete(ctx, opts.Code)
 	if err != nil {
+		if replayErr := uc.replays.DetectReplay(ctx, opts.Code); replayErr != nil {
+			return nil, nil, replayErr
+		}
+
 		return nil, nil, fmt.Errorf("cannot find session in store: %w", err)
 	}
 
-	if opts.ClientID.String() != session.ClientID.String() {
+	if s.Pushed {
+		return nil, nil, fmt.Errorf("cannot find session in store: %w", session.ErrNotExist)
+	}
+
+	// NOTE(toby3d): store can keep expired codes until the next garbage
+	// collection.
+	if !s.Expiry.IsZero() && uc.clock.Now().After(s.Expiry) {
+		return nil, nil, session.ErrExpired
+	}
+
+	if opts.ClientID.String() != s.ClientID.String() {
 		return nil, nil, auth.ErrMismatchClientID
 	}
 
-	if opts.RedirectURI.String() != session.RedirectURI.String() {
+	if opts.RedirectURI.String() != s.RedirectURI.String() {
 		return nil, nil, auth.ErrMismatchRedirectURI
 	}
 
-	if session.CodeChallenge != "" &&
-		session.CodeChallengeMethod != domain.CodeChallengeMethodUnd &&
-		!session.CodeChallengeMethod.Validate(session.CodeChallenge, opts.CodeVerifier) {
+	if s.CodeChallenge != "" &&
+		s.CodeChallengeMethod != domain.CodeChallengeMethodUnd &&
+		!s.CodeChallengeMethod.Validate(s.CodeChallenge, opts.CodeVerifier) {
 		return nil, nil, auth.ErrMismatchPKCE
 	}
 
-	return &session.Me, session.Profile, nil
+	// NOTE(toby3d): profile can be changed after the code is issued.
+	if err = uc.config.Security.GetProfile().ValidateCodeChallenge(s.CodeChallengeMethod,
+		s.CodeChallenge); err != nil {
+		return nil, nil, err //nolint:wrapcheck // domain error
+	}
+
+	// NOTE(toby3d): profile URL redemption mints no tokens, but the same
+	// code presented to the token endpoint must still be detected.
+	if err = uc.sessions.CreateRedemption(ctx, domain.Redemption{
+		CreatedAt: uc.clock.Now(),
+		ClientID:  s.ClientID,
+		Me:        s.Me,
+		ID:        domain.NewRedemptionID(opts.Code),
+		TokenIDs:  make([]string, 0),
+	}); err != nil {
+		return nil, nil, fmt.Errorf("cannot record redemption of the code: %w", err)
+	}
+
+	return &s.Me, s.Profile, nil
+}
+
+func (uc *authUseCase) Push(ctx context.Context, request domain.Session) (string, error) {
+	id, err := random.String(uc.config.Code.Length)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate random request_uri: %w", err)
+	}
+
+	requestURI := auth.RequestURIPrefix + id
+	request.Code = session.PushedPrefix + requestURI
+	request.Expiry = uc.clock.Now().Add(uc.config.PAR.Expiry)
+	request.Pushed = true
+	request.Profile = nil
+
+	if err = uc.sessions.Create(ctx, request); err != nil {
+		return "", fmt.Errorf("cannot save pushed request in store: %w", err)
+	}
+
+	return requestURI, nil
+}
+
+func (uc *authUseCase) Pull(ctx context.Context, cid domain.ClientID, requestURI string) (*domain.Session, error) {
+	// NOTE(toby3d): pushed request is deleted only after the code is
+	// issued, so owner can reload the consent page until then.
+	request, err := uc.sessions.Get(ctx, session.PushedPrefix+requestURI)
+	if err != nil || !request.Pushed || uc.clock.Now().After(request.Expiry) {
+		return nil, auth.ErrInvalidRequestURI
+	}
+
+	if !request.ClientID.IsEqual(cid) {
+		return nil, auth.ErrInvalidRequestURI
+	}
+
+	return request, nil
 }
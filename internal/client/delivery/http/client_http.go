@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -9,6 +10,8 @@ import (
 
 	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
 	"source.toby3d.me/toby3d/auth/internal/token"
 	"source.toby3d.me/toby3d/auth/internal/urlutil"
 	"source.toby3d.me/toby3d/auth/web"
@@ -16,26 +19,42 @@ import (
 
 type (
 	NewHandlerOptions struct {
+		Images  imageproxy.UseCase
 		Matcher language.Matcher
 		Tokens  token.UseCase
-		Client  domain.Client
-		Config  domain.Config
+		// ForwardAuth completes logins started by reverse proxies, nil
+		// if forward-auth is disabled.
+		ForwardAuth forwardauth.UseCase
+		// Renderer writes pages. Built-in templates are used if nil.
+		Renderer web.Renderer
+		Client   domain.Client
+		Config   domain.Config
 	}
 
 	Handler struct {
-		matcher language.Matcher
-		tokens  token.UseCase
-		client  domain.Client
-		config  domain.Config
+		images      imageproxy.UseCase
+		matcher     language.Matcher
+		tokens      token.UseCase
+		forwardAuth forwardauth.UseCase
+		renderer    web.Renderer
+		client      domain.Client
+		config      domain.Config
 	}
 )
 
 func NewHandler(opts NewHandlerOptions) *Handler {
+	if opts.Renderer == nil {
+		opts.Renderer = web.DefaultRenderer
+	}
+
 	return &Handler{
-		client:  opts.Client,
-		config:  opts.Config,
-		matcher: opts.Matcher,
-		tokens:  opts.Tokens,
+		renderer:    opts.Renderer,
+		client:      opts.Client,
+		config:      opts.Config,
+		forwardAuth: opts.ForwardAuth,
+		images:      opts.Images,
+		matcher:     opts.Matcher,
+		tokens:      opts.Tokens,
 	}
 }
 
@@ -80,9 +99,10 @@ func (h *Handler) handleRender(w http.ResponseWriter, r *http.Request) {
 	// TODO(toby3d): generate and store PKCE
 
 	w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
-	web.WriteTemplate(w, &web.HomePage{
+	h.renderer.Render(w, &web.HomePage{
 		BaseOf: web.BaseOf{
 			Config:   &h.config,
+			Images:   h.images,
 			Language: tag,
 			Printer:  message.NewPrinter(tag),
 		},
@@ -105,6 +125,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	tag, _, _ := h.matcher.Match(tags...)
 	baseOf := web.BaseOf{
 		Config:   &h.config,
+		Images:   h.images,
 		Language: tag,
 		Printer:  message.NewPrinter(tag),
 	}
@@ -112,7 +133,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	req := new(ClientCallbackRequest)
 	if err := req.bind(r); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		web.WriteTemplate(w, &web.ErrorPage{
+		h.renderer.Render(w, &web.ErrorPage{
 			BaseOf: baseOf,
 			Error:  err,
 		})
@@ -122,7 +143,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 
 	if req.Error != domain.ErrorCodeUnd {
 		w.WriteHeader(http.StatusUnauthorized)
-		web.WriteTemplate(w, &web.ErrorPage{
+		h.renderer.Render(w, &web.ErrorPage{
 			BaseOf: baseOf,
 			Error: domain.NewError(
 				domain.ErrorCodeAccessDenied,
@@ -139,7 +160,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 
 	if req.Iss.String() != h.client.ID.String() {
 		w.WriteHeader(http.StatusBadRequest)
-		web.WriteTemplate(w, &web.ErrorPage{
+		h.renderer.Render(w, &web.ErrorPage{
 			BaseOf: baseOf,
 			Error: domain.NewError(
 				domain.ErrorCodeInvalidClient,
@@ -152,7 +173,13 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, _, err := h.tokens.Exchange(r.Context(), token.ExchangeOptions{
+	if cookie, err := r.Cookie(forwardauth.LoginCookieName); err == nil && h.forwardAuth != nil {
+		h.handleForwardAuthCallback(w, r, baseOf, cookie.Value, *req)
+
+		return
+	}
+
+	token, profile, err := h.tokens.Exchange(r.Context(), token.ExchangeOptions{
 		ClientID:     h.client.ID,
 		RedirectURI:  h.client.RedirectURI[0],
 		Code:         req.Code,
@@ -160,7 +187,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		web.WriteTemplate(w, &web.ErrorPage{
+		h.renderer.Render(w, &web.ErrorPage{
 			BaseOf: baseOf,
 			Error:  err,
 		})
@@ -169,8 +196,61 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
-	web.WriteTemplate(w, &web.CallbackPage{
-		BaseOf: baseOf,
-		Token:  token,
+	h.renderer.Render(w, &web.CallbackPage{
+		BaseOf:  baseOf,
+		Token:   token,
+		Profile: profile,
+	})
+}
+
+// handleForwardAuthCallback completes the login started by forward-auth and
+// returns the owner back to the protected upstream.
+func (h *Handler) handleForwardAuthCallback(w http.ResponseWriter, r *http.Request, baseOf web.BaseOf,
+	login string, req ClientCallbackRequest,
+) {
+	//nolint:exhaustivestruct
+	http.SetCookie(w, &http.Cookie{
+		Name:     forwardauth.LoginCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	session, target, err := h.forwardAuth.Callback(r.Context(), forwardauth.CallbackOptions{
+		Login: login,
+		State: req.State,
+		Code:  req.Code,
 	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, forwardauth.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+
+		w.WriteHeader(status)
+		h.renderer.Render(w, &web.ErrorPage{
+			BaseOf: baseOf,
+			Error:  err,
+		})
+
+		return
+	}
+
+	//nolint:exhaustivestruct
+	http.SetCookie(w, &http.Cookie{
+		Name:  forwardauth.SessionCookieName,
+		Value: session,
+		// NOTE(toby3d): cookie must be shared with all upstreams behind
+		// the reverse proxy.
+		Domain:   h.config.ForwardAuth.CookieDomain,
+		Path:     "/",
+		MaxAge:   int(h.config.ForwardAuth.Expiry.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
 }
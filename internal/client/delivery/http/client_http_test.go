@@ -1,15 +1,20 @@
 package http_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
 	delivery "source.toby3d.me/toby3d/auth/internal/client/delivery/http"
+	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	forwardauthucase "source.toby3d.me/toby3d/auth/internal/forwardauth/usecase"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/session"
@@ -48,6 +53,74 @@ func TestRead(t *testing.T) {
 	}
 }
 
+func TestCallback_ForwardAuth(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	forwardAuth := forwardauthucase.NewForwardAuthUseCase(forwardauthucase.Config{
+		Tokens:   deps.tokenService,
+		Sessions: deps.sessions,
+		Client:   *deps.client,
+		Rules:    []domain.ForwardAuthRule{{Host: []string{"app.example.org"}}},
+		Config:   *deps.config,
+	})
+	target := &url.URL{Scheme: "https", Host: "app.example.org", Path: "/admin"}
+
+	u, login, err := forwardAuth.Login(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := domain.TestSession(t)
+	s.ClientID = deps.client.ID
+	s.RedirectURI = deps.client.RedirectURI[0]
+	s.CodeChallenge = u.Query().Get("code_challenge")
+	s.CodeChallengeMethod = domain.CodeChallengeMethodS256
+
+	if err = deps.sessions.Create(context.Background(), *s); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://app.example.com/callback?"+url.Values{
+		"code":  []string{s.Code},
+		"iss":   []string{deps.client.ID.String()},
+		"state": []string{u.Query().Get("state")},
+	}.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: forwardauth.LoginCookieName, Value: login})
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Client:      *deps.client,
+		Config:      *deps.config,
+		ForwardAuth: forwardAuth,
+		Matcher:     deps.matcher,
+		Tokens:      deps.tokenService,
+	}).ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusFound)
+	}
+
+	if location := resp.Header.Get(common.HeaderLocation); location != target.String() {
+		t.Errorf("%s %s returns Location %s, want %s", req.Method, req.RequestURI, location, target)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != forwardauth.SessionCookieName {
+			continue
+		}
+
+		if _, err = forwardAuth.Verify(context.Background(), cookie.Value, target); err != nil {
+			t.Errorf("%s %s sets invalid session: %s", req.Method, req.RequestURI, err)
+		}
+
+		return
+	}
+
+	t.Errorf("%s %s does not set %s cookie", req.Method, req.RequestURI, forwardauth.SessionCookieName)
+}
+
 func NewDependencies(tb testing.TB) Dependencies {
 	tb.Helper()
 
@@ -2,17 +2,50 @@ package client
 
 import (
 	"context"
+	"net/url"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 )
 
-type Repository interface {
-	Create(ctx context.Context, client domain.Client) error
-	Get(ctx context.Context, cid domain.ClientID) (*domain.Client, error)
-}
+type (
+	Repository interface {
+		Create(ctx context.Context, client domain.Client) error
+		Get(ctx context.Context, cid domain.ClientID) (*domain.Client, error)
+		Update(ctx context.Context, client domain.Client) error
+		Delete(ctx context.Context, cid domain.ClientID) error
+	}
+
+	// KeySetRepository fetches JSON Web Key Sets published by clients for
+	// private_key_jwt authentication.
+	KeySetRepository interface {
+		Get(ctx context.Context, u *url.URL) (jwk.Set, error)
+	}
+
+	// RequestObjectRepository fetches request objects referenced by the
+	// request_uri parameter of authorization requests.
+	RequestObjectRepository interface {
+		Get(ctx context.Context, u *url.URL) (string, error)
+	}
+)
+
+var (
+	ErrNotExist error = domain.NewError(
+		domain.ErrorCodeInvalidClient,
+		"client with the specified ID does not exist",
+		"",
+	)
+
+	ErrKeySetNotExist error = domain.NewError(
+		domain.ErrorCodeInvalidClient,
+		"cannot fetch JSON Web Key Set of the client",
+		"",
+	)
 
-var ErrNotExist error = domain.NewError(
-	domain.ErrorCodeInvalidClient,
-	"client with the specified ID does not exist",
-	"",
+	ErrRequestObjectNotExist error = domain.NewError(
+		domain.ErrorCodeInvalidRequestURI,
+		"cannot fetch request object by provided request_uri",
+		"https://www.rfc-editor.org/rfc/rfc9101#section-5.2.3",
+	)
 )
@@ -55,6 +55,16 @@ func (httpClientRepository) Create(_ context.Context, _ domain.Client) error {
 	return nil
 }
 
+// WARN(toby3d): not implemented.
+func (httpClientRepository) Update(_ context.Context, _ domain.Client) error {
+	return nil
+}
+
+// WARN(toby3d): not implemented.
+func (httpClientRepository) Delete(_ context.Context, _ domain.ClientID) error {
+	return nil
+}
+
 func (repo httpClientRepository) Get(ctx context.Context, cid domain.ClientID) (*domain.Client, error) {
 	out := &domain.Client{
 		ID:          cid,
@@ -101,15 +111,24 @@ func (repo httpClientRepository) Get(ctx context.Context, cid domain.ClientID) (
 		}
 	}
 
-	// NOTE(toby3d): fetch redirect uri's from Link header
+	for _, val := range mf2.Rels[common.RelJWKSURI] {
+		if out.JWKSURI, err = url.Parse(val); err == nil {
+			break
+		}
+	}
+
+	// NOTE(toby3d): fetch redirect uri's and JWKS from Link header
 	for _, link := range linkheader.Parse(resp.Header.Get(common.HeaderLink)) {
-		if link.Rel != common.RelRedirectURI {
+		var u *url.URL
+		if u, err = url.Parse(link.URL); err != nil {
 			continue
 		}
 
-		var u *url.URL
-		if u, err = url.Parse(link.URL); err == nil {
+		switch link.Rel {
+		case common.RelRedirectURI:
 			out.RedirectURI = append(out.RedirectURI, u)
+		case common.RelJWKSURI:
+			out.JWKSURI = u
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -21,6 +22,7 @@ const testBody string = `<!DOCTYPE html>
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>%[1]s</title>
     <link rel="redirect_uri" href="%[4]s">
+    <link rel="jwks_uri" href="%[5]s">
   </head>
   <body>
     <div class="h-app h-x-app">
@@ -42,6 +44,7 @@ func TestGet(t *testing.T) {
 	t.Cleanup(srv.Close)
 
 	client.ID = *domain.TestClientID(t, srv.URL+"/")
+	client.JWKSURI, _ = url.Parse(srv.URL + "/jwks.json")
 	clients := repository.NewHTTPClientRepository(srv.Client())
 
 	result, err := clients.Get(context.Background(), client.ID)
@@ -68,14 +71,19 @@ func TestGet(t *testing.T) {
 	if !cmp.Equal(result.RedirectURI, client.RedirectURI) {
 		t.Errorf("GET %s = %+s, want %+s", client.ID, result.RedirectURI, client.RedirectURI)
 	}
+
+	if !cmp.Equal(result.JWKSURI, client.JWKSURI) {
+		t.Errorf("GET %s = %+s, want %+s", client.ID, result.JWKSURI, client.JWKSURI)
+	}
 }
 
 func testHandler(tb testing.TB, client domain.Client) http.Handler {
 	tb.Helper()
 
-	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(common.HeaderContentType, common.MIMETextHTMLCharsetUTF8)
 		w.Header().Set(common.HeaderLink, `<`+client.RedirectURI[1].String()+`>; rel="redirect_uri"`)
-		fmt.Fprintf(w, testBody, client.Name, client.URL, client.Logo, client.RedirectURI[0])
+		fmt.Fprintf(w, testBody, client.Name, client.URL, client.Logo, client.RedirectURI[0],
+			"https://"+r.Host+"/jwks.json")
 	})
 }
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+)
+
+type httpKeySetRepository struct {
+	client *http.Client
+}
+
+// NewHTTPKeySetRepository creates a new repository which fetches client JWKS
+// documents through the provided HTTP client.
+func NewHTTPKeySetRepository(c *http.Client) client.KeySetRepository {
+	return &httpKeySetRepository{
+		client: c,
+	}
+}
+
+func (repo httpKeySetRepository) Get(ctx context.Context, u *url.URL) (jwk.Set, error) {
+	if u == nil {
+		return nil, client.ErrKeySetNotExist
+	}
+
+	set, err := jwk.Fetch(ctx, u.String(), jwk.WithHTTPClient(repo.client))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", client.ErrKeySetNotExist, err)
+	}
+
+	return set, nil
+}
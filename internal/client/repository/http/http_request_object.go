@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+type httpRequestObjectRepository struct {
+	client *http.Client
+}
+
+// MaxRequestObjectSize is the maximum size of fetched request object in
+// bytes.
+const MaxRequestObjectSize int64 = 64 << 10
+
+// NewHTTPRequestObjectRepository creates a new repository which fetches
+// request objects through the provided HTTP client.
+func NewHTTPRequestObjectRepository(c *http.Client) client.RequestObjectRepository {
+	return &httpRequestObjectRepository{
+		client: c,
+	}
+}
+
+func (repo httpRequestObjectRepository) Get(ctx context.Context, u *url.URL) (string, error) {
+	// NOTE(toby3d): request object must not be fetched over plain HTTP,
+	// otherwise anyone on the network can replace it.
+	if u == nil || u.Scheme != "https" {
+		return "", client.ErrRequestObjectNotExist
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", client.ErrRequestObjectNotExist, err)
+	}
+
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationOAuthAuthzReqJWT)
+
+	resp, err := repo.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", client.ErrRequestObjectNotExist, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: status is %d", client.ErrRequestObjectNotExist, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxRequestObjectSize))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", client.ErrRequestObjectNotExist, err)
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
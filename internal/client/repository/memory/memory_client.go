@@ -21,8 +21,8 @@ func NewMemoryClientRepository() client.Repository {
 }
 
 func (repo memoryClientRepository) Create(ctx context.Context, client domain.Client) error {
-	repo.mutex.RLock()
-	defer repo.mutex.RUnlock()
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
 
 	repo.clients[client.ID.String()] = client
 
@@ -39,3 +39,29 @@ func (repo memoryClientRepository) Get(ctx context.Context, cid domain.ClientID)
 
 	return nil, client.ErrNotExist
 }
+
+func (repo memoryClientRepository) Update(ctx context.Context, c domain.Client) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.clients[c.ID.String()]; !ok {
+		return client.ErrNotExist
+	}
+
+	repo.clients[c.ID.String()] = c
+
+	return nil
+}
+
+func (repo memoryClientRepository) Delete(ctx context.Context, cid domain.ClientID) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.clients[cid.String()]; !ok {
+		return client.ErrNotExist
+	}
+
+	delete(repo.clients, cid.String())
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	Client struct {
+		ClientID          string       `db:"client_id"`
+		Name              string       `db:"name"`
+		URL               string       `db:"url"`
+		Logo              string       `db:"logo"`
+		JWKSURI           string       `db:"jwks_uri"`
+		RedirectURIs      string       `db:"redirect_uris"`
+		Secret            string       `db:"secret"`
+		RegistrationToken string       `db:"registration_token"`
+		AuthMethod        string       `db:"auth_method"`
+		GrantTypes        string       `db:"grant_types"`
+		ResponseTypes     string       `db:"response_types"`
+		Contacts          string       `db:"contacts"`
+		Scope             string       `db:"scope"`
+		CreatedAt         sql.NullTime `db:"created_at"`
+		SecretExpiresAt   sql.NullTime `db:"secret_expires_at"`
+	}
+
+	sqlite3ClientRepository struct {
+		db *sqlx.DB
+	}
+)
+
+const (
+	QueryTable string = `CREATE TABLE IF NOT EXISTS clients (
+		client_id TEXT UNIQUE PRIMARY KEY NOT NULL,
+		created_at DATETIME NOT NULL,
+		name TEXT,
+		url TEXT,
+		logo TEXT,
+		redirect_uris TEXT NOT NULL,
+		secret TEXT,
+		secret_expires_at DATETIME,
+		registration_token TEXT,
+		auth_method TEXT NOT NULL,
+		grant_types TEXT NOT NULL,
+		response_types TEXT NOT NULL,
+		contacts TEXT,
+		scope TEXT,
+		jwks_uri TEXT
+	);`
+
+	QueryGet string = `SELECT *
+		FROM clients
+		WHERE client_id=$1;`
+
+	QueryCreate string = `INSERT INTO clients (client_id, created_at, name, url, logo, redirect_uris, secret,
+		secret_expires_at, registration_token, auth_method, grant_types, response_types, contacts, scope,
+		jwks_uri)
+		VALUES (:client_id, :created_at, :name, :url, :logo, :redirect_uris, :secret, :secret_expires_at,
+		:registration_token, :auth_method, :grant_types, :response_types, :contacts, :scope, :jwks_uri);`
+
+	QueryUpdate string = `UPDATE clients
+		SET name=:name, url=:url, logo=:logo, redirect_uris=:redirect_uris, secret=:secret,
+		secret_expires_at=:secret_expires_at, registration_token=:registration_token,
+		auth_method=:auth_method, grant_types=:grant_types, response_types=:response_types,
+		contacts=:contacts, scope=:scope, jwks_uri=:jwks_uri
+		WHERE client_id=:client_id;`
+
+	QueryDelete string = `DELETE FROM clients
+		WHERE client_id=$1;`
+)
+
+func NewSQLite3ClientRepository(db *sqlx.DB) client.Repository {
+	db.MustExec(QueryTable)
+
+	return &sqlite3ClientRepository{
+		db: db,
+	}
+}
+
+func (repo *sqlite3ClientRepository) Create(ctx context.Context, c domain.Client) error {
+	if _, err := repo.db.NamedExecContext(ctx, QueryCreate, NewClient(&c)); err != nil {
+		return fmt.Errorf("cannot create client record in db: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ClientRepository) Get(ctx context.Context, cid domain.ClientID) (*domain.Client, error) {
+	c := new(Client)
+	if err := repo.db.GetContext(ctx, c, QueryGet, cid.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, client.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot find client in db: %w", err)
+	}
+
+	result := domain.NewClient(cid)
+	c.Populate(result)
+
+	return result, nil
+}
+
+func (repo *sqlite3ClientRepository) Update(ctx context.Context, c domain.Client) error {
+	result, err := repo.db.NamedExecContext(ctx, QueryUpdate, NewClient(&c))
+	if err != nil {
+		return fmt.Errorf("cannot update client record in db: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return client.ErrNotExist
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ClientRepository) Delete(ctx context.Context, cid domain.ClientID) error {
+	result, err := repo.db.ExecContext(ctx, QueryDelete, cid.String())
+	if err != nil {
+		return fmt.Errorf("cannot remove client from db: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return client.ErrNotExist
+	}
+
+	return nil
+}
+
+func NewClient(src *domain.Client) *Client {
+	out := &Client{
+		ClientID:          src.ID.String(),
+		CreatedAt:         sql.NullTime{Time: src.CreatedAt.UTC(), Valid: true},
+		SecretExpiresAt:   sql.NullTime{Time: src.SecretExpiresAt.UTC(), Valid: !src.SecretExpiresAt.IsZero()},
+		Name:              src.Name,
+		Secret:            src.Secret,
+		RegistrationToken: src.RegistrationToken,
+		AuthMethod:        src.AuthMethod.String(),
+		Contacts:          strings.Join(src.Contacts, " "),
+		Scope:             src.Scope.String(),
+		URL:               "",
+		Logo:              "",
+		JWKSURI:           "",
+		RedirectURIs:      "",
+		GrantTypes:        "",
+		ResponseTypes:     "",
+	}
+
+	if src.URL != nil {
+		out.URL = src.URL.String()
+	}
+
+	if src.Logo != nil {
+		out.Logo = src.Logo.String()
+	}
+
+	if src.JWKSURI != nil {
+		out.JWKSURI = src.JWKSURI.String()
+	}
+
+	redirectURIs := make([]string, len(src.RedirectURI))
+	for i := range src.RedirectURI {
+		redirectURIs[i] = src.RedirectURI[i].String()
+	}
+
+	grantTypes := make([]string, len(src.GrantTypes))
+	for i := range src.GrantTypes {
+		grantTypes[i] = src.GrantTypes[i].String()
+	}
+
+	responseTypes := make([]string, len(src.ResponseTypes))
+	for i := range src.ResponseTypes {
+		responseTypes[i] = src.ResponseTypes[i].String()
+	}
+
+	out.RedirectURIs = strings.Join(redirectURIs, " ")
+	out.GrantTypes = strings.Join(grantTypes, " ")
+	out.ResponseTypes = strings.Join(responseTypes, " ")
+
+	return out
+}
+
+func (c *Client) Populate(dst *domain.Client) {
+	dst.Name = c.Name
+	dst.Secret = c.Secret
+	dst.RegistrationToken = c.RegistrationToken
+	dst.AuthMethod, _ = domain.ParseClientAuthMethod(c.AuthMethod)
+	dst.Contacts = strings.Fields(c.Contacts)
+	dst.URL, _ = url.Parse(c.URL)
+	dst.Logo, _ = url.Parse(c.Logo)
+	dst.JWKSURI, _ = url.Parse(c.JWKSURI)
+
+	if c.CreatedAt.Valid {
+		dst.CreatedAt = c.CreatedAt.Time
+	}
+
+	if c.SecretExpiresAt.Valid {
+		dst.SecretExpiresAt = c.SecretExpiresAt.Time
+	}
+
+	if c.URL == "" {
+		dst.URL = nil
+	}
+
+	if c.Logo == "" {
+		dst.Logo = nil
+	}
+
+	if c.JWKSURI == "" {
+		dst.JWKSURI = nil
+	}
+
+	for _, v := range strings.Fields(c.RedirectURIs) {
+		if u, err := url.Parse(v); err == nil {
+			dst.RedirectURI = append(dst.RedirectURI, u)
+		}
+	}
+
+	for _, v := range strings.Fields(c.GrantTypes) {
+		if grantType, err := domain.ParseGrantType(v); err == nil {
+			dst.GrantTypes = append(dst.GrantTypes, grantType)
+		}
+	}
+
+	for _, v := range strings.Fields(c.ResponseTypes) {
+		if responseType, err := domain.ParseResponseType(v); err == nil {
+			dst.ResponseTypes = append(dst.ResponseTypes, responseType)
+		}
+	}
+
+	for _, v := range strings.Fields(c.Scope) {
+		if scope, err := domain.ParseScope(v); err == nil {
+			dst.Scope = append(dst.Scope, scope)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package sqlite3_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	repository "source.toby3d.me/toby3d/auth/internal/client/repository/sqlite3"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/testing/sqltest"
+)
+
+//nolint:gochecknoglobals // slices cannot be contants
+var tableColumns = []string{
+	"client_id", "created_at", "name", "url", "logo", "redirect_uris", "secret", "secret_expires_at",
+	"registration_token", "auth_method", "grant_types", "response_types", "contacts", "scope",
+	"jwks_uri",
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	client := domain.TestClient(t)
+	model := repository.NewClient(client)
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO clients`)).
+		WithArgs(
+			model.ClientID,
+			sqltest.Time{},
+			model.Name,
+			model.URL,
+			model.Logo,
+			model.RedirectURIs,
+			model.Secret,
+			model.SecretExpiresAt,
+			model.RegistrationToken,
+			model.AuthMethod,
+			model.GrantTypes,
+			model.ResponseTypes,
+			model.Contacts,
+			model.Scope,
+			model.JWKSURI,
+		).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repository.NewSQLite3ClientRepository(db).Create(context.Background(), *client); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	client := domain.TestClient(t)
+	model := repository.NewClient(client)
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM clients`)).
+		WithArgs(model.ClientID).
+		WillReturnRows(sqlmock.NewRows(tableColumns).
+			AddRow(
+				model.ClientID,
+				model.CreatedAt.Time,
+				model.Name,
+				model.URL,
+				model.Logo,
+				model.RedirectURIs,
+				model.Secret,
+				nil,
+				model.RegistrationToken,
+				model.AuthMethod,
+				model.GrantTypes,
+				model.ResponseTypes,
+				model.Contacts,
+				model.Scope,
+				model.JWKSURI,
+			))
+
+	result, err := repository.NewSQLite3ClientRepository(db).Get(context.Background(), client.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Name != client.Name || len(result.RedirectURI) != len(client.RedirectURI) {
+		t.Errorf("Get(%s) = %+v, want %+v", client.ID, result, client)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	client := domain.TestClient(t)
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM clients`)).
+		WithArgs(client.ID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repository.NewSQLite3ClientRepository(db).Delete(context.Background(), client.ID); err != nil {
+		t.Error(err)
+	}
+}
+
+func createTable(tb testing.TB, mock sqlmock.Sqlmock) {
+	tb.Helper()
+
+	mock.ExpectExec(regexp.QuoteMeta(repository.QueryTable)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
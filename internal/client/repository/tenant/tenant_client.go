@@ -0,0 +1,59 @@
+package tenant
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type tenantClientRepository struct {
+	repo      client.Repository
+	namespace string
+}
+
+// NewTenantClientRepository creates a new clients repository which stores
+// clients registered by the tenant in the provided shared repository under its
+// own namespace. URL clients are described by their own public pages, which
+// are the same for all tenants, so they are passed as is.
+func NewTenantClientRepository(repo client.Repository, namespace string) client.Repository {
+	return &tenantClientRepository{
+		namespace: namespace,
+		repo:      repo,
+	}
+}
+
+func (repo *tenantClientRepository) Create(ctx context.Context, c domain.Client) error {
+	c.ID = repo.id(c.ID)
+
+	return repo.repo.Create(ctx, c) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantClientRepository) Get(ctx context.Context, cid domain.ClientID) (*domain.Client, error) {
+	out, err := repo.repo.Get(ctx, repo.id(cid))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // decorator returns errors as is
+	}
+
+	out.ID = cid
+
+	return out, nil
+}
+
+func (repo *tenantClientRepository) Update(ctx context.Context, c domain.Client) error {
+	c.ID = repo.id(c.ID)
+
+	return repo.repo.Update(ctx, c) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantClientRepository) Delete(ctx context.Context, cid domain.ClientID) error {
+	return repo.repo.Delete(ctx, repo.id(cid)) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantClientRepository) id(cid domain.ClientID) domain.ClientID {
+	if !cid.IsOpaque() {
+		return cid
+	}
+
+	return domain.NewTenantClientID(repo.namespace, cid)
+}
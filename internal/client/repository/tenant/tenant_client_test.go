@@ -0,0 +1,55 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	repository "source.toby3d.me/toby3d/auth/internal/client/repository/tenant"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	shared := memory.NewMemoryClientRepository()
+	alice := repository.NewTenantClientRepository(shared, "alice")
+	bob := repository.NewTenantClientRepository(shared, "bob")
+
+	cid, err := domain.ParseClientID("Zs8H1y3_mPq-4kXw2nLc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := domain.TestClient(t)
+	registered.ID = *cid
+
+	if err = alice.Create(context.Background(), *registered); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bob.Get(context.Background(), *cid); !errors.Is(err, client.ErrNotExist) {
+		t.Errorf("Get(%s) = %v, want %v", cid, err, client.ErrNotExist)
+	}
+
+	result, err := alice.Get(context.Background(), *cid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.ID.IsEqual(*cid) {
+		t.Errorf("Get(%s) = %s, want %s", cid, result.ID, cid)
+	}
+
+	// NOTE(toby3d): URL clients are the same for all tenants.
+	public := domain.TestClient(t)
+	if err = shared.Create(context.Background(), *public); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bob.Get(context.Background(), public.ID); err != nil {
+		t.Errorf("Get(%s) = %v, want nil", public.ID, err)
+	}
+}
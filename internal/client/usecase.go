@@ -2,17 +2,89 @@ package client
 
 import (
 	"context"
+	"net/url"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 )
 
-type UseCase interface {
-	// Discovery returns client public information bu ClientID URL.
-	Discovery(ctx context.Context, id domain.ClientID) (*domain.Client, error)
-}
+type (
+	// AuthenticateOptions contains client credentials provided on the token,
+	// introspection or revocation endpoints.
+	AuthenticateOptions struct {
+		ClientID domain.ClientID
+		Method   domain.ClientAuthMethod
 
-var ErrInvalidMe error = domain.NewError(
-	domain.ErrorCodeInvalidRequest,
-	"cannot fetch client endpoints on provided me",
-	"",
+		// Client secret for client_secret_basic and client_secret_post
+		// methods.
+		Secret string
+
+		// Signed JWT for private_key_jwt method.
+		Assertion string
+
+		// Acceptable values of the aud claim in the client assertion,
+		// i.e. issuer identifier and URL of the requested endpoint.
+		Audience []string
+	}
+
+	// RequestObjectOptions contains request object passed by value or by
+	// reference in the authorization request.
+	RequestObjectOptions struct {
+		ClientID domain.ClientID
+
+		// Signed request object provided by the request parameter.
+		Request string
+
+		// URL of the signed request object provided by the
+		// request_uri parameter. Used only if Request is empty.
+		RequestURI *url.URL
+
+		// Acceptable values of the aud claim in the request object,
+		// i.e. issuer identifier of this server.
+		Audience []string
+	}
+
+	UseCase interface {
+		// Discovery returns client public information bu ClientID URL.
+		Discovery(ctx context.Context, id domain.ClientID) (*domain.Client, error)
+
+		// Authenticate verifies provided client credentials and returns
+		// authenticated client.
+		Authenticate(ctx context.Context, opts AuthenticateOptions) (*domain.Client, error)
+
+		// RequestObject verifies signature of the request object
+		// against keys of the client and returns its claims.
+		RequestObject(ctx context.Context, opts RequestObjectOptions) (map[string]any, error)
+	}
+)
+
+var (
+	ErrInvalidMe error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"cannot fetch client endpoints on provided me",
+		"",
+	)
+
+	ErrInvalidCredentials error = domain.NewError(
+		domain.ErrorCodeInvalidClient,
+		"client authentication failed",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-5.2",
+	)
+
+	ErrAuthenticationRequired error = domain.NewError(
+		domain.ErrorCodeInvalidClient,
+		"confidential client must authenticate with registered method",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-3.2.1",
+	)
+
+	ErrInvalidRequestObject error = domain.NewError(
+		domain.ErrorCodeInvalidRequestObject,
+		"request object is malformed, not signed by the client or not intended for this server",
+		"https://www.rfc-editor.org/rfc/rfc9101#section-6.3",
+	)
+
+	ErrUnsupportedAuthMethod error = domain.NewError(
+		domain.ErrorCodeInvalidClient,
+		"client authentication method is not supported for this client",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-5.2",
+	)
 )
@@ -3,26 +3,246 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"golang.org/x/exp/slices"
 
 	"source.toby3d.me/toby3d/auth/internal/client"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/replay"
 )
 
 type clientUseCase struct {
-	repo client.Repository
+	clock    domain.Clock
+	repo     client.Repository
+	registry client.Repository
+	keys     client.KeySetRepository
+	requests client.RequestObjectRepository
+	replays  replay.Repository
 }
 
-func NewClientUseCase(repo client.Repository) client.UseCase {
+// AssertionSkew is an acceptable clock skew for client assertions.
+const AssertionSkew time.Duration = time.Minute
+
+// AssertionAlgorithms contains JWS algorithms accepted in client assertions.
+// Algorithm is inferred from the type of the client public key, so only
+// asymmetric algorithms can be used.
+//
+//nolint:gochecknoglobals // slices cannot be constants
+var AssertionAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// NewClientUseCase creates a new client use case which discovers URL clients
+// through repo and dynamically registered clients through registry. Public
+// keys for private_key_jwt authentication and request objects are fetched
+// through keys, request objects passed by reference through requests. Used
+// client assertions are remembered in replays until their expiration. System
+// clock is used if clock is nil.
+func NewClientUseCase(repo, registry client.Repository, keys client.KeySetRepository,
+	requests client.RequestObjectRepository, replays replay.Repository, clock domain.Clock,
+) client.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
 	return &clientUseCase{
-		repo: repo,
+		clock:    clock,
+		repo:     repo,
+		registry: registry,
+		keys:     keys,
+		requests: requests,
+		replays:  replays,
 	}
 }
 
 func (useCase *clientUseCase) Discovery(ctx context.Context, id domain.ClientID) (*domain.Client, error) {
-	c, err := useCase.repo.Get(ctx, id)
+	repo := useCase.repo
+	if id.IsOpaque() {
+		repo = useCase.registry
+	}
+
+	c, err := repo.Get(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("cannot discovery client by id: %w", err)
 	}
 
 	return c, nil
 }
+
+func (useCase *clientUseCase) Authenticate(ctx context.Context, opts client.AuthenticateOptions,
+) (*domain.Client, error) {
+	c, err := useCase.Discovery(ctx, opts.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", client.ErrInvalidCredentials, err)
+	}
+
+	switch opts.Method {
+	case domain.ClientAuthMethodNone:
+		if c.IsConfidential() {
+			return nil, client.ErrAuthenticationRequired
+		}
+	case domain.ClientAuthMethodClientSecretBasic, domain.ClientAuthMethodClientSecretPost:
+		// NOTE(toby3d): both secret methods transfer the same secret, so
+		// accept any of them for clients registered with a secret.
+		if c.AuthMethod != domain.ClientAuthMethodClientSecretBasic &&
+			c.AuthMethod != domain.ClientAuthMethodClientSecretPost {
+			return nil, client.ErrUnsupportedAuthMethod
+		}
+
+		if !c.VerifySecret(opts.Secret, useCase.clock.Now()) {
+			return nil, client.ErrInvalidCredentials
+		}
+	case domain.ClientAuthMethodPrivateKeyJWT:
+		if c.IsConfidential() && c.AuthMethod != domain.ClientAuthMethodPrivateKeyJWT ||
+			c.JWKSURI == nil || useCase.keys == nil || useCase.replays == nil {
+			return nil, client.ErrUnsupportedAuthMethod
+		}
+
+		if err = useCase.verifyAssertion(ctx, *c, opts); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, client.ErrUnsupportedAuthMethod
+	}
+
+	return c, nil
+}
+
+// verifyAssertion validates client assertion by RFC 7523 section 3 rules.
+func (useCase *clientUseCase) verifyAssertion(ctx context.Context, c domain.Client,
+	opts client.AuthenticateOptions,
+) error {
+	msg, err := jws.ParseString(opts.Assertion)
+	if err != nil || len(msg.Signatures()) != 1 ||
+		!slices.Contains(AssertionAlgorithms, msg.Signatures()[0].ProtectedHeaders().Algorithm().String()) {
+		return fmt.Errorf("%w: unsupported assertion signature", client.ErrInvalidCredentials)
+	}
+
+	set, err := useCase.keys.Get(ctx, c.JWKSURI)
+	if err != nil {
+		return fmt.Errorf("cannot fetch client keys: %w", err)
+	}
+
+	cid := c.ID.String()
+
+	assertion, err := jwt.ParseString(opts.Assertion,
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false)),
+		jwt.WithValidate(true),
+		jwt.WithClock(useCase.clock),
+		jwt.WithAcceptableSkew(AssertionSkew),
+		jwt.WithIssuer(cid),
+		jwt.WithSubject(cid),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+		jwt.WithRequiredClaim(jwt.JwtIDKey),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", client.ErrInvalidCredentials, err)
+	}
+
+	if !slices.ContainsFunc(assertion.Audience(), func(aud string) bool {
+		return slices.Contains(opts.Audience, aud)
+	}) {
+		return fmt.Errorf("%w: assertion is not intended for this server", client.ErrInvalidCredentials)
+	}
+
+	// NOTE(toby3d): RFC 7523 section 3: the same assertion cannot be used
+	// twice while it's valid.
+	if err = useCase.replays.Create(ctx, "assertion:"+cid+":"+assertion.JwtID(),
+		assertion.Expiration().Add(AssertionSkew)); err != nil {
+		return fmt.Errorf("%w: %w", client.ErrInvalidCredentials, err)
+	}
+
+	return nil
+}
+
+// RequestObject validates request object by RFC 9101 section 6 rules.
+//
+//nolint:cyclop
+func (useCase *clientUseCase) RequestObject(ctx context.Context, opts client.RequestObjectOptions,
+) (map[string]any, error) {
+	raw := opts.Request
+	if raw == "" {
+		if useCase.requests == nil {
+			return nil, client.ErrRequestObjectNotExist
+		}
+
+		var err error
+		if raw, err = useCase.requests.Get(ctx, opts.RequestURI); err != nil {
+			return nil, fmt.Errorf("cannot fetch request object: %w", err)
+		}
+	}
+
+	c, err := useCase.Discovery(ctx, opts.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", client.ErrInvalidRequestObject, err)
+	}
+
+	if c.JWKSURI == nil || useCase.keys == nil {
+		return nil, fmt.Errorf("%w: client does not publish keys", client.ErrInvalidRequestObject)
+	}
+
+	// NOTE(toby3d): unsigned request objects cannot protect anything, so
+	// alg=none is rejected here too.
+	msg, err := jws.ParseString(raw)
+	if err != nil || len(msg.Signatures()) != 1 ||
+		!slices.Contains(AssertionAlgorithms, msg.Signatures()[0].ProtectedHeaders().Algorithm().String()) {
+		return nil, fmt.Errorf("%w: unsupported request object signature", client.ErrInvalidRequestObject)
+	}
+
+	set, err := useCase.keys.Get(ctx, c.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch client keys: %w", err)
+	}
+
+	cid := c.ID.String()
+
+	request, err := jwt.ParseString(raw,
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false)),
+		jwt.WithValidate(true),
+		jwt.WithClock(useCase.clock),
+		jwt.WithAcceptableSkew(AssertionSkew),
+		jwt.WithIssuer(cid),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", client.ErrInvalidRequestObject, err)
+	}
+
+	if len(request.Audience()) > 0 && !slices.ContainsFunc(request.Audience(), func(aud string) bool {
+		return slices.Contains(opts.Audience, aud)
+	}) {
+		return nil, fmt.Errorf("%w: request object is not intended for this server",
+			client.ErrInvalidRequestObject)
+	}
+
+	claims, err := request.AsMap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", client.ErrInvalidRequestObject, err)
+	}
+
+	if val, ok := claims["client_id"]; ok && val != cid {
+		return nil, fmt.Errorf("%w: client_id does not match the issuer", client.ErrInvalidRequestObject)
+	}
+
+	// NOTE(toby3d): request object must not reference another one.
+	if _, ok := claims["request"]; ok {
+		return nil, fmt.Errorf("%w: request object contains request", client.ErrInvalidRequestObject)
+	}
+
+	if _, ok := claims["request_uri"]; ok {
+		return nil, fmt.Errorf("%w: request object contains request_uri", client.ErrInvalidRequestObject)
+	}
+
+	for _, key := range []string{
+		jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey, jwt.IssuerKey, jwt.JwtIDKey, jwt.NotBeforeKey,
+	} {
+		delete(claims, key)
+	}
+
+	return claims, nil
+}
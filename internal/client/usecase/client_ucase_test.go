@@ -2,13 +2,30 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	httprepo "source.toby3d.me/toby3d/auth/internal/client/repository/http"
 	repository "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/client/usecase"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	replayrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
 )
 
 func TestDiscovery(t *testing.T) {
@@ -21,6 +38,19 @@ func TestDiscovery(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	cid, err := domain.ParseClientID("Zs8H1y3_mPq-4kXw2nLc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registeredClient := domain.TestClient(t)
+	registeredClient.ID = *cid
+	registry := repository.NewMemoryClientRepository()
+
+	if err := registry.Create(context.Background(), *registeredClient); err != nil {
+		t.Fatal(err)
+	}
+
 	for _, tc := range []struct {
 		expError error
 		in       *domain.Client
@@ -30,13 +60,17 @@ func TestDiscovery(t *testing.T) {
 		name: "default",
 		in:   testClient,
 		out:  testClient,
+	}, {
+		name: "registered",
+		in:   registeredClient,
+		out:  registeredClient,
 	}} {
 		tc := tc
 
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			result, err := usecase.NewClientUseCase(clients).
+			result, err := usecase.NewClientUseCase(clients, registry, nil, nil, nil, nil).
 				Discovery(context.Background(), tc.in.ID)
 			if tc.expError != nil && !errors.Is(err, tc.expError) {
 				t.Errorf("Discovery(%s) = %+v, want %+v", tc.in.ID, err, tc.expError)
@@ -54,3 +88,374 @@ func TestDiscovery(t *testing.T) {
 		})
 	}
 }
+
+//nolint:funlen
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwk.FromRaw(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = key.Set(jwk.KeyIDKey, "test")
+
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jwk.NewSet()
+	_ = set.AddKey(publicKey)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	registry := repository.NewMemoryClientRepository()
+
+	secretClient := domain.TestClient(t)
+	secretClient.ID = *parseClientID(t, "Zs8H1y3_mPq-4kXw2nLc")
+	secretClient.AuthMethod = domain.ClientAuthMethodClientSecretBasic
+	secretClient.Secret = domain.HashSecret("secret")
+
+	keyClient := domain.TestClient(t)
+	keyClient.ID = *parseClientID(t, "aPq-4kXw2nLcZs8H1y3_m")
+	keyClient.AuthMethod = domain.ClientAuthMethodPrivateKeyJWT
+	keyClient.JWKSURI, _ = url.Parse(srv.URL + "/jwks.json")
+
+	for _, c := range []*domain.Client{secretClient, keyClient} {
+		if err = registry.Create(context.Background(), *c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertion := func(aud, jti string) string {
+		tkn, err := jwt.NewBuilder().
+			Issuer(keyClient.ID.String()).
+			Subject(keyClient.ID.String()).
+			Audience([]string{aud}).
+			JwtID(jti).
+			Expiration(time.Now().Add(time.Minute)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signed, err := jwt.Sign(tkn, jwt.WithKey(jwa.ES256, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(signed)
+	}
+
+	for name, tc := range map[string]struct {
+		expError error
+		in       client.AuthenticateOptions
+	}{
+		"secret": {
+			in: client.AuthenticateOptions{
+				ClientID: secretClient.ID,
+				Method:   domain.ClientAuthMethodClientSecretPost,
+				Secret:   "secret",
+			},
+		},
+		"invalid secret": {
+			in: client.AuthenticateOptions{
+				ClientID: secretClient.ID,
+				Method:   domain.ClientAuthMethodClientSecretBasic,
+				Secret:   "wrong",
+			},
+			expError: client.ErrInvalidCredentials,
+		},
+		"none for confidential": {
+			in: client.AuthenticateOptions{
+				ClientID: secretClient.ID,
+				Method:   domain.ClientAuthMethodNone,
+			},
+			expError: client.ErrAuthenticationRequired,
+		},
+		"private key jwt": {
+			in: client.AuthenticateOptions{
+				ClientID:  keyClient.ID,
+				Method:    domain.ClientAuthMethodPrivateKeyJWT,
+				Assertion: assertion("https://example.com/token", "a1b2c3"),
+				Audience:  []string{"https://example.com/", "https://example.com/token"},
+			},
+		},
+		"private key jwt for another audience": {
+			in: client.AuthenticateOptions{
+				ClientID:  keyClient.ID,
+				Method:    domain.ClientAuthMethodPrivateKeyJWT,
+				Assertion: assertion("https://example.net/token", "d4e5f6"),
+				Audience:  []string{"https://example.com/", "https://example.com/token"},
+			},
+			expError: client.ErrInvalidCredentials,
+		},
+		"secret for private key jwt client": {
+			in: client.AuthenticateOptions{
+				ClientID: keyClient.ID,
+				Method:   domain.ClientAuthMethodClientSecretBasic,
+				Secret:   "secret",
+			},
+			expError: client.ErrUnsupportedAuthMethod,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := usecase.NewClientUseCase(repository.NewMemoryClientRepository(), registry,
+				httprepo.NewHTTPKeySetRepository(srv.Client()), nil, replayrepo.NewMemoryReplayRepository(), nil).
+				Authenticate(context.Background(), tc.in)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Authenticate(%s) = %+v, want %+v", tc.in.ClientID, err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Authenticate(%s) = %+v, want %+v", tc.in.ClientID, err, nil)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_Replay(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwk.FromRaw(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jwk.NewSet()
+	_ = set.AddKey(publicKey)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	keyClient := domain.TestClient(t)
+	keyClient.ID = *parseClientID(t, "aPq-4kXw2nLcZs8H1y3_m")
+	keyClient.AuthMethod = domain.ClientAuthMethodPrivateKeyJWT
+	keyClient.JWKSURI, _ = url.Parse(srv.URL + "/jwks.json")
+
+	registry := repository.NewMemoryClientRepository()
+	if err = registry.Create(context.Background(), *keyClient); err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := jwt.NewBuilder().
+		Issuer(keyClient.ID.String()).
+		Subject(keyClient.ID.String()).
+		Audience([]string{"https://example.com/token"}).
+		JwtID("a1b2c3").
+		Expiration(time.Now().Add(time.Minute)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertion, err := jwt.Sign(tkn, jwt.WithKey(jwa.ES256, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clients := usecase.NewClientUseCase(repository.NewMemoryClientRepository(), registry,
+		httprepo.NewHTTPKeySetRepository(srv.Client()), nil, replayrepo.NewMemoryReplayRepository(), nil)
+	opts := client.AuthenticateOptions{
+		ClientID:  keyClient.ID,
+		Method:    domain.ClientAuthMethodPrivateKeyJWT,
+		Assertion: string(assertion),
+		Audience:  []string{"https://example.com/token"},
+	}
+
+	if _, err = clients.Authenticate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = clients.Authenticate(context.Background(), opts); !errors.Is(err, replay.ErrExist) ||
+		!errors.Is(err, client.ErrInvalidCredentials) {
+		t.Errorf("Authenticate(%s) = %+v, want %+v", opts.ClientID, err, replay.ErrExist)
+	}
+}
+
+//nolint:funlen
+func TestRequestObject(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwk.FromRaw(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jwk.NewSet()
+	_ = set.AddKey(publicKey)
+
+	keyClient := domain.TestClient(t)
+	keyClient.ID = *parseClientID(t, "aPq-4kXw2nLcZs8H1y3_m")
+
+	request := func(iss string, claims map[string]any) string {
+		tkn, err := jwt.NewBuilder().
+			Issuer(iss).
+			Audience([]string{"https://example.com/"}).
+			Expiration(time.Now().Add(time.Minute)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for k, v := range claims {
+			_ = tkn.Set(k, v)
+		}
+
+		signed, err := jwt.Sign(tkn, jwt.WithKey(jwa.ES256, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(signed)
+	}
+
+	signed := request(keyClient.ID.String(), map[string]any{
+		"client_id": keyClient.ID.String(),
+		"scope":     "profile email",
+		"state":     "1234567890",
+	})
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwks.json":
+			_ = json.NewEncoder(w).Encode(set)
+		case "/request.jwt":
+			_, _ = w.Write([]byte(signed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	keyClient.JWKSURI, _ = url.Parse(srv.URL + "/jwks.json")
+	registry := repository.NewMemoryClientRepository()
+
+	if err = registry.Create(context.Background(), *keyClient); err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned, err := jwt.Sign(jwt.New(), jwt.WithInsecureNoSignature())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		expError error
+		in       client.RequestObjectOptions
+	}{
+		"value": {
+			in: client.RequestObjectOptions{Request: signed},
+		},
+		"reference": {
+			in: client.RequestObjectOptions{RequestURI: &url.URL{
+				Scheme: "https",
+				Host:   strings.TrimPrefix(srv.URL, "https://"),
+				Path:   "/request.jwt",
+			}},
+		},
+		"unsigned": {
+			in:       client.RequestObjectOptions{Request: string(unsigned)},
+			expError: client.ErrInvalidRequestObject,
+		},
+		"another issuer": {
+			in:       client.RequestObjectOptions{Request: request("https://evil.example.com/", nil)},
+			expError: client.ErrInvalidRequestObject,
+		},
+		"another client_id": {
+			in: client.RequestObjectOptions{Request: request(keyClient.ID.String(), map[string]any{
+				"client_id": "https://evil.example.com/",
+			})},
+			expError: client.ErrInvalidRequestObject,
+		},
+		"nested request": {
+			in: client.RequestObjectOptions{Request: request(keyClient.ID.String(), map[string]any{
+				"request_uri": srv.URL + "/request.jwt",
+			})},
+			expError: client.ErrInvalidRequestObject,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.in.ClientID = keyClient.ID
+			tc.in.Audience = []string{"https://example.com/"}
+
+			result, err := usecase.NewClientUseCase(repository.NewMemoryClientRepository(), registry,
+				httprepo.NewHTTPKeySetRepository(srv.Client()),
+				httprepo.NewHTTPRequestObjectRepository(srv.Client()), nil, nil).
+				RequestObject(context.Background(), tc.in)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("RequestObject(%s) = %+v, want %+v", tc.in.ClientID, err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RequestObject(%s) = %+v, want %+v", tc.in.ClientID, err, nil)
+			}
+
+			if result["state"] != "1234567890" || result["scope"] != "profile email" {
+				t.Errorf("RequestObject(%s) = %+v, want state and scope claims", tc.in.ClientID, result)
+			}
+
+			if _, ok := result[jwt.IssuerKey]; ok {
+				t.Errorf("RequestObject(%s) = %+v, want without registered claims", tc.in.ClientID, result)
+			}
+		})
+	}
+}
+
+func parseClientID(tb testing.TB, src string) *domain.ClientID {
+	tb.Helper()
+
+	cid, err := domain.ParseClientID(src)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return cid
+}
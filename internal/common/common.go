@@ -3,13 +3,15 @@ package common
 const charsetUTF8 = "charset=UTF-8"
 
 const (
-	MIMEApplicationForm            string = "application/x-www-form-urlencoded"
-	MIMEApplicationJSON            string = "application/json"
-	MIMEApplicationJSONCharsetUTF8 string = MIMEApplicationJSON + "; " + charsetUTF8
-	MIMETextHTML                   string = "text/html"
-	MIMETextHTMLCharsetUTF8        string = MIMETextHTML + "; " + charsetUTF8
-	MIMETextPlain                  string = "text/plain"
-	MIMETextPlainCharsetUTF8       string = MIMETextPlain + "; " + charsetUTF8
+	MIMEApplicationForm             string = "application/x-www-form-urlencoded"
+	MIMEApplicationJSON             string = "application/json"
+	MIMEApplicationJSONCharsetUTF8  string = MIMEApplicationJSON + "; " + charsetUTF8
+	MIMEApplicationJWKSet           string = "application/jwk-set+json"
+	MIMEApplicationOAuthAuthzReqJWT string = "application/oauth-authz-req+jwt"
+	MIMETextHTML                    string = "text/html"
+	MIMETextHTMLCharsetUTF8         string = MIMETextHTML + "; " + charsetUTF8
+	MIMETextPlain                   string = "text/plain"
+	MIMETextPlainCharsetUTF8        string = MIMETextPlain + "; " + charsetUTF8
 )
 
 const (
@@ -17,14 +19,26 @@ const (
 	HeaderAcceptLanguage           string = "Accept-Language"
 	HeaderAccessControlAllowOrigin string = "Access-Control-Allow-Origin"
 	HeaderAuthorization            string = "Authorization"
+	HeaderCacheControl             string = "Cache-Control"
+	HeaderContentLength            string = "Content-Length"
+	HeaderContentSecurityPolicy    string = "Content-Security-Policy"
 	HeaderContentType              string = "Content-Type"
 	HeaderCookie                   string = "Cookie"
+	HeaderDPoP                     string = "DPoP"
 	HeaderHost                     string = "Host"
 	HeaderLink                     string = "Link"
 	HeaderLocation                 string = "Location"
+	HeaderPragma                   string = "Pragma"
 	HeaderVary                     string = "Vary"
 	HeaderWWWAuthenticate          string = "WWW-Authenticate"
+	HeaderXAuthMe                  string = "X-Auth-Me"
+	HeaderXAuthScope               string = "X-Auth-Scope"
 	HeaderXCSRFToken               string = "X-CSRF-Token"
+	HeaderXContentTypeOptions      string = "X-Content-Type-Options"
+	HeaderXForwardedHost           string = "X-Forwarded-Host"
+	HeaderXForwardedProto          string = "X-Forwarded-Proto"
+	HeaderXForwardedURI            string = "X-Forwarded-Uri"
+	HeaderXOriginalURL             string = "X-Original-URL"
 )
 
 const (
@@ -45,6 +59,7 @@ const (
 	RelAuthn                 string = "authn"
 	RelAuthorizationEndpoint string = "authorization_endpoint"
 	RelIndieAuthMetadata     string = "indieauth-metadata"
+	RelJWKSURI               string = "jwks_uri"
 	RelMicropub              string = "micropub"
 	RelMicrosub              string = "microsub"
 	RelRedirectURI           string = "redirect_uri"
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/middleware"
+	"source.toby3d.me/toby3d/auth/internal/urlutil"
+)
+
+type Handler struct {
+	consents consent.UseCase
+	config   domain.Config
+}
+
+// NewHandler creates a new admin API handler of the consent screen risk
+// reports. It's protected by the owner credentials.
+func NewHandler(consents consent.UseCase, config domain.Config) *Handler {
+	return &Handler{
+		config:   config,
+		consents: consents,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	//nolint:exhaustivestruct
+	chain := middleware.Chain{
+		middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+			Validator: func(_ http.ResponseWriter, _ *http.Request, login, password string) (bool, error) {
+				userMatch := subtle.ConstantTimeCompare([]byte(login),
+					[]byte(h.config.IndieAuth.Username))
+				passMatch := subtle.ConstantTimeCompare([]byte(password),
+					[]byte(h.config.IndieAuth.Password))
+
+				return userMatch == 1 && passMatch == 1, nil
+			},
+			Realm: "",
+		}),
+	}
+
+	head, _ := urlutil.ShiftPath(r.URL.Path)
+
+	switch {
+	default:
+		http.NotFound(w, r)
+	case r.Method != http.MethodGet && r.Method != "":
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	case head == "report":
+		chain.Handler(h.handleReport).ServeHTTP(w, r)
+	}
+}
+
+func (h *Handler) handleReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+
+	encoder := json.NewEncoder(w)
+
+	req := NewConsentReportRequest()
+	if err := req.bind(r); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		_ = encoder.Encode(err)
+
+		return
+	}
+
+	report, err := h.consents.Assess(r.Context(), consent.AssessOptions{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI.URL,
+	})
+	if err != nil {
+		var target *domain.Error
+		if !errors.As(err, &target) {
+			target = domain.NewError(domain.ErrorCodeServerError, err.Error(), "")
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+
+		_ = encoder.Encode(target)
+
+		return
+	}
+
+	_ = encoder.Encode(NewConsentReportResponse(report))
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/form"
+)
+
+type (
+	ConsentReportRequest struct {
+		ClientID    domain.ClientID `form:"client_id"`
+		RedirectURI domain.URL      `form:"redirect_uri"`
+	}
+
+	//nolint:tagliatelle
+	ConsentReportResponse struct {
+		ClientID    string                  `json:"client_id"`
+		ClientName  string                  `json:"client_name,omitempty"`
+		RedirectURI string                  `json:"redirect_uri"`
+		Warnings    []domain.ConsentWarning `json:"warnings"`
+	}
+)
+
+func NewConsentReportRequest() *ConsentReportRequest {
+	return &ConsentReportRequest{
+		ClientID:    domain.ClientID{},
+		RedirectURI: domain.URL{},
+	}
+}
+
+func (r *ConsentReportRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), "")
+	}
+
+	if r.RedirectURI.URL == nil {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "redirect_uri is required", "")
+	}
+
+	return nil
+}
+
+func NewConsentReportResponse(report *domain.ConsentReport) *ConsentReportResponse {
+	out := &ConsentReportResponse{
+		ClientID:    report.Client.ID.String(),
+		ClientName:  report.Client.Name,
+		RedirectURI: "",
+		Warnings:    report.Warnings,
+	}
+
+	if report.RedirectURI != nil {
+		out.RedirectURI = report.RedirectURI.String()
+	}
+
+	return out
+}
@@ -0,0 +1,80 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"golang.org/x/exp/slices"
+
+	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	delivery "source.toby3d.me/toby3d/auth/internal/consent/delivery/http"
+	repository "source.toby3d.me/toby3d/auth/internal/consent/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/consent/usecase"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	client := domain.TestClient(t)
+	clients := clientrepo.NewMemoryClientRepository()
+
+	if err := clients.Create(context.Background(), *client); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := delivery.NewHandler(usecase.NewConsentUseCase(
+		clientucase.NewClientUseCase(clients, clients, nil, nil, nil, nil), repository.NewMemoryConsentRepository(), nil),
+		*config)
+
+	q := make(url.Values)
+	q.Set("client_id", client.ID.String())
+	q.Set("redirect_uri", client.RedirectURI[0].String())
+
+	t.Run("unauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/report?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if resp := w.Result(); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode,
+				http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/report?"+q.Encode(), nil)
+		req.SetBasicAuth(config.IndieAuth.Username, config.IndieAuth.Password)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusOK)
+		}
+
+		result := new(delivery.ConsentReportResponse)
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result.ClientID != client.ID.String() {
+			t.Errorf("ClientID = %s, want %s", result.ClientID, client.ID)
+		}
+
+		if !slices.Contains(result.Warnings, domain.ConsentWarningNewClient) {
+			t.Errorf("Warnings = %v, want contains %s", result.Warnings, domain.ConsentWarningNewClient)
+		}
+	})
+}
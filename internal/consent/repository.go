@@ -0,0 +1,20 @@
+package consent
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Create stores the consent for the client. Consent which already
+	// exists for the client is replaced, except its creation time.
+	Create(ctx context.Context, consent domain.Consent) error
+	Get(ctx context.Context, cid domain.ClientID) (*domain.Consent, error)
+}
+
+var ErrNotExist error = domain.NewError(
+	domain.ErrorCodeServerError,
+	"consent for this client does not exist",
+	"",
+)
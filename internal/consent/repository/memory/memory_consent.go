@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type memoryConsentRepository struct {
+	mutex    *sync.RWMutex
+	consents map[string]domain.Consent
+}
+
+func NewMemoryConsentRepository() consent.Repository {
+	return &memoryConsentRepository{
+		mutex:    new(sync.RWMutex),
+		consents: make(map[string]domain.Consent),
+	}
+}
+
+func (repo *memoryConsentRepository) Create(_ context.Context, c domain.Consent) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if prev, ok := repo.consents[c.ClientID.String()]; ok {
+		c.CreatedAt = prev.CreatedAt
+	}
+
+	repo.consents[c.ClientID.String()] = c
+
+	return nil
+}
+
+func (repo *memoryConsentRepository) Get(_ context.Context, cid domain.ClientID) (*domain.Consent, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	if c, ok := repo.consents[cid.String()]; ok {
+		return &c, nil
+	}
+
+	return nil, consent.ErrNotExist
+}
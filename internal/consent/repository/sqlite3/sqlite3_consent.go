@@ -0,0 +1,108 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	Consent struct {
+		ClientID  string       `db:"client_id"`
+		Me        string       `db:"me"`
+		Scope     string       `db:"scope"`
+		CreatedAt sql.NullTime `db:"created_at"`
+	}
+
+	sqlite3ConsentRepository struct {
+		db *sqlx.DB
+	}
+)
+
+const (
+	QueryTable string = `CREATE TABLE IF NOT EXISTS consents (
+		client_id TEXT UNIQUE PRIMARY KEY NOT NULL,
+		created_at DATETIME NOT NULL,
+		me TEXT NOT NULL,
+		scope TEXT
+	);`
+
+	QueryGet string = `SELECT *
+		FROM consents
+		WHERE client_id=$1;`
+
+	QueryCreate string = `INSERT INTO consents (client_id, created_at, me, scope)
+		VALUES (:client_id, :created_at, :me, :scope)
+		ON CONFLICT (client_id) DO UPDATE SET me=excluded.me, scope=excluded.scope;`
+)
+
+func NewSQLite3ConsentRepository(db *sqlx.DB) consent.Repository {
+	db.MustExec(QueryTable)
+
+	return &sqlite3ConsentRepository{
+		db: db,
+	}
+}
+
+func (repo *sqlite3ConsentRepository) Create(ctx context.Context, c domain.Consent) error {
+	if _, err := repo.db.NamedExecContext(ctx, QueryCreate, NewConsent(&c)); err != nil {
+		return fmt.Errorf("cannot create consent record in db: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ConsentRepository) Get(ctx context.Context, cid domain.ClientID) (*domain.Consent, error) {
+	c := new(Consent)
+	if err := repo.db.GetContext(ctx, c, QueryGet, cid.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, consent.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot find consent in db: %w", err)
+	}
+
+	result := &domain.Consent{
+		ClientID: cid,
+	}
+	c.Populate(result)
+
+	return result, nil
+}
+
+func NewConsent(src *domain.Consent) *Consent {
+	return &Consent{
+		ClientID:  src.ClientID.String(),
+		Me:        src.Me.String(),
+		Scope:     src.Scope.String(),
+		CreatedAt: sql.NullTime{Time: src.CreatedAt.UTC(), Valid: true},
+	}
+}
+
+func (c *Consent) Populate(dst *domain.Consent) {
+	if me, err := domain.ParseMe(c.Me); err == nil {
+		dst.Me = *me
+	}
+
+	if c.CreatedAt.Valid {
+		dst.CreatedAt = c.CreatedAt.Time
+	}
+
+	dst.Scope = make(domain.Scopes, 0)
+
+	for _, scope := range strings.Fields(c.Scope) {
+		s, err := domain.ParseScope(scope)
+		if err != nil {
+			continue
+		}
+
+		dst.Scope = append(dst.Scope, s)
+	}
+}
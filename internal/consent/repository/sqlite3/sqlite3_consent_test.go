@@ -0,0 +1,68 @@
+package sqlite3_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	repository "source.toby3d.me/toby3d/auth/internal/consent/repository/sqlite3"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/testing/sqltest"
+)
+
+//nolint:gochecknoglobals // slices cannot be contants
+var tableColumns = []string{"client_id", "created_at", "me", "scope"}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	consent := domain.TestConsent(t)
+	model := repository.NewConsent(consent)
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO consents`)).
+		WithArgs(model.ClientID, sqltest.Time{}, model.Me, model.Scope).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repository.NewSQLite3ConsentRepository(db).Create(context.Background(), *consent); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	consent := domain.TestConsent(t)
+	model := repository.NewConsent(consent)
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM consents`)).
+		WithArgs(model.ClientID).
+		WillReturnRows(sqlmock.NewRows(tableColumns).
+			AddRow(model.ClientID, model.CreatedAt.Time, model.Me, model.Scope))
+
+	result, err := repository.NewSQLite3ConsentRepository(db).Get(context.Background(), consent.ClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Me.String() != consent.Me.String() || !result.CreatedAt.Equal(consent.CreatedAt) ||
+		result.Scope.String() != consent.Scope.String() {
+		t.Errorf("Get(%s) = %+v, want %+v", consent.ClientID, result, consent)
+	}
+}
+
+func createTable(tb testing.TB, mock sqlmock.Sqlmock) {
+	tb.Helper()
+
+	mock.ExpectExec(regexp.QuoteMeta(repository.QueryTable)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
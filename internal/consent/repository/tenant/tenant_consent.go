@@ -0,0 +1,40 @@
+package tenant
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type tenantConsentRepository struct {
+	repo      consent.Repository
+	namespace string
+}
+
+// NewTenantConsentRepository creates a new consents repository which stores
+// consents given by the owner of the tenant in the provided shared repository
+// under its own namespace.
+func NewTenantConsentRepository(repo consent.Repository, namespace string) consent.Repository {
+	return &tenantConsentRepository{
+		namespace: namespace,
+		repo:      repo,
+	}
+}
+
+func (repo *tenantConsentRepository) Create(ctx context.Context, c domain.Consent) error {
+	c.ClientID = domain.NewTenantClientID(repo.namespace, c.ClientID)
+
+	return repo.repo.Create(ctx, c) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantConsentRepository) Get(ctx context.Context, cid domain.ClientID) (*domain.Consent, error) {
+	out, err := repo.repo.Get(ctx, domain.NewTenantClientID(repo.namespace, cid))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // decorator returns errors as is
+	}
+
+	out.ClientID = cid
+
+	return out, nil
+}
@@ -0,0 +1,43 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/consent/repository/memory"
+	repository "source.toby3d.me/toby3d/auth/internal/consent/repository/tenant"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	shared := memory.NewMemoryConsentRepository()
+	alice := repository.NewTenantConsentRepository(shared, "alice")
+	bob := repository.NewTenantConsentRepository(shared, "bob")
+	cid := domain.TestClientID(t)
+
+	if err := alice.Create(context.Background(), domain.Consent{
+		CreatedAt: time.Now().UTC(),
+		ClientID:  *cid,
+		Me:        *domain.TestMe(t, "https://alice.example.net/"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bob.Get(context.Background(), *cid); !errors.Is(err, consent.ErrNotExist) {
+		t.Errorf("Get(%s) = %v, want %v", cid, err, consent.ErrNotExist)
+	}
+
+	result, err := alice.Get(context.Background(), *cid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.ClientID.IsEqual(*cid) {
+		t.Errorf("Get(%s) = %s, want %s", cid, result.ClientID, cid)
+	}
+}
@@ -0,0 +1,41 @@
+package consent
+
+import (
+	"context"
+	"net/url"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	AssessOptions struct {
+		RedirectURI *url.URL
+		ClientID    domain.ClientID
+		// Exact requires redirect URI to be exactly equal to one of
+		// the published by the client.
+		Exact bool
+	}
+
+	UseCase interface {
+		// Assess discovers the client, validates requested redirect URI
+		// and reports risks of the authorization request which owner
+		// must be warned about on the consent screen.
+		Assess(ctx context.Context, opts AssessOptions) (*domain.ConsentReport, error)
+
+		// Grant remembers that owner has authorized the client, so it
+		// is not reported as a new one anymore. Scopes granted before on
+		// behalf of the same identity are kept.
+		Grant(ctx context.Context, consent domain.Consent) error
+
+		// Covers reports whether the owner has already granted all of
+		// provided scopes to the client on behalf of me, so it can be
+		// authorized without interaction.
+		Covers(ctx context.Context, cid domain.ClientID, me domain.Me, scope domain.Scopes) (bool, error)
+	}
+)
+
+var ErrRedirectURI error = domain.NewError(
+	domain.ErrorCodeInvalidClient,
+	"requested redirect_uri is not registered on client_id side",
+	"https://indieauth.net/source/#authorization-request",
+)
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"unicode"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type consentUseCase struct {
+	clients  client.UseCase
+	clock    domain.Clock
+	consents consent.Repository
+}
+
+// confusableScripts contains scripts which letters are commonly used to
+// imitate each other in host names.
+//
+//nolint:gochecknoglobals // slices cannot be constants
+var confusableScripts = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Cyrillic,
+	unicode.Greek,
+	unicode.Armenian,
+	unicode.Georgian,
+	unicode.Cherokee,
+}
+
+// NewConsentUseCase creates a new consent use case. System clock is used if
+// clock is nil.
+func NewConsentUseCase(clients client.UseCase, consents consent.Repository, clock domain.Clock) consent.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
+	return &consentUseCase{
+		clients:  clients,
+		clock:    clock,
+		consents: consents,
+	}
+}
+
+//nolint:cyclop
+func (uc *consentUseCase) Assess(ctx context.Context, opts consent.AssessOptions) (*domain.ConsentReport, error) {
+	out := &domain.ConsentReport{
+		Client:      nil,
+		RedirectURI: opts.RedirectURI,
+		Warnings:    make([]domain.ConsentWarning, 0),
+	}
+
+	var err error
+	if out.Client, err = uc.clients.Discovery(ctx, opts.ClientID); err != nil {
+		if opts.ClientID.IsOpaque() {
+			return nil, fmt.Errorf("cannot discovery client: %w", err)
+		}
+
+		// NOTE(toby3d): client page is unavailable, so only redirect
+		// URIs on the client_id host can be used.
+		out.Client = domain.NewClient(opts.ClientID)
+		out.Warnings = append(out.Warnings, domain.ConsentWarningUnreachable)
+	}
+
+	if !out.Client.ValidateRedirectURI(opts.RedirectURI) ||
+		(opts.Exact && !out.Client.ValidateRedirectURIExact(opts.RedirectURI)) {
+		return nil, consent.ErrRedirectURI
+	}
+
+	if _, err = uc.consents.Get(ctx, opts.ClientID); err != nil {
+		if !errors.Is(err, consent.ErrNotExist) {
+			return nil, fmt.Errorf("cannot check client consent: %w", err)
+		}
+
+		out.Warnings = append(out.Warnings, domain.ConsentWarningNewClient)
+	}
+
+	if domain.IsNativeRedirectURI(opts.RedirectURI) {
+		out.Warnings = append(out.Warnings, domain.ConsentWarningNativeApp)
+	}
+
+	// NOTE(toby3d): registered clients do not have URL identifier to
+	// compare with.
+	cid := opts.ClientID.URL()
+	if cid == nil {
+		return out, nil
+	}
+
+	if !domain.IsNativeRedirectURI(opts.RedirectURI) &&
+		!strings.EqualFold(cid.Hostname(), opts.RedirectURI.Hostname()) {
+		out.Warnings = append(out.Warnings, domain.ConsentWarningRedirectMismatch)
+	}
+
+	if isConfusableHost(cid.Hostname()) {
+		out.Warnings = append(out.Warnings, domain.ConsentWarningHomoglyph)
+	}
+
+	if (cid.Scheme == "http" && !opts.ClientID.IsLocalhost() && !isLocalhost(cid.Hostname())) ||
+		(opts.RedirectURI.Scheme == "http" && !domain.IsNativeRedirectURI(opts.RedirectURI) &&
+			!isLocalhost(opts.RedirectURI.Hostname())) {
+		out.Warnings = append(out.Warnings, domain.ConsentWarningInsecure)
+	}
+
+	return out, nil
+}
+
+func (uc *consentUseCase) Grant(ctx context.Context, c domain.Consent) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = uc.clock.Now()
+	}
+
+	prev, err := uc.consents.Get(ctx, c.ClientID)
+	if err != nil && !errors.Is(err, consent.ErrNotExist) {
+		return fmt.Errorf("cannot check client consent: %w", err)
+	}
+
+	// NOTE(toby3d): scopes granted on behalf of another identity are
+	// forgotten, so they cannot be authorized silently for this one.
+	if prev != nil && prev.Me.String() == c.Me.String() {
+		c.Scope = slices.Clone(c.Scope)
+
+		for i := range prev.Scope {
+			if !c.Scope.Has(prev.Scope[i]) {
+				c.Scope = append(c.Scope, prev.Scope[i])
+			}
+		}
+	}
+
+	if err = uc.consents.Create(ctx, c); err != nil {
+		return fmt.Errorf("cannot save client consent: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *consentUseCase) Covers(ctx context.Context, cid domain.ClientID, me domain.Me, scope domain.Scopes,
+) (bool, error) {
+	c, err := uc.consents.Get(ctx, cid)
+	if err != nil {
+		if errors.Is(err, consent.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("cannot check client consent: %w", err)
+	}
+
+	return c.Covers(me, scope), nil
+}
+
+// isConfusableHost reports whether host contains punycode labels or labels
+// which mix letters of different scripts, e.g. latin "a" and cyrillic "а".
+func isConfusableHost(host string) bool {
+	for _, label := range strings.Split(strings.ToLower(host), ".") {
+		if strings.HasPrefix(label, "xn--") {
+			return true
+		}
+
+		var script *unicode.RangeTable
+
+		for _, r := range label {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+
+			for _, table := range confusableScripts {
+				if !unicode.Is(table, r) {
+					continue
+				}
+
+				if script != nil && script != table {
+					return true
+				}
+
+				script = table
+			}
+		}
+	}
+
+	return false
+}
+
+func isLocalhost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,215 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	repository "source.toby3d.me/toby3d/auth/internal/consent/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/consent/usecase"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+//nolint:funlen
+func TestAssess(t *testing.T) {
+	t.Parallel()
+
+	clients := clientrepo.NewMemoryClientRepository()
+	consents := repository.NewMemoryConsentRepository()
+
+	trusted := domain.TestClient(t)
+	trusted.ID = *domain.TestClientID(t, "https://app.example.com/")
+
+	fresh := domain.TestClient(t)
+	fresh.ID = *domain.TestClientID(t, "https://new.example.com/")
+	fresh.RedirectURI = []*url.URL{{Scheme: "https", Host: "evil.example.net", Path: "/callback"}}
+
+	insecure := domain.TestClient(t)
+	insecure.ID = *domain.TestClientID(t, "http://insecure.example.com/")
+
+	confusable := domain.TestClient(t)
+	confusable.ID = *domain.TestClientID(t, "https://xn--pple-43d.example/")
+
+	for _, c := range []*domain.Client{trusted, fresh, insecure, confusable} {
+		if err := clients.Create(context.Background(), *c); err != nil {
+			t.Fatal(err)
+		}
+
+		if c == fresh {
+			continue
+		}
+
+		if err := consents.Create(context.Background(), domain.Consent{ClientID: c.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	consentService := usecase.NewConsentUseCase(clientucase.NewClientUseCase(clients, clients, nil, nil, nil, nil),
+		consents, nil)
+
+	for name, tc := range map[string]struct {
+		expError    error
+		clientID    domain.ClientID
+		redirectURI string
+		expWarnings []domain.ConsentWarning
+		exact       bool
+	}{
+		"trusted": {
+			clientID:    trusted.ID,
+			redirectURI: "https://app.example.com/callback",
+			expWarnings: []domain.ConsentWarning{},
+		},
+		"new client on another host": {
+			clientID:    fresh.ID,
+			redirectURI: "https://evil.example.net/callback",
+			expWarnings: []domain.ConsentWarning{
+				domain.ConsentWarningNewClient,
+				domain.ConsentWarningRedirectMismatch,
+			},
+		},
+		"unreachable": {
+			clientID:    *domain.TestClientID(t, "https://unknown.example.com/"),
+			redirectURI: "https://unknown.example.com/callback",
+			expWarnings: []domain.ConsentWarning{
+				domain.ConsentWarningUnreachable,
+				domain.ConsentWarningNewClient,
+			},
+		},
+		"native": {
+			clientID:    trusted.ID,
+			redirectURI: "com.example.app:/callback",
+			expWarnings: []domain.ConsentWarning{domain.ConsentWarningNativeApp},
+		},
+		"insecure": {
+			clientID:    insecure.ID,
+			redirectURI: "http://insecure.example.com/callback",
+			expWarnings: []domain.ConsentWarning{domain.ConsentWarningInsecure},
+		},
+		"punycode": {
+			clientID:    confusable.ID,
+			redirectURI: "https://xn--pple-43d.example/callback",
+			expWarnings: []domain.ConsentWarning{domain.ConsentWarningHomoglyph},
+		},
+		"mixed scripts": {
+			clientID:    *domain.TestClientID(t, "https://аpple.example/"),
+			redirectURI: "https://аpple.example/callback",
+			expWarnings: []domain.ConsentWarning{
+				domain.ConsentWarningUnreachable,
+				domain.ConsentWarningNewClient,
+				domain.ConsentWarningHomoglyph,
+			},
+		},
+		"not registered redirect": {
+			clientID:    trusted.ID,
+			redirectURI: "https://evil.example.net/callback",
+			expError:    consent.ErrRedirectURI,
+		},
+		"exact registered redirect": {
+			clientID:    trusted.ID,
+			redirectURI: "https://app.example.com/redirect",
+			expWarnings: []domain.ConsentWarning{},
+			exact:       true,
+		},
+		"exact same host redirect": {
+			clientID:    trusted.ID,
+			redirectURI: "https://app.example.com/callback",
+			expError:    consent.ErrRedirectURI,
+			exact:       true,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			redirectURI, err := url.Parse(tc.redirectURI)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := consentService.Assess(context.Background(), consent.AssessOptions{
+				ClientID:    tc.clientID,
+				RedirectURI: redirectURI,
+				Exact:       tc.exact,
+			})
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Assess(%s) = %+v, want %+v", tc.clientID, err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(result.Warnings, tc.expWarnings) {
+				t.Errorf("Assess(%s) = %v, want %v", tc.clientID, result.Warnings, tc.expWarnings)
+			}
+		})
+	}
+}
+
+func TestGrant(t *testing.T) {
+	t.Parallel()
+
+	consents := repository.NewMemoryConsentRepository()
+	consentService := usecase.NewConsentUseCase(nil, consents, nil)
+	in := domain.TestConsent(t)
+
+	if err := consentService.Grant(context.Background(), *in); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := consents.Get(context.Background(), in.ClientID); err != nil {
+		t.Errorf("Grant(%s) = %+v, want %+v", in.ClientID, err, nil)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	t.Parallel()
+
+	consentService := usecase.NewConsentUseCase(nil, repository.NewMemoryConsentRepository(), nil)
+	in := domain.TestConsent(t)
+
+	for _, scope := range []domain.Scopes{{domain.ScopeProfile}, {domain.ScopeCreate}} {
+		c := *in
+		c.Scope = scope
+
+		if err := consentService.Grant(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		me    *domain.Me
+		scope domain.Scopes
+		exp   bool
+	}{
+		"granted":      {me: &in.Me, scope: domain.Scopes{domain.ScopeProfile, domain.ScopeCreate}, exp: true},
+		"exceeded":     {me: &in.Me, scope: domain.Scopes{domain.ScopeProfile, domain.ScopeUpdate}},
+		"other me":     {me: domain.TestMe(t, "https://other.example.net/"), scope: domain.Scopes{domain.ScopeProfile}},
+		"empty scopes": {me: &in.Me, scope: domain.Scopes{}, exp: true},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := consentService.Covers(context.Background(), in.ClientID, *tc.me, tc.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != tc.exp {
+				t.Errorf("Covers(%s, %s) = %t, want %t", tc.me, tc.scope, result, tc.exp)
+			}
+		})
+	}
+}
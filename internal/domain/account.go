@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// Account describes the owner which can sign in by the single login and use
+// any of the verified profile URLs as identity.
+type Account struct {
+	CreatedAt  time.Time
+	Username   string
+	Identities []*Me
+}
+
+// TestAccount returns valid random generated account for tests.
+func TestAccount(tb testing.TB) *Account {
+	tb.Helper()
+
+	return &Account{
+		CreatedAt: time.Now().UTC().Add(-1 * time.Hour),
+		Username:  "user",
+		Identities: []*Me{
+			TestMe(tb, "https://user.example.net/"),
+			TestMe(tb, "https://photos.example.net/"),
+		},
+	}
+}
+
+// HasIdentity reports whether account owns provided profile URL.
+func (a Account) HasIdentity(me Me) bool {
+	for i := range a.Identities {
+		if a.Identities[i] != nil && a.Identities[i].String() == me.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddIdentity appends provided profile URL to the account identities, if it
+// is not already there.
+func (a *Account) AddIdentity(me Me) {
+	if a.HasIdentity(me) {
+		return
+	}
+
+	a.Identities = append(a.Identities, &me)
+}
@@ -0,0 +1,29 @@
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestAccount_AddIdentity(t *testing.T) {
+	t.Parallel()
+
+	account := domain.TestAccount(t)
+	me := domain.TestMe(t, "https://blog.example.net/")
+
+	if account.HasIdentity(*me) {
+		t.Fatalf("HasIdentity(%s) = %t, want %t", me, true, false)
+	}
+
+	account.AddIdentity(*me)
+	account.AddIdentity(*me)
+
+	if !account.HasIdentity(*me) {
+		t.Errorf("HasIdentity(%s) = %t, want %t", me, false, true)
+	}
+
+	if len(account.Identities) != 3 { //nolint:gomnd
+		t.Errorf("len(Identities) = %d, want %d", len(account.Identities), 3)
+	}
+}
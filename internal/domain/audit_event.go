@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// AuditEvent describes a security-relevant event which is recorded for the
+// server owner.
+//
+//nolint:tagliatelle
+type AuditEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+	ClientID  ClientID  `json:"client_id"`
+	Me        Me        `json:"me"`
+	Type      string    `json:"type"`
+	TokenIDs  []string  `json:"token_ids,omitempty"`
+}
+
+// AuditEventCodeReplay is recorded when an already redeemed authorization
+// code is presented again, which means that the code has been leaked.
+const AuditEventCodeReplay string = "code_replay"
+
+// AuditEventRefreshTokenReplay is recorded when an already rotated refresh
+// token is presented again, which means that the token has been leaked.
+const AuditEventRefreshTokenReplay string = "refresh_token_replay"
@@ -1,32 +1,87 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"net"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Client describes the client requesting data about the user.
 type Client struct {
-	Logo        *url.URL
-	URL         *url.URL
-	ID          ClientID
-	Name        string
-	RedirectURI []*url.URL
+	// Time of the dynamic client registration.
+	CreatedAt time.Time
+
+	// Time at which the client secret will expire or zero if it will not
+	// expire.
+	SecretExpiresAt time.Time
+
+	Logo *url.URL
+	URL  *url.URL
+
+	// URL of the client's JSON Web Key Set document with public keys
+	// used for private_key_jwt authentication.
+	JWKSURI *url.URL
+
+	ID ClientID
+
+	// SHA-256 hash of the client secret issued to the registered
+	// confidential client, if any.
+	Secret string
+
+	// SHA-256 hash of the registration access token which protects the
+	// client configuration endpoint.
+	RegistrationToken string
+
+	Name string
+
+	// Requested authentication method for the token endpoint.
+	AuthMethod ClientAuthMethod
+
+	RedirectURI   []*url.URL
+	GrantTypes    []GrantType
+	ResponseTypes []ResponseType
+
+	// Email addresses of people responsible for this client.
+	Contacts []string
+
+	// Scope values that the client can use when requesting access tokens.
+	Scope Scopes
 }
 
 // NewClient creates a new empty Client with provided ClientID, if any.
 func NewClient(cid ClientID) *Client {
 	return &Client{
-		ID:          cid,
-		Logo:        nil,
-		RedirectURI: make([]*url.URL, 0),
-		URL:         nil,
-		Name:        "",
+		ID:                cid,
+		Logo:              nil,
+		RedirectURI:       make([]*url.URL, 0),
+		URL:               nil,
+		Name:              "",
+		AuthMethod:        ClientAuthMethodUnd,
+		Contacts:          make([]string, 0),
+		CreatedAt:         time.Time{},
+		GrantTypes:        make([]GrantType, 0),
+		JWKSURI:           nil,
+		RegistrationToken: "",
+		ResponseTypes:     make([]ResponseType, 0),
+		Scope:             make(Scopes, 0),
+		Secret:            "",
+		SecretExpiresAt:   time.Time{},
 	}
 }
 
+// HashSecret returns hex-encoded SHA-256 hash of the provided client secret or
+// registration access token for storing.
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
 // TestClient returns valid random generated client for tests.
 func TestClient(tb testing.TB) *Client {
 	tb.Helper()
@@ -50,11 +105,29 @@ func TestClient(tb testing.TB) *Client {
 // match that of the client_id, then the authorization endpoint SHOULD verify
 // that the requested redirect_uri matches one of the redirect URLs published by
 // the client, and SHOULD block the request from proceeding if not.
+//
+// Redirect URIs of native applications are also matched by RFC 8252 rules:
+// any port is allowed for published loopback IP redirect URIs, and private-use
+// URI scheme is allowed if its reverse domain name corresponds to the client_id
+// host.
 func (c *Client) ValidateRedirectURI(redirectURI *url.URL) bool {
 	if redirectURI == nil {
 		return false
 	}
 
+	for i := range c.RedirectURI {
+		if redirectURI.String() == c.RedirectURI[i].String() ||
+			matchLoopbackRedirectURI(c.RedirectURI[i], redirectURI) {
+			return true
+		}
+	}
+
+	// NOTE(toby3d): registered clients do not have URL identifier, so
+	// only registered redirect URIs can be used.
+	if c.ID.IsOpaque() {
+		return false
+	}
+
 	rHost, rPort, err := net.SplitHostPort(redirectURI.Host)
 	if err != nil {
 		rHost = redirectURI.Hostname()
@@ -71,13 +144,103 @@ func (c *Client) ValidateRedirectURI(redirectURI *url.URL) bool {
 		return true
 	}
 
+	return matchPrivateUseRedirectURI(cHost, redirectURI)
+}
+
+// ValidateRedirectURIExact reports whether requested redirect URI is exactly
+// equal to one of the redirect URIs published by the client, without any
+// relaxations of ValidateRedirectURI, see OAuth 2.1 section 2.3.1.
+func (c *Client) ValidateRedirectURIExact(redirectURI *url.URL) bool {
+	if redirectURI == nil {
+		return false
+	}
+
 	for i := range c.RedirectURI {
-		if redirectURI.String() != c.RedirectURI[i].String() {
-			continue
+		if redirectURI.String() == c.RedirectURI[i].String() {
+			return true
 		}
-
-		return true
 	}
 
 	return false
 }
+
+// IsNativeRedirectURI reports whether redirect URI points to the native
+// application on the user's device, e.g. loopback IP address or private-use
+// URI scheme, instead of the web site.
+func IsNativeRedirectURI(u *url.URL) bool {
+	return u != nil && (isLoopbackRedirectURI(u) || isPrivateUseScheme(u.Scheme))
+}
+
+// RFC 8252 section 7.3: the authorization server MUST allow any port to be
+// specified at the time of the request for loopback IP redirect URIs.
+func matchLoopbackRedirectURI(published, requested *url.URL) bool {
+	return isLoopbackRedirectURI(published) && isLoopbackRedirectURI(requested) &&
+		published.Hostname() == requested.Hostname() &&
+		published.Path == requested.Path &&
+		published.RawQuery == requested.RawQuery
+}
+
+func isLoopbackRedirectURI(u *url.URL) bool {
+	if u.Scheme != "http" || u.Fragment != "" {
+		return false
+	}
+
+	// NOTE(toby3d): RFC 8252 section 8.3: use of localhost is NOT
+	// RECOMMENDED, only IP literals are accepted.
+	ip := net.ParseIP(u.Hostname())
+
+	return ip != nil && (ip.Equal(net.IPv4(127, 0, 0, 1)) || ip.Equal(net.IPv6loopback))
+}
+
+// RFC 8252 section 7.1: private-use URI scheme is based on a domain name under
+// the control of the app expressed in reverse order, e.g. com.example.app for
+// app.example.com.
+func matchPrivateUseRedirectURI(host string, u *url.URL) bool {
+	if host == "" || !isPrivateUseScheme(u.Scheme) || u.Host != "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+
+	labels := strings.Split(strings.ToLower(u.Scheme), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	domain, host := strings.Join(labels, "."), strings.ToLower(host)
+
+	return domain == host || strings.HasSuffix(domain, "."+host)
+}
+
+func isPrivateUseScheme(scheme string) bool {
+	return strings.Contains(scheme, ".") && !strings.EqualFold(scheme, "http") &&
+		!strings.EqualFold(scheme, "https")
+}
+
+// VerifySecret reports whether provided secret matches the stored hash of the
+// client secret and it is not expired at now.
+func (c Client) VerifySecret(secret string, now time.Time) bool {
+	if !c.SecretExpiresAt.IsZero() && c.SecretExpiresAt.Before(now) {
+		return false
+	}
+
+	return verifyHash(c.Secret, secret)
+}
+
+// IsConfidential reports whether client MUST authenticate on the token
+// endpoint by one of the registered authentication methods.
+func (c Client) IsConfidential() bool {
+	return c.AuthMethod != ClientAuthMethodUnd && c.AuthMethod != ClientAuthMethodNone
+}
+
+// VerifyRegistrationToken reports whether provided registration access token
+// matches the stored hash of it.
+func (c Client) VerifyRegistrationToken(token string) bool {
+	return verifyHash(c.RegistrationToken, token)
+}
+
+func verifyHash(hash, secret string) bool {
+	if hash == "" || secret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashSecret(secret))) == 1
+}
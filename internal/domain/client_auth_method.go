@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+// ClientAuthMethod represent fixed token_endpoint_auth_method parameter of
+// the registered client.
+//
+// NOTE(toby3d): Encapsulate enums in structs for extra compile-time safety:
+// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+type ClientAuthMethod struct {
+	clientAuthMethod string
+}
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	ClientAuthMethodUnd = ClientAuthMethod{clientAuthMethod: ""} // "und"
+
+	// ClientAuthMethodNone describes the public client, which does not
+	// have a client secret.
+	ClientAuthMethodNone = ClientAuthMethod{clientAuthMethod: "none"} // "none"
+
+	// ClientAuthMethodClientSecretBasic describes the client which uses
+	// HTTP Basic authentication scheme with client_id and client_secret.
+	ClientAuthMethodClientSecretBasic = ClientAuthMethod{
+		clientAuthMethod: "client_secret_basic",
+	} // "client_secret_basic"
+
+	// ClientAuthMethodClientSecretPost describes the client which sends
+	// client_id and client_secret in the request body.
+	ClientAuthMethodClientSecretPost = ClientAuthMethod{
+		clientAuthMethod: "client_secret_post",
+	} // "client_secret_post"
+
+	// ClientAuthMethodPrivateKeyJWT describes the client which sends
+	// JWT assertion signed by one of the keys published in its JWKS.
+	ClientAuthMethodPrivateKeyJWT = ClientAuthMethod{
+		clientAuthMethod: "private_key_jwt",
+	} // "private_key_jwt"
+)
+
+var ErrClientAuthMethodUnknown error = NewError(
+	ErrorCodeInvalidClientMetadata,
+	"unknown token endpoint authentication method",
+	"https://www.rfc-editor.org/rfc/rfc7591#section-2",
+)
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsClientAuthMethods = map[string]ClientAuthMethod{
+	ClientAuthMethodClientSecretBasic.clientAuthMethod: ClientAuthMethodClientSecretBasic,
+	ClientAuthMethodClientSecretPost.clientAuthMethod:  ClientAuthMethodClientSecretPost,
+	ClientAuthMethodNone.clientAuthMethod:              ClientAuthMethodNone,
+	ClientAuthMethodPrivateKeyJWT.clientAuthMethod:     ClientAuthMethodPrivateKeyJWT,
+}
+
+// ParseClientAuthMethod parse token_endpoint_auth_method value as
+// ClientAuthMethod struct enum.
+func ParseClientAuthMethod(uid string) (ClientAuthMethod, error) {
+	if method, ok := uidsClientAuthMethods[strings.ToLower(uid)]; ok {
+		return method, nil
+	}
+
+	return ClientAuthMethodUnd, fmt.Errorf("%w: %s", ErrClientAuthMethodUnknown, uid)
+}
+
+// UnmarshalJSON implements custom unmarshler for JSON.
+func (cam *ClientAuthMethod) UnmarshalJSON(v []byte) error {
+	src, err := strconv.Unquote(string(v))
+	if err != nil {
+		return fmt.Errorf("ClientAuthMethod: UnmarshalJSON: %w", err)
+	}
+
+	method, err := ParseClientAuthMethod(src)
+	if err != nil {
+		return fmt.Errorf("ClientAuthMethod: UnmarshalJSON: %w", err)
+	}
+
+	*cam = method
+
+	return nil
+}
+
+func (cam ClientAuthMethod) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(cam.clientAuthMethod)), nil
+}
+
+// String returns string representation of client authentication method.
+func (cam ClientAuthMethod) String() string {
+	if cam.clientAuthMethod != "" {
+		return cam.clientAuthMethod
+	}
+
+	return common.Und
+}
+
+func (cam ClientAuthMethod) GoString() string {
+	return "domain.ClientAuthMethod(" + cam.String() + ")"
+}
@@ -0,0 +1,60 @@
+//nolint:dupl
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseClientAuthMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in  string
+		out domain.ClientAuthMethod
+	}{
+		{in: "none", out: domain.ClientAuthMethodNone},
+		{in: "client_secret_basic", out: domain.ClientAuthMethodClientSecretBasic},
+		{in: "client_secret_post", out: domain.ClientAuthMethodClientSecretPost},
+		{in: "private_key_jwt", out: domain.ClientAuthMethodPrivateKeyJWT},
+	} {
+		tc := tc
+
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseClientAuthMethod(tc.in)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if result != tc.out {
+				t.Errorf("ParseClientAuthMethod(%s) = %v, want %v", tc.in, result, tc.out)
+			}
+		})
+	}
+}
+
+func TestClientAuthMethod_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`"client_secret_post"`)
+	result := domain.ClientAuthMethodUnd
+
+	if err := result.UnmarshalJSON(input); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if result != domain.ClientAuthMethodClientSecretPost {
+		t.Errorf("UnmarshalJSON(%s) = %v, want %v", input, result, domain.ClientAuthMethodClientSecretPost)
+	}
+}
+
+func TestClientAuthMethod_String(t *testing.T) {
+	t.Parallel()
+
+	if result := domain.ClientAuthMethodClientSecretBasic.String(); result != "client_secret_basic" {
+		t.Errorf("String() = %s, want %s", result, "client_secret_basic")
+	}
+}
@@ -13,7 +13,8 @@ import (
 	"source.toby3d.me/toby3d/auth/internal/common"
 )
 
-// ClientID is a URL client identifier.
+// ClientID is a URL client identifier or an opaque identifier issued to the
+// dynamically registered client.
 type ClientID struct {
 	clientID    *url.URL
 	isLocalhost bool
@@ -25,10 +26,24 @@ var (
 	localhostIPv6 = netaddr.MustParseIP("::1")
 )
 
-// ParseClientID parse string as client ID URL identifier.
+// Length limits of the opaque client identifier.
+const (
+	MinOpaqueClientIDLength int = 16
+	MaxOpaqueClientIDLength int = 255
+)
+
+// ParseClientID parse string as client ID URL identifier or as opaque
+// identifier of the registered client.
 //
 //nolint:funlen,cyclop
 func ParseClientID(src string) (*ClientID, error) {
+	if isOpaqueClientID(src) {
+		return &ClientID{
+			clientID:    &url.URL{Opaque: src},
+			isLocalhost: false,
+		}, nil
+	}
+
 	cid, err := url.Parse(src)
 	if err != nil {
 		return nil, NewError(
@@ -181,8 +196,13 @@ func (cid ClientID) IsEqual(v ClientID) bool {
 	return cid.clientID.String() == v.clientID.String()
 }
 
-// URL returns url.URL representation of client ID.
+// URL returns url.URL representation of client ID. Opaque client ID does not
+// have URL representation, so nil will be returned.
 func (cid ClientID) URL() *url.URL {
+	if cid.IsOpaque() {
+		return nil
+	}
+
 	out, _ := url.Parse(cid.clientID.String())
 
 	return out
@@ -192,6 +212,12 @@ func (cid ClientID) IsLocalhost() bool {
 	return cid.isLocalhost
 }
 
+// IsOpaque reports whether cid is an opaque identifier issued by dynamic
+// client registration instead of URL.
+func (cid ClientID) IsOpaque() bool {
+	return cid.clientID != nil && cid.clientID.Scheme == "" && cid.clientID.Opaque != ""
+}
+
 // String returns string representation of client ID.
 func (cid ClientID) String() string {
 	if cid.clientID == nil {
@@ -208,3 +234,19 @@ func (cid ClientID) GoString() string {
 
 	return "domain.ClientID(" + cid.clientID.String() + ")"
 }
+
+// isOpaqueClientID reports whether src contains only unreserved characters
+// without dots, so it cannot be confused with the URL or the domain name.
+func isOpaqueClientID(src string) bool {
+	if len(src) < MinOpaqueClientIDLength || len(src) > MaxOpaqueClientIDLength {
+		return false
+	}
+
+	for _, r := range src {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
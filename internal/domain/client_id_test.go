@@ -20,6 +20,8 @@ func TestParseClientID(t *testing.T) {
 		{name: "valid query", in: "https://example.com/users?id=100", expError: false},
 		{name: "valid port", in: "https://example.com:8443/", expError: false},
 		{name: "valid loopback", in: "https://127.0.0.1:8443/", expError: false},
+		{name: "valid opaque", in: "Zs8H1y3_mPq-4kXw2nLc", expError: false},
+		{name: "short opaque", in: "abc123", expError: true},
 		{name: "missing scheme", in: "example.com", expError: true},
 		{name: "invalid scheme", in: "mailto:user@example.com", expError: true},
 		{name: "invalid double-dot path", in: "https://example.com/foo/../bar", expError: true},
@@ -3,6 +3,7 @@ package domain_test
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 )
@@ -27,3 +28,139 @@ func TestClient_ValidateRedirectURI(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ValidateRedirectURI_Opaque(t *testing.T) {
+	t.Parallel()
+
+	cid, err := domain.ParseClientID("Zs8H1y3_mPq-4kXw2nLc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := domain.TestClient(t)
+	client.ID = *cid
+
+	for in, expect := range map[string]bool{
+		client.RedirectURI[0].String():       true,
+		"https://app.example.com/redirect/2": false,
+	} {
+		in, expect := in, expect
+
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+
+			u, _ := url.Parse(in)
+
+			if out := client.ValidateRedirectURI(u); out != expect {
+				t.Errorf("ValidateRedirectURI(%v) = %t, want %t", in, out, expect)
+			}
+		})
+	}
+}
+
+func TestClient_ValidateRedirectURIExact(t *testing.T) {
+	t.Parallel()
+
+	client := domain.TestClient(t)
+
+	for name, tc := range map[string]struct {
+		in     *url.URL
+		expect bool
+	}{
+		"registered":    {in: client.RedirectURI[0], expect: true},
+		"prefix":        {in: client.ID.URL().JoinPath("/callback"), expect: false},
+		"extra query":   {in: &url.URL{Scheme: "https", Host: "app.example.com", Path: "/redirect", RawQuery: "a=b"}},
+		"other path":    {in: &url.URL{Scheme: "https", Host: "app.example.com", Path: "/redirect/"}},
+		"missing value": {in: nil, expect: false},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if out := client.ValidateRedirectURIExact(tc.in); out != tc.expect {
+				t.Errorf("ValidateRedirectURIExact(%v) = %t, want %t", tc.in, out, tc.expect)
+			}
+		})
+	}
+}
+
+func TestClient_VerifySecret(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	client := domain.TestClient(t)
+	client.Secret = domain.HashSecret("hackme")
+	client.SecretExpiresAt = now.Add(time.Hour)
+
+	for in, expect := range map[string]bool{
+		"hackme":  true,
+		"hackme2": false,
+		"":        false,
+	} {
+		if out := client.VerifySecret(in, now); out != expect {
+			t.Errorf("VerifySecret(%s) = %t, want %t", in, out, expect)
+		}
+	}
+
+	if client.VerifySecret("hackme", now.Add(2*time.Hour)) {
+		t.Errorf("VerifySecret(%s) after %s = true, want false", "hackme", client.SecretExpiresAt)
+	}
+}
+
+func TestClient_ValidateRedirectURI_Native(t *testing.T) {
+	t.Parallel()
+
+	client := domain.TestClient(t)
+	client.ID = *domain.TestClientID(t, "https://app.example.com/")
+	client.RedirectURI = []*url.URL{
+		{Scheme: "http", Host: "127.0.0.1:8080", Path: "/callback"},
+		{Scheme: "http", Host: "[::1]", Path: "/callback"},
+	}
+
+	for in, expect := range map[string]bool{
+		"http://127.0.0.1:51004/callback":   true,
+		"http://127.0.0.1/callback":         true,
+		"http://[::1]:61000/callback":       true,
+		"http://127.0.0.1:51004/other":      false,
+		"http://localhost:51004/callback":   false,
+		"https://127.0.0.1:51004/callback":  false,
+		"com.example.app:/callback":         true,
+		"com.example.app.desktop:/callback": true,
+		"com.example:/callback":             false,
+		"net.example.app:/callback":         false,
+		"com.example.app://evil/callback":   false,
+	} {
+		in, expect := in, expect
+
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out := client.ValidateRedirectURI(u); out != expect {
+				t.Errorf("ValidateRedirectURI(%v) = %t, want %t", in, out, expect)
+			}
+		})
+	}
+}
+
+func TestIsNativeRedirectURI(t *testing.T) {
+	t.Parallel()
+
+	for in, expect := range map[string]bool{
+		"http://127.0.0.1:51004/callback":  true,
+		"com.example.app:/callback":        true,
+		"https://app.example.com/callback": false,
+		"http://localhost/callback":        false,
+	} {
+		u, _ := url.Parse(in)
+
+		if out := domain.IsNativeRedirectURI(u); out != expect {
+			t.Errorf("IsNativeRedirectURI(%v) = %t, want %t", in, out, expect)
+		}
+	}
+}
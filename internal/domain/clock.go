@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+type (
+	// Clock provides the current time to expire codes, sessions and
+	// tokens. Inject a fixed one to test time-dependent behavior.
+	Clock interface {
+		Now() time.Time
+	}
+
+	// ClockFunc is an adapter to use ordinary function as a Clock.
+	ClockFunc func() time.Time
+)
+
+// SystemClock returns the current system time in UTC.
+//
+//nolint:gochecknoglobals // interfaces cannot be constants
+var SystemClock Clock = ClockFunc(func() time.Time { return time.Now().UTC() })
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// TestClock returns clock which always returns the provided time for tests.
+func TestClock(tb testing.TB, now time.Time) Clock {
+	tb.Helper()
+
+	return ClockFunc(func() time.Time { return now.UTC() })
+}
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"net"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,14 +13,25 @@ import (
 
 type (
 	Config struct {
-		Server     ConfigServer     `envPrefix:"SERVER_"`
-		Database   ConfigDatabase   `envPrefix:"DATABASE_"`
-		Name       string           `env:"NAME"              envDefault:"IndieAuth"`
-		RunMode    string           `env:"RUN_MODE"          envDefault:"dev"`
-		IndieAuth  ConfigIndieAuth  `envPrefix:"INDIEAUTH_"`
-		JWT        ConfigJWT        `envPrefix:"JWT_"`
-		Code       ConfigCode       `envPrefix:"CODE_"`
-		TicketAuth ConfigTicketAuth `envPrefix:"TICKETAUTH_"`
+		Server       ConfigServer       `envPrefix:"SERVER_"`
+		Database     ConfigDatabase     `envPrefix:"DATABASE_"`
+		Name         string             `env:"NAME"              envDefault:"IndieAuth"`
+		RunMode      string             `env:"RUN_MODE"          envDefault:"dev"`
+		IndieAuth    ConfigIndieAuth    `envPrefix:"INDIEAUTH_"`
+		JWT          ConfigJWT          `envPrefix:"JWT_"`
+		Code         ConfigCode         `envPrefix:"CODE_"`
+		TicketAuth   ConfigTicketAuth   `envPrefix:"TICKETAUTH_"`
+		ImageProxy   ConfigImageProxy   `envPrefix:"IMAGE_PROXY_"`
+		Registration ConfigRegistration `envPrefix:"REGISTRATION_"`
+		Tenants      ConfigTenants      `envPrefix:"TENANTS_"`
+		PAR          ConfigPAR          `envPrefix:"PAR_"`
+		OIDC         ConfigOIDC         `envPrefix:"OIDC_"`
+		Scopes       ConfigScopes       `envPrefix:"SCOPES_"`
+		Policy       ConfigPolicy       `envPrefix:"POLICY_"`
+		Security     ConfigSecurity     `envPrefix:"SECURITY_"`
+		StepUp       ConfigStepUp       `envPrefix:"STEP_UP_"`
+		ForwardAuth  ConfigForwardAuth  `envPrefix:"FORWARD_AUTH_"`
+		Legacy       ConfigLegacy       `envPrefix:"LEGACY_"`
 	}
 
 	ConfigServer struct {
@@ -49,12 +62,110 @@ type (
 		Secret      string        `env:"SECRET"`
 		Expiry      time.Duration `env:"EXPIRY"       envDefault:"1h"`
 		NonceLength uint8         `env:"NONCE_LENGTH" envDefault:"22"`
+		// How long the client can refresh access tokens without the
+		// owner interaction. Zero disables refresh tokens.
+		RefreshExpiry time.Duration `env:"REFRESH_EXPIRY" envDefault:"720h"` // 30 days
 	}
 
 	ConfigIndieAuth struct {
 		Password string `env:"PASSWORD"`
 		Username string `env:"USERNAME"`
-		Enabled  bool   `env:"ENABLED"  envDefault:"true"` // true
+		// Base32 encoded secret of time-based one-time passwords used
+		// as the second factor. If empty, second factor is not
+		// available.
+		TOTPSecret string `env:"TOTP_SECRET"`
+		// Profile URLs owned by the account. Each of them must
+		// delegate authorization to this server. If empty, any profile
+		// URL which delegates to this server can be used.
+		Identities []string `env:"IDENTITIES" envSeparator:","`
+		// How long the owner stays authenticated after the password is
+		// verified, so clients can be authorized without interaction.
+		SessionExpiry time.Duration `env:"SESSION_EXPIRY" envDefault:"24h"`  // 24h
+		Enabled       bool          `env:"ENABLED"        envDefault:"true"` // true
+	}
+
+	// Configuration of the multi-tenant hosting. If Path is empty, server
+	// hosts the single tenant described by the rest of configuration.
+	ConfigTenants struct {
+		// Path to the JSON file with the array of hosted tenants.
+		Path string `env:"PATH"`
+	}
+
+	// Configuration of the pushed authorization requests (RFC 9126).
+	ConfigPAR struct {
+		Expiry time.Duration `env:"EXPIRY" envDefault:"1m"` // 1m
+		// Reject authorization requests which parameters are not
+		// pushed by the client before.
+		Required bool `env:"REQUIRED" envDefault:"false"` // false
+	}
+
+	// Configuration of the OpenID Connect mode.
+	ConfigOIDC struct {
+		// Path to the PEM file with the private key used to sign ID
+		// Tokens. If empty, a new key is generated on every start.
+		KeyFile string        `env:"KEY_FILE"`
+		Expiry  time.Duration `env:"EXPIRY"   envDefault:"10m"`   // 10m
+		Enabled bool          `env:"ENABLED"  envDefault:"false"` // false
+	}
+
+	// Configuration of the scopes which can be requested by clients in
+	// addition to the well-known ones.
+	ConfigScopes struct {
+		// Path to the JSON file with the array of scope definitions.
+		// Definitions of the well-known scopes can be overridden.
+		Path string `env:"PATH"`
+		// What to do with scopes which are not defined: reject or
+		// allow.
+		Unknown string `env:"UNKNOWN" envDefault:"reject"` // reject
+	}
+
+	// Configuration of the rules applied to clients during authorization
+	// and token issuance.
+	ConfigPolicy struct {
+		// Path to the JSON file with the array of rules evaluated in
+		// order. If empty, any request is allowed.
+		Path string `env:"PATH"`
+	}
+
+	// Configuration of the constraints applied to all clients.
+	ConfigSecurity struct {
+		// Security profile: default or strict. Strict profile enforces
+		// OAuth 2.1 best current practices and refuses legacy clients.
+		Profile string `env:"PROFILE" envDefault:"default"` // default
+	}
+
+	// Configuration of the step-up authentication for sensitive scopes.
+	ConfigStepUp struct {
+		// Scopes which must be confirmed by the second factor if the
+		// last strong authentication of the owner is older than
+		// MaxAge, in addition to scopes defined with high sensitivity.
+		// The latter require step-up only if the owner has TOTP
+		// secret.
+		Scopes []string `env:"SCOPES" envSeparator:","`
+		// How long the second factor confirmation is remembered.
+		MaxAge time.Duration `env:"MAX_AGE" envDefault:"15m"` // 15m
+	}
+
+	// Configuration of the forward-auth endpoint used by reverse proxies
+	// to protect upstreams.
+	ConfigForwardAuth struct {
+		// Path to the JSON file with the array of rules of protected
+		// upstreams. If empty, forward-auth endpoint is disabled.
+		Path string `env:"PATH"`
+		// Domain of the session cookie shared by the server and all
+		// protected upstreams, like "example.com".
+		CookieDomain string `env:"COOKIE_DOMAIN"`
+		// How long the owner stays authenticated on upstreams.
+		Expiry time.Duration `env:"EXPIRY" envDefault:"24h"` // 24h
+	}
+
+	// Configuration of the compatibility with clients and resource servers
+	// implementing outdated revisions of the IndieAuth specification.
+	ConfigLegacy struct {
+		// Answer GET requests to the token endpoint with the me,
+		// client_id and scope of the access token provided by Bearer
+		// scheme, as resource servers did before 2020.
+		TokenVerification bool `env:"TOKEN_VERIFICATION" envDefault:"false"` // false
 	}
 
 	ConfigTicketAuth struct {
@@ -62,6 +173,26 @@ type (
 		Length uint8         `env:"LENGTH" envDefault:"24"` // 24
 	}
 
+	// Configuration of the proxy for third-party images, like client logos
+	// and profile photos. Proxied URLs are signed by Secret, so the proxy
+	// cannot be used as an open one.
+	ConfigImageProxy struct {
+		Secret      string        `env:"SECRET"`
+		CachePath   string        `env:"CACHE_PATH"`
+		CacheExpiry time.Duration `env:"CACHE_EXPIRY" envDefault:"24h"`     // 24h
+		MaxSize     int64         `env:"MAX_SIZE"     envDefault:"5242880"` // 5 MiB
+	}
+
+	// Configuration of the dynamic client registration for OAuth clients
+	// which does not support URL client identifiers.
+	ConfigRegistration struct {
+		// Optional token required to register a new client. If empty,
+		// registration is open for everyone.
+		InitialAccessToken string        `env:"INITIAL_ACCESS_TOKEN"`
+		SecretExpiry       time.Duration `env:"SECRET_EXPIRY"`                           // 0 means never
+		Enabled            bool          `env:"ENABLED"              envDefault:"false"` // false
+	}
+
 	ConfigRelMeAuth struct {
 		Providers []ConfigRelMeAuthProvider `envPrefix:"PROVIDERS_"`
 		Enabled   bool                      `env:"ENABLED"          envDefault:"true"` // true
@@ -101,20 +232,69 @@ func TestConfig(tb testing.TB) *Config {
 			Length: 32,
 		},
 		JWT: ConfigJWT{
-			Expiry:      time.Hour,
-			NonceLength: 22,
-			Secret:      "hackme",
-			Algorithm:   "HS256",
+			Expiry:        time.Hour,
+			RefreshExpiry: 24 * time.Hour,
+			NonceLength:   22,
+			Secret:        "hackme",
+			Algorithm:     "HS256",
 		},
 		IndieAuth: ConfigIndieAuth{
-			Enabled:  true,
-			Username: "user",
-			Password: "password",
+			Enabled:       true,
+			Username:      "user",
+			Password:      "password",
+			Identities:    make([]string, 0),
+			SessionExpiry: 24 * time.Hour,
+			TOTPSecret:    "",
 		},
 		TicketAuth: ConfigTicketAuth{
 			Expiry: time.Minute,
 			Length: 24,
 		},
+		ImageProxy: ConfigImageProxy{
+			Secret:      "hackme",
+			CachePath:   "",
+			CacheExpiry: 24 * time.Hour,
+			MaxSize:     5 << 20,
+		},
+		Registration: ConfigRegistration{
+			Enabled:            true,
+			InitialAccessToken: "",
+			SecretExpiry:       0,
+		},
+		Tenants: ConfigTenants{
+			Path: "",
+		},
+		PAR: ConfigPAR{
+			Expiry:   time.Minute,
+			Required: false,
+		},
+		OIDC: ConfigOIDC{
+			KeyFile: "",
+			Expiry:  10 * time.Minute,
+			Enabled: true,
+		},
+		Scopes: ConfigScopes{
+			Path:    "",
+			Unknown: "reject",
+		},
+		Policy: ConfigPolicy{
+			Path: "",
+		},
+		Security: ConfigSecurity{
+			Profile: "default",
+		},
+		StepUp: ConfigStepUp{
+			Scopes: make([]string, 0),
+			MaxAge: 15 * time.Minute,
+		},
+		ForwardAuth: ConfigForwardAuth{
+			Path:         "",
+			CookieDomain: "example.com",
+			Expiry:       24 * time.Hour,
+		},
+		Legacy: ConfigLegacy{
+			TokenVerification: false,
+		},
 	}
 }
 
@@ -132,3 +312,63 @@ func (cs ConfigServer) GetRootURL() string {
 		"protocol": cs.Protocol,
 	})
 }
+
+// GetPath returns the absolute path of provided path under the path of the
+// root URL, so cookies and links of tenants hosted at the same domain do not
+// overlap.
+func (cs ConfigServer) GetPath(path string) string {
+	root, err := url.Parse(cs.GetRootURL())
+	if err != nil {
+		return path
+	}
+
+	return strings.TrimSuffix(root.Path, "/") + path
+}
+
+// GetHostname returns the host name of the root URL without port.
+func (cs ConfigServer) GetHostname() string {
+	root, err := url.Parse(cs.GetRootURL())
+	if err != nil || root.Hostname() == "" {
+		return cs.Domain
+	}
+
+	return root.Hostname()
+}
+
+// GetOrigin returns the origin of the root URL: scheme, host and port.
+func (cs ConfigServer) GetOrigin() string {
+	root, err := url.Parse(cs.GetRootURL())
+	if err != nil {
+		return cs.Protocol + "://" + cs.Domain
+	}
+
+	return root.Scheme + "://" + root.Host
+}
+
+// GetProfile returns the configured security profile. Unknown profiles fall
+// back to the default one, so the configuration must be validated on start.
+func (cs ConfigSecurity) GetProfile() SecurityProfile {
+	profile, err := ParseSecurityProfile(cs.Profile)
+	if err != nil {
+		return SecurityProfileDefault
+	}
+
+	return profile
+}
+
+// Sensitive returns provided scopes which require step-up authentication.
+func (cs ConfigStepUp) Sensitive(scopes Scopes) Scopes {
+	out := make(Scopes, 0)
+
+	for _, s := range scopes {
+		for _, raw := range cs.Scopes {
+			if strings.EqualFold(strings.TrimSpace(raw), s.String()) {
+				out = append(out, s)
+
+				break
+			}
+		}
+	}
+
+	return out
+}
@@ -27,3 +27,33 @@ func TestConfigServer_GetRootURL(t *testing.T) {
 		t.Errorf("GetRootURL() = %s, want %s", result, expResult)
 	}
 }
+
+func TestConfigServer_GetPath(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.Server.RootURL = "https://auth.example.com/alice/"
+
+	if result := config.Server.GetPath("/authorize"); result != "/alice/authorize" {
+		t.Errorf("GetPath(%s) = %s, want %s", "/authorize", result, "/alice/authorize")
+	}
+
+	if result := config.Server.GetHostname(); result != "auth.example.com" {
+		t.Errorf("GetHostname() = %s, want %s", result, "auth.example.com")
+	}
+
+	if result := config.Server.GetOrigin(); result != "https://auth.example.com" {
+		t.Errorf("GetOrigin() = %s, want %s", result, "https://auth.example.com")
+	}
+}
+
+func TestConfigStepUp_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	config := domain.ConfigStepUp{Scopes: []string{"delete", " Media"}}
+	scopes := domain.Scopes{domain.ScopeProfile, domain.ScopeMedia, domain.ScopeDelete}
+
+	if result := config.Sensitive(scopes); result.String() != "media delete" {
+		t.Errorf("Sensitive(%s) = %s, want %s", scopes, result, "media delete")
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+type (
+	// Consent describes the fact that the owner has authorized the client
+	// at least once.
+	Consent struct {
+		CreatedAt time.Time
+		ClientID  ClientID
+		Me        Me
+		// Scope contains all scopes which the owner has granted to the
+		// client on behalf of Me.
+		Scope Scopes
+	}
+
+	// ConsentReport describes the risk assessment of the authorization
+	// request which is shown on the consent screen.
+	ConsentReport struct {
+		Client      *Client
+		RedirectURI *url.URL
+		Warnings    []ConsentWarning
+	}
+)
+
+// TestConsent returns valid random generated consent for tests.
+func TestConsent(tb testing.TB) *Consent {
+	tb.Helper()
+
+	return &Consent{
+		CreatedAt: time.Now().UTC().Add(-1 * time.Hour),
+		ClientID:  *TestClientID(tb),
+		Me:        *TestMe(tb, "https://user.example.net/"),
+		Scope:     Scopes{ScopeCreate, ScopeProfile},
+	}
+}
+
+// Covers reports whether the owner has granted all of provided scopes to the
+// client on behalf of me, so it can be authorized again without consent.
+func (c Consent) Covers(me Me, scope Scopes) bool {
+	if c.Me.String() != me.String() {
+		return false
+	}
+
+	for i := range scope {
+		if !c.Scope.Has(scope[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasWarning reports whether report contains provided warning.
+func (cr ConsentReport) HasWarning(warning ConsentWarning) bool {
+	for i := range cr.Warnings {
+		if cr.Warnings[i] == warning {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+// ConsentWarning represent a risk of the authorization request which owner
+// must be warned about on the consent screen.
+//
+// NOTE(toby3d): Encapsulate enums in structs for extra compile-time safety:
+// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+type ConsentWarning struct {
+	consentWarning string
+}
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	ConsentWarningUnd = ConsentWarning{consentWarning: ""} // "und"
+
+	// ConsentWarningRedirectMismatch describes redirect_uri on the host
+	// which differs from the client_id host.
+	ConsentWarningRedirectMismatch = ConsentWarning{
+		consentWarning: "redirect_host_mismatch",
+	} // "redirect_host_mismatch"
+
+	// ConsentWarningNewClient describes client which has never been
+	// authorized before.
+	ConsentWarningNewClient = ConsentWarning{consentWarning: "new_client"} // "new_client"
+
+	// ConsentWarningHomoglyph describes client_id host in punycode or
+	// mixing letters of different scripts which looks like another host.
+	ConsentWarningHomoglyph = ConsentWarning{consentWarning: "homoglyph"} // "homoglyph"
+
+	// ConsentWarningUnreachable describes client which page could not be
+	// fetched, so its name, logo and redirect URIs are unknown.
+	ConsentWarningUnreachable = ConsentWarning{consentWarning: "unreachable"} // "unreachable"
+
+	// ConsentWarningInsecure describes client_id or redirect_uri which
+	// uses plain HTTP outside of localhost.
+	ConsentWarningInsecure = ConsentWarning{consentWarning: "insecure"} // "insecure"
+
+	// ConsentWarningNativeApp describes redirect_uri handled by the native
+	// application on the user's device, see IsNativeRedirectURI.
+	ConsentWarningNativeApp = ConsentWarning{consentWarning: "native_app"} // "native_app"
+)
+
+var ErrConsentWarningUnknown error = NewError(ErrorCodeInvalidRequest, "unknown consent warning", "")
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsConsentWarnings = map[string]ConsentWarning{
+	ConsentWarningHomoglyph.consentWarning:        ConsentWarningHomoglyph,
+	ConsentWarningInsecure.consentWarning:         ConsentWarningInsecure,
+	ConsentWarningNativeApp.consentWarning:        ConsentWarningNativeApp,
+	ConsentWarningNewClient.consentWarning:        ConsentWarningNewClient,
+	ConsentWarningRedirectMismatch.consentWarning: ConsentWarningRedirectMismatch,
+	ConsentWarningUnreachable.consentWarning:      ConsentWarningUnreachable,
+}
+
+// ParseConsentWarning parse string identifier of consent warning into struct
+// enum.
+func ParseConsentWarning(uid string) (ConsentWarning, error) {
+	if warning, ok := uidsConsentWarnings[strings.ToLower(uid)]; ok {
+		return warning, nil
+	}
+
+	return ConsentWarningUnd, fmt.Errorf("%w: %s", ErrConsentWarningUnknown, uid)
+}
+
+// UnmarshalJSON implements custom unmarshler for JSON.
+func (cw *ConsentWarning) UnmarshalJSON(v []byte) error {
+	src, err := strconv.Unquote(string(v))
+	if err != nil {
+		return fmt.Errorf("ConsentWarning: UnmarshalJSON: %w", err)
+	}
+
+	warning, err := ParseConsentWarning(src)
+	if err != nil {
+		return fmt.Errorf("ConsentWarning: UnmarshalJSON: %w", err)
+	}
+
+	*cw = warning
+
+	return nil
+}
+
+// MarshalJSON implements custom marshler for JSON.
+func (cw ConsentWarning) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(cw.consentWarning)), nil
+}
+
+// String returns string representation of consent warning.
+func (cw ConsentWarning) String() string {
+	if cw.consentWarning != "" {
+		return cw.consentWarning
+	}
+
+	return common.Und
+}
+
+func (cw ConsentWarning) GoString() string {
+	return "domain.ConsentWarning(" + cw.String() + ")"
+}
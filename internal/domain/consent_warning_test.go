@@ -0,0 +1,51 @@
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseConsentWarning(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in  string
+		out domain.ConsentWarning
+	}{
+		{in: "redirect_host_mismatch", out: domain.ConsentWarningRedirectMismatch},
+		{in: "new_client", out: domain.ConsentWarningNewClient},
+		{in: "homoglyph", out: domain.ConsentWarningHomoglyph},
+		{in: "unreachable", out: domain.ConsentWarningUnreachable},
+		{in: "insecure", out: domain.ConsentWarningInsecure},
+		{in: "native_app", out: domain.ConsentWarningNativeApp},
+	} {
+		tc := tc
+
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseConsentWarning(tc.in)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if result != tc.out {
+				t.Errorf("ParseConsentWarning(%s) = %v, want %v", tc.in, result, tc.out)
+			}
+		})
+	}
+}
+
+func TestConsentWarning_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	result, err := domain.ConsentWarningNewClient.MarshalJSON()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if string(result) != `"new_client"` {
+		t.Errorf("MarshalJSON() = %s, want %s", result, `"new_client"`)
+	}
+}
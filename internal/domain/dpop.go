@@ -0,0 +1,262 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+type (
+	// DPoPProof describes the verified proof of possession of the client
+	// key, see RFC 9449 section 4.
+	DPoPProof struct {
+		IssuedAt time.Time
+		// JKT is the base64url-encoded SHA-256 thumbprint of the
+		// public key which the proof is signed by.
+		JKT string
+		ID  string
+	}
+
+	// DPoPProofOptions describes the HTTP request which the proof is sent
+	// with.
+	DPoPProofOptions struct {
+		URL    *url.URL
+		Method string
+		// AccessToken is the token presented with the proof to the
+		// protected resource. Empty for the token requests.
+		AccessToken string
+	}
+)
+
+// DPoPProofExpiry is the maximum difference between the issue time of the DPoP
+// proof and the server time.
+const DPoPProofExpiry time.Duration = time.Minute
+
+// DPoPProofType is the type of the DPoP proof JWT.
+const DPoPProofType string = "dpop+jwt"
+
+// DPoPSigningAlgorithms contains asymmetric algorithms supported for DPoP
+// proofs.
+//
+//nolint:gochecknoglobals // slices cannot be constants
+var DPoPSigningAlgorithms = []string{
+	jwa.ES256.String(),
+	jwa.ES384.String(),
+	jwa.EdDSA.String(),
+	jwa.PS256.String(),
+	jwa.RS256.String(),
+}
+
+var (
+	ErrDPoPProof error = NewError(
+		ErrorCodeInvalidDPoPProof,
+		"DPoP proof is missing or invalid",
+		"https://www.rfc-editor.org/rfc/rfc9449#section-4.3",
+	)
+	ErrDPoPBinding error = NewError(
+		ErrorCodeInvalidToken,
+		"access token is bound to another key or must be presented with DPoP scheme",
+		"https://www.rfc-editor.org/rfc/rfc9449#section-7.1",
+	)
+)
+
+// ParseDPoPProof verifies DPoP proof JWT sent with the request described by
+// options and returns the thumbprint of its key.
+//
+// NOTE(toby3d): jti values are not stored here, caller must remember ID of
+// the returned proof until its Expiry to prevent replays. Server-provided
+// nonces are not supported.
+//
+//nolint:cyclop
+func ParseDPoPProof(proof string, opts DPoPProofOptions) (*DPoPProof, error) {
+	msg, err := jws.ParseString(proof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDPoPProof, err)
+	}
+
+	if len(msg.Signatures()) != 1 {
+		return nil, fmt.Errorf("%w: proof must contain single signature", ErrDPoPProof)
+	}
+
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != DPoPProofType {
+		return nil, fmt.Errorf("%w: unexpected typ %q", ErrDPoPProof, headers.Type())
+	}
+
+	alg := headers.Algorithm()
+	if !isDPoPSigningAlgorithm(alg) {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrDPoPProof, alg)
+	}
+
+	key := headers.JWK()
+	if key == nil || isPrivateKey(key) {
+		return nil, fmt.Errorf("%w: jwk header must contain public key", ErrDPoPProof)
+	}
+
+	tkn, err := jwt.ParseString(proof, jwt.WithKey(alg, key), jwt.WithValidate(false))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDPoPProof, err)
+	}
+
+	if tkn.JwtID() == "" {
+		return nil, fmt.Errorf("%w: jti is required", ErrDPoPProof)
+	}
+
+	if now := time.Now().UTC(); tkn.IssuedAt().Before(now.Add(-DPoPProofExpiry)) ||
+		tkn.IssuedAt().After(now.Add(DPoPProofExpiry)) {
+		return nil, fmt.Errorf("%w: iat is out of acceptable window", ErrDPoPProof)
+	}
+
+	claims := tkn.PrivateClaims()
+
+	if htm, _ := claims["htm"].(string); !strings.EqualFold(htm, opts.Method) {
+		return nil, fmt.Errorf("%w: htm does not match request method", ErrDPoPProof)
+	}
+
+	if htu, _ := claims["htu"].(string); !matchDPoPURL(htu, opts.URL) {
+		return nil, fmt.Errorf("%w: htu does not match request URL", ErrDPoPProof)
+	}
+
+	if opts.AccessToken != "" {
+		if ath, _ := claims["ath"].(string); ath != hashDPoPAccessToken(opts.AccessToken) {
+			return nil, fmt.Errorf("%w: ath does not match access token", ErrDPoPProof)
+		}
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDPoPProof, err)
+	}
+
+	return &DPoPProof{
+		IssuedAt: tkn.IssuedAt(),
+		JKT:      base64.RawURLEncoding.EncodeToString(thumbprint),
+		ID:       tkn.JwtID(),
+	}, nil
+}
+
+// Expiry returns the time after which the proof is no longer accepted, so its
+// ID can be forgotten.
+func (p DPoPProof) Expiry() time.Time {
+	return p.IssuedAt.Add(DPoPProofExpiry)
+}
+
+// NewDPoPProof creates a new DPoP proof JWT signed by the provided private key
+// for the request described by options.
+func NewDPoPProof(key jwk.Key, alg jwa.SignatureAlgorithm, jti string, opts DPoPProofOptions) (string, error) {
+	public, err := key.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("cannot get public key: %w", err)
+	}
+
+	headers := jws.NewHeaders()
+
+	for k, v := range map[string]any{
+		jws.TypeKey: DPoPProofType,
+		jws.JWKKey:  public,
+	} {
+		if err = headers.Set(k, v); err != nil {
+			return "", fmt.Errorf("cannot set DPoP proof header: %w", err)
+		}
+	}
+
+	tkn := jwt.New()
+
+	htu := *opts.URL
+	htu.RawQuery, htu.Fragment = "", ""
+
+	claims := map[string]any{
+		jwt.JwtIDKey:    jti,
+		jwt.IssuedAtKey: time.Now().UTC(),
+		"htm":           opts.Method,
+		"htu":           htu.String(),
+	}
+
+	if opts.AccessToken != "" {
+		claims["ath"] = hashDPoPAccessToken(opts.AccessToken)
+	}
+
+	for k, v := range claims {
+		if err = tkn.Set(k, v); err != nil {
+			return "", fmt.Errorf("cannot set DPoP proof claim: %w", err)
+		}
+	}
+
+	proof, err := jwt.Sign(tkn, jwt.WithKey(alg, key, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign DPoP proof: %w", err)
+	}
+
+	return string(proof), nil
+}
+
+func isDPoPSigningAlgorithm(alg jwa.SignatureAlgorithm) bool {
+	for i := range DPoPSigningAlgorithms {
+		if DPoPSigningAlgorithms[i] == alg.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPrivateKey(key jwk.Key) bool {
+	switch key.(type) {
+	default:
+		return false
+	case jwk.RSAPrivateKey, jwk.ECDSAPrivateKey, jwk.OKPPrivateKey, jwk.SymmetricKey:
+		return true
+	}
+}
+
+// matchDPoPURL compares htu claim with the request URL without query and
+// fragment parts, see RFC 9449 section 4.3.
+func matchDPoPURL(htu string, target *url.URL) bool {
+	if target == nil {
+		return false
+	}
+
+	u, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Scheme, target.Scheme) && strings.EqualFold(u.Host, target.Host) &&
+		u.EscapedPath() == target.EscapedPath()
+}
+
+func hashDPoPAccessToken(accessToken string) string {
+	hash := sha256.Sum256([]byte(accessToken))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// TestDPoPKey returns a new private key for signing DPoP proofs in tests.
+func TestDPoPKey(tb testing.TB) jwk.Key {
+	tb.Helper()
+
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return key
+}
@@ -0,0 +1,156 @@
+package domain_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseDPoPProof(t *testing.T) {
+	t.Parallel()
+
+	key := domain.TestDPoPKey(t)
+	target := &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/token"}
+
+	for name, tc := range map[string]struct {
+		sign      domain.DPoPProofOptions
+		verify    domain.DPoPProofOptions
+		expectErr bool
+	}{
+		"token request": {
+			sign:   domain.DPoPProofOptions{Method: http.MethodPost, URL: target},
+			verify: domain.DPoPProofOptions{Method: http.MethodPost, URL: target},
+		},
+		"ignores query": {
+			sign: domain.DPoPProofOptions{Method: http.MethodPost, URL: target},
+			verify: domain.DPoPProofOptions{
+				Method: http.MethodPost,
+				URL:    &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/token", RawQuery: "a=b"},
+			},
+		},
+		"resource request": {
+			sign:   domain.DPoPProofOptions{Method: http.MethodGet, URL: target, AccessToken: "abc"},
+			verify: domain.DPoPProofOptions{Method: http.MethodGet, URL: target, AccessToken: "abc"},
+		},
+		"other method": {
+			sign:      domain.DPoPProofOptions{Method: http.MethodGet, URL: target},
+			verify:    domain.DPoPProofOptions{Method: http.MethodPost, URL: target},
+			expectErr: true,
+		},
+		"other url": {
+			sign: domain.DPoPProofOptions{Method: http.MethodPost, URL: target},
+			verify: domain.DPoPProofOptions{
+				Method: http.MethodPost,
+				URL:    &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/userinfo"},
+			},
+			expectErr: true,
+		},
+		"other access token": {
+			sign:      domain.DPoPProofOptions{Method: http.MethodGet, URL: target, AccessToken: "abc"},
+			verify:    domain.DPoPProofOptions{Method: http.MethodGet, URL: target, AccessToken: "xyz"},
+			expectErr: true,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			proof, err := domain.NewDPoPProof(key, jwa.ES256, "jti-"+name, tc.sign)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := domain.ParseDPoPProof(proof, tc.verify)
+			if tc.expectErr {
+				if !errors.Is(err, domain.ErrDPoPProof) {
+					t.Errorf("ParseDPoPProof(%s) = %v, want %v", proof, err, domain.ErrDPoPProof)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.JKT == "" || result.ID != "jti-"+name {
+				t.Errorf("ParseDPoPProof(%s) = %+v, want thumbprint and jti", proof, result)
+			}
+		})
+	}
+}
+
+func TestParseDPoPProof_Symmetric(t *testing.T) {
+	t.Parallel()
+
+	tkn := domain.TestToken(t)
+
+	if _, err := domain.ParseDPoPProof(tkn.AccessToken, domain.DPoPProofOptions{
+		Method: http.MethodPost,
+		URL:    &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/token"},
+	}); !errors.Is(err, domain.ErrDPoPProof) {
+		t.Errorf("ParseDPoPProof(%s) = %v, want %v", tkn.AccessToken, err, domain.ErrDPoPProof)
+	}
+}
+
+func TestToken_Confirm(t *testing.T) {
+	t.Parallel()
+
+	key := domain.TestDPoPKey(t)
+	target := &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/userinfo"}
+	bearer := domain.TestToken(t)
+
+	proof, err := domain.NewDPoPProof(key, jwa.ES256, "jti", domain.DPoPProofOptions{
+		Method:      http.MethodGet,
+		URL:         target,
+		AccessToken: bearer.AccessToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := domain.ParseDPoPProof(proof, domain.DPoPProofOptions{Method: http.MethodGet, URL: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bound := *bearer
+	bound.JKT = parsed.JKT
+
+	other := *bearer
+	other.JKT = "other"
+
+	opts := domain.DPoPProofOptions{Method: http.MethodGet, URL: target}
+
+	for name, tc := range map[string]struct {
+		token     domain.Token
+		scheme    string
+		expectErr bool
+	}{
+		"bearer":          {token: *bearer, scheme: "Bearer"},
+		"bearer as dpop":  {token: *bearer, scheme: "DPoP", expectErr: true},
+		"bound":           {token: bound, scheme: "DPoP"},
+		"bound as bearer": {token: bound, scheme: "Bearer", expectErr: true},
+		"other key":       {token: other, scheme: "DPoP", expectErr: true},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tc.token.Confirm(tc.scheme, proof, opts); (err != nil) != tc.expectErr {
+				t.Errorf("Confirm(%s) = %v, want error %t", tc.scheme, err, tc.expectErr)
+			}
+		})
+	}
+
+	if bound.Type() != "DPoP" || bearer.Type() != "Bearer" {
+		t.Errorf("Type() = %s, %s, want DPoP, Bearer", bound.Type(), bearer.Type())
+	}
+}
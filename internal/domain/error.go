@@ -148,6 +148,69 @@ var (
 	//
 	// IndieAuth: The request requires higher privileges than provided.
 	ErrorCodeInsufficientScope = ErrorCode{errorCode: "insufficient_scope"} // "insufficient_scope"
+
+	// ErrorCodeInvalidRedirectURI describes the invalid_redirect_uri error code.
+	//
+	// RFC 7591 section 3.2.2: The value of one or more redirection URIs is
+	// invalid.
+	ErrorCodeInvalidRedirectURI = ErrorCode{errorCode: "invalid_redirect_uri"} // "invalid_redirect_uri"
+
+	// ErrorCodeInvalidClientMetadata describes the invalid_client_metadata error code.
+	//
+	// RFC 7591 section 3.2.2: The value of one of the client metadata
+	// fields is invalid and the server has rejected this request.
+	ErrorCodeInvalidClientMetadata = ErrorCode{
+		errorCode: "invalid_client_metadata",
+	} // "invalid_client_metadata"
+
+	// ErrorCodeInvalidRequestURI describes the invalid_request_uri error code.
+	//
+	// RFC 9101 section 6.2: The request_uri in the authorization request
+	// returns an error or contains invalid data.
+	ErrorCodeInvalidRequestURI = ErrorCode{errorCode: "invalid_request_uri"} // "invalid_request_uri"
+
+	// ErrorCodeInvalidRequestObject describes the invalid_request_object
+	// error code.
+	//
+	// RFC 9101 section 6.2: The request parameter contains an invalid
+	// Request Object.
+	ErrorCodeInvalidRequestObject = ErrorCode{
+		errorCode: "invalid_request_object",
+	} // "invalid_request_object"
+
+	// ErrorCodeInvalidTarget describes the invalid_target error code.
+	//
+	// RFC 8707 section 2: The requested resource is invalid, missing,
+	// unknown, or malformed.
+	ErrorCodeInvalidTarget = ErrorCode{errorCode: "invalid_target"} // "invalid_target"
+
+	// ErrorCodeInteractionRequired describes the interaction_required
+	// error code.
+	//
+	// OpenID Connect Core section 3.1.2.6: The Authorization Server
+	// requires End-User interaction of some form to proceed.
+	ErrorCodeInteractionRequired = ErrorCode{
+		errorCode: "interaction_required",
+	} // "interaction_required"
+
+	// ErrorCodeLoginRequired describes the login_required error code.
+	//
+	// OpenID Connect Core section 3.1.2.6: The Authorization Server
+	// requires End-User authentication.
+	ErrorCodeLoginRequired = ErrorCode{errorCode: "login_required"} // "login_required"
+
+	// ErrorCodeConsentRequired describes the consent_required error code.
+	//
+	// OpenID Connect Core section 3.1.2.6: The Authorization Server
+	// requires End-User consent.
+	ErrorCodeConsentRequired = ErrorCode{errorCode: "consent_required"} // "consent_required"
+
+	// ErrorCodeInvalidDPoPProof describes the invalid_dpop_proof error
+	// code.
+	//
+	// RFC 9449 section 5: The DPoP proof JWT is missing, malformed or
+	// invalid.
+	ErrorCodeInvalidDPoPProof = ErrorCode{errorCode: "invalid_dpop_proof"} // "invalid_dpop_proof"
 )
 
 var ErrErrorCodeUnknown error = NewError(ErrorCodeInvalidRequest, "unknown error code", "")
@@ -155,12 +218,21 @@ var ErrErrorCodeUnknown error = NewError(ErrorCodeInvalidRequest, "unknown error
 //nolint:gochecknoglobals // maps cannot be constants
 var uidsErrorCodes = map[string]ErrorCode{
 	ErrorCodeAccessDenied.errorCode:            ErrorCodeAccessDenied,
+	ErrorCodeConsentRequired.errorCode:         ErrorCodeConsentRequired,
 	ErrorCodeInsufficientScope.errorCode:       ErrorCodeInsufficientScope,
+	ErrorCodeInteractionRequired.errorCode:     ErrorCodeInteractionRequired,
 	ErrorCodeInvalidClient.errorCode:           ErrorCodeInvalidClient,
+	ErrorCodeInvalidClientMetadata.errorCode:   ErrorCodeInvalidClientMetadata,
+	ErrorCodeInvalidDPoPProof.errorCode:        ErrorCodeInvalidDPoPProof,
 	ErrorCodeInvalidGrant.errorCode:            ErrorCodeInvalidGrant,
+	ErrorCodeInvalidRedirectURI.errorCode:      ErrorCodeInvalidRedirectURI,
 	ErrorCodeInvalidRequest.errorCode:          ErrorCodeInvalidRequest,
+	ErrorCodeInvalidRequestURI.errorCode:       ErrorCodeInvalidRequestURI,
+	ErrorCodeInvalidRequestObject.errorCode:    ErrorCodeInvalidRequestObject,
 	ErrorCodeInvalidScope.errorCode:            ErrorCodeInvalidScope,
+	ErrorCodeInvalidTarget.errorCode:           ErrorCodeInvalidTarget,
 	ErrorCodeInvalidToken.errorCode:            ErrorCodeInvalidToken,
+	ErrorCodeLoginRequired.errorCode:           ErrorCodeLoginRequired,
 	ErrorCodeServerError.errorCode:             ErrorCodeServerError,
 	ErrorCodeTemporarilyUnavailable.errorCode:  ErrorCodeTemporarilyUnavailable,
 	ErrorCodeUnauthorizedClient.errorCode:      ErrorCodeUnauthorizedClient,
@@ -235,6 +307,18 @@ func (e Error) SetReirectURI(u *url.URL) {
 
 	q := u.Query()
 
+	for key, val := range e.Params() {
+		q.Set(key, val)
+	}
+
+	u.RawQuery = q.Encode()
+}
+
+// Params returns non-empty parameters of the error response returned to the
+// client from the authorization endpoint, see RFC 6749 section 4.1.2.1.
+func (e Error) Params() map[string]string {
+	out := make(map[string]string, 4)
+
 	for key, val := range map[string]string{
 		"error":             e.Code.String(),
 		"error_description": e.Description,
@@ -245,17 +329,12 @@ func (e Error) SetReirectURI(u *url.URL) {
 			continue
 		}
 
-		q.Set(key, val)
+		out[key] = val
 	}
 
-	u.RawQuery = q.Encode()
+	return out
 }
 
-// NewError creates a new Error with the stack pointing to the function call
-// line number.
-//
-// If no code or ErrorCodeUnd is provided, ErrorCodeAccessDenied will be
-// used instead.
 func NewError(code ErrorCode, description, uri string, requestState ...string) *Error {
 	if code == ErrorCodeUnd {
 		code = ErrorCodeAccessDenied
@@ -2,6 +2,7 @@ package domain_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
@@ -26,3 +27,19 @@ func TestErrorCode_UnmarshalForm(t *testing.T) {
 		t.Errorf("UnmarshalForm(%s) = %v, want %v", input, result, domain.ErrorCodeAccessDenied)
 	}
 }
+
+func TestError_Params(t *testing.T) {
+	t.Parallel()
+
+	result := domain.NewError(domain.ErrorCodeAccessDenied, "user deny authorization request", "", "1234567890").
+		Params()
+	expResult := map[string]string{
+		"error":             "access_denied",
+		"error_description": "user deny authorization request",
+		"state":             "1234567890",
+	}
+
+	if !reflect.DeepEqual(result, expResult) {
+		t.Errorf("Params() = %v, want %v", result, expResult)
+	}
+}
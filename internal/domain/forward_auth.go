@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type (
+	// ForwardAuthRule describes which identities can access the upstream
+	// protected by the forward-auth endpoint of the reverse proxy.
+	ForwardAuthRule struct {
+		// Host contains patterns of the upstream hosts, where '*'
+		// matches any sequence of characters.
+		Host []string
+		// Me contains patterns of the profile URLs allowed to access
+		// the upstream. If empty, any identity of the owner is allowed.
+		Me []string
+		// Scope is requested on login and passed to the upstream.
+		Scope Scopes
+	}
+
+	// ForwardAuthSession describes the identity authenticated for the
+	// upstreams by the forward-auth endpoint.
+	ForwardAuthSession struct {
+		Expiry time.Time
+		Me     Me
+		Scope  Scopes
+	}
+)
+
+// MatchesHost reports whether the rule protects upstream with provided host.
+func (far ForwardAuthRule) MatchesHost(host string) bool {
+	return matchAny(far.Host, host)
+}
+
+// Allows reports whether provided identity can access the upstream.
+func (far ForwardAuthRule) Allows(me Me) bool {
+	return len(far.Me) == 0 || matchAny(far.Me, me.String())
+}
@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestForwardAuthRule_Allows(t *testing.T) {
+	t.Parallel()
+
+	me := domain.TestMe(t, "https://user.example.net/")
+
+	for name, tc := range map[string]struct {
+		rule   domain.ForwardAuthRule
+		host   string
+		expect bool
+	}{
+		"any":       {rule: domain.ForwardAuthRule{Host: []string{"*.example.com"}}, host: "dash.example.com", expect: true},
+		"me":        {rule: domain.ForwardAuthRule{Host: []string{"*"}, Me: []string{"https://*.example.net/"}}, host: "example.org", expect: true},
+		"other me":  {rule: domain.ForwardAuthRule{Host: []string{"*"}, Me: []string{"https://example.net/"}}, host: "example.org", expect: false},
+		"unmatched": {rule: domain.ForwardAuthRule{Host: []string{"dash.example.com"}}, host: "wiki.example.com", expect: false},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := tc.rule.MatchesHost(tc.host) && tc.rule.Allows(*me); result != tc.expect {
+				t.Errorf("MatchesHost(%s) && Allows(%s) = %t, want %t", tc.host, me, result, tc.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+// GrantExpiry describes how long the owner grants access to the client on the
+// consent page.
+//
+// NOTE(toby3d): Encapsulate enums in structs for extra compile-time safety:
+// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+type GrantExpiry struct {
+	grantExpiry string
+	duration    time.Duration
+}
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	// GrantExpiryUnd describes access granted for the default lifetime of
+	// tokens configured on the server.
+	GrantExpiryUnd = GrantExpiry{grantExpiry: "", duration: 0} // "und"
+
+	// GrantExpiryDay describes access granted for one day.
+	GrantExpiryDay = GrantExpiry{grantExpiry: "day", duration: 24 * time.Hour} // "day"
+
+	// GrantExpiryWeek describes access granted for one week.
+	GrantExpiryWeek = GrantExpiry{grantExpiry: "week", duration: 7 * 24 * time.Hour} // "week"
+
+	// GrantExpiryForever describes access granted until tokens are
+	// revoked.
+	GrantExpiryForever = GrantExpiry{grantExpiry: "forever", duration: 0} // "forever"
+)
+
+var ErrGrantExpiryUnknown error = NewError(ErrorCodeInvalidRequest, "unknown grant expiry", "")
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsGrantExpiries = map[string]GrantExpiry{
+	GrantExpiryDay.grantExpiry:     GrantExpiryDay,
+	GrantExpiryForever.grantExpiry: GrantExpiryForever,
+	GrantExpiryWeek.grantExpiry:    GrantExpiryWeek,
+}
+
+// ParseGrantExpiry parse string identifier of grant expiry into struct enum.
+func ParseGrantExpiry(uid string) (GrantExpiry, error) {
+	if grantExpiry, ok := uidsGrantExpiries[strings.ToLower(uid)]; ok {
+		return grantExpiry, nil
+	}
+
+	return GrantExpiryUnd, fmt.Errorf("%w: %s", ErrGrantExpiryUnknown, uid)
+}
+
+// UnmarshalForm implements custom unmarshler for form values. Empty value
+// means the default lifetime.
+func (ge *GrantExpiry) UnmarshalForm(src []byte) error {
+	if len(src) == 0 {
+		*ge = GrantExpiryUnd
+
+		return nil
+	}
+
+	grantExpiry, err := ParseGrantExpiry(string(src))
+	if err != nil {
+		return fmt.Errorf("GrantExpiry: UnmarshalForm: %w", err)
+	}
+
+	*ge = grantExpiry
+
+	return nil
+}
+
+// UnmarshalJSON implements custom unmarshler for JSON.
+func (ge *GrantExpiry) UnmarshalJSON(v []byte) error {
+	uid, err := strconv.Unquote(string(v))
+	if err != nil {
+		return fmt.Errorf("GrantExpiry: UnmarshalJSON: %w", err)
+	}
+
+	// NOTE(toby3d): sessions stored before grant expiry support.
+	if uid == "" {
+		*ge = GrantExpiryUnd
+
+		return nil
+	}
+
+	grantExpiry, err := ParseGrantExpiry(uid)
+	if err != nil {
+		return fmt.Errorf("GrantExpiry: UnmarshalJSON: %w", err)
+	}
+
+	*ge = grantExpiry
+
+	return nil
+}
+
+// MarshalJSON implements custom marshler for JSON.
+func (ge GrantExpiry) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(ge.grantExpiry)), nil
+}
+
+// Expiration returns lifetime of tokens issued by this grant: provided default
+// one for undefined expiry or zero for access granted forever.
+func (ge GrantExpiry) Expiration(defaultExpiration time.Duration) time.Duration {
+	if ge == GrantExpiryUnd {
+		return defaultExpiration
+	}
+
+	return ge.duration
+}
+
+// String returns string representation of grant expiry.
+func (ge GrantExpiry) String() string {
+	if ge.grantExpiry != "" {
+		return ge.grantExpiry
+	}
+
+	return common.Und
+}
+
+func (ge GrantExpiry) GoString() string {
+	return "domain.GrantExpiry(" + ge.String() + ")"
+}
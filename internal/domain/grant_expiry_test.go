@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestGrantExpiry_UnmarshalForm(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		in            string
+		expExpiration time.Duration
+		expError      bool
+	}{
+		"default": {in: "", expExpiration: time.Hour},
+		"day":     {in: "day", expExpiration: 24 * time.Hour},
+		"week":    {in: "week", expExpiration: 7 * 24 * time.Hour},
+		"forever": {in: "forever", expExpiration: 0},
+		"unknown": {in: "month", expError: true},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var result domain.GrantExpiry
+			if err := result.UnmarshalForm([]byte(tc.in)); (err != nil) != tc.expError {
+				t.Fatalf("UnmarshalForm(%s) = %v, want error %t", tc.in, err, tc.expError)
+			}
+
+			if tc.expError {
+				return
+			}
+
+			if expiration := result.Expiration(time.Hour); expiration != tc.expExpiration {
+				t.Errorf("Expiration(%s) = %s, want %s", time.Hour, expiration, tc.expExpiration)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// Image describes a third-party image prepared for displaying on our pages.
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// TestImage returns a valid generated PNG Image of provided size for tests.
+func TestImage(tb testing.TB, width, height int) *Image {
+	tb.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0xff, A: 0xff})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &Image{
+		ContentType: "image/png",
+		Data:        buf.Bytes(),
+		Width:       width,
+		Height:      height,
+	}
+}
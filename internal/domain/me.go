@@ -130,6 +130,14 @@ func (m *Me) UnmarshalJSON(v []byte) error {
 		return fmt.Errorf("Me: UnmarshalJSON: %w", err)
 	}
 
+	// NOTE(toby3d): me is optional in authorization requests, so empty
+	// value is decoded as is.
+	if src == "" {
+		*m = Me{}
+
+		return nil
+	}
+
 	me, err := ParseMe(src)
 	if err != nil {
 		return fmt.Errorf("Me: UnmarshalJSON: %w", err)
@@ -40,6 +40,16 @@ type Metadata struct {
 	// The User Info Endpoint.
 	UserinfoEndpoint *url.URL
 
+	// The Dynamic Client Registration Endpoint, if enabled.
+	RegistrationEndpoint *url.URL
+
+	// The Pushed Authorization Request Endpoint.
+	PushedAuthorizationRequestEndpoint *url.URL
+
+	// URL of the JSON Web Key Set document of keys used to sign ID Tokens,
+	// if OpenID Connect is enabled.
+	JWKSURI *url.URL
+
 	// URL of a page containing human-readable information that developers
 	// might need to know when using the server. This might be a link to the
 	// IndieAuth spec or something more personal to your implementation.
@@ -55,6 +65,14 @@ type Metadata struct {
 	// omitted, the default is code.
 	ResponseTypesSupported []ResponseType
 
+	// JSON array containing a list of the response_mode values that this
+	// authorization server supports.
+	ResponseModesSupported []ResponseMode
+
+	// List of the JWS signing algorithms supported for signing JWT-secured
+	// authorization responses.
+	AuthorizationSigningAlgValuesSupported []string
+
 	// JSON array containing grant type values supported. If omitted, the
 	// default value differs from RFC8414 and is authorization_code.
 	GrantTypesSupported []GrantType
@@ -63,6 +81,17 @@ type Metadata struct {
 	// differs from RFC8414 in that it is not optional as PKCE is REQUIRED.
 	CodeChallengeMethodsSupported []CodeChallengeMethod
 
+	// List of client authentication methods supported by this token
+	// endpoint.
+	TokenEndpointAuthMethodsSupported []string
+
+	// List of the JWS signing algorithms supported by the token endpoint
+	// for the signature on the JWT used to authenticate the client.
+	TokenEndpointAuthSigningAlgValuesSupported []string
+
+	// List of the JWS signing algorithms supported for request objects.
+	RequestObjectSigningAlgValuesSupported []string
+
 	// List of client authentication methods supported by this introspection endpoint.
 	IntrospectionEndpointAuthMethodsSupported []string // ["Bearer"]
 
@@ -73,6 +102,24 @@ type Metadata struct {
 	// As the iss parameter is REQUIRED, this is provided for compatibility
 	// with OAuth 2.0 servers implementing the parameter.
 	AuthorizationResponseIssParameterSupported bool
+
+	// Boolean parameter indicating whether the authorization server
+	// accepts authorization request data only via PAR.
+	RequirePushedAuthorizationRequests bool
+
+	// Boolean parameters indicating whether the authorization server
+	// accepts request objects passed by value and by reference.
+	RequestParameterSupported    bool
+	RequestURIParameterSupported bool
+
+	// List of the subject identifier types, the JWS signing algorithms of
+	// ID Tokens and the claims supported by the OpenID Provider.
+	SubjectTypesSupported            []string
+	IDTokenSigningAlgValuesSupported []string
+	ClaimsSupported                  []string
+
+	// List of the JWS signing algorithms supported for DPoP proofs.
+	DPoPSigningAlgValuesSupported []string
 }
 
 // TestMetadata returns valid random generated Metadata for tests.
@@ -93,7 +140,13 @@ func TestMetadata(tb testing.TB) *Metadata {
 		IntrospectionEndpoint: &url.URL{Scheme: "https", Host: "indieauth.example.com", Path: "/introspect"},
 		RevocationEndpoint:    &url.URL{Scheme: "https", Host: "indieauth.example.com", Path: "/revocation"},
 		UserinfoEndpoint:      &url.URL{Scheme: "https", Host: "indieauth.example.com", Path: "/userinfo"},
-		ServiceDocumentation:  &url.URL{Scheme: "https", Host: "indieauth.net", Path: "/draft/"},
+		RegistrationEndpoint:  &url.URL{Scheme: "https", Host: "indieauth.example.com", Path: "/register"},
+		PushedAuthorizationRequestEndpoint: &url.URL{
+			Scheme: "https",
+			Host:   "indieauth.example.com",
+			Path:   "/par",
+		},
+		ServiceDocumentation: &url.URL{Scheme: "https", Host: "indieauth.net", Path: "/draft/"},
 		ScopesSupported: Scopes{
 			ScopeBlock,
 			ScopeChannels,
@@ -123,8 +176,11 @@ func TestMetadata(tb testing.TB) *Metadata {
 			CodeChallengeMethodS256,
 			CodeChallengeMethodS512,
 		},
+		TokenEndpointAuthMethodsSupported:          []string{"none", "client_secret_basic"},
+		TokenEndpointAuthSigningAlgValuesSupported: []string{"RS256", "ES256"},
 		IntrospectionEndpointAuthMethodsSupported:  []string{"Bearer"},
 		RevocationEndpointAuthMethodsSupported:     []string{"none"},
 		AuthorizationResponseIssParameterSupported: true,
+		RequirePushedAuthorizationRequests:         false,
 	}
 }
@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+type (
+	// PolicyRule describes constraints applied to the authorization and
+	// token requests matched by the rule.
+	PolicyRule struct {
+		// Name identifies the rule in the dry-run explanation.
+		Name  string
+		Match PolicyMatch
+		// Strip contains scopes which are silently removed from the
+		// request.
+		Strip  Scopes
+		Action PolicyAction
+		// AccessTokenExpiry is the default and the maximum lifetime of
+		// access tokens, zero means the server default.
+		AccessTokenExpiry time.Duration
+		// RefreshTokenExpiry is the lifetime of refresh tokens, zero
+		// means the server default.
+		RefreshTokenExpiry time.Duration
+		// MaxTokens caps the number of concurrently valid tokens of the
+		// client, the oldest ones are revoked first. Zero means no cap.
+		MaxTokens           int
+		RequirePKCE         bool
+		RequireSecondFactor bool
+	}
+
+	// PolicyMatch describes which requests are matched by the rule. Each
+	// non-empty field must match, patterns can contain '*' wildcards.
+	PolicyMatch struct {
+		ClientHost   []string
+		RedirectHost []string
+		Me           []string
+		// Scope matches requests with any of provided scopes.
+		Scope Scopes
+	}
+
+	// PolicyRequest contains parameters of the authorization or token
+	// request evaluated by policy.
+	PolicyRequest struct {
+		RedirectURI *url.URL
+		// Me is nil if the owner has not chosen identity yet.
+		Me       *Me
+		ClientID ClientID
+		Scope    Scopes
+	}
+
+	// PolicyDecision describes the result of the policy evaluation.
+	PolicyDecision struct {
+		// Rule is the matched rule, nil if none of rules is matched
+		// and request is allowed by default.
+		Rule *PolicyRule
+		// Scope contains requested scopes without stripped ones.
+		Scope Scopes
+		// Stripped contains removed requested scopes.
+		Stripped Scopes
+	}
+
+	// PolicyAction describes what to do with the matched request.
+	//
+	// NOTE(toby3d): Encapsulate enums in structs for extra compile-time
+	// safety:
+	// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+	PolicyAction struct {
+		policyAction string
+	}
+)
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	PolicyActionUnd = PolicyAction{policyAction: ""} // "und"
+
+	// PolicyActionAllow allows the matched request with constraints of
+	// the rule.
+	PolicyActionAllow = PolicyAction{policyAction: "allow"} // "allow"
+
+	// PolicyActionDeny denies the matched request by access_denied
+	// error.
+	PolicyActionDeny = PolicyAction{policyAction: "deny"} // "deny"
+)
+
+var ErrPolicyActionUnknown error = NewError(ErrorCodeInvalidRequest, "unknown policy action", "")
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsPolicyActions = map[string]PolicyAction{
+	PolicyActionAllow.policyAction: PolicyActionAllow,
+	PolicyActionDeny.policyAction:  PolicyActionDeny,
+}
+
+// ParsePolicyAction parse string identifier of policy action into struct enum.
+func ParsePolicyAction(uid string) (PolicyAction, error) {
+	if action, ok := uidsPolicyActions[strings.ToLower(uid)]; ok {
+		return action, nil
+	}
+
+	return PolicyActionUnd, fmt.Errorf("%w: %s", ErrPolicyActionUnknown, uid)
+}
+
+// String returns string representation of policy action.
+func (pa PolicyAction) String() string {
+	if pa.policyAction != "" {
+		return pa.policyAction
+	}
+
+	return common.Und
+}
+
+func (pa PolicyAction) GoString() string {
+	return "domain.PolicyAction(" + pa.String() + ")"
+}
+
+// Matches reports whether the rule is applied to the provided request.
+func (pr PolicyRule) Matches(req PolicyRequest) bool {
+	var clientHost, redirectHost, me string
+
+	if u := req.ClientID.URL(); u != nil {
+		clientHost = u.Hostname()
+	}
+
+	if req.RedirectURI != nil {
+		redirectHost = req.RedirectURI.Hostname()
+	}
+
+	if req.Me != nil {
+		me = req.Me.String()
+	}
+
+	for patterns, value := range map[*[]string]string{
+		&pr.Match.ClientHost:   clientHost,
+		&pr.Match.RedirectHost: redirectHost,
+		&pr.Match.Me:           me,
+	} {
+		if len(*patterns) > 0 && !matchAny(*patterns, value) {
+			return false
+		}
+	}
+
+	if len(pr.Match.Scope) == 0 {
+		return true
+	}
+
+	for _, s := range pr.Match.Scope {
+		if req.Scope.Has(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsDenied reports whether the request is denied by the matched rule.
+func (pd PolicyDecision) IsDenied() bool {
+	return pd.Rule != nil && pd.Rule.Action == PolicyActionDeny
+}
+
+// RequirePKCE reports whether the matched rule requires PKCE.
+func (pd PolicyDecision) RequirePKCE() bool {
+	return pd.Rule != nil && pd.Rule.RequirePKCE
+}
+
+// RequireSecondFactor reports whether the matched rule requires the owner to
+// confirm authorization by the second factor.
+func (pd PolicyDecision) RequireSecondFactor() bool {
+	return pd.Rule != nil && pd.Rule.RequireSecondFactor
+}
+
+// MaxTokens returns the number of concurrently valid tokens allowed for the
+// client, zero means no cap.
+func (pd PolicyDecision) MaxTokens() int {
+	if pd.Rule == nil {
+		return 0
+	}
+
+	return pd.Rule.MaxTokens
+}
+
+// Expiration returns the lifetime of the access token granted by the owner
+// for the provided period, limited by the matched rule.
+func (pd PolicyDecision) Expiration(grant GrantExpiry, fallback time.Duration) time.Duration {
+	if pd.Rule == nil || pd.Rule.AccessTokenExpiry == 0 {
+		return grant.Expiration(fallback)
+	}
+
+	out := grant.Expiration(pd.Rule.AccessTokenExpiry)
+	if out == 0 || out > pd.Rule.AccessTokenExpiry {
+		return pd.Rule.AccessTokenExpiry
+	}
+
+	return out
+}
+
+// RefreshExpiration returns the lifetime of the refresh token set by the
+// matched rule, which is never longer than the period granted by the owner.
+// Zero means no refresh token.
+func (pd PolicyDecision) RefreshExpiration(grant GrantExpiry, fallback time.Duration) time.Duration {
+	if pd.Rule != nil && pd.Rule.RefreshTokenExpiry != 0 {
+		fallback = pd.Rule.RefreshTokenExpiry
+	}
+
+	if limit := grant.Expiration(0); limit != 0 && limit < fallback {
+		return limit
+	}
+
+	return fallback
+}
+
+// Explain returns human-readable description of the decision: which rule is
+// matched and which constraints are applied.
+func (pd PolicyDecision) Explain() string {
+	out := new(strings.Builder)
+
+	if pd.Rule == nil {
+		fmt.Fprintln(out, "rule: none, allowed by default")
+		fmt.Fprintln(out, "scope:", pd.Scope)
+
+		return out.String()
+	}
+
+	fmt.Fprintln(out, "rule:", pd.Rule.Name)
+	fmt.Fprintln(out, "action:", pd.Rule.Action)
+
+	if pd.IsDenied() {
+		return out.String()
+	}
+
+	fmt.Fprintln(out, "scope:", pd.Scope)
+
+	if len(pd.Stripped) > 0 {
+		fmt.Fprintln(out, "stripped scope:", pd.Stripped)
+	}
+
+	if pd.Rule.AccessTokenExpiry != 0 {
+		fmt.Fprintln(out, "access token expiry:", pd.Rule.AccessTokenExpiry)
+	}
+
+	if pd.Rule.RefreshTokenExpiry != 0 {
+		fmt.Fprintln(out, "refresh token expiry:", pd.Rule.RefreshTokenExpiry)
+	}
+
+	if pd.Rule.MaxTokens > 0 {
+		fmt.Fprintln(out, "max tokens:", pd.Rule.MaxTokens)
+	}
+
+	if pd.Rule.RequirePKCE {
+		fmt.Fprintln(out, "requires PKCE")
+	}
+
+	if pd.Rule.RequireSecondFactor {
+		fmt.Fprintln(out, "requires second factor")
+	}
+
+	return out.String()
+}
+
+// matchAny reports whether value matches any of provided case-insensitive
+// patterns, where '*' matches any sequence of characters.
+func matchAny(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+
+	for _, pattern := range patterns {
+		if matchPattern(strings.ToLower(pattern), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchPattern(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+
+	value = value[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+
+		value = value[i+len(part):]
+	}
+
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
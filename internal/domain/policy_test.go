@@ -0,0 +1,153 @@
+package domain_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestPolicyRule_Matches(t *testing.T) {
+	t.Parallel()
+
+	req := domain.PolicyRequest{
+		ClientID:    *domain.TestClientID(t),
+		RedirectURI: &url.URL{Scheme: "https", Host: "app.example.com", Path: "/callback"},
+		Me:          domain.TestMe(t, "https://user.example.net/"),
+		Scope:       domain.Scopes{domain.ScopeProfile, domain.ScopeCreate},
+	}
+
+	for name, tc := range map[string]struct {
+		match  domain.PolicyMatch
+		expect bool
+	}{
+		"any":           {match: domain.PolicyMatch{}, expect: true},
+		"client host":   {match: domain.PolicyMatch{ClientHost: []string{"127.0.0.1"}}, expect: true},
+		"redirect host": {match: domain.PolicyMatch{RedirectHost: []string{"*.EXAMPLE.com"}}, expect: true},
+		"me":            {match: domain.PolicyMatch{Me: []string{"https://*.example.net/*"}}, expect: true},
+		"scope":         {match: domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeDelete, domain.ScopeCreate}}, expect: true},
+		"other client":  {match: domain.PolicyMatch{ClientHost: []string{"*.example.org"}}, expect: false},
+		"other me":      {match: domain.PolicyMatch{Me: []string{"https://example.net/"}}, expect: false},
+		"other scope":   {match: domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeDelete}}, expect: false},
+		"all but scopes": {
+			match: domain.PolicyMatch{
+				ClientHost: []string{"*"},
+				Me:         []string{"*"},
+				Scope:      domain.Scopes{domain.ScopeMedia},
+			},
+			expect: false,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := (domain.PolicyRule{Match: tc.match}).Matches(req); result != tc.expect {
+				t.Errorf("Matches(%+v) = %t, want %t", req, result, tc.expect)
+			}
+		})
+	}
+}
+
+func TestPolicyDecision_Expiration(t *testing.T) {
+	t.Parallel()
+
+	rule := &domain.PolicyRule{AccessTokenExpiry: 48 * time.Hour}
+
+	for name, tc := range map[string]struct {
+		decision domain.PolicyDecision
+		grant    domain.GrantExpiry
+		expect   time.Duration
+	}{
+		"default":        {decision: domain.PolicyDecision{}, grant: domain.GrantExpiryUnd, expect: time.Hour},
+		"forever":        {decision: domain.PolicyDecision{}, grant: domain.GrantExpiryForever, expect: 0},
+		"rule default":   {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryUnd, expect: 48 * time.Hour},
+		"rule shorter":   {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryDay, expect: 24 * time.Hour},
+		"rule caps":      {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryWeek, expect: 48 * time.Hour},
+		"rule caps ever": {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryForever, expect: 48 * time.Hour},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := tc.decision.Expiration(tc.grant, time.Hour); result != tc.expect {
+				t.Errorf("Expiration(%s, %s) = %s, want %s", tc.grant, time.Hour, result, tc.expect)
+			}
+		})
+	}
+}
+
+func TestPolicyDecision_RefreshExpiration(t *testing.T) {
+	t.Parallel()
+
+	rule := &domain.PolicyRule{RefreshTokenExpiry: 48 * time.Hour}
+
+	for name, tc := range map[string]struct {
+		decision domain.PolicyDecision
+		grant    domain.GrantExpiry
+		expect   time.Duration
+	}{
+		"default":  {decision: domain.PolicyDecision{}, grant: domain.GrantExpiryUnd, expect: 720 * time.Hour},
+		"forever":  {decision: domain.PolicyDecision{}, grant: domain.GrantExpiryForever, expect: 720 * time.Hour},
+		"day":      {decision: domain.PolicyDecision{}, grant: domain.GrantExpiryDay, expect: 24 * time.Hour},
+		"rule":     {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryUnd, expect: 48 * time.Hour},
+		"rule day": {decision: domain.PolicyDecision{Rule: rule}, grant: domain.GrantExpiryDay, expect: 24 * time.Hour},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := tc.decision.RefreshExpiration(tc.grant, 720*time.Hour); result != tc.expect {
+				t.Errorf("RefreshExpiration(%s, %s) = %s, want %s", tc.grant, 720*time.Hour, result,
+					tc.expect)
+			}
+		})
+	}
+}
+
+func TestPolicyDecision_Explain(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		decision domain.PolicyDecision
+		expect   string
+	}{
+		"default": {
+			decision: domain.PolicyDecision{Scope: domain.Scopes{domain.ScopeProfile}},
+			expect:   "rule: none, allowed by default\nscope: profile\n",
+		},
+		"denied": {
+			decision: domain.PolicyDecision{Rule: &domain.PolicyRule{Name: "block", Action: domain.PolicyActionDeny}},
+			expect:   "rule: block\naction: deny\n",
+		},
+		"constrained": {
+			decision: domain.PolicyDecision{
+				Rule: &domain.PolicyRule{
+					Name:              "micropub",
+					Action:            domain.PolicyActionAllow,
+					AccessTokenExpiry: time.Hour,
+					MaxTokens:         2,
+					RequirePKCE:       true,
+				},
+				Scope:    domain.Scopes{domain.ScopeCreate},
+				Stripped: domain.Scopes{domain.ScopeDelete},
+			},
+			expect: "rule: micropub\naction: allow\nscope: create\nstripped scope: delete\n" +
+				"access token expiry: 1h0m0s\nmax tokens: 2\nrequires PKCE\n",
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := tc.decision.Explain(); result != tc.expect {
+				t.Errorf("Explain() = %q, want %q", result, tc.expect)
+			}
+		})
+	}
+}
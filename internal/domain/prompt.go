@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+type (
+	// Prompt describes whether the authorization server prompts the owner
+	// for reauthentication and consent.
+	//
+	// NOTE(toby3d): Encapsulate enums in structs for extra compile-time
+	// safety:
+	// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+	Prompt struct {
+		prompt string
+	}
+
+	// Prompts represent set of Prompt domains.
+	Prompts []Prompt
+)
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	PromptUnd = Prompt{prompt: ""} // "und"
+
+	// PromptNone requires the authorization server to not display any
+	// user interface pages. The error is returned if the owner is not
+	// already authenticated or the client does not have pre-configured
+	// consent.
+	PromptNone = Prompt{prompt: "none"} // "none"
+
+	// PromptLogin requires the authorization server to prompt the owner
+	// for reauthentication.
+	PromptLogin = Prompt{prompt: "login"} // "login"
+
+	// PromptConsent requires the authorization server to prompt the
+	// owner for consent before returning information to the client.
+	PromptConsent = Prompt{prompt: "consent"} // "consent"
+)
+
+var (
+	ErrPromptUnknown error = NewError(
+		ErrorCodeInvalidRequest,
+		"unknown prompt",
+		"https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest",
+	)
+	ErrPromptNone error = NewError(
+		ErrorCodeInvalidRequest,
+		"prompt none cannot be combined with any other value",
+		"https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest",
+	)
+)
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsPrompts = map[string]Prompt{
+	PromptNone.prompt:    PromptNone,
+	PromptLogin.prompt:   PromptLogin,
+	PromptConsent.prompt: PromptConsent,
+}
+
+// ParsePrompt parse string as prompt struct enum.
+func ParsePrompt(uid string) (Prompt, error) {
+	if prompt, ok := uidsPrompts[strings.ToLower(uid)]; ok {
+		return prompt, nil
+	}
+
+	return PromptUnd, fmt.Errorf("%w: %s", ErrPromptUnknown, uid)
+}
+
+// String returns string representation of prompt.
+func (p Prompt) String() string {
+	if p.prompt != "" {
+		return p.prompt
+	}
+
+	return common.Und
+}
+
+func (p Prompt) GoString() string {
+	return "domain.Prompt(" + p.String() + ")"
+}
+
+// ParsePrompts parse space-separated list of prompts. The none value cannot
+// be combined with any other one.
+func ParsePrompts(src string) (Prompts, error) {
+	out := make(Prompts, 0)
+
+	for _, rawPrompt := range strings.Fields(src) {
+		prompt, err := ParsePrompt(rawPrompt)
+		if err != nil {
+			return nil, err
+		}
+
+		if out.Has(prompt) {
+			continue
+		}
+
+		out = append(out, prompt)
+	}
+
+	if out.Has(PromptNone) && len(out) > 1 {
+		return nil, ErrPromptNone
+	}
+
+	return out, nil
+}
+
+// UnmarshalForm implements custom unmarshler for form values.
+func (p *Prompts) UnmarshalForm(v []byte) error {
+	out, err := ParsePrompts(string(v))
+	if err != nil {
+		return fmt.Errorf("Prompts: UnmarshalForm: %w", err)
+	}
+
+	*p = out
+
+	return nil
+}
+
+// UnmarshalJSON implements custom unmarshler for JSON.
+func (p *Prompts) UnmarshalJSON(v []byte) error {
+	src, err := strconv.Unquote(string(v))
+	if err != nil {
+		return fmt.Errorf("Prompts: UnmarshalJSON: %w", err)
+	}
+
+	out, err := ParsePrompts(src)
+	if err != nil {
+		return fmt.Errorf("Prompts: UnmarshalJSON: %w", err)
+	}
+
+	*p = out
+
+	return nil
+}
+
+// MarshalJSON implements custom marshler for JSON.
+func (p Prompts) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(p.String())), nil
+}
+
+// Has check what input prompt contains in current prompts collection.
+func (p Prompts) Has(prompt Prompt) bool {
+	for i := range p {
+		if p[i] == prompt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns string representation of prompts.
+func (p Prompts) String() string {
+	prompts := make([]string, len(p))
+
+	for i := range p {
+		prompts[i] = p[i].String()
+	}
+
+	return strings.Join(prompts, " ")
+}
@@ -0,0 +1,39 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParsePrompts(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		expError error
+		in       string
+		out      domain.Prompts
+	}{
+		"empty":    {in: "", out: domain.Prompts{}},
+		"none":     {in: "none", out: domain.Prompts{domain.PromptNone}},
+		"multiple": {in: "login consent login", out: domain.Prompts{domain.PromptLogin, domain.PromptConsent}},
+		"combined": {in: "none consent", expError: domain.ErrPromptNone},
+		"unknown":  {in: "silent", expError: domain.ErrPromptUnknown},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParsePrompts(tc.in)
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("ParsePrompts(%s) = %+v, want %+v", tc.in, err, tc.expError)
+			}
+
+			if result.String() != tc.out.String() {
+				t.Errorf("ParsePrompts(%s) = %s, want %s", tc.in, result, tc.out)
+			}
+		})
+	}
+}
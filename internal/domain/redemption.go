@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// Redemption describes the fact of the exchange of the authorization code and
+// the tokens minted from it, so any reuse of the same code can be detected
+// and the whole family of the tokens revoked.
+//
+//nolint:tagliatelle
+type Redemption struct {
+	CreatedAt time.Time `json:"created_at"`
+	RevokedAt time.Time `json:"revoked_at,omitempty"`
+	// Expiry is the time after which minted tokens are expired, zero if
+	// they never expire.
+	Expiry   time.Time `json:"expiry,omitempty"`
+	ClientID ClientID  `json:"client_id"`
+	Me       Me        `json:"me"`
+	ID       string    `json:"-"`
+	// RefreshToken is the hash of the only refresh token of the family
+	// which can be used, empty if there is none. Any other refresh token
+	// of the family is a replay.
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	TokenIDs     []string `json:"token_ids"`
+}
+
+// NewRedemptionID returns identifier of the redemption of the provided code.
+// The code itself never leaves the store: identifier is a SHA-256 hash of it.
+func NewRedemptionID(code string) string {
+	hash := sha256.Sum256([]byte(code))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// TestRedemption returns valid random generated redemption for tests.
+func TestRedemption(tb testing.TB) *Redemption {
+	tb.Helper()
+
+	return &Redemption{
+		CreatedAt: time.Now().UTC().Add(-1 * time.Minute),
+		RevokedAt: time.Time{},
+		ClientID:  *TestClientID(tb),
+		Me:        *TestMe(tb, "https://user.example.net/"),
+		ID:        NewRedemptionID(TestSession(tb).Code),
+		TokenIDs:  []string{"abc123"},
+	}
+}
+
+// IsActive reports whether tokens minted from the code can still be used at
+// the provided time.
+func (r Redemption) IsActive(ts time.Time) bool {
+	return !r.IsRevoked() && (r.Expiry.IsZero() || r.Expiry.After(ts))
+}
+
+// IsRevoked reports whether all tokens minted from the code are revoked.
+func (r Redemption) IsRevoked() bool {
+	return !r.RevokedAt.IsZero()
+}
+
+// IsExpired reports whether the redemption is no longer needed at the provided
+// time: tokens minted from the code are expired. Fallback is the lifetime of
+// tokens used if the expiry of minted tokens is unknown, zero if tokens never
+// expire.
+func (r Redemption) IsExpired(ts time.Time, fallback time.Duration) bool {
+	return !r.Expiry.After(ts) && fallback != 0 && !r.CreatedAt.Add(fallback).After(ts)
+}
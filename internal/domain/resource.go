@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"net/url"
+)
+
+// ErrResource describes invalid resource indicator.
+var ErrResource error = NewError(
+	ErrorCodeInvalidTarget,
+	"resource must be an absolute URI without a fragment component",
+	"https://www.rfc-editor.org/rfc/rfc8707#section-2",
+)
+
+// ValidateResources checks resource indicators requested by the client as
+// described in RFC 8707 section 2.
+func ValidateResources(src []string) error {
+	for i := range src {
+		u, err := url.Parse(src[i])
+		if err != nil || !u.IsAbs() || u.Fragment != "" || u.RawFragment != "" {
+			return ErrResource
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestValidateResources(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		expError error
+		in       []string
+	}{
+		"empty":    {in: nil},
+		"valid":    {in: []string{"https://api.example.com/", "https://example.com/micropub"}},
+		"relative": {in: []string{"/micropub"}, expError: domain.ErrResource},
+		"fragment": {in: []string{"https://api.example.com/#me"}, expError: domain.ErrResource},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := domain.ValidateResources(tc.in); !errors.Is(err, tc.expError) {
+				t.Errorf("ValidateResources(%v) = %+v, want %+v", tc.in, err, tc.expError)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+// ResponseMode describes mechanism to be used for returning authorization
+// response parameters from the authorization endpoint.
+//
+// NOTE(toby3d): Encapsulate enums in structs for extra compile-time safety:
+// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+type ResponseMode struct {
+	responseMode string
+}
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	ResponseModeUnd = ResponseMode{responseMode: ""} // "und"
+
+	// ResponseModeQuery encodes response parameters in the query string
+	// of the redirect URI. It's the default mode for the code response
+	// type.
+	ResponseModeQuery = ResponseMode{responseMode: "query"} // "query"
+
+	// ResponseModeFragment encodes response parameters in the fragment
+	// of the redirect URI.
+	ResponseModeFragment = ResponseMode{responseMode: "fragment"} // "fragment"
+
+	// ResponseModeFormPost encodes response parameters as HTML form
+	// values which are auto-submitted in the user agent by POST method:
+	// https://openid.net/specs/oauth-v2-form-post-response-mode-1_0.html
+	ResponseModeFormPost = ResponseMode{responseMode: "form_post"} // "form_post"
+
+	// ResponseModeJWT wraps response parameters in a signed JWT which is
+	// passed in the default mode of response type, i.e. query:
+	// https://openid.net/specs/oauth-v2-jarm.html#section-2.3.4
+	ResponseModeJWT = ResponseMode{responseMode: "jwt"} // "jwt"
+
+	// ResponseModeQueryJWT wraps response parameters in a signed JWT
+	// which is passed in the query string of the redirect URI.
+	ResponseModeQueryJWT = ResponseMode{responseMode: "query.jwt"} // "query.jwt"
+
+	// ResponseModeFragmentJWT wraps response parameters in a signed JWT
+	// which is passed in the fragment of the redirect URI.
+	ResponseModeFragmentJWT = ResponseMode{responseMode: "fragment.jwt"} // "fragment.jwt"
+
+	// ResponseModeFormPostJWT wraps response parameters in a signed JWT
+	// which is passed as auto-submitted HTML form value.
+	ResponseModeFormPostJWT = ResponseMode{responseMode: "form_post.jwt"} // "form_post.jwt"
+)
+
+var ErrResponseModeUnknown error = NewError(
+	ErrorCodeInvalidRequest,
+	"unknown response mode",
+	"https://openid.net/specs/oauth-v2-multiple-response-types-1_0.html#ResponseModes",
+)
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsResponseModes = map[string]ResponseMode{
+	ResponseModeQuery.responseMode:       ResponseModeQuery,
+	ResponseModeFragment.responseMode:    ResponseModeFragment,
+	ResponseModeFormPost.responseMode:    ResponseModeFormPost,
+	ResponseModeJWT.responseMode:         ResponseModeJWT,
+	ResponseModeQueryJWT.responseMode:    ResponseModeQueryJWT,
+	ResponseModeFragmentJWT.responseMode: ResponseModeFragmentJWT,
+	ResponseModeFormPostJWT.responseMode: ResponseModeFormPostJWT,
+}
+
+// ResponseModesSupported contains all response modes supported by the
+// authorization endpoint.
+//
+//nolint:gochecknoglobals // slices cannot be constants
+var ResponseModesSupported = []ResponseMode{
+	ResponseModeQuery,
+	ResponseModeFragment,
+	ResponseModeFormPost,
+	ResponseModeJWT,
+	ResponseModeQueryJWT,
+	ResponseModeFragmentJWT,
+	ResponseModeFormPostJWT,
+}
+
+// ParseResponseMode parse string as response mode struct enum.
+func ParseResponseMode(uid string) (ResponseMode, error) {
+	if responseMode, ok := uidsResponseModes[strings.ToLower(uid)]; ok {
+		return responseMode, nil
+	}
+
+	return ResponseModeUnd, fmt.Errorf("%w: %s", ErrResponseModeUnknown, uid)
+}
+
+// UnmarshalForm implements custom unmarshler for form values.
+func (rm *ResponseMode) UnmarshalForm(src []byte) error {
+	responseMode, err := ParseResponseMode(string(src))
+	if err != nil {
+		return fmt.Errorf("ResponseMode: UnmarshalForm: %w", err)
+	}
+
+	*rm = responseMode
+
+	return nil
+}
+
+// UnmarshalJSON implements custom unmarshler for JSON.
+func (rm *ResponseMode) UnmarshalJSON(v []byte) error {
+	uid, err := strconv.Unquote(string(v))
+	if err != nil {
+		return fmt.Errorf("ResponseMode: UnmarshalJSON: %w", err)
+	}
+
+	// NOTE(toby3d): sessions stored before response modes support.
+	if uid == "" {
+		*rm = ResponseModeUnd
+
+		return nil
+	}
+
+	responseMode, err := ParseResponseMode(uid)
+	if err != nil {
+		return fmt.Errorf("ResponseMode: UnmarshalJSON: %w", err)
+	}
+
+	*rm = responseMode
+
+	return nil
+}
+
+func (rm ResponseMode) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(rm.responseMode)), nil
+}
+
+// IsJWT reports whether response parameters must be wrapped in a signed JWT.
+func (rm ResponseMode) IsJWT() bool {
+	return strings.HasSuffix(rm.responseMode, "jwt")
+}
+
+// Transport returns mode used for passing response parameters itself without
+// JWT wrapping. Undefined mode is treated as query as the default one for the
+// code response type.
+func (rm ResponseMode) Transport() ResponseMode {
+	switch rm {
+	case ResponseModeFragment, ResponseModeFragmentJWT:
+		return ResponseModeFragment
+	case ResponseModeFormPost, ResponseModeFormPostJWT:
+		return ResponseModeFormPost
+	default:
+		return ResponseModeQuery
+	}
+}
+
+// String returns string representation of response mode.
+func (rm ResponseMode) String() string {
+	if rm.responseMode != "" {
+		return rm.responseMode
+	}
+
+	return common.Und
+}
+
+func (rm ResponseMode) GoString() string {
+	return "domain.ResponseMode(" + rm.String() + ")"
+}
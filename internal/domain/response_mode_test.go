@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseResponseMode(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in           string
+		out          domain.ResponseMode
+		expTransport domain.ResponseMode
+		expJWT       bool
+	}{
+		{in: "query", out: domain.ResponseModeQuery, expTransport: domain.ResponseModeQuery},
+		{in: "fragment", out: domain.ResponseModeFragment, expTransport: domain.ResponseModeFragment},
+		{in: "form_post", out: domain.ResponseModeFormPost, expTransport: domain.ResponseModeFormPost},
+		{in: "jwt", out: domain.ResponseModeJWT, expTransport: domain.ResponseModeQuery, expJWT: true},
+		{in: "query.jwt", out: domain.ResponseModeQueryJWT, expTransport: domain.ResponseModeQuery, expJWT: true},
+		{
+			in:           "fragment.jwt",
+			out:          domain.ResponseModeFragmentJWT,
+			expTransport: domain.ResponseModeFragment,
+			expJWT:       true,
+		},
+		{
+			in:           "form_post.jwt",
+			out:          domain.ResponseModeFormPostJWT,
+			expTransport: domain.ResponseModeFormPost,
+			expJWT:       true,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseResponseMode(tc.in)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if result != tc.out {
+				t.Errorf("ParseResponseMode(%s) = %v, want %v", tc.in, result, tc.out)
+			}
+
+			if transport := result.Transport(); transport != tc.expTransport {
+				t.Errorf("Transport() = %v, want %v", transport, tc.expTransport)
+			}
+
+			if isJWT := result.IsJWT(); isJWT != tc.expJWT {
+				t.Errorf("IsJWT() = %t, want %t", isJWT, tc.expJWT)
+			}
+		})
+	}
+}
+
+func TestResponseMode_UnmarshalForm(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("form_post")
+	result := domain.ResponseModeUnd
+
+	if err := result.UnmarshalForm(input); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if result != domain.ResponseModeFormPost {
+		t.Errorf("UnmarshalForm(%s) = %v, want %v", input, result, domain.ResponseModeFormPost)
+	}
+
+	if err := result.UnmarshalForm([]byte("web_message")); err == nil {
+		t.Errorf("UnmarshalForm(%s) = %v, want error", "web_message", result)
+	}
+}
@@ -16,7 +16,14 @@ type Scope struct {
 	scope string
 }
 
-var ErrScopeUnknown error = NewError(ErrorCodeInvalidRequest, "unknown scope", "https://indieweb.org/scope")
+var (
+	ErrScopeUnknown error = NewError(ErrorCodeInvalidScope, "unknown scope", "https://indieweb.org/scope")
+	ErrScopeInvalid error = NewError(
+		ErrorCodeInvalidScope,
+		"scope contains forbidden characters",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-3.3",
+	)
+)
 
 //nolint:gochecknoglobals // structs cannot be constants
 var (
@@ -52,6 +59,12 @@ var (
 	//
 	// NOTE(toby3d): https://indieauth.net/source/#profile-information
 	ScopeEmail = Scope{scope: "email"} // "email"
+
+	// This scope requests an OpenID Connect authentication, so the token
+	// endpoint also issues an ID Token about the authentication event.
+	//
+	// NOTE(toby3d): https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+	ScopeOpenID = Scope{scope: "openid"} // "openid"
 )
 
 //nolint:gochecknoglobals // maps cannot be constants
@@ -65,19 +78,29 @@ var uidsScopes = map[string]Scope{
 	ScopeFollow.scope:   ScopeFollow,
 	ScopeMedia.scope:    ScopeMedia,
 	ScopeMute.scope:     ScopeMute,
+	ScopeOpenID.scope:   ScopeOpenID,
 	ScopeProfile.scope:  ScopeProfile,
 	ScopeRead.scope:     ScopeRead,
 	ScopeUndelete.scope: ScopeUndelete,
 	ScopeUpdate.scope:   ScopeUpdate,
 }
 
-// ParseScope parses scope slug into Scope domain.
+// ParseScope parses scope slug into Scope domain. Any scope-token is accepted,
+// not only the well-known ones: whether unknown scope can be requested is
+// decided by the scopes registry.
 func ParseScope(uid string) (Scope, error) {
 	if scope, ok := uidsScopes[strings.ToLower(uid)]; ok {
 		return scope, nil
 	}
 
-	return ScopeUnd, fmt.Errorf("%w: %s", ErrScopeUnknown, uid)
+	// NOTE(toby3d): scope-token = 1*( %x21 / %x23-5B / %x5D-7E )
+	if uid == "" || strings.IndexFunc(uid, func(r rune) bool {
+		return r < 0x21 || r > 0x7e || r == '"' || r == '\\'
+	}) != -1 {
+		return ScopeUnd, fmt.Errorf("%w: %q", ErrScopeInvalid, uid)
+	}
+
+	return Scope{scope: uid}, nil
 }
 
 func (s *Scope) UnmarshalJSON(v []byte) error {
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+type (
+	// ScopeDefinition describes the scope which can be requested by
+	// clients: how it is presented to the owner on the consent page and
+	// which scopes it cannot be granted without.
+	ScopeDefinition struct {
+		// Title is the short localized name of the scope.
+		Title ScopeText
+
+		// Description is the localized explanation of what the client
+		// can do with the scope.
+		Description ScopeText
+
+		// Implies contains scopes which are granted along with this one,
+		// like profile for email.
+		Implies Scopes
+
+		Scope       Scope
+		Sensitivity ScopeSensitivity
+	}
+
+	// ScopeText represent texts of the scope definition by language.
+	ScopeText map[language.Tag]string
+
+	// ScopeSensitivity describes how dangerous is to grant the scope.
+	// Sensitivity levels are ordered, so they can be compared with
+	// a threshold.
+	//
+	// NOTE(toby3d): Encapsulate enums in structs for extra compile-time
+	// safety:
+	// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+	ScopeSensitivity struct {
+		scopeSensitivity string
+		level            uint8
+	}
+
+	// ScopePolicy describes what to do with scopes which are not defined.
+	ScopePolicy struct {
+		scopePolicy string
+	}
+)
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	ScopeSensitivityUnd = ScopeSensitivity{scopeSensitivity: "", level: 0} // "und"
+
+	// ScopeSensitivityLow describes scope which only gives read access to
+	// the public data.
+	ScopeSensitivityLow = ScopeSensitivity{scopeSensitivity: "low", level: 1} // "low"
+
+	// ScopeSensitivityMedium describes scope which gives access to the
+	// private data or creates new content on behalf of the owner.
+	ScopeSensitivityMedium = ScopeSensitivity{scopeSensitivity: "medium", level: 2} // "medium"
+
+	// ScopeSensitivityHigh describes scope which can destroy the content
+	// of the owner.
+	ScopeSensitivityHigh = ScopeSensitivity{scopeSensitivity: "high", level: 3} // "high"
+)
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	ScopePolicyUnd = ScopePolicy{scopePolicy: ""} // "und"
+
+	// ScopePolicyReject rejects authorization requests with unknown
+	// scopes by the invalid_scope error.
+	ScopePolicyReject = ScopePolicy{scopePolicy: "reject"} // "reject"
+
+	// ScopePolicyAllow passes unknown scopes through as is, so they can
+	// be handled by resource servers.
+	ScopePolicyAllow = ScopePolicy{scopePolicy: "allow"} // "allow"
+)
+
+var (
+	ErrScopeSensitivityUnknown error = NewError(ErrorCodeInvalidRequest, "unknown scope sensitivity", "")
+	ErrScopePolicyUnknown      error = NewError(ErrorCodeInvalidRequest, "unknown scope policy", "")
+)
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsScopeSensitivities = map[string]ScopeSensitivity{
+	ScopeSensitivityHigh.scopeSensitivity:   ScopeSensitivityHigh,
+	ScopeSensitivityLow.scopeSensitivity:    ScopeSensitivityLow,
+	ScopeSensitivityMedium.scopeSensitivity: ScopeSensitivityMedium,
+}
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsScopePolicies = map[string]ScopePolicy{
+	ScopePolicyAllow.scopePolicy:  ScopePolicyAllow,
+	ScopePolicyReject.scopePolicy: ScopePolicyReject,
+}
+
+// NewScopeDefinition creates definition of the scope which is not described
+// by anyone: its title is the scope itself.
+func NewScopeDefinition(scope Scope) ScopeDefinition {
+	return ScopeDefinition{
+		Scope:       scope,
+		Title:       ScopeText{language.English: scope.String()},
+		Description: make(ScopeText),
+		Implies:     make(Scopes, 0),
+		Sensitivity: ScopeSensitivityMedium,
+	}
+}
+
+// DefaultScopeDefinitions returns definitions of the well-known IndieAuth and
+// OpenID Connect scopes.
+func DefaultScopeDefinitions() []ScopeDefinition {
+	out := make([]ScopeDefinition, 0, len(uidsScopes))
+
+	for _, def := range []struct {
+		scope       Scope
+		title       string
+		description string
+		sensitivity ScopeSensitivity
+		implies     Scopes
+	}{
+		{ScopeProfile, "Profile", "View your name, photo and profile URL.", ScopeSensitivityLow, nil},
+		{ScopeEmail, "Email", "View your email address.", ScopeSensitivityMedium, Scopes{ScopeProfile}},
+		{ScopeOpenID, "OpenID", "Confirm your identity to the application.", ScopeSensitivityLow, nil},
+		{ScopeCreate, "Create", "Publish new posts on your site.", ScopeSensitivityMedium, nil},
+		{ScopeDraft, "Draft", "Create drafts on your site.", ScopeSensitivityLow, nil},
+		{ScopeUpdate, "Update", "Edit posts on your site.", ScopeSensitivityMedium, nil},
+		{ScopeDelete, "Delete", "Delete posts on your site.", ScopeSensitivityHigh, nil},
+		{ScopeUndelete, "Undelete", "Restore deleted posts on your site.", ScopeSensitivityMedium, nil},
+		{ScopeMedia, "Media", "Upload files to your site.", ScopeSensitivityMedium, nil},
+		{ScopeRead, "Read", "Read your feeds and channels.", ScopeSensitivityLow, nil},
+		{ScopeFollow, "Follow", "Follow and unfollow feeds.", ScopeSensitivityLow, nil},
+		{ScopeMute, "Mute", "Mute and unmute users.", ScopeSensitivityLow, nil},
+		{ScopeBlock, "Block", "Block and unblock users.", ScopeSensitivityLow, nil},
+		{ScopeChannels, "Channels", "Manage your channels.", ScopeSensitivityLow, nil},
+	} {
+		implies := make(Scopes, 0, len(def.implies))
+		implies = append(implies, def.implies...)
+
+		out = append(out, ScopeDefinition{
+			Scope:       def.scope,
+			Title:       ScopeText{language.English: def.title},
+			Description: ScopeText{language.English: def.description},
+			Implies:     implies,
+			Sensitivity: def.sensitivity,
+		})
+	}
+
+	return out
+}
+
+// Get returns text for the provided language. If there is no text neither for
+// this language nor for its base, English or any other available text is
+// returned with false.
+func (st ScopeText) Get(tag language.Tag) (string, bool) {
+	if text, ok := st[tag]; ok {
+		return text, true
+	}
+
+	if base, _ := tag.Base(); base.String() != "und" {
+		for t, text := range st {
+			if b, _ := t.Base(); b == base {
+				return text, true
+			}
+		}
+	}
+
+	if text, ok := st[language.English]; ok {
+		return text, false
+	}
+
+	for _, text := range st {
+		return text, false
+	}
+
+	return "", false
+}
+
+// ParseScopeSensitivity parse string identifier of scope sensitivity into
+// struct enum.
+func ParseScopeSensitivity(uid string) (ScopeSensitivity, error) {
+	if sensitivity, ok := uidsScopeSensitivities[strings.ToLower(uid)]; ok {
+		return sensitivity, nil
+	}
+
+	return ScopeSensitivityUnd, fmt.Errorf("%w: %s", ErrScopeSensitivityUnknown, uid)
+}
+
+// IsAtLeast reports whether sensitivity is the same or higher than provided
+// threshold.
+func (ss ScopeSensitivity) IsAtLeast(threshold ScopeSensitivity) bool {
+	return ss.level >= threshold.level
+}
+
+// String returns string representation of scope sensitivity.
+func (ss ScopeSensitivity) String() string {
+	if ss.scopeSensitivity != "" {
+		return ss.scopeSensitivity
+	}
+
+	return common.Und
+}
+
+func (ss ScopeSensitivity) GoString() string {
+	return "domain.ScopeSensitivity(" + ss.String() + ")"
+}
+
+// ParseScopePolicy parse string identifier of scope policy into struct enum.
+func ParseScopePolicy(uid string) (ScopePolicy, error) {
+	if policy, ok := uidsScopePolicies[strings.ToLower(uid)]; ok {
+		return policy, nil
+	}
+
+	return ScopePolicyUnd, fmt.Errorf("%w: %s", ErrScopePolicyUnknown, uid)
+}
+
+// String returns string representation of scope policy.
+func (sp ScopePolicy) String() string {
+	if sp.scopePolicy != "" {
+		return sp.scopePolicy
+	}
+
+	return common.Und
+}
+
+func (sp ScopePolicy) GoString() string {
+	return "domain.ScopePolicy(" + sp.String() + ")"
+}
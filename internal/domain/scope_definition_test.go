@@ -0,0 +1,72 @@
+package domain_test
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseScopeSensitivity(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in  string
+		out domain.ScopeSensitivity
+	}{
+		{in: "low", out: domain.ScopeSensitivityLow},
+		{in: "medium", out: domain.ScopeSensitivityMedium},
+		{in: "HIGH", out: domain.ScopeSensitivityHigh},
+	} {
+		tc := tc
+
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseScopeSensitivity(tc.in)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if result != tc.out {
+				t.Errorf("ParseScopeSensitivity(%s) = %v, want %v", tc.in, result, tc.out)
+			}
+		})
+	}
+
+	if !domain.ScopeSensitivityHigh.IsAtLeast(domain.ScopeSensitivityMedium) ||
+		domain.ScopeSensitivityLow.IsAtLeast(domain.ScopeSensitivityMedium) {
+		t.Error("IsAtLeast() does not respect order of sensitivity levels")
+	}
+}
+
+func TestScopeText_Get(t *testing.T) {
+	t.Parallel()
+
+	text := domain.ScopeText{
+		language.English: "Gallery",
+		language.Russian: "Галерея",
+	}
+
+	for name, tc := range map[string]struct {
+		in       language.Tag
+		expText  string
+		expExact bool
+	}{
+		"exact":    {in: language.Russian, expText: "Галерея", expExact: true},
+		"base":     {in: language.BritishEnglish, expText: "Gallery", expExact: true},
+		"fallback": {in: language.German, expText: "Gallery", expExact: false},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, exact := text.Get(tc.in)
+			if result != tc.expText || exact != tc.expExact {
+				t.Errorf("Get(%s) = %s, %t, want %s, %t", tc.in, result, exact, tc.expText, tc.expExact)
+			}
+		})
+	}
+}
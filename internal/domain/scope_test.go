@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -26,6 +27,7 @@ func TestParseScope(t *testing.T) {
 		{in: "read", out: domain.ScopeRead},
 		{in: "profile", out: domain.ScopeProfile},
 		{in: "email", out: domain.ScopeEmail},
+		{in: "openid", out: domain.ScopeOpenID},
 	} {
 		tc := tc
 
@@ -44,6 +46,36 @@ func TestParseScope(t *testing.T) {
 	}
 }
 
+func TestParseScope_Custom(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		in       string
+		expError error
+	}{
+		"uri":       {in: "https://example.com/gallery"},
+		"namespace": {in: "read:gallery"},
+		"space":     {in: "read gallery", expError: domain.ErrScopeInvalid},
+		"quote":     {in: `read"gallery`, expError: domain.ErrScopeInvalid},
+		"empty":     {in: "", expError: domain.ErrScopeInvalid},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseScope(tc.in)
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("ParseScope(%q) = %v, want %v", tc.in, err, tc.expError)
+			}
+
+			if tc.expError == nil && result.String() != tc.in {
+				t.Errorf("ParseScope(%q) = %s, want %s", tc.in, result, tc.in)
+			}
+		})
+	}
+}
+
 func TestScope_String(t *testing.T) {
 	t.Parallel()
 
@@ -63,6 +95,7 @@ func TestScope_String(t *testing.T) {
 		{in: domain.ScopeRead, out: "read"},
 		{in: domain.ScopeProfile, out: "profile"},
 		{in: domain.ScopeEmail, out: "email"},
+		{in: domain.ScopeOpenID, out: "openid"},
 	} {
 		tc := tc
 
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+// SecurityProfile describes the set of constraints applied to all clients of
+// the server.
+//
+// NOTE(toby3d): Encapsulate enums in structs for extra compile-time safety:
+// https://threedots.tech/post/safer-enums-in-go/#struct-based-enums
+type SecurityProfile struct {
+	securityProfile string
+}
+
+//nolint:gochecknoglobals // structs cannot be constants
+var (
+	SecurityProfileUnd = SecurityProfile{securityProfile: ""} // "und"
+
+	// SecurityProfileDefault supports legacy IndieAuth clients: PKCE is
+	// optional, any challenge method and response_type=id are accepted
+	// and tokens are bearer ones.
+	SecurityProfileDefault = SecurityProfile{securityProfile: "default"} // "default"
+
+	// SecurityProfileStrict enforces OAuth 2.1 and FAPI 2.0 best current
+	// practices: PKCE with S256 only, response_type=code only, exact
+	// redirect URIs, short-lived codes, authenticated clients and
+	// DPoP-bound tokens.
+	SecurityProfileStrict = SecurityProfile{securityProfile: "strict"} // "strict"
+)
+
+// StrictCodeExpiry is the maximum lifetime of the authorization code in the
+// strict profile, see FAPI 2.0 Security Profile section 5.3.2.1.
+const StrictCodeExpiry time.Duration = time.Minute
+
+var ErrSecurityProfileUnknown error = NewError(ErrorCodeInvalidRequest, "unknown security profile", "")
+
+var (
+	ErrStrictResponseType error = NewError(
+		ErrorCodeUnsupportedResponseType,
+		"only response_type=code is supported",
+		"https://datatracker.ietf.org/doc/html/draft-ietf-oauth-v2-1#section-4.1.1",
+	)
+	ErrStrictCodeChallenge error = NewError(
+		ErrorCodeInvalidRequest,
+		"code_challenge with code_challenge_method=S256 is required",
+		"https://datatracker.ietf.org/doc/html/draft-ietf-oauth-v2-1#section-4.1.1",
+	)
+	ErrStrictClientAuthMethod error = NewError(
+		ErrorCodeInvalidClient,
+		"client authentication is required",
+		"https://openid.net/specs/fapi-2_0-security-profile.html#section-5.3.1.2",
+	)
+)
+
+//nolint:gochecknoglobals // maps cannot be constants
+var uidsSecurityProfiles = map[string]SecurityProfile{
+	SecurityProfileDefault.securityProfile: SecurityProfileDefault,
+	SecurityProfileStrict.securityProfile:  SecurityProfileStrict,
+}
+
+// ParseSecurityProfile parse string identifier of security profile into struct
+// enum.
+func ParseSecurityProfile(uid string) (SecurityProfile, error) {
+	if profile, ok := uidsSecurityProfiles[strings.ToLower(uid)]; ok {
+		return profile, nil
+	}
+
+	return SecurityProfileUnd, fmt.Errorf("%w: %s", ErrSecurityProfileUnknown, uid)
+}
+
+// ResponseTypes returns response types accepted by the authorization endpoint.
+func (sp SecurityProfile) ResponseTypes() []ResponseType {
+	if sp == SecurityProfileStrict {
+		return []ResponseType{ResponseTypeCode}
+	}
+
+	return []ResponseType{ResponseTypeCode, ResponseTypeID}
+}
+
+// CodeChallengeMethods returns PKCE methods accepted by the authorization
+// endpoint.
+func (sp SecurityProfile) CodeChallengeMethods() []CodeChallengeMethod {
+	if sp == SecurityProfileStrict {
+		return []CodeChallengeMethod{CodeChallengeMethodS256}
+	}
+
+	return []CodeChallengeMethod{
+		CodeChallengeMethodMD5,
+		CodeChallengeMethodPLAIN,
+		CodeChallengeMethodS1,
+		CodeChallengeMethodS256,
+		CodeChallengeMethodS512,
+	}
+}
+
+// GrantTypes returns grant types advertised by the server.
+func (sp SecurityProfile) GrantTypes() []GrantType {
+	if sp == SecurityProfileStrict {
+		return []GrantType{GrantTypeAuthorizationCode, GrantTypeRefreshToken}
+	}
+
+	return []GrantType{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeTicket}
+}
+
+// ClientAuthMethods returns client authentication methods accepted by the
+// token endpoint. Public clients are not accepted by the strict profile.
+func (sp SecurityProfile) ClientAuthMethods() []ClientAuthMethod {
+	methods := []ClientAuthMethod{
+		ClientAuthMethodClientSecretBasic,
+		ClientAuthMethodClientSecretPost,
+		ClientAuthMethodPrivateKeyJWT,
+	}
+
+	if sp == SecurityProfileStrict {
+		return methods
+	}
+
+	return append([]ClientAuthMethod{ClientAuthMethodNone}, methods...)
+}
+
+// ValidateClientAuthMethod checks that client authentication method of the
+// token request is allowed by profile.
+func (sp SecurityProfile) ValidateClientAuthMethod(method ClientAuthMethod) error {
+	for _, m := range sp.ClientAuthMethods() {
+		if m == method {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrStrictClientAuthMethod, method)
+}
+
+// RequireExactRedirectURI reports whether redirect_uri must be equal to one of
+// the registered by client, without any loopback port or same host
+// relaxations.
+func (sp SecurityProfile) RequireExactRedirectURI() bool {
+	return sp == SecurityProfileStrict
+}
+
+// RequireSenderConstrainedTokens reports whether access tokens must be bound
+// to the client key by DPoP.
+func (sp SecurityProfile) RequireSenderConstrainedTokens() bool {
+	return sp == SecurityProfileStrict
+}
+
+// CodeExpiry returns lifetime of the authorization code limited by the
+// profile.
+func (sp SecurityProfile) CodeExpiry(expiry time.Duration) time.Duration {
+	if sp == SecurityProfileStrict && (expiry <= 0 || expiry > StrictCodeExpiry) {
+		return StrictCodeExpiry
+	}
+
+	return expiry
+}
+
+// ValidateResponseType checks that response type of the authorization request
+// is allowed by profile.
+func (sp SecurityProfile) ValidateResponseType(responseType ResponseType) error {
+	// NOTE(toby3d): response_type is optional for the legacy clients.
+	if responseType == ResponseTypeUnd && sp != SecurityProfileStrict {
+		return nil
+	}
+
+	for _, rt := range sp.ResponseTypes() {
+		if rt == responseType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrStrictResponseType, responseType)
+}
+
+// ValidateCodeChallenge checks that PKCE parameters of the authorization
+// request are allowed by profile.
+func (sp SecurityProfile) ValidateCodeChallenge(method CodeChallengeMethod, challenge string) error {
+	if sp != SecurityProfileStrict {
+		return nil
+	}
+
+	if challenge == "" || method != CodeChallengeMethodS256 {
+		return fmt.Errorf("%w: %s", ErrStrictCodeChallenge, method)
+	}
+
+	return nil
+}
+
+// String returns string representation of security profile.
+func (sp SecurityProfile) String() string {
+	if sp.securityProfile != "" {
+		return sp.securityProfile
+	}
+
+	return common.Und
+}
+
+func (sp SecurityProfile) GoString() string {
+	return "domain.SecurityProfile(" + sp.String() + ")"
+}
@@ -0,0 +1,172 @@
+package domain_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseSecurityProfile(t *testing.T) {
+	t.Parallel()
+
+	for in, expect := range map[string]domain.SecurityProfile{
+		"default": domain.SecurityProfileDefault,
+		"Strict":  domain.SecurityProfileStrict,
+	} {
+		in, expect := in, expect
+
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := domain.ParseSecurityProfile(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != expect {
+				t.Errorf("ParseSecurityProfile(%s) = %v, want %v", in, result, expect)
+			}
+		})
+	}
+
+	if _, err := domain.ParseSecurityProfile("fapi"); err == nil {
+		t.Error("ParseSecurityProfile(fapi) = nil, want error")
+	}
+}
+
+// TestSecurityProfile_Strict checks each rule of the strict profile against
+// the default one.
+func TestSecurityProfile_Strict(t *testing.T) {
+	t.Parallel()
+
+	strict, legacy := domain.SecurityProfileStrict, domain.SecurityProfileDefault
+
+	t.Run("metadata", func(t *testing.T) {
+		t.Parallel()
+
+		if expect := []domain.ResponseType{domain.ResponseTypeCode}; !reflect.DeepEqual(strict.ResponseTypes(),
+			expect) {
+			t.Errorf("ResponseTypes() = %v, want %v", strict.ResponseTypes(), expect)
+		}
+
+		if expect := []domain.CodeChallengeMethod{domain.CodeChallengeMethodS256}; !reflect.DeepEqual(
+			strict.CodeChallengeMethods(), expect) {
+			t.Errorf("CodeChallengeMethods() = %v, want %v", strict.CodeChallengeMethods(), expect)
+		}
+
+		if slices.Contains(strict.GrantTypes(), domain.GrantTypeTicket) {
+			t.Errorf("GrantTypes() = %v, want without %s", strict.GrantTypes(), domain.GrantTypeTicket)
+		}
+
+		if slices.Contains(strict.ClientAuthMethods(), domain.ClientAuthMethodNone) {
+			t.Errorf("ClientAuthMethods() = %v, want without %s", strict.ClientAuthMethods(),
+				domain.ClientAuthMethodNone)
+		}
+
+		if len(legacy.CodeChallengeMethods()) != 5 || len(legacy.ResponseTypes()) != 2 ||
+			len(legacy.GrantTypes()) != 3 || len(legacy.ClientAuthMethods()) != 4 {
+			t.Errorf("default profile must advertise all methods, grant and response types")
+		}
+	})
+
+	t.Run("client authentication", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			profile   domain.SecurityProfile
+			method    domain.ClientAuthMethod
+			expectErr bool
+		}{
+			{profile: legacy, method: domain.ClientAuthMethodNone},
+			{profile: legacy, method: domain.ClientAuthMethodClientSecretBasic},
+			{profile: strict, method: domain.ClientAuthMethodNone, expectErr: true},
+			{profile: strict, method: domain.ClientAuthMethodPrivateKeyJWT},
+		} {
+			if err := tc.profile.ValidateClientAuthMethod(tc.method); (err != nil) != tc.expectErr {
+				t.Errorf("%s: ValidateClientAuthMethod(%s) = %v, want error %t", tc.profile, tc.method,
+					err, tc.expectErr)
+			}
+		}
+	})
+
+	t.Run("response type", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			profile      domain.SecurityProfile
+			responseType domain.ResponseType
+			expectErr    bool
+		}{
+			{profile: legacy, responseType: domain.ResponseTypeUnd},
+			{profile: legacy, responseType: domain.ResponseTypeID},
+			{profile: legacy, responseType: domain.ResponseTypeCode},
+			{profile: strict, responseType: domain.ResponseTypeUnd, expectErr: true},
+			{profile: strict, responseType: domain.ResponseTypeID, expectErr: true},
+			{profile: strict, responseType: domain.ResponseTypeCode},
+		} {
+			if err := tc.profile.ValidateResponseType(tc.responseType); (err != nil) != tc.expectErr {
+				t.Errorf("%s: ValidateResponseType(%s) = %v, want error %t", tc.profile, tc.responseType,
+					err, tc.expectErr)
+			}
+		}
+	})
+
+	t.Run("pkce", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			profile   domain.SecurityProfile
+			method    domain.CodeChallengeMethod
+			challenge string
+			expectErr bool
+		}{
+			{profile: legacy, method: domain.CodeChallengeMethodUnd},
+			{profile: legacy, method: domain.CodeChallengeMethodPLAIN, challenge: "abc"},
+			{profile: strict, method: domain.CodeChallengeMethodUnd, expectErr: true},
+			{profile: strict, method: domain.CodeChallengeMethodPLAIN, challenge: "abc", expectErr: true},
+			{profile: strict, method: domain.CodeChallengeMethodS256, expectErr: true},
+			{profile: strict, method: domain.CodeChallengeMethodS256, challenge: "abc"},
+		} {
+			if err := tc.profile.ValidateCodeChallenge(tc.method, tc.challenge); (err != nil) != tc.expectErr {
+				t.Errorf("%s: ValidateCodeChallenge(%s, %q) = %v, want error %t", tc.profile, tc.method,
+					tc.challenge, err, tc.expectErr)
+			}
+		}
+	})
+
+	t.Run("redirect uri", func(t *testing.T) {
+		t.Parallel()
+
+		if !strict.RequireExactRedirectURI() || legacy.RequireExactRedirectURI() {
+			t.Error("RequireExactRedirectURI() must be required by strict profile only")
+		}
+	})
+
+	t.Run("code expiry", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			profile       domain.SecurityProfile
+			input, expect time.Duration
+		}{
+			{profile: legacy, input: 10 * time.Minute, expect: 10 * time.Minute},
+			{profile: strict, input: 10 * time.Minute, expect: domain.StrictCodeExpiry},
+			{profile: strict, input: 30 * time.Second, expect: 30 * time.Second},
+		} {
+			if result := tc.profile.CodeExpiry(tc.input); result != tc.expect {
+				t.Errorf("%s: CodeExpiry(%s) = %s, want %s", tc.profile, tc.input, result, tc.expect)
+			}
+		}
+	})
+
+	t.Run("sender-constrained tokens", func(t *testing.T) {
+		t.Parallel()
+
+		if !strict.RequireSenderConstrainedTokens() || legacy.RequireSenderConstrainedTokens() {
+			t.Error("RequireSenderConstrainedTokens() must be required by strict profile only")
+		}
+	})
+}
@@ -3,20 +3,51 @@ package domain
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"source.toby3d.me/toby3d/auth/internal/random"
 )
 
 //nolint:tagliatelle
 type Session struct {
+	// Expiry is the time after which the code or the pushed
+	// authorization request cannot be used.
+	Expiry              time.Time           `json:"expiry,omitempty"`
 	ClientID            ClientID            `json:"client_id"`
 	RedirectURI         *url.URL            `json:"redirect_uri"`
 	Me                  Me                  `json:"me"`
 	Profile             *Profile            `json:"profile,omitempty"`
 	CodeChallengeMethod CodeChallengeMethod `json:"code_challenge_method,omitempty"`
+	ResponseMode        ResponseMode        `json:"response_mode,omitempty"`
 	CodeChallenge       string              `json:"code_challenge,omitempty"`
 	Code                string              `json:"-"`
+	State               string              `json:"state,omitempty"`
 	Scope               Scopes              `json:"scope"`
+	// Resource contains URLs of the protected resources where requested
+	// access token is intended to be used.
+	Resource []string `json:"resource,omitempty"`
+	// Pushed reports whether session contains parameters of the pushed
+	// authorization request instead of the issued code. Such sessions
+	// cannot be exchanged for a token.
+	Pushed bool `json:"pushed,omitempty"`
+	// AuthTime is the time when the owner was authenticated.
+	AuthTime time.Time `json:"auth_time,omitempty"`
+	// Nonce is the value provided by the OpenID Connect client to
+	// associate its session with the ID Token.
+	Nonce string `json:"nonce,omitempty"`
+	// ACR is the authentication context class reference satisfied by the
+	// authentication of the owner.
+	ACR string `json:"acr,omitempty"`
+	// AMR contains methods used to authenticate the owner.
+	AMR []string `json:"amr,omitempty"`
+	// GrantExpiry is how long the owner has granted access to the client.
+	GrantExpiry GrantExpiry `json:"grant_expiry,omitempty"`
+	// Prompt, MaxAge and LoginHint are parameters of the pushed
+	// authorization request which control the authentication of the
+	// owner on the authorization endpoint.
+	Prompt    Prompts `json:"prompt,omitempty"`
+	MaxAge    string  `json:"max_age,omitempty"`
+	LoginHint string  `json:"login_hint,omitempty"`
 }
 
 // TestSession returns valid random generated session for tests.
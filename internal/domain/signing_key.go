@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// NewSigningKey generates a new private key suitable for signing of the ID
+// Tokens by ES256 algorithm.
+func NewSigningKey() (jwk.Key, error) {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate signing key: %w", err)
+	}
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert signing key: %w", err)
+	}
+
+	return prepareSigningKey(key)
+}
+
+// ParseSigningKey parses PEM-encoded private key and detects its signature
+// algorithm by the key type.
+func ParseSigningKey(src []byte) (jwk.Key, error) {
+	key, err := jwk.ParseKey(src, jwk.WithPEM(true))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse signing key: %w", err)
+	}
+
+	return prepareSigningKey(key)
+}
+
+// TestSigningKey returns valid random generated signing key for tests.
+func TestSigningKey(tb testing.TB) jwk.Key {
+	tb.Helper()
+
+	key, err := NewSigningKey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return key
+}
+
+func prepareSigningKey(key jwk.Key) (jwk.Key, error) {
+	if key.Algorithm().String() == "" {
+		var alg jwa.SignatureAlgorithm
+
+		switch key := key.(type) {
+		default:
+			return nil, fmt.Errorf("%s key type is not supported for signing", key.KeyType())
+		case jwk.RSAPrivateKey:
+			alg = jwa.RS256
+		case jwk.OKPPrivateKey:
+			alg = jwa.EdDSA
+		case jwk.ECDSAPrivateKey:
+			switch key.Crv() {
+			default:
+				return nil, fmt.Errorf("%s curve is not supported for signing", key.Crv())
+			case jwa.P256:
+				alg = jwa.ES256
+			case jwa.P384:
+				alg = jwa.ES384
+			case jwa.P521:
+				alg = jwa.ES512
+			}
+		}
+
+		if err := key.Set(jwk.AlgorithmKey, alg); err != nil {
+			return nil, fmt.Errorf("cannot set signing key algorithm: %w", err)
+		}
+	}
+
+	if key.KeyID() == "" {
+		if err := jwk.AssignKeyID(key); err != nil {
+			return nil, fmt.Errorf("cannot assign signing key ID: %w", err)
+		}
+	}
+
+	return key, nil
+}
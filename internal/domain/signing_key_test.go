@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestParseSigningKey(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, okpKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		in     any
+		expect jwa.SignatureAlgorithm
+	}{
+		"ecdsa": {in: ecKey, expect: jwa.ES384},
+		"rsa":   {in: rsaKey, expect: jwa.RS256},
+		"okp":   {in: okpKey, expect: jwa.EdDSA},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := jwk.EncodePEM(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := domain.ParseSigningKey(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if key.Algorithm() != tc.expect {
+				t.Errorf("ParseSigningKey() = %s, want %s", key.Algorithm(), tc.expect)
+			}
+
+			if key.KeyID() == "" {
+				t.Error("ParseSigningKey() returns key without ID")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Tenant describes the personal site hosted by this server instance. Each
+// tenant has its own issuer, credentials, signing keys and storage namespace.
+type Tenant struct {
+	// Logo is the optional branding logo of the tenant.
+	Logo *url.URL
+
+	// ID is the unique identifier of the tenant which is used as a
+	// storage namespace.
+	ID string
+
+	// Name is the branding name of the tenant.
+	Name string
+
+	// Host is the host name with an optional port which requests of the
+	// tenant are routed by.
+	Host string
+
+	// PathPrefix is the path prefix which requests of the tenant are
+	// routed by, without leading and trailing slashes.
+	PathPrefix string
+
+	// Username and Password of the owner account.
+	Username string
+	Password string
+
+	// JWTSecret is the secret key used to sign tokens of the tenant.
+	JWTSecret string
+
+	// TOTPSecret is the base32 encoded secret of the owner second
+	// factor.
+	TOTPSecret string
+
+	// Identities is the profile URLs owned by the owner account.
+	Identities []string
+}
+
+// TestTenant returns valid random generated tenant for tests.
+func TestTenant(tb testing.TB) *Tenant {
+	tb.Helper()
+
+	return &Tenant{
+		ID:         "alice",
+		Name:       "Alice",
+		Host:       "auth.example.com",
+		PathPrefix: "alice",
+		Username:   "alice",
+		Password:   "password",
+		JWTSecret:  "hackme",
+		TOTPSecret: "",
+		Identities: []string{"https://alice.example.net/"},
+		Logo:       &url.URL{Scheme: "https", Host: "alice.example.net", Path: "/logo.png"},
+	}
+}
+
+// Config returns copy of base configuration with issuer, branding,
+// credentials and signing keys of the tenant.
+func (t Tenant) Config(base Config) Config {
+	out := base
+	out.Server.RootURL = t.RootURL(base.Server)
+	out.IndieAuth.Username = t.Username
+	out.IndieAuth.Password = t.Password
+	out.IndieAuth.TOTPSecret = t.TOTPSecret
+	out.IndieAuth.Identities = append(make([]string, 0, len(t.Identities)), t.Identities...)
+
+	if t.Name != "" {
+		out.Name = t.Name
+	}
+
+	if t.JWTSecret != "" {
+		out.JWT.Secret = t.JWTSecret
+	}
+
+	return out
+}
+
+// RootURL returns the issuer URL of the tenant: provided host or server root
+// URL with the optional path prefix.
+func (t Tenant) RootURL(server ConfigServer) string {
+	root := server.GetRootURL()
+
+	if t.Host != "" {
+		root = server.Protocol + "://" + t.Host + "/"
+	}
+
+	if prefix := strings.Trim(t.PathPrefix, "/"); prefix != "" {
+		root = strings.TrimSuffix(root, "/") + "/" + prefix + "/"
+	}
+
+	return root
+}
+
+// NewTenantClientID returns the opaque identifier of the client in the storage
+// namespace of the tenant, so records of the same client kept by different
+// tenants in the shared storage never overlap.
+func NewTenantClientID(namespace string, cid ClientID) ClientID {
+	hash := sha256.Sum256([]byte(namespace + ":" + cid.String()))
+
+	return ClientID{
+		clientID:    &url.URL{Opaque: base64.RawURLEncoding.EncodeToString(hash[:])},
+		isLocalhost: false,
+	}
+}
@@ -0,0 +1,59 @@
+package domain_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+func TestTenant_RootURL(t *testing.T) {
+	t.Parallel()
+
+	server := domain.TestConfig(t).Server
+	server.Protocol = "https"
+	server.RootURL = "{{protocol}}://{{domain}}/"
+	server.Domain = "example.com"
+
+	for name, tc := range map[string]struct {
+		host, prefix, expResult string
+	}{
+		"host":   {host: "auth.example.net", prefix: "", expResult: "https://auth.example.net/"},
+		"prefix": {host: "", prefix: "/alice/", expResult: "https://example.com/alice/"},
+		"both":   {host: "auth.example.net:8080", prefix: "bob", expResult: "https://auth.example.net:8080/bob/"},
+		"none":   {host: "", prefix: "", expResult: "https://example.com/"},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tenant := domain.TestTenant(t)
+			tenant.Host, tenant.PathPrefix = tc.host, tc.prefix
+
+			if result := tenant.RootURL(server); result != tc.expResult {
+				t.Errorf("RootURL(%+v) = %s, want %s", server, result, tc.expResult)
+			}
+		})
+	}
+}
+
+func TestTenant_Config(t *testing.T) {
+	t.Parallel()
+
+	base := domain.TestConfig(t)
+	tenant := domain.TestTenant(t)
+	result := tenant.Config(*base)
+
+	if result.Name != tenant.Name || result.IndieAuth.Username != tenant.Username ||
+		result.IndieAuth.Password != tenant.Password || result.JWT.Secret != tenant.JWTSecret {
+		t.Errorf("Config(%+v) = %+v, want tenant branding and credentials", base, result)
+	}
+
+	if root := result.Server.GetRootURL(); root != tenant.RootURL(base.Server) {
+		t.Errorf("Config(%+v).Server.GetRootURL() = %s, want %s", base, root, tenant.RootURL(base.Server))
+	}
+
+	if base.IndieAuth.Username == tenant.Username {
+		t.Errorf("Config(%+v) modifies base config", base)
+	}
+}
@@ -3,10 +3,14 @@ package domain
 import (
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 
 	"source.toby3d.me/toby3d/auth/internal/common"
@@ -16,38 +20,93 @@ import (
 type (
 	// Token describes the data of the token used by the clients.
 	Token struct {
-		CreatedAt    time.Time
-		Expiry       time.Time
-		ClientID     ClientID
-		Me           Me
-		AccessToken  string
-		RefreshToken string
-		Scope        Scopes
+		CreatedAt time.Time
+		Expiry    time.Time
+		// RefreshExpiry is the time after which the refresh token
+		// cannot be used, zero if there is no refresh token.
+		RefreshExpiry time.Time
+		AuthTime      time.Time
+		ClientID      ClientID
+		Me            Me
+		ID            string
+		Family        string
+		AccessToken   string
+		RefreshToken  string
+		Nonce         string
+		ACR           string
+		// AMR contains methods used to authenticate the owner, like
+		// "pwd" and "otp", see RFC 8176.
+		AMR []string
+		// JKT is the thumbprint of the client key which the token is
+		// bound to by DPoP. Empty for the bearer tokens.
+		JKT string
+		// Audience contains resource servers which the token is
+		// restricted to, empty if the token is not restricted.
+		Audience []string
+		Scope    Scopes
 	}
 
 	// NewTokenOptions contains options for NewToken function.
 	NewTokenOptions struct {
-		Issuer      ClientID
-		Subject     Me
-		Algorithm   string
-		Scope       Scopes
-		Secret      []byte
-		Expiration  time.Duration
-		NonceLength uint8
+		AuthTime time.Time
+		// IssuedAt is the issue time of the token, the current time
+		// if zero.
+		IssuedAt time.Time
+		// Key is the private key which signs the token instead of
+		// Secret, so resource servers can verify the token offline by
+		// the published key set.
+		Key      jwk.Key
+		ClientID ClientID
+		Subject  Me
+		// Issuer is the URL of the authorization server.
+		Issuer     string
+		ID         string
+		Family     string
+		Algorithm  string
+		JKT        string
+		ACR        string
+		AMR        []string
+		Scope      Scopes
+		Audience   []string
+		Secret     []byte
+		Expiration time.Duration
+		// RefreshExpiration is the lifetime of the refresh token minted
+		// along with the access token. Zero means no refresh token.
+		RefreshExpiration time.Duration
+		NonceLength       uint8
 	}
 )
 
+// AccessTokenType is the typ header of JWT access tokens, so other JWTs signed
+// by the same key cannot be used as access tokens, see RFC 9068 section 2.1.
+const AccessTokenType string = "at+jwt"
+
+// TokenUseRefresh is the value of the private "token_use" claim of refresh
+// tokens, so they cannot be used as access tokens.
+const TokenUseRefresh string = "refresh"
+
 // DefaultNewTokenOptions describes the default settings for NewToken.
 //
 //nolint:gochecknoglobals,gomnd
 var DefaultNewTokenOptions = NewTokenOptions{
-	Expiration:  0,
-	Scope:       nil,
-	Issuer:      ClientID{},
-	Subject:     Me{},
-	Secret:      nil,
-	Algorithm:   "HS256",
-	NonceLength: 32,
+	AuthTime:          time.Time{},
+	IssuedAt:          time.Time{},
+	Expiration:        0,
+	RefreshExpiration: 0,
+	Scope:             nil,
+	Key:               nil,
+	ClientID:          ClientID{},
+	Subject:           Me{},
+	Issuer:            "",
+	ID:                "",
+	Family:            "",
+	JKT:               "",
+	ACR:               "",
+	AMR:               nil,
+	Audience:          nil,
+	Secret:            nil,
+	Algorithm:         "HS256",
+	NonceLength:       32,
 }
 
 // NewToken create a new token by provided options.
@@ -62,17 +121,29 @@ func NewToken(opts NewTokenOptions) (*Token, error) {
 		opts.Algorithm = DefaultNewTokenOptions.Algorithm
 	}
 
-	now := time.Now().UTC().Round(time.Second)
+	now := opts.IssuedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	now = now.UTC().Truncate(time.Second)
 
 	nonce, err := random.String(opts.NonceLength)
 	if err != nil {
 		return nil, fmt.Errorf("cannot generate nonce: %w", err)
 	}
 
+	if opts.ID == "" {
+		if opts.ID, err = random.String(opts.NonceLength); err != nil {
+			return nil, fmt.Errorf("cannot generate token ID: %w", err)
+		}
+	}
+
 	tkn := jwt.New()
 
 	for key, val := range map[string]any{
 		"nonce":          nonce,
+		jwt.JwtIDKey:     opts.ID,
 		"scope":          opts.Scope,
 		jwt.IssuedAtKey:  now,
 		jwt.NotBeforeKey: now,
@@ -83,8 +154,43 @@ func NewToken(opts NewTokenOptions) (*Token, error) {
 		}
 	}
 
-	if opts.Issuer.clientID != nil {
-		if err = tkn.Set(jwt.IssuerKey, opts.Issuer.String()); err != nil {
+	// NOTE(toby3d): RFC 9068 section 2.2: the client is described by the
+	// separate claim, issuer is the authorization server itself.
+	if opts.ClientID.clientID != nil {
+		if err = tkn.Set("client_id", opts.ClientID.String()); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	if opts.Issuer != "" {
+		if err = tkn.Set(jwt.IssuerKey, opts.Issuer); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	// NOTE(toby3d): token restricted to resource servers is still
+	// addressed to the authorization server, so it can be introspected.
+	if len(opts.Audience) > 0 {
+		audience := opts.Audience
+		if opts.Issuer != "" && !slices.Contains(audience, opts.Issuer) {
+			audience = append([]string{opts.Issuer}, audience...)
+		}
+
+		if err = tkn.Set(jwt.AudienceKey, audience); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	if opts.Family != "" {
+		if err = tkn.Set("family", opts.Family); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	// NOTE(toby3d): RFC 9449 section 6.1: token is bound to the key by
+	// the confirmation claim.
+	if opts.JKT != "" {
+		if err = tkn.Set("cnf", map[string]any{"jkt": opts.JKT}); err != nil {
 			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
 		}
 	}
@@ -95,20 +201,103 @@ func NewToken(opts NewTokenOptions) (*Token, error) {
 		}
 	}
 
-	accessToken, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(opts.Algorithm), opts.Secret))
+	if !opts.AuthTime.IsZero() {
+		if err = tkn.Set("auth_time", opts.AuthTime.Unix()); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	// NOTE(toby3d): resource servers can require the step-up
+	// authentication for destructive actions by these claims.
+	if opts.ACR != "" {
+		if err = tkn.Set("acr", opts.ACR); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	if len(opts.AMR) > 0 {
+		if err = tkn.Set("amr", opts.AMR); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	headers := jws.NewHeaders()
+	if err = headers.Set(jws.TypeKey, AccessTokenType); err != nil {
+		return nil, fmt.Errorf("failed to set JWT token header: %w", err)
+	}
+
+	accessToken, err := jwt.Sign(tkn, opts.signingKey(jws.WithProtectedHeaders(headers)))
 	if err != nil {
 		return nil, fmt.Errorf("cannot sign a new access token: %w", err)
 	}
 
-	return &Token{
+	var expiry time.Time
+	if opts.Expiration != 0 {
+		expiry = now.Add(opts.Expiration)
+	}
+
+	out := &Token{
 		AccessToken:  string(accessToken),
-		ClientID:     opts.Issuer,
+		AuthTime:     opts.AuthTime,
+		ClientID:     opts.ClientID,
 		CreatedAt:    now,
-		Expiry:       now.Add(opts.Expiration),
+		Expiry:       expiry,
+		Family:       opts.Family,
+		ID:           opts.ID,
+		JKT:          opts.JKT,
+		ACR:          opts.ACR,
+		AMR:          opts.AMR,
+		Audience:     opts.Audience,
 		Me:           opts.Subject,
-		RefreshToken: "", // TODO(toby3d)
+		RefreshToken: "",
 		Scope:        opts.Scope,
-	}, nil
+	}
+
+	if opts.RefreshExpiration == 0 {
+		return out, nil
+	}
+
+	// NOTE(toby3d): refresh token carries the same grant as the access
+	// token, but it's never accepted by resource servers.
+	refresh, err := tkn.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("cannot copy JWT token fields: %w", err)
+	}
+
+	out.RefreshExpiry = now.Add(opts.RefreshExpiration)
+
+	refreshID, err := random.String(opts.NonceLength)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate refresh token ID: %w", err)
+	}
+
+	for key, val := range map[string]any{
+		jwt.JwtIDKey:      refreshID,
+		jwt.ExpirationKey: out.RefreshExpiry,
+		"token_use":       TokenUseRefresh,
+	} {
+		if err = refresh.Set(key, val); err != nil {
+			return nil, fmt.Errorf("failed to set JWT token field: %w", err)
+		}
+	}
+
+	refreshToken, err := jwt.Sign(refresh, opts.signingKey())
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign a new refresh token: %w", err)
+	}
+
+	out.RefreshToken = string(refreshToken)
+
+	return out, nil
+}
+
+// signingKey returns the private key if provided, the shared secret otherwise.
+func (opts NewTokenOptions) signingKey(options ...jwt.Option) jwt.SignEncryptParseOption {
+	if opts.Key != nil {
+		return jwt.WithKey(jwa.SignatureAlgorithm(opts.Key.Algorithm().String()), opts.Key, options...)
+	}
+
+	return jwt.WithKey(jwa.SignatureAlgorithm(opts.Algorithm), opts.Secret, options...)
 }
 
 // TestToken returns valid random generated token for tests.
@@ -141,8 +330,9 @@ func TestToken(tb testing.TB) *Token {
 		jwt.ExpirationKey: now.Add(1 * time.Hour),
 		jwt.NotBeforeKey:  now.Add(-1 * time.Hour),
 		jwt.IssuedAtKey:   now.Add(-1 * time.Hour),
+		jwt.JwtIDKey:      nonce[:16],
+		"client_id":       cid.String(),
 		// TODO(toby3d): jwt.AudienceKey
-		// TODO(toby3d): jwt.JwtIDKey
 		// NOTE(toby3d): optional
 		"scope": scope,
 		"nonce": nonce,
@@ -150,7 +340,10 @@ func TestToken(tb testing.TB) *Token {
 		_ = tkn.Set(key, val)
 	}
 
-	accessToken, err := jwt.Sign(tkn, jwt.WithKey(jwa.HS256, []byte("hackme")))
+	headers := jws.NewHeaders()
+	_ = headers.Set(jws.TypeKey, AccessTokenType)
+
+	accessToken, err := jwt.Sign(tkn, jwt.WithKey(jwa.HS256, []byte("hackme"), jws.WithProtectedHeaders(headers)))
 	if err != nil {
 		tb.Fatal(err)
 	}
@@ -160,9 +353,10 @@ func TestToken(tb testing.TB) *Token {
 		Expiry:       now.Add(1 * time.Hour),
 		ClientID:     *cid,
 		Me:           *me,
+		ID:           nonce[:16],
 		Scope:        scope,
 		AccessToken:  string(accessToken),
-		RefreshToken: "", // TODO(toby3d)
+		RefreshToken: "",
 	}
 }
 
@@ -175,11 +369,52 @@ func (t Token) SetAuthHeader(r *http.Request) {
 	r.Header.Set(common.HeaderAuthorization, t.String())
 }
 
+// Type returns the type of token: DPoP if token is bound to the client key,
+// Bearer otherwise.
+func (t Token) Type() string {
+	if t.JKT != "" {
+		return "DPoP"
+	}
+
+	return "Bearer"
+}
+
+// Confirm checks that the token is presented with provided authorization
+// scheme by the holder of the key which the token is bound to. Verified DPoP
+// proof is returned for the bound token only, its ID must be remembered by
+// caller to prevent replays.
+func (t Token) Confirm(scheme, proof string, opts DPoPProofOptions) (*DPoPProof, error) {
+	if t.JKT == "" {
+		if strings.EqualFold(scheme, "DPoP") {
+			return nil, ErrDPoPBinding
+		}
+
+		return nil, nil
+	}
+
+	if !strings.EqualFold(scheme, "DPoP") {
+		return nil, ErrDPoPBinding
+	}
+
+	opts.AccessToken = t.AccessToken
+
+	result, err := ParseDPoPProof(proof, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.JKT != t.JKT {
+		return nil, ErrDPoPBinding
+	}
+
+	return result, nil
+}
+
 // String returns string representation of token.
 func (t Token) String() string {
 	if t.AccessToken == "" {
 		return ""
 	}
 
-	return "Bearer " + t.AccessToken
+	return t.Type() + " " + t.AccessToken
 }
@@ -17,7 +17,7 @@ func TestNewToken(t *testing.T) {
 	opts := domain.NewTokenOptions{
 		Algorithm:   "",
 		NonceLength: 0,
-		Issuer:      expResult.ClientID,
+		ClientID:    expResult.ClientID,
 		Expiration:  1 * time.Hour,
 		Scope:       expResult.Scope,
 		Subject:     expResult.Me,
@@ -9,8 +9,11 @@ type User struct {
 	Me                    *Me
 	AuthorizationEndpoint *url.URL
 	IndieAuthMetadata     *url.URL
+	IntrospectionEndpoint *url.URL
+	Issuer                *url.URL
 	Micropub              *url.URL
 	Microsub              *url.URL
+	RevocationEndpoint    *url.URL
 	TicketEndpoint        *url.URL
 	TokenEndpoint         *url.URL
 	*Profile
@@ -28,6 +31,7 @@ func TestUser(tb testing.TB) *User {
 			Scheme: "https", Host: "example.org",
 			Path: "/.well-known/oauth-authorization-server",
 		},
+		Issuer:         &url.URL{Scheme: "https", Host: "example.org", Path: "/"},
 		Micropub:       &url.URL{Scheme: "https", Host: "microsub.example.org", Path: "/"},
 		Microsub:       &url.URL{Scheme: "https", Host: "micropub.example.org", Path: "/"},
 		TicketEndpoint: &url.URL{Scheme: "https", Host: "example.org", Path: "/ticket"},
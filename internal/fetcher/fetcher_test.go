@@ -0,0 +1,54 @@
+package fetcher_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"inet.af/netaddr"
+
+	"source.toby3d.me/toby3d/auth/internal/fetcher"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	resp, err := fetcher.New().Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	if !errors.Is(err, fetcher.ErrForbiddenAddress) {
+		t.Errorf("Get(%s) = %v, want %v", srv.URL, err, fetcher.ErrForbiddenAddress)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	t.Parallel()
+
+	for input, expect := range map[string]bool{
+		"127.0.0.1":       false,
+		"10.0.0.1":        false,
+		"169.254.169.254": false,
+		"::1":             false,
+		"0.0.0.0":         false,
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+	} {
+		input, expect := input, expect
+
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := fetcher.IsPublic(netaddr.MustParseIP(input)); actual != expect {
+				t.Errorf("IsPublic(%s) = %t, want %t", input, actual, expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	"source.toby3d.me/toby3d/auth/internal/urlutil"
+)
+
+// Handler serves subrequests of reverse proxies: nginx auth_request, Traefik
+// ForwardAuth and Caddy forward_auth.
+//
+// The subrequest is allowed by 200 response with X-Auth-Me and X-Auth-Scope
+// headers, which can be passed to the upstream. Anonymous browser is
+// redirected to the login, which authorizes the owner through the self-client
+// of the server. nginx cannot follow redirects of auth_request, so it gets 401
+// response and must redirect to the login by error_page:
+//
+//	location @login {
+//		return 302 https://auth.example.com/forward-auth/login?rd=$scheme://$http_host$request_uri;
+//	}
+type Handler struct {
+	forwardAuth forwardauth.UseCase
+	config      domain.Config
+}
+
+func NewHandler(forwardAuth forwardauth.UseCase, config domain.Config) *Handler {
+	return &Handler{
+		forwardAuth: forwardAuth,
+		config:      config,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	head, _ := urlutil.ShiftPath(r.URL.Path)
+
+	switch head {
+	default:
+		http.NotFound(w, r)
+	case "":
+		h.handleVerify(w, r)
+	case "login":
+		h.handleLogin(w, r)
+	}
+}
+
+func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	target, err := upstream(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var raw string
+	if cookie, err := r.Cookie(forwardauth.SessionCookieName); err == nil {
+		raw = cookie.Value
+	}
+
+	session, err := h.forwardAuth.Verify(r.Context(), raw, target)
+
+	switch {
+	case err == nil:
+		w.Header().Set(common.HeaderXAuthMe, session.Me.String())
+		w.Header().Set(common.HeaderXAuthScope, session.Scope.String())
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, forwardauth.ErrSession):
+		h.redirectToLogin(w, r, target)
+	default:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	}
+}
+
+func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	target, err := url.Parse(r.URL.Query().Get("rd"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// NOTE(toby3d): only URLs of the protected upstreams are accepted, so
+	// login cannot be used as an open redirect.
+	u, login, err := h.forwardAuth.Login(r.Context(), target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	//nolint:exhaustivestruct
+	http.SetCookie(w, &http.Cookie{
+		Name:     forwardauth.LoginCookieName,
+		Value:    login,
+		Path:     "/",
+		MaxAge:   int(forwardauth.LoginExpiry.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		// NOTE(toby3d): cookie must be sent with the callback
+		// navigated from the authorization endpoint.
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+func (h *Handler) redirectToLogin(w http.ResponseWriter, r *http.Request, target *url.URL) {
+	u, err := url.Parse(h.config.Server.GetRootURL())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	u = u.JoinPath("forward-auth", "login")
+	u.RawQuery = url.Values{"rd": []string{target.String()}}.Encode()
+
+	w.Header().Set(common.HeaderLocation, u.String())
+
+	// NOTE(toby3d): nginx auth_request accepts only 2xx, 401 and 403
+	// responses.
+	if r.Header.Get(common.HeaderXOriginalURL) != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusFound)
+}
+
+// upstream returns URL of the original request to the protected upstream
+// provided by the reverse proxy.
+func upstream(r *http.Request) (*url.URL, error) {
+	// NOTE(toby3d): nginx passes the whole URL by the custom header,
+	// Traefik and Caddy pass its parts.
+	if raw := r.Header.Get(common.HeaderXOriginalURL); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s header: %w", common.HeaderXOriginalURL, err)
+		}
+
+		return u, nil
+	}
+
+	host := r.Header.Get(common.HeaderXForwardedHost)
+	if host == "" {
+		return nil, fmt.Errorf("%w: %s header is required", forwardauth.ErrUpstream, common.HeaderXForwardedHost)
+	}
+
+	out := &url.URL{Scheme: r.Header.Get(common.HeaderXForwardedProto), Host: host, Path: "/"}
+	if out.Scheme == "" {
+		out.Scheme = "https"
+	}
+
+	if raw := r.Header.Get(common.HeaderXForwardedURI); raw != "" {
+		uri, err := url.ParseRequestURI(raw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s header: %w", common.HeaderXForwardedURI, err)
+		}
+
+		out.Path, out.RawPath, out.RawQuery = uri.Path, uri.RawPath, uri.RawQuery
+	}
+
+	return out, nil
+}
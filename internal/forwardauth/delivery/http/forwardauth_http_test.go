@@ -0,0 +1,160 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	delivery "source.toby3d.me/toby3d/auth/internal/forwardauth/delivery/http"
+	ucase "source.toby3d.me/toby3d/auth/internal/forwardauth/usecase"
+	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	tokenrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
+)
+
+type Dependencies struct {
+	config      *domain.Config
+	forwardAuth forwardauth.UseCase
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	for name, tc := range map[string]struct {
+		headers     map[string]string
+		expStatus   int
+		expLocation string
+	}{
+		"nginx": {
+			headers:     map[string]string{common.HeaderXOriginalURL: "https://app.example.org/admin?page=1"},
+			expStatus:   http.StatusUnauthorized,
+			expLocation: "https://app.example.org/admin?page=1",
+		},
+		"traefik": {
+			headers: map[string]string{
+				common.HeaderXForwardedProto: "https",
+				common.HeaderXForwardedHost:  "app.example.org",
+				common.HeaderXForwardedURI:   "/admin?page=1",
+			},
+			expStatus:   http.StatusFound,
+			expLocation: "https://app.example.org/admin?page=1",
+		},
+		"without upstream": {
+			headers:   map[string]string{},
+			expStatus: http.StatusBadRequest,
+		},
+		"unprotected": {
+			headers:   map[string]string{common.HeaderXOriginalURL: "https://example.net/"},
+			expStatus: http.StatusForbidden,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+			for key, val := range tc.headers {
+				req.Header.Set(key, val)
+			}
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(deps.forwardAuth, *deps.config).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expLocation == "" {
+				return
+			}
+
+			location, err := resp.Location()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rd := location.Query().Get("rd"); rd != tc.expLocation ||
+				!strings.HasSuffix(location.Path, "/forward-auth/login") {
+				t.Errorf("%s %s returns Location %s, want login for %s", req.Method, req.RequestURI,
+					location, tc.expLocation)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	for name, tc := range map[string]struct {
+		target    string
+		expStatus int
+	}{
+		"protected":   {target: "https://app.example.org/admin", expStatus: http.StatusFound},
+		"unprotected": {target: "https://evil.example.net/", expStatus: http.StatusBadRequest},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/login?"+url.Values{
+				"rd": []string{tc.target},
+			}.Encode(), nil)
+			w := httptest.NewRecorder()
+
+			delivery.NewHandler(deps.forwardAuth, *deps.config).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expStatus != http.StatusFound {
+				return
+			}
+
+			for _, cookie := range resp.Cookies() {
+				if cookie.Name == forwardauth.LoginCookieName && cookie.Value != "" {
+					return
+				}
+			}
+
+			t.Errorf("%s %s does not set %s cookie", req.Method, req.RequestURI, forwardauth.LoginCookieName)
+		})
+	}
+}
+
+func NewDependencies(tb testing.TB) Dependencies {
+	tb.Helper()
+
+	config := domain.TestConfig(tb)
+	sessions := sessionrepo.NewMemorySessionRepository(*config)
+
+	return Dependencies{
+		config: config,
+		forwardAuth: ucase.NewForwardAuthUseCase(ucase.Config{
+			Tokens: tokenucase.NewTokenUseCase(tokenucase.Config{
+				Config:   *config,
+				Profiles: profilerepo.NewMemoryProfileRepository(),
+				Sessions: sessions,
+				Tokens:   tokenrepo.NewMemoryTokenRepository(),
+			}),
+			Sessions: sessions,
+			Client:   *domain.TestClient(tb),
+			Rules:    []domain.ForwardAuthRule{{Host: []string{"*.example.org"}}},
+			Config:   *config,
+		}),
+	}
+}
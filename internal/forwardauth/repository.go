@@ -0,0 +1,15 @@
+package forwardauth
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Fetch returns all configured rules of protected upstreams in order
+	// of evaluation.
+	Fetch(ctx context.Context) ([]domain.ForwardAuthRule, error)
+}
+
+var ErrInvalid error = domain.NewError(domain.ErrorCodeServerError, "invalid forward-auth configuration", "")
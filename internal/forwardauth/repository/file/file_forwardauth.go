@@ -0,0 +1,90 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+)
+
+type (
+	Rule struct {
+		Host  []string `json:"host"`
+		Me    []string `json:"me,omitempty"`
+		Scope []string `json:"scope,omitempty"`
+	}
+
+	fileForwardAuthRepository struct {
+		scopes scope.UseCase
+		path   string
+	}
+)
+
+// NewFileForwardAuthRepository creates a new forward-auth repository which
+// reads the JSON array of upstream rules from the provided file path. Rule
+// scopes are resolved by the provided scopes registry.
+func NewFileForwardAuthRepository(path string, scopes scope.UseCase) forwardauth.Repository {
+	return &fileForwardAuthRepository{
+		path:   path,
+		scopes: scopes,
+	}
+}
+
+func (repo *fileForwardAuthRepository) Fetch(ctx context.Context) ([]domain.ForwardAuthRule, error) {
+	src, err := os.ReadFile(repo.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read forward-auth file: %w", err)
+	}
+
+	in := make([]Rule, 0)
+	if err = json.Unmarshal(src, &in); err != nil {
+		return nil, fmt.Errorf("cannot decode forward-auth file: %w", err)
+	}
+
+	out := make([]domain.ForwardAuthRule, 0, len(in))
+
+	for i := range in {
+		rule, err := in[i].populate()
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule #%d: %w", forwardauth.ErrInvalid, i, err)
+		}
+
+		if rule.Scope, err = repo.scopes.Resolve(ctx, rule.Scope); err != nil {
+			return nil, fmt.Errorf("%w: rule #%d: %w", forwardauth.ErrInvalid, i, err)
+		}
+
+		out = append(out, rule)
+	}
+
+	return out, nil
+}
+
+func (r Rule) populate() (domain.ForwardAuthRule, error) {
+	out := domain.ForwardAuthRule{
+		Host:  r.Host,
+		Me:    r.Me,
+		Scope: make(domain.Scopes, 0, len(r.Scope)),
+	}
+
+	// NOTE(toby3d): rule without hosts protects nothing, most likely it
+	// is a typo in the configuration.
+	if len(r.Host) == 0 {
+		return out, fmt.Errorf("host is required")
+	}
+
+	for _, raw := range r.Scope {
+		s, err := domain.ParseScope(raw)
+		if err != nil {
+			return out, fmt.Errorf("cannot parse scope: %w", err)
+		}
+
+		out.Scope = append(out.Scope, s)
+	}
+
+	return out, nil
+}
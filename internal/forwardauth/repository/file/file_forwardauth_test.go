@@ -0,0 +1,75 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	repository "source.toby3d.me/toby3d/auth/internal/forwardauth/repository/file"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input    string
+		expError error
+		expCount int
+	}{
+		"valid": {
+			input: `[
+				{"host": ["dash.example.com"], "me": ["https://example.com/"], "scope": ["profile"]},
+				{"host": ["*.example.org"]}
+			]`,
+			expCount: 2,
+		},
+		"without host": {
+			input:    `[{"me": ["https://example.com/"]}]`,
+			expError: forwardauth.ErrInvalid,
+		},
+		"invalid scope": {
+			input:    `[{"host": ["dash.example.com"], "scope": ["with space"]}]`,
+			expError: forwardauth.ErrInvalid,
+		},
+		"unknown scope": {
+			input:    `[{"host": ["dash.example.com"], "scope": ["unknown"]}]`,
+			expError: domain.ErrScopeUnknown,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "forward-auth.json")
+			if err := os.WriteFile(path, []byte(tc.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			scopes := scopeucase.NewScopeUseCase(domain.ScopePolicyReject)
+
+			result, err := repository.NewFileForwardAuthRepository(path, scopes).Fetch(context.Background())
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Fetch() = %v, want %v", err, tc.expError)
+			}
+
+			if len(result) != tc.expCount {
+				t.Errorf("Fetch() = %d rules, want %d", len(result), tc.expCount)
+			}
+
+			if tc.expCount == 0 {
+				return
+			}
+
+			if rule := result[0]; !rule.MatchesHost("dash.example.com") ||
+				!rule.Allows(*domain.TestMe(t, "https://example.com/")) || !rule.Scope.Has(domain.ScopeProfile) {
+				t.Errorf("Fetch() = %+v, want parsed rule", rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package forwardauth
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	CallbackOptions struct {
+		// Login is the signed state of the login returned by Login.
+		Login string
+		State string
+		Code  string
+	}
+
+	UseCase interface {
+		// Login starts authorization of the owner through the
+		// self-client for provided upstream URL. It returns URL of the
+		// authorization request and the signed state of the login,
+		// which must be presented back on callback.
+		Login(ctx context.Context, target *url.URL) (*url.URL, string, error)
+
+		// Callback completes the login by the authorization code and
+		// returns the signed session for upstreams along with the
+		// upstream URL to return to.
+		Callback(ctx context.Context, opts CallbackOptions) (string, *url.URL, error)
+
+		// Verify returns the identity of the signed session if it is
+		// allowed to access provided upstream URL and the grant which
+		// the session is bound to is not revoked.
+		Verify(ctx context.Context, session string, target *url.URL) (*domain.ForwardAuthSession, error)
+	}
+)
+
+const (
+	// SessionCookieName is the name of the domain-scoped cookie which
+	// remembers the identity authenticated for upstreams.
+	SessionCookieName string = "__Secure-forward-auth"
+
+	// LoginCookieName is the name of the cookie which keeps the state of
+	// the login in progress until the self-client callback.
+	LoginCookieName string = "__Secure-forward-auth-login"
+
+	// LoginExpiry is the time in which the owner must complete the login.
+	LoginExpiry time.Duration = 10 * time.Minute
+)
+
+var (
+	ErrUpstream error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"upstream is not protected by any forward-auth rule",
+		"",
+	)
+	ErrForbidden error = domain.NewError(
+		domain.ErrorCodeAccessDenied,
+		"identity is not allowed to access this upstream",
+		"",
+	)
+	ErrSession error = domain.NewError(
+		domain.ErrorCodeLoginRequired,
+		"forward-auth session is missing, expired or invalid",
+		"",
+	)
+	ErrLogin error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"forward-auth login is expired or state does not match",
+		"",
+	)
+)
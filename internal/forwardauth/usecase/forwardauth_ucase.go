@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	"source.toby3d.me/toby3d/auth/internal/random"
+	"source.toby3d.me/toby3d/auth/internal/session"
+	"source.toby3d.me/toby3d/auth/internal/token"
+)
+
+type (
+	Config struct {
+		Tokens token.UseCase
+		// Sessions stores redemptions of the codes, so sessions of
+		// the revoked token family are rejected.
+		Sessions session.Repository
+		// Clock provides the current time. System clock is used if
+		// nil.
+		Clock domain.Clock
+		// Client is the server instance itself as a client, which
+		// authorizes the owner for upstreams.
+		Client domain.Client
+		Rules  []domain.ForwardAuthRule
+		Config domain.Config
+	}
+
+	forwardAuthUseCase struct {
+		tokens   token.UseCase
+		sessions session.Repository
+		clock    domain.Clock
+		client   domain.Client
+		rules    []domain.ForwardAuthRule
+		config   domain.Config
+	}
+)
+
+// NewForwardAuthUseCase creates a new forward-auth of provided upstream rules,
+// which are evaluated in the same order. Without rules any upstream is
+// rejected.
+func NewForwardAuthUseCase(cfg Config) forwardauth.UseCase {
+	if cfg.Clock == nil {
+		cfg.Clock = domain.SystemClock
+	}
+
+	return &forwardAuthUseCase{
+		clock:    cfg.Clock,
+		client:   cfg.Client,
+		config:   cfg.Config,
+		rules:    cfg.Rules,
+		sessions: cfg.Sessions,
+		tokens:   cfg.Tokens,
+	}
+}
+
+func (uc *forwardAuthUseCase) Login(_ context.Context, target *url.URL) (*url.URL, string, error) {
+	rule, err := uc.rule(target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	state, err := random.String(uc.config.JWT.NonceLength)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot generate state: %w", err)
+	}
+
+	verifier, err := random.String(64, random.Alphanumeric) //nolint:gomnd // RFC 7636 section 4.1
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot generate code verifier: %w", err)
+	}
+
+	login, err := uc.sign(forwardauth.LoginExpiry, map[string]any{
+		jwt.AudienceKey: uc.client.RedirectURI[0].String(),
+		"target":        target.String(),
+		"state":         state,
+		"code_verifier": verifier,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot sign login: %w", err)
+	}
+
+	// NOTE(toby3d): authorization request without scopes issues no access
+	// token, so rule without scopes requests profile only.
+	scope := rule.Scope
+	if len(scope) == 0 {
+		scope = domain.Scopes{domain.ScopeProfile}
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+	u := uc.client.ID.URL().JoinPath("authorize")
+	q := u.Query()
+
+	for key, val := range map[string]string{
+		"client_id":             uc.client.ID.String(),
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(hash[:]),
+		"code_challenge_method": domain.CodeChallengeMethodS256.String(),
+		"redirect_uri":          uc.client.RedirectURI[0].String(),
+		"response_type":         domain.ResponseTypeCode.String(),
+		"scope":                 scope.String(),
+		"state":                 state,
+	} {
+		q.Set(key, val)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u, login, nil
+}
+
+func (uc *forwardAuthUseCase) Callback(ctx context.Context, opts forwardauth.CallbackOptions,
+) (string, *url.URL, error) {
+	login, err := uc.parse(opts.Login, uc.client.RedirectURI[0].String())
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", forwardauth.ErrLogin, err)
+	}
+
+	state, _ := login.PrivateClaims()["state"].(string)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(opts.State)) != 1 {
+		return "", nil, forwardauth.ErrLogin
+	}
+
+	rawTarget, _ := login.PrivateClaims()["target"].(string)
+
+	target, err := url.Parse(rawTarget)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", forwardauth.ErrLogin, err)
+	}
+
+	verifier, _ := login.PrivateClaims()["code_verifier"].(string)
+
+	// NOTE(toby3d): self-client does not prove possession of any key, so
+	// forward-auth cannot be used with the strict security profile.
+	tkn, _, err := uc.tokens.Exchange(ctx, token.ExchangeOptions{
+		ClientID:     uc.client.ID,
+		RedirectURI:  uc.client.RedirectURI[0],
+		Code:         opts.Code,
+		CodeVerifier: verifier,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot exchange code: %w", err)
+	}
+
+	rule, err := uc.rule(target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !rule.Allows(tkn.Me) {
+		return "", nil, fmt.Errorf("%w: %s", forwardauth.ErrForbidden, tkn.Me)
+	}
+
+	// NOTE(toby3d): session is bound to the family of the minted tokens,
+	// so it's ended by revocation of the grant.
+	session, err := uc.sign(uc.config.ForwardAuth.Expiry, map[string]any{
+		jwt.AudienceKey: forwardauth.SessionCookieName,
+		jwt.SubjectKey:  tkn.Me.String(),
+		"family":        tkn.Family,
+		"scope":         tkn.Scope.String(),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot sign session: %w", err)
+	}
+
+	return session, target, nil
+}
+
+func (uc *forwardAuthUseCase) Verify(ctx context.Context, session string, target *url.URL,
+) (*domain.ForwardAuthSession, error) {
+	// NOTE(toby3d): unprotected upstreams are rejected before the session
+	// check, so anonymous owner is not sent to the login which fails.
+	rule, err := uc.rule(target)
+	if err != nil {
+		return nil, err
+	}
+
+	tkn, err := uc.parse(session, forwardauth.SessionCookieName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", forwardauth.ErrSession, err)
+	}
+
+	me, err := domain.ParseMe(tkn.Subject())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", forwardauth.ErrSession, err)
+	}
+
+	family, _ := tkn.PrivateClaims()["family"].(string)
+	if family == "" {
+		return nil, fmt.Errorf("%w: session is not bound to any grant", forwardauth.ErrSession)
+	}
+
+	redemption, err := uc.sessions.GetRedemption(ctx, family)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", forwardauth.ErrSession, err)
+	}
+
+	if !redemption.IsActive(uc.clock.Now()) {
+		return nil, fmt.Errorf("%w: grant is revoked or expired", forwardauth.ErrSession)
+	}
+
+	if !rule.Allows(*me) {
+		return nil, fmt.Errorf("%w: %s", forwardauth.ErrForbidden, me)
+	}
+
+	out := &domain.ForwardAuthSession{
+		Expiry: tkn.Expiration(),
+		Me:     *me,
+		Scope:  make(domain.Scopes, 0),
+	}
+
+	if scope, ok := tkn.PrivateClaims()["scope"].(string); ok {
+		_ = out.Scope.UnmarshalForm([]byte(scope))
+	}
+
+	return out, nil
+}
+
+// rule returns the first rule which protects provided upstream URL.
+func (uc *forwardAuthUseCase) rule(target *url.URL) (*domain.ForwardAuthRule, error) {
+	if target == nil || target.Hostname() == "" {
+		return nil, fmt.Errorf("%w: upstream URL is required", forwardauth.ErrUpstream)
+	}
+
+	for i := range uc.rules {
+		if uc.rules[i].MatchesHost(target.Hostname()) {
+			return &uc.rules[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", forwardauth.ErrUpstream, target.Hostname())
+}
+
+func (uc *forwardAuthUseCase) sign(expiry time.Duration, claims map[string]any) (string, error) {
+	now := uc.clock.Now()
+	tkn := jwt.New()
+
+	claims[jwt.ExpirationKey] = now.Add(expiry)
+	claims[jwt.IssuedAtKey] = now
+	claims[jwt.IssuerKey] = uc.config.Server.GetRootURL()
+
+	for key, val := range claims {
+		if err := tkn.Set(key, val); err != nil {
+			return "", fmt.Errorf("cannot set claim: %w", err)
+		}
+	}
+
+	out, err := jwt.Sign(tkn, jwt.WithKey(jwa.SignatureAlgorithm(uc.config.JWT.Algorithm),
+		[]byte(uc.config.JWT.Secret)))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign: %w", err)
+	}
+
+	return string(out), nil
+}
+
+func (uc *forwardAuthUseCase) parse(raw, audience string) (jwt.Token, error) {
+	tkn, err := jwt.ParseString(raw, jwt.WithKey(jwa.SignatureAlgorithm(uc.config.JWT.Algorithm),
+		[]byte(uc.config.JWT.Secret)), jwt.WithValidate(true), jwt.WithIssuer(uc.config.Server.GetRootURL()),
+		jwt.WithAudience(audience), jwt.WithClock(uc.clock))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse: %w", err)
+	}
+
+	return tkn, nil
+}
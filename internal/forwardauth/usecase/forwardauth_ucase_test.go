@@ -0,0 +1,226 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	ucase "source.toby3d.me/toby3d/auth/internal/forwardauth/usecase"
+	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/session"
+	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	tokenrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
+)
+
+type Dependencies struct {
+	client      *domain.Client
+	config      *domain.Config
+	sessions    session.Repository
+	forwardAuth forwardauth.UseCase
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	for name, tc := range map[string]struct {
+		target   string
+		expError error
+	}{
+		"protected":   {target: "https://app.example.org/admin?page=1", expError: nil},
+		"scope-less":  {target: "https://wiki.example.com/", expError: nil},
+		"unprotected": {target: "https://example.net/", expError: forwardauth.ErrUpstream},
+		"relative":    {target: "/admin", expError: forwardauth.ErrUpstream},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			target, _ := url.Parse(tc.target)
+
+			u, login, err := deps.forwardAuth.Login(context.Background(), target)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Login(%s) = %v, want %v", tc.target, err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if login == "" {
+				t.Error("Login() returns empty login state")
+			}
+
+			q := u.Query()
+
+			for key, expect := range map[string]string{
+				"client_id":             deps.client.ID.String(),
+				"code_challenge_method": domain.CodeChallengeMethodS256.String(),
+				"redirect_uri":          deps.client.RedirectURI[0].String(),
+				"response_type":         domain.ResponseTypeCode.String(),
+				"scope":                 domain.ScopeProfile.String(),
+			} {
+				if actual := q.Get(key); actual != expect {
+					t.Errorf("Login(%s) returns %s = %q, want %q", tc.target, key, actual, expect)
+				}
+			}
+
+			if q.Get("state") == "" || q.Get("code_challenge") == "" {
+				t.Errorf("Login(%s) returns URL without state or code_challenge: %s", tc.target, u)
+			}
+		})
+	}
+}
+
+func TestCallback(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		me       string
+		state    string
+		expError error
+	}{
+		"allowed":       {me: "https://user.example.net/", expError: nil},
+		"forbidden":     {me: "https://stranger.example.com/", expError: forwardauth.ErrForbidden},
+		"invalid state": {me: "https://user.example.net/", state: "hackme", expError: forwardauth.ErrLogin},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deps := NewDependencies(t)
+			target := &url.URL{Scheme: "https", Host: "app.example.org", Path: "/admin"}
+
+			u, login, err := deps.forwardAuth.Login(context.Background(), target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := domain.TestSession(t)
+			s.ClientID = deps.client.ID
+			s.RedirectURI = deps.client.RedirectURI[0]
+			s.Me = *domain.TestMe(t, tc.me)
+			s.CodeChallenge = u.Query().Get("code_challenge")
+			s.CodeChallengeMethod = domain.CodeChallengeMethodS256
+
+			if err = deps.sessions.Create(context.Background(), *s); err != nil {
+				t.Fatal(err)
+			}
+
+			state := u.Query().Get("state")
+			if tc.state != "" {
+				state = tc.state
+			}
+
+			session, result, err := deps.forwardAuth.Callback(context.Background(), forwardauth.CallbackOptions{
+				Login: login,
+				State: state,
+				Code:  s.Code,
+			})
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Callback() = %v, want %v", err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.String() != target.String() {
+				t.Errorf("Callback() returns target %s, want %s", result, target)
+			}
+
+			out, err := deps.forwardAuth.Verify(context.Background(), session, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out.Me.String() != tc.me {
+				t.Errorf("Verify() returns me %s, want %s", out.Me, tc.me)
+			}
+
+			if _, err = deps.forwardAuth.Verify(context.Background(), session, &url.URL{
+				Scheme: "https",
+				Host:   "example.net",
+				Path:   "/",
+			}); !errors.Is(err, forwardauth.ErrUpstream) {
+				t.Errorf("Verify() for unprotected upstream = %v, want %v", err, forwardauth.ErrUpstream)
+			}
+
+			redemption, err := deps.sessions.GetRedemption(context.Background(), domain.NewRedemptionID(s.Code))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			redemption.RevokedAt = time.Now().UTC()
+			if err = deps.sessions.UpdateRedemption(context.Background(), *redemption); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = deps.forwardAuth.Verify(context.Background(), session, target); !errors.Is(err,
+				forwardauth.ErrSession) {
+				t.Errorf("Verify() for revoked grant = %v, want %v", err, forwardauth.ErrSession)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	target := &url.URL{Scheme: "https", Host: "app.example.org", Path: "/"}
+
+	if _, err := deps.forwardAuth.Verify(context.Background(), "hackme", target); !errors.Is(err,
+		forwardauth.ErrSession) {
+		t.Errorf("Verify() = %v, want %v", err, forwardauth.ErrSession)
+	}
+}
+
+func NewDependencies(tb testing.TB) Dependencies {
+	tb.Helper()
+
+	client := domain.TestClient(tb)
+	config := domain.TestConfig(tb)
+	sessions := sessionrepo.NewMemorySessionRepository(*config)
+	tokens := tokenucase.NewTokenUseCase(tokenucase.Config{
+		Config:   *config,
+		Profiles: profilerepo.NewMemoryProfileRepository(),
+		Sessions: sessions,
+		Tokens:   tokenrepo.NewMemoryTokenRepository(),
+	})
+
+	return Dependencies{
+		client:   client,
+		config:   config,
+		sessions: sessions,
+		forwardAuth: ucase.NewForwardAuthUseCase(ucase.Config{
+			Tokens:   tokens,
+			Sessions: sessions,
+			Client:   *client,
+			Rules: []domain.ForwardAuthRule{{
+				Host:  []string{"*.example.org"},
+				Me:    []string{"https://user.example.net/"},
+				Scope: domain.Scopes{domain.ScopeProfile},
+			}, {
+				Host: []string{"wiki.example.com"},
+			}},
+			Config: *config,
+		}),
+	}
+}
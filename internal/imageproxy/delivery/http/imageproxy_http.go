@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+)
+
+type Handler struct {
+	images imageproxy.UseCase
+	config domain.Config
+}
+
+func NewHandler(images imageproxy.UseCase, config domain.Config) *Handler {
+	return &Handler{
+		config: config,
+		images: images,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	req := NewImageProxyRequest()
+	if err := req.bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	img, err := h.images.Fetch(r.Context(), imageproxy.FetchOptions{
+		Source:    req.Source.URL,
+		Signature: req.Signature,
+		Width:     req.Width,
+		Height:    req.Height,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, imageproxy.ErrInvalidSignature):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, imageproxy.ErrInvalidSize):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		}
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, img.ContentType)
+	w.Header().Set(common.HeaderContentLength, strconv.Itoa(len(img.Data)))
+	w.Header().Set(common.HeaderCacheControl, "public, max-age="+
+		strconv.Itoa(int(h.config.ImageProxy.CacheExpiry.Seconds()))+", immutable")
+	// NOTE(toby3d): never let browsers interpret proxied content as
+	// anything other than image.
+	w.Header().Set(common.HeaderXContentTypeOptions, "nosniff")
+	w.Header().Set(common.HeaderContentSecurityPolicy, "default-src 'none'; sandbox")
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write(img.Data)
+}
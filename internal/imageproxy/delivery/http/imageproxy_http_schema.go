@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/form"
+)
+
+type ImageProxyRequest struct {
+	// The original URL of the third-party image.
+	Source domain.URL `form:"src"`
+
+	// HMAC signature of the source URL and requested size.
+	Signature string `form:"sig"`
+
+	// The maximum width of the resized image.
+	Width int `form:"w"`
+
+	// The maximum height of the resized image.
+	Height int `form:"h"`
+}
+
+func NewImageProxyRequest() *ImageProxyRequest {
+	return &ImageProxyRequest{
+		Source:    domain.URL{},
+		Signature: "",
+		Width:     0,
+		Height:    0,
+	}
+}
+
+func (r *ImageProxyRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), "")
+	}
+
+	if r.Source.URL == nil || (r.Source.Scheme != "http" && r.Source.Scheme != "https") {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "image source MUST be an absolute HTTP URL", "")
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+	delivery "source.toby3d.me/toby3d/auth/internal/imageproxy/delivery/http"
+	imageproxymemoryrepo "source.toby3d.me/toby3d/auth/internal/imageproxy/repository/memory"
+	ucase "source.toby3d.me/toby3d/auth/internal/imageproxy/usecase"
+)
+
+type Dependencies struct {
+	server *httptest.Server
+	images imageproxy.UseCase
+	config *domain.Config
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	t.Cleanup(deps.server.Close)
+
+	src, _ := url.Parse(deps.server.URL + "/logo.png")
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com"+deps.images.URL(src, 32, 32).String(), nil)
+	w := httptest.NewRecorder()
+
+	delivery.NewHandler(deps.images, *deps.config).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+
+	if exp := http.StatusOK; resp.StatusCode != exp {
+		t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, exp)
+	}
+
+	if exp := "nosniff"; resp.Header.Get(common.HeaderXContentTypeOptions) != exp {
+		t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI,
+			resp.Header.Get(common.HeaderXContentTypeOptions), exp)
+	}
+
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 32 || bounds.Dy() != 16 {
+		t.Errorf("%s %s = %dx%d, want %dx%d", req.Method, req.RequestURI, bounds.Dx(), bounds.Dy(), 32, 16)
+	}
+}
+
+func TestHandler_ServeHTTP_Forbidden(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	t.Cleanup(deps.server.Close)
+
+	src, _ := url.Parse(deps.server.URL + "/logo.png")
+	u := deps.images.URL(src, 32, 32)
+	q := u.Query()
+	q.Set("w", "64")
+	u.RawQuery = q.Encode()
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com"+u.String(), nil)
+	w := httptest.NewRecorder()
+
+	delivery.NewHandler(deps.images, *deps.config).
+		ServeHTTP(w, req)
+
+	if resp, exp := w.Result(), http.StatusForbidden; resp.StatusCode != exp {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, exp)
+	}
+}
+
+func NewDependencies(tb testing.TB) Dependencies {
+	tb.Helper()
+
+	config := domain.TestConfig(tb)
+	logo := domain.TestImage(tb, 128, 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(common.HeaderContentType, logo.ContentType)
+		_, _ = w.Write(logo.Data)
+	}))
+
+	return Dependencies{
+		config: config,
+		server: server,
+		images: ucase.NewImageProxyUseCase(ucase.Config{
+			Client: server.Client(),
+			Images: imageproxymemoryrepo.NewMemoryImageProxyRepository(),
+			Config: *config,
+		}),
+	}
+}
@@ -0,0 +1,14 @@
+package imageproxy
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	Create(ctx context.Context, key string, img domain.Image) error
+	Get(ctx context.Context, key string) (*domain.Image, error)
+}
+
+var ErrNotExist error = domain.NewError(domain.ErrorCodeServerError, "image not exist in cache", "")
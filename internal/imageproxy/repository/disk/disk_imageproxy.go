@@ -0,0 +1,106 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+)
+
+type diskImageProxyRepository struct {
+	root   string
+	expiry time.Duration
+}
+
+// NewDiskImageProxyRepository creates a new disk cache of proxied images in the
+// root directory. Cached images older than expiry are treated as not exist.
+func NewDiskImageProxyRepository(root string, expiry time.Duration) (imageproxy.Repository, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil { //nolint:gomnd
+		return nil, fmt.Errorf("cannot create image cache directory: %w", err)
+	}
+
+	return &diskImageProxyRepository{
+		root:   root,
+		expiry: expiry,
+	}, nil
+}
+
+func (repo *diskImageProxyRepository) Create(_ context.Context, key string, img domain.Image) error {
+	tmp, err := os.CreateTemp(repo.root, ".tmp_*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary cache file: %w", err)
+	}
+
+	if _, err = tmp.Write(img.Data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("cannot write image into cache: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("cannot close cache file: %w", err)
+	}
+
+	// NOTE(toby3d): rename is atomic, so concurrent readers never get a
+	// partially written image.
+	if err = os.Rename(tmp.Name(), repo.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("cannot store image in cache: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *diskImageProxyRepository) Get(_ context.Context, key string) (*domain.Image, error) {
+	info, err := os.Stat(repo.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, imageproxy.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot stat cached image: %w", err)
+	}
+
+	if repo.expiry > 0 && info.ModTime().Add(repo.expiry).Before(time.Now()) {
+		_ = os.Remove(repo.path(key))
+
+		return nil, imageproxy.ErrNotExist
+	}
+
+	data, err := os.ReadFile(repo.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cached image: %w", err)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode cached image: %w", err)
+	}
+
+	return &domain.Image{
+		ContentType: http.DetectContentType(data),
+		Data:        data,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+func (repo *diskImageProxyRepository) path(key string) string {
+	return filepath.Join(repo.root, filepath.Base(key))
+}
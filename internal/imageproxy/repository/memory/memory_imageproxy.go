@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+)
+
+type memoryImageProxyRepository struct {
+	mutex  *sync.RWMutex
+	images map[string]domain.Image
+}
+
+func NewMemoryImageProxyRepository() imageproxy.Repository {
+	return &memoryImageProxyRepository{
+		mutex:  new(sync.RWMutex),
+		images: make(map[string]domain.Image),
+	}
+}
+
+func (repo *memoryImageProxyRepository) Create(_ context.Context, key string, img domain.Image) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	repo.images[key] = img
+
+	return nil
+}
+
+func (repo *memoryImageProxyRepository) Get(_ context.Context, key string) (*domain.Image, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	if img, ok := repo.images[key]; ok {
+		return &img, nil
+	}
+
+	return nil, imageproxy.ErrNotExist
+}
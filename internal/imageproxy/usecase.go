@@ -0,0 +1,51 @@
+package imageproxy
+
+import (
+	"context"
+	"net/url"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	FetchOptions struct {
+		Source    *url.URL
+		Signature string
+		Width     int
+		Height    int
+	}
+
+	UseCase interface {
+		// URL returns a signed proxy URL for the source image resized
+		// to fit into width x height box.
+		URL(src *url.URL, width, height int) *url.URL
+
+		// Fetch validates options signature, downloads the source
+		// image, resizes it and returns the result from the cache if
+		// possible.
+		Fetch(ctx context.Context, opts FetchOptions) (*domain.Image, error)
+	}
+)
+
+var (
+	ErrInvalidSignature error = domain.NewError(
+		domain.ErrorCodeAccessDenied,
+		"image URL signature is invalid",
+		"",
+	)
+	ErrInvalidSize error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"requested image size is out of allowed range",
+		"",
+	)
+	ErrUnsupportedContentType error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"requested resource is not a supported image",
+		"",
+	)
+	ErrTooLarge error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"requested image is too large",
+		"",
+	)
+)
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+)
+
+type (
+	Config struct {
+		Client *http.Client
+		Images imageproxy.Repository
+		Config domain.Config
+	}
+
+	imageProxyUseCase struct {
+		client *http.Client
+		images imageproxy.Repository
+		secret []byte
+		config domain.Config
+	}
+)
+
+const (
+	// MaxSize is the maximum width or height of the resized image.
+	MaxSize int = 1024
+
+	// MaxPixels is the maximum number of pixels in the source image.
+	MaxPixels int64 = 4096 * 4096
+)
+
+const jpegQuality int = 85
+
+//nolint:gochecknoglobals // slices cannot be constants
+var supportedContentTypes = []string{
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+}
+
+func NewImageProxyUseCase(config Config) imageproxy.UseCase {
+	return &imageProxyUseCase{
+		client: config.Client,
+		config: config.Config,
+		images: config.Images,
+		secret: []byte(config.Config.ImageProxy.Secret),
+	}
+}
+
+func (uc *imageProxyUseCase) URL(src *url.URL, width, height int) *url.URL {
+	if src == nil {
+		return nil
+	}
+
+	// NOTE(toby3d): our own images do not leak anything, serve them as is.
+	if !src.IsAbs() || strings.EqualFold(src.Hostname(), uc.config.Server.GetHostname()) {
+		return src
+	}
+
+	q := make(url.Values)
+	q.Set("src", src.String())
+	q.Set("w", strconv.Itoa(width))
+	q.Set("h", strconv.Itoa(height))
+	q.Set("sig", uc.sign(src.String(), width, height))
+
+	return &url.URL{Path: uc.config.Server.GetPath("/img"), RawQuery: q.Encode()}
+}
+
+//nolint:cyclop
+func (uc *imageProxyUseCase) Fetch(ctx context.Context, opts imageproxy.FetchOptions) (*domain.Image, error) {
+	if opts.Source == nil || !hmac.Equal([]byte(opts.Signature),
+		[]byte(uc.sign(opts.Source.String(), opts.Width, opts.Height))) {
+		return nil, imageproxy.ErrInvalidSignature
+	}
+
+	if opts.Width < 1 || opts.Width > MaxSize || opts.Height < 1 || opts.Height > MaxSize {
+		return nil, imageproxy.ErrInvalidSize
+	}
+
+	key := uc.key(opts.Source.String(), opts.Width, opts.Height)
+
+	if img, err := uc.images.Get(ctx, key); err == nil {
+		return img, nil
+	} else if !errors.Is(err, imageproxy.ErrNotExist) {
+		return nil, fmt.Errorf("cannot read image from cache: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.Source.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create image request: %w", err)
+	}
+
+	req.Header.Set(common.HeaderAccept, "image/png, image/jpeg, image/gif")
+
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch image: unexpected status %d", resp.StatusCode)
+	}
+
+	// NOTE(toby3d): validate both declared and sniffed content types, so
+	// HTML or SVG documents cannot be served on our origin.
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(common.HeaderContentType)); !slices.Contains(
+		supportedContentTypes, mediaType) {
+		return nil, fmt.Errorf("%w: %s", imageproxy.ErrUnsupportedContentType, mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, uc.config.ImageProxy.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read image body: %w", err)
+	}
+
+	if int64(len(data)) > uc.config.ImageProxy.MaxSize {
+		return nil, imageproxy.ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(supportedContentTypes, contentType) {
+		return nil, fmt.Errorf("%w: %s", imageproxy.ErrUnsupportedContentType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", imageproxy.ErrUnsupportedContentType, err)
+	}
+
+	// NOTE(toby3d): protect from decompression bombs.
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, imageproxy.ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", imageproxy.ErrUnsupportedContentType, err)
+	}
+
+	out, err := encode(resize(src, opts.Width, opts.Height), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode resized image: %w", err)
+	}
+
+	if err = uc.images.Create(ctx, key, *out); err != nil {
+		return nil, fmt.Errorf("cannot save image in cache: %w", err)
+	}
+
+	return out, nil
+}
+
+func (uc *imageProxyUseCase) sign(src string, width, height int) string {
+	hash := hmac.New(sha256.New, uc.secret)
+	_, _ = io.WriteString(hash, src+"\n"+strconv.Itoa(width)+"x"+strconv.Itoa(height))
+
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+}
+
+func (uc *imageProxyUseCase) key(src string, width, height int) string {
+	hash := sha256.Sum256([]byte(src + "\n" + strconv.Itoa(width) + "x" + strconv.Itoa(height)))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func encode(img image.Image, contentType string) (*domain.Image, error) {
+	buf := new(bytes.Buffer)
+
+	switch contentType {
+	case "image/jpeg":
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("cannot encode JPEG: %w", err)
+		}
+	default:
+		contentType = "image/png"
+
+		if err := png.Encode(buf, img); err != nil {
+			return nil, fmt.Errorf("cannot encode PNG: %w", err)
+		}
+	}
+
+	return &domain.Image{
+		ContentType: contentType,
+		Data:        buf.Bytes(),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}, nil
+}
+
+// resize scales src down to fit into the width x height box preserving aspect
+// ratio by averaging the source pixels covered by each destination pixel.
+// Images which already fit into the box are returned as is.
+func resize(src image.Image, width, height int) image.Image {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	if srcWidth <= width && srcHeight <= height {
+		return src
+	}
+
+	dstWidth, dstHeight := width, srcHeight*width/srcWidth
+	if dstHeight > height {
+		dstWidth, dstHeight = srcWidth*height/srcHeight, height
+	}
+
+	dstWidth, dstHeight = max(dstWidth, 1), max(dstHeight, 1)
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/dstHeight
+		y1 := max(bounds.Min.Y+(y+1)*srcHeight/dstHeight, y0+1)
+
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*srcWidth/dstWidth
+			x1 := max(bounds.Min.X+(x+1)*srcWidth/dstWidth, x0+1)
+
+			var r, g, b, a, count uint64
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64) //nolint:forcetypeassert
+
+					r, g, b, a = r+uint64(c.R), g+uint64(c.G), b+uint64(c.B), a+uint64(c.A)
+					count++
+				}
+			}
+
+			dst.Set(x, y, color.NRGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return dst
+}
@@ -48,10 +48,31 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.metadata.CodeChallengeMethodsSupported[i].String())
 	}
 
+	responseModes := make([]string, 0, len(h.metadata.ResponseModesSupported))
+	for i := range h.metadata.ResponseModesSupported {
+		responseModes = append(responseModes, h.metadata.ResponseModesSupported[i].String())
+	}
+
+	var registrationEndpoint string
+	if h.metadata.RegistrationEndpoint != nil {
+		registrationEndpoint = h.metadata.RegistrationEndpoint.String()
+	}
+
+	var parEndpoint string
+	if h.metadata.PushedAuthorizationRequestEndpoint != nil {
+		parEndpoint = h.metadata.PushedAuthorizationRequestEndpoint.String()
+	}
+
+	var jwksURI string
+	if h.metadata.JWKSURI != nil {
+		jwksURI = h.metadata.JWKSURI.String()
+	}
+
 	_ = json.NewEncoder(w).Encode(&MetadataResponse{
 		AuthorizationEndpoint: h.metadata.AuthorizationEndpoint.String(),
 		IntrospectionEndpoint: h.metadata.IntrospectionEndpoint.String(),
 		Issuer:                h.metadata.Issuer.String(),
+		RegistrationEndpoint:  registrationEndpoint,
 		RevocationEndpoint:    h.metadata.RevocationEndpoint.String(),
 		ServiceDocumentation:  h.metadata.ServiceDocumentation.String(),
 		TokenEndpoint:         h.metadata.TokenEndpoint.String(),
@@ -62,11 +83,25 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		IntrospectionEndpointAuthMethodsSupported:  h.metadata.IntrospectionEndpointAuthMethodsSupported,
 		ResponseTypesSupported:                     responseTypes,
 		ScopesSupported:                            scopes,
+		TokenEndpointAuthMethodsSupported:          h.metadata.TokenEndpointAuthMethodsSupported,
+		TokenEndpointAuthSigningAlgValuesSupported: h.metadata.TokenEndpointAuthSigningAlgValuesSupported,
 		// NOTE(toby3d): If a revocation endpoint is provided, this
-		// property should also be provided with the value ["none"],
-		// since the omission of this value defaults to
-		// client_secret_basic according to RFC8414.
+		// property should also be provided and contain "none", since
+		// the omission of this value defaults to client_secret_basic
+		// according to RFC8414.
 		RevocationEndpointAuthMethodsSupported: h.metadata.RevocationEndpointAuthMethodsSupported,
+		PushedAuthorizationRequestEndpoint:     parEndpoint,
+		RequirePushedAuthorizationRequests:     h.metadata.RequirePushedAuthorizationRequests,
+		RequestObjectSigningAlgValuesSupported: h.metadata.RequestObjectSigningAlgValuesSupported,
+		RequestParameterSupported:              h.metadata.RequestParameterSupported,
+		RequestURIParameterSupported:           h.metadata.RequestURIParameterSupported,
+		ResponseModesSupported:                 responseModes,
+		AuthorizationSigningAlgValuesSupported: h.metadata.AuthorizationSigningAlgValuesSupported,
+		JWKSURI:                                jwksURI,
+		SubjectTypesSupported:                  h.metadata.SubjectTypesSupported,
+		IDTokenSigningAlgValuesSupported:       h.metadata.IDTokenSigningAlgValuesSupported,
+		ClaimsSupported:                        h.metadata.ClaimsSupported,
+		DPoPSigningAlgValuesSupported:          h.metadata.DPoPSigningAlgValuesSupported,
 	})
 
 	w.WriteHeader(http.StatusOK)
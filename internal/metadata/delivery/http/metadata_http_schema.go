@@ -21,6 +21,12 @@ type MetadataResponse struct {
 	// The Revocation Endpoint.
 	RevocationEndpoint string `json:"revocation_endpoint,omitempty"`
 
+	// The Dynamic Client Registration Endpoint.
+	RegistrationEndpoint string `json:"registration_endpoint,omitempty"`
+
+	// The Pushed Authorization Request Endpoint.
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint,omitempty"`
+
 	// The server's issuer identifier.
 	Issuer string `json:"issuer"`
 
@@ -40,6 +46,15 @@ type MetadataResponse struct {
 	// JSON array containing the methods supported for PKCE.
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
 
+	// JSON array containing a list of client authentication methods
+	// supported by this token endpoint.
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+
+	// JSON array containing a list of the JWS signing algorithms supported
+	// by the token endpoint for the signature on the JWT used to
+	// authenticate the client.
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"` //nolint:lll
+
 	// JSON array containing a list of client authentication methods
 	// supported by this introspection endpoint.
 	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported,omitempty"` //nolint:lll
@@ -47,4 +62,47 @@ type MetadataResponse struct {
 	// Boolean parameter indicating whether the authorization server
 	// provides the iss parameter.
 	AuthorizationResponseIssParameterSupported bool `json:"authorization_response_iss_parameter_supported,omitempty"` //nolint:lll
+
+	// Boolean parameter indicating whether the authorization server
+	// accepts authorization request data only via PAR.
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+
+	// List of the JWS signing algorithms supported for request objects.
+	RequestObjectSigningAlgValuesSupported []string `json:"request_object_signing_alg_values_supported,omitempty"`
+
+	// Boolean parameter indicating whether the authorization server
+	// accepts request objects passed by value.
+	RequestParameterSupported bool `json:"request_parameter_supported,omitempty"`
+
+	// Boolean parameter indicating whether the authorization server
+	// accepts request objects passed by reference.
+	RequestURIParameterSupported bool `json:"request_uri_parameter_supported,omitempty"`
+
+	// JSON array containing a list of the response_mode values that this
+	// authorization server supports.
+	ResponseModesSupported []string `json:"response_modes_supported,omitempty"`
+
+	// List of the JWS signing algorithms supported for signing JWT-secured
+	// authorization responses.
+	AuthorizationSigningAlgValuesSupported []string `json:"authorization_signing_alg_values_supported,omitempty"`
+
+	// URL of the JSON Web Key Set document of keys used to sign ID
+	// Tokens.
+	JWKSURI string `json:"jwks_uri,omitempty"`
+
+	// JSON array containing a list of the subject identifier types that
+	// this OpenID Provider supports.
+	SubjectTypesSupported []string `json:"subject_types_supported,omitempty"`
+
+	// JSON array containing a list of the JWS signing algorithms supported
+	// for the ID Token.
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported,omitempty"`
+
+	// JSON array containing a list of the claim names which values this
+	// OpenID Provider may be able to supply.
+	ClaimsSupported []string `json:"claims_supported,omitempty"`
+
+	// JSON array containing a list of the JWS signing algorithms supported
+	// for DPoP proof JWTs.
+	DPoPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
 }
@@ -22,6 +22,8 @@ type (
 		AuthorizationEndpoint                      domain.URL                   `json:"authorization_endpoint"`
 		IntrospectionEndpoint                      domain.URL                   `json:"introspection_endpoint"`
 		RevocationEndpoint                         domain.URL                   `json:"revocation_endpoint,omitempty"`
+		RegistrationEndpoint                       domain.URL                   `json:"registration_endpoint,omitempty"`
+		PushedAuthorizationRequestEndpoint         domain.URL                   `json:"pushed_authorization_request_endpoint,omitempty"`
 		ServiceDocumentation                       domain.URL                   `json:"service_documentation,omitempty"`
 		TokenEndpoint                              domain.URL                   `json:"token_endpoint"`
 		UserinfoEndpoint                           domain.URL                   `json:"userinfo_endpoint,omitempty"`
@@ -30,11 +32,14 @@ type (
 		Micropub                                   domain.URL                   `json:"micropub"`
 		GrantTypesSupported                        []domain.GrantType           `json:"grant_types_supported,omitempty"`
 		IntrospectionEndpointAuthMethodsSupported  []string                     `json:"introspection_endpoint_auth_methods_supported,omitempty"`
+		TokenEndpointAuthMethodsSupported          []string                     `json:"token_endpoint_auth_methods_supported,omitempty"`
+		TokenEndpointAuthSigningAlgValuesSupported []string                     `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
 		RevocationEndpointAuthMethodsSupported     []string                     `json:"revocation_endpoint_auth_methods_supported,omitempty"`
 		ScopesSupported                            []domain.Scope               `json:"scopes_supported,omitempty"`
 		ResponseTypesSupported                     []domain.ResponseType        `json:"response_types_supported,omitempty"`
 		CodeChallengeMethodsSupported              []domain.CodeChallengeMethod `json:"code_challenge_methods_supported"`
 		AuthorizationResponseIssParameterSupported bool                         `json:"authorization_response_iss_parameter_supported,omitempty"`
+		RequirePushedAuthorizationRequests         bool                         `json:"require_pushed_authorization_requests,omitempty"`
 	}
 
 	httpMetadataRepository struct {
@@ -117,12 +122,14 @@ func populateBuffer(dst map[string][]string, rel, u string) {
 
 func NewResponse() *Response {
 	return &Response{
-		CodeChallengeMethodsSupported:             make([]domain.CodeChallengeMethod, 0),
-		GrantTypesSupported:                       make([]domain.GrantType, 0),
-		ResponseTypesSupported:                    make([]domain.ResponseType, 0),
-		ScopesSupported:                           make([]domain.Scope, 0),
-		IntrospectionEndpointAuthMethodsSupported: make([]string, 0),
-		RevocationEndpointAuthMethodsSupported:    make([]string, 0),
+		CodeChallengeMethodsSupported:              make([]domain.CodeChallengeMethod, 0),
+		GrantTypesSupported:                        make([]domain.GrantType, 0),
+		ResponseTypesSupported:                     make([]domain.ResponseType, 0),
+		ScopesSupported:                            make([]domain.Scope, 0),
+		IntrospectionEndpointAuthMethodsSupported:  make([]string, 0),
+		RevocationEndpointAuthMethodsSupported:     make([]string, 0),
+		TokenEndpointAuthMethodsSupported:          make([]string, 0),
+		TokenEndpointAuthSigningAlgValuesSupported: make([]string, 0),
 	}
 }
 
@@ -142,6 +149,9 @@ func (r Response) populate(dst *domain.Metadata) {
 	dst.MicropubEndpoint = r.Micropub.URL
 	dst.MicrosubEndpoint = r.Microsub.URL
 	dst.RevocationEndpoint = r.RevocationEndpoint.URL
+	dst.RegistrationEndpoint = r.RegistrationEndpoint.URL
+	dst.PushedAuthorizationRequestEndpoint = r.PushedAuthorizationRequestEndpoint.URL
+	dst.RequirePushedAuthorizationRequests = r.RequirePushedAuthorizationRequests
 	dst.ServiceDocumentation = r.ServiceDocumentation.URL
 	dst.TicketEndpoint = r.TicketEndpoint.URL
 	dst.TokenEndpoint = r.TokenEndpoint.URL
@@ -151,6 +161,10 @@ func (r Response) populate(dst *domain.Metadata) {
 	dst.ResponseTypesSupported = append(dst.ResponseTypesSupported, r.ResponseTypesSupported...)
 	dst.IntrospectionEndpointAuthMethodsSupported = append(dst.IntrospectionEndpointAuthMethodsSupported,
 		r.IntrospectionEndpointAuthMethodsSupported...)
+	dst.TokenEndpointAuthMethodsSupported = append(dst.TokenEndpointAuthMethodsSupported,
+		r.TokenEndpointAuthMethodsSupported...)
+	dst.TokenEndpointAuthSigningAlgValuesSupported = append(dst.TokenEndpointAuthSigningAlgValuesSupported,
+		r.TokenEndpointAuthSigningAlgValuesSupported...)
 	dst.GrantTypesSupported = append(dst.GrantTypesSupported, r.GrantTypesSupported...)
 	dst.CodeChallengeMethodsSupported = append(dst.CodeChallengeMethodsSupported,
 		r.CodeChallengeMethodsSupported...)
@@ -24,6 +24,8 @@ type Response struct {
 	IntrospectionEndpoint                      string   `json:"introspection_endpoint"`
 	Microsub                                   string   `json:"microsub"`
 	RevocationEndpoint                         string   `json:"revocation_endpoint,omitempty"`
+	RegistrationEndpoint                       string   `json:"registration_endpoint,omitempty"`
+	PushedAuthorizationRequestEndpoint         string   `json:"pushed_authorization_request_endpoint,omitempty"`
 	Micropub                                   string   `json:"micropub"`
 	Issuer                                     string   `json:"issuer"`
 	ServiceDocumentation                       string   `json:"service_documentation,omitempty"`
@@ -31,6 +33,8 @@ type Response struct {
 	TokenEndpoint                              string   `json:"token_endpoint"`
 	RevocationEndpointAuthMethodsSupported     []string `json:"revocation_endpoint_auth_methods_supported,omitempty"`
 	IntrospectionEndpointAuthMethodsSupported  []string `json:"introspection_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported          []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
 	CodeChallengeMethodsSupported              []string `json:"code_challenge_methods_supported"`
 	ResponseTypesSupported                     []string `json:"response_types_supported,omitempty"`
 	GrantTypesSupported                        []string `json:"grant_types_supported,omitempty"`
@@ -141,10 +145,14 @@ func NewResponse(tb testing.TB, src domain.Metadata) *Response {
 		ScopesSupported:                            make([]string, 0),
 		IntrospectionEndpointAuthMethodsSupported:  make([]string, 0),
 		RevocationEndpointAuthMethodsSupported:     make([]string, 0),
+		TokenEndpointAuthMethodsSupported:          make([]string, 0),
+		TokenEndpointAuthSigningAlgValuesSupported: make([]string, 0),
 		Issuer:                                     src.Issuer.String(),
 		AuthorizationEndpoint:                      src.AuthorizationEndpoint.String(),
 		IntrospectionEndpoint:                      src.IntrospectionEndpoint.String(),
 		RevocationEndpoint:                         src.RevocationEndpoint.String(),
+		RegistrationEndpoint:                       src.RegistrationEndpoint.String(),
+		PushedAuthorizationRequestEndpoint:         src.PushedAuthorizationRequestEndpoint.String(),
 		ServiceDocumentation:                       src.ServiceDocumentation.String(),
 		TokenEndpoint:                              src.TokenEndpoint.String(),
 		UserinfoEndpoint:                           src.UserinfoEndpoint.String(),
@@ -176,5 +184,11 @@ func NewResponse(tb testing.TB, src domain.Metadata) *Response {
 	out.RevocationEndpointAuthMethodsSupported = append(out.RevocationEndpointAuthMethodsSupported,
 		src.RevocationEndpointAuthMethodsSupported...)
 
+	out.TokenEndpointAuthMethodsSupported = append(out.TokenEndpointAuthMethodsSupported,
+		src.TokenEndpointAuthMethodsSupported...)
+
+	out.TokenEndpointAuthSigningAlgValuesSupported = append(out.TokenEndpointAuthSigningAlgValuesSupported,
+		src.TokenEndpointAuthSigningAlgValuesSupported...)
+
 	return out
 }
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/oidc"
+)
+
+type Handler struct {
+	oidc oidc.UseCase
+}
+
+func NewHandler(oidc oidc.UseCase) *Handler {
+	return &Handler{
+		oidc: oidc,
+	}
+}
+
+// ServeHTTP serves the JSON Web Key Set document with public keys which ID
+// Tokens and access tokens can be verified by.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "" && r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	keys, err := h.oidc.KeySet(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJWKSet)
+
+	_ = json.NewEncoder(w).Encode(keys)
+
+	w.WriteHeader(http.StatusOK)
+}
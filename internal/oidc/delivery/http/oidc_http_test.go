@@ -0,0 +1,62 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	delivery "source.toby3d.me/toby3d/auth/internal/oidc/delivery/http"
+	ucase "source.toby3d.me/toby3d/auth/internal/oidc/usecase"
+)
+
+func TestKeySet(t *testing.T) {
+	t.Parallel()
+
+	oidc := ucase.NewOIDCUseCase(domain.TestSigningKey(t), nil, *domain.TestConfig(t))
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	delivery.NewHandler(oidc).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+
+	if exp := http.StatusOK; resp.StatusCode != exp {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, exp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwk.Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < keys.Len(); i++ {
+		if key, _ := keys.Key(i); key.KeyType() != jwa.EC {
+			t.Errorf("%s %s = %+v, want EC key", req.Method, req.RequestURI, key)
+		} else if _, ok := key.(jwk.ECDSAPublicKey); !ok {
+			t.Errorf("%s %s = %+v, want only public keys", req.Method, req.RequestURI, key)
+		}
+	}
+
+	idToken, err := oidc.IDToken(context.Background(), *domain.TestToken(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = jwt.ParseString(idToken, jwt.WithKeySet(keys)); err != nil {
+		t.Errorf("%s %s = %+v, cannot verify ID Token: %s", req.Method, req.RequestURI, keys, err)
+	}
+}
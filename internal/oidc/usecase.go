@@ -0,0 +1,19 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type UseCase interface {
+	// IDToken issues a signed ID Token about the authentication event
+	// which the provided access token was minted by.
+	IDToken(ctx context.Context, tkn domain.Token) (string, error)
+
+	// KeySet returns public keys which ID Tokens and access tokens can
+	// be verified by.
+	KeySet(ctx context.Context) (jwk.Set, error)
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/oidc"
+)
+
+type oidcUseCase struct {
+	key    jwk.Key
+	clock  domain.Clock
+	config domain.Config
+}
+
+// NewOIDCUseCase creates a new OpenID Connect use case which signs ID Tokens
+// by the provided private key. Key must contain the signature algorithm.
+// System clock is used if clock is nil.
+func NewOIDCUseCase(key jwk.Key, clock domain.Clock, config domain.Config) oidc.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
+	return &oidcUseCase{
+		key:    key,
+		clock:  clock,
+		config: config,
+	}
+}
+
+func (uc *oidcUseCase) IDToken(_ context.Context, tkn domain.Token) (string, error) {
+	// NOTE(toby3d): JWT contains time in seconds, so truncated time is
+	// valid immediately after issue.
+	now := uc.clock.Now().Truncate(time.Second)
+	claims := map[string]any{
+		jwt.AudienceKey:   tkn.ClientID.String(),
+		jwt.ExpirationKey: now.Add(uc.config.OIDC.Expiry),
+		jwt.IssuedAtKey:   now,
+		jwt.IssuerKey:     uc.config.Server.GetRootURL(),
+		jwt.SubjectKey:    tkn.Me.String(),
+	}
+
+	if !tkn.AuthTime.IsZero() {
+		claims["auth_time"] = tkn.AuthTime.Unix()
+	}
+
+	if tkn.Nonce != "" {
+		claims["nonce"] = tkn.Nonce
+	}
+
+	if tkn.ACR != "" {
+		claims["acr"] = tkn.ACR
+	}
+
+	if len(tkn.AMR) > 0 {
+		claims["amr"] = tkn.AMR
+	}
+
+	idToken := jwt.New()
+
+	for key, val := range claims {
+		if err := idToken.Set(key, val); err != nil {
+			return "", fmt.Errorf("cannot set ID Token claim: %w", err)
+		}
+	}
+
+	out, err := jwt.Sign(idToken, jwt.WithKey(jwa.SignatureAlgorithm(uc.key.Algorithm().String()), uc.key))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign ID Token: %w", err)
+	}
+
+	return string(out), nil
+}
+
+func (uc *oidcUseCase) KeySet(_ context.Context) (jwk.Set, error) {
+	key, err := jwk.PublicKeyOf(uc.key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get public key: %w", err)
+	}
+
+	set := jwk.NewSet()
+	if err = set.AddKey(key); err != nil {
+		return nil, fmt.Errorf("cannot add public key into set: %w", err)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,51 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	ucase "source.toby3d.me/toby3d/auth/internal/oidc/usecase"
+)
+
+func TestIDToken(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	oidc := ucase.NewOIDCUseCase(domain.TestSigningKey(t), nil, *config)
+
+	tkn := domain.TestToken(t)
+	tkn.AuthTime = time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	tkn.Nonce = "n-0S6_WzA2Mj"
+	tkn.ACR = "1"
+
+	idToken, err := oidc.IDToken(context.Background(), *tkn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := oidc.KeySet(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := jwt.ParseString(idToken, jwt.WithKeySet(keys), jwt.WithValidate(true),
+		jwt.WithIssuer(config.Server.GetRootURL()), jwt.WithAudience(tkn.ClientID.String()),
+		jwt.WithSubject(tkn.Me.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expect := range map[string]any{
+		"nonce":     tkn.Nonce,
+		"acr":       tkn.ACR,
+		"auth_time": float64(tkn.AuthTime.Unix()),
+	} {
+		if actual, _ := result.Get(key); actual != expect {
+			t.Errorf("IDToken() %s = %v, want %v", key, actual, expect)
+		}
+	}
+}
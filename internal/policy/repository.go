@@ -0,0 +1,14 @@
+package policy
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Fetch returns all configured rules in order of evaluation.
+	Fetch(ctx context.Context) ([]domain.PolicyRule, error)
+}
+
+var ErrInvalid error = domain.NewError(domain.ErrorCodeServerError, "invalid policy configuration", "")
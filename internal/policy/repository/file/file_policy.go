@@ -0,0 +1,145 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+)
+
+type (
+	//nolint:tagliatelle
+	Rule struct {
+		Match               Match    `json:"match"`
+		Name                string   `json:"name"`
+		Action              string   `json:"action,omitempty"`
+		AccessTokenExpiry   string   `json:"access_token_expiry,omitempty"`
+		RefreshTokenExpiry  string   `json:"refresh_token_expiry,omitempty"`
+		Strip               []string `json:"strip_scope,omitempty"`
+		MaxTokens           int      `json:"max_tokens,omitempty"`
+		RequirePKCE         bool     `json:"require_pkce,omitempty"`
+		RequireSecondFactor bool     `json:"require_second_factor,omitempty"`
+	}
+
+	//nolint:tagliatelle
+	Match struct {
+		ClientHost   []string `json:"client_host,omitempty"`
+		RedirectHost []string `json:"redirect_host,omitempty"`
+		Me           []string `json:"me,omitempty"`
+		Scope        []string `json:"scope,omitempty"`
+	}
+
+	filePolicyRepository struct {
+		path string
+	}
+)
+
+// NewFilePolicyRepository creates a new policy repository which reads the JSON
+// array of rules from the provided file path.
+func NewFilePolicyRepository(path string) policy.Repository {
+	return &filePolicyRepository{
+		path: path,
+	}
+}
+
+func (repo *filePolicyRepository) Fetch(_ context.Context) ([]domain.PolicyRule, error) {
+	src, err := os.ReadFile(repo.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy file: %w", err)
+	}
+
+	in := make([]Rule, 0)
+	if err = json.Unmarshal(src, &in); err != nil {
+		return nil, fmt.Errorf("cannot decode policy file: %w", err)
+	}
+
+	out := make([]domain.PolicyRule, 0, len(in))
+	names := make(map[string]struct{}, len(in))
+
+	for i := range in {
+		rule, err := in[i].populate()
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule #%d: %w", policy.ErrInvalid, i, err)
+		}
+
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i)
+		}
+
+		if _, ok := names[rule.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicated rule '%s'", policy.ErrInvalid, rule.Name)
+		}
+
+		names[rule.Name] = struct{}{}
+		out = append(out, rule)
+	}
+
+	return out, nil
+}
+
+func (r Rule) populate() (domain.PolicyRule, error) {
+	out := domain.PolicyRule{
+		Name:                r.Name,
+		Action:              domain.PolicyActionAllow,
+		MaxTokens:           r.MaxTokens,
+		RequirePKCE:         r.RequirePKCE,
+		RequireSecondFactor: r.RequireSecondFactor,
+		Match: domain.PolicyMatch{
+			ClientHost:   r.Match.ClientHost,
+			RedirectHost: r.Match.RedirectHost,
+			Me:           r.Match.Me,
+		},
+	}
+
+	var err error
+
+	if r.Action != "" {
+		if out.Action, err = domain.ParsePolicyAction(r.Action); err != nil {
+			return out, fmt.Errorf("cannot parse action: %w", err)
+		}
+	}
+
+	if r.MaxTokens < 0 {
+		return out, fmt.Errorf("max_tokens cannot be negative: %d", r.MaxTokens)
+	}
+
+	for dst, src := range map[*time.Duration]string{
+		&out.AccessTokenExpiry:  r.AccessTokenExpiry,
+		&out.RefreshTokenExpiry: r.RefreshTokenExpiry,
+	} {
+		if src == "" {
+			continue
+		}
+
+		if *dst, err = time.ParseDuration(src); err != nil {
+			return out, fmt.Errorf("cannot parse expiry: %w", err)
+		}
+
+		if *dst < 0 {
+			return out, fmt.Errorf("expiry cannot be negative: %s", src)
+		}
+	}
+
+	for dst, src := range map[*domain.Scopes][]string{
+		&out.Match.Scope: r.Match.Scope,
+		&out.Strip:       r.Strip,
+	} {
+		*dst = make(domain.Scopes, 0, len(src))
+
+		for _, raw := range src {
+			s, err := domain.ParseScope(raw)
+			if err != nil {
+				return out, fmt.Errorf("cannot parse scope: %w", err)
+			}
+
+			*dst = append(*dst, s)
+		}
+	}
+
+	return out, nil
+}
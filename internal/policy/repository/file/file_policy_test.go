@@ -0,0 +1,85 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+	repository "source.toby3d.me/toby3d/auth/internal/policy/repository/file"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input    string
+		expError error
+		expCount int
+	}{
+		"valid": {
+			input: `[
+				{"name": "micropub", "match": {"client_host": ["*.example.com"], "scope": ["create"]},
+				 "strip_scope": ["delete"], "access_token_expiry": "24h", "require_pkce": true,
+				 "require_second_factor": true, "max_tokens": 3},
+				{"action": "deny", "match": {"redirect_host": ["evil.example"]}}
+			]`,
+			expCount: 2,
+		},
+		"unknown action": {
+			input:    `[{"name": "block", "action": "block"}]`,
+			expError: policy.ErrInvalid,
+		},
+		"invalid expiry": {
+			input:    `[{"name": "short", "access_token_expiry": "-1h"}]`,
+			expError: policy.ErrInvalid,
+		},
+		"invalid scope": {
+			input:    `[{"name": "strip", "strip_scope": ["with space"]}]`,
+			expError: policy.ErrInvalid,
+		},
+		"duplicated": {
+			input:    `[{"name": "micropub"}, {"name": "micropub"}]`,
+			expError: policy.ErrInvalid,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(tc.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := repository.NewFilePolicyRepository(path).Fetch(context.Background())
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Fetch() = %v, want %v", err, tc.expError)
+			}
+
+			if len(result) != tc.expCount {
+				t.Errorf("Fetch() = %d rules, want %d", len(result), tc.expCount)
+			}
+
+			if tc.expCount == 0 {
+				return
+			}
+
+			if rule := result[0]; rule.Name != "micropub" || rule.Action != domain.PolicyActionAllow ||
+				!rule.Strip.Has(domain.ScopeDelete) || !rule.Match.Scope.Has(domain.ScopeCreate) ||
+				rule.AccessTokenExpiry != 24*time.Hour || !rule.RequirePKCE || !rule.RequireSecondFactor ||
+				rule.MaxTokens != 3 {
+				t.Errorf("Fetch() = %+v, want parsed rule", rule)
+			}
+
+			if rule := result[1]; rule.Name != "#1" || rule.Action != domain.PolicyActionDeny {
+				t.Errorf("Fetch() = %+v, want parsed rule", rule)
+			}
+		})
+	}
+}
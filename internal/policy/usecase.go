@@ -0,0 +1,30 @@
+package policy
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type UseCase interface {
+	// Evaluate returns decision of the first rule matched by the request.
+	// Request is allowed if none of rules is matched. Decision of the
+	// denied request is returned along with ErrDenied, so it can be
+	// explained.
+	Evaluate(ctx context.Context, req domain.PolicyRequest) (*domain.PolicyDecision, error)
+}
+
+var (
+	ErrDenied error = domain.NewError(domain.ErrorCodeAccessDenied, "request is denied by the policy", "")
+
+	ErrPKCERequired error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"code_challenge is required by the policy",
+		"https://indieauth.net/source/#authorization-request",
+	)
+	ErrSecondFactorRequired error = domain.NewError(
+		domain.ErrorCodeInteractionRequired,
+		"authorization must be confirmed by the second factor",
+		"",
+	)
+)
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+)
+
+type policyUseCase struct {
+	rules []domain.PolicyRule
+}
+
+// NewPolicyUseCase creates a new policy of provided rules, which are evaluated
+// in the same order. Without rules any request is allowed.
+func NewPolicyUseCase(rules ...domain.PolicyRule) policy.UseCase {
+	return &policyUseCase{
+		rules: rules,
+	}
+}
+
+func (uc *policyUseCase) Evaluate(_ context.Context, req domain.PolicyRequest) (*domain.PolicyDecision, error) {
+	out := &domain.PolicyDecision{
+		Rule:     nil,
+		Scope:    req.Scope,
+		Stripped: make(domain.Scopes, 0),
+	}
+
+	for i := range uc.rules {
+		if !uc.rules[i].Matches(req) {
+			continue
+		}
+
+		out.Rule = &uc.rules[i]
+
+		break
+	}
+
+	if out.Rule == nil {
+		return out, nil
+	}
+
+	if out.IsDenied() {
+		return out, policy.ErrDenied
+	}
+
+	out.Scope = make(domain.Scopes, 0, len(req.Scope))
+
+	for _, s := range req.Scope {
+		if out.Rule.Strip.Has(s) {
+			out.Stripped = append(out.Stripped, s)
+
+			continue
+		}
+
+		out.Scope = append(out.Scope, s)
+	}
+
+	return out, nil
+}
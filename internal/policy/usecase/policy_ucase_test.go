@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+	ucase "source.toby3d.me/toby3d/auth/internal/policy/usecase"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	uc := ucase.NewPolicyUseCase(
+		domain.PolicyRule{
+			Name:   "block",
+			Action: domain.PolicyActionDeny,
+			Match:  domain.PolicyMatch{ClientHost: []string{"*.evil.example"}},
+		},
+		domain.PolicyRule{
+			Name:   "no-delete",
+			Action: domain.PolicyActionAllow,
+			Match:  domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeCreate}},
+			Strip:  domain.Scopes{domain.ScopeDelete},
+		},
+		domain.PolicyRule{
+			Name:        "shadowed",
+			Action:      domain.PolicyActionAllow,
+			Match:       domain.PolicyMatch{Scope: domain.Scopes{domain.ScopeDelete}},
+			RequirePKCE: true,
+		},
+	)
+
+	for name, tc := range map[string]struct {
+		clientID string
+		expError error
+		expRule  string
+		scope    domain.Scopes
+		expScope domain.Scopes
+	}{
+		"default": {
+			clientID: "https://127.0.0.1/",
+			scope:    domain.Scopes{domain.ScopeProfile},
+			expScope: domain.Scopes{domain.ScopeProfile},
+		},
+		"denied": {
+			clientID: "https://app.evil.example/",
+			scope:    domain.Scopes{domain.ScopeProfile},
+			expError: policy.ErrDenied,
+			expRule:  "block",
+			expScope: domain.Scopes{domain.ScopeProfile},
+		},
+		"stripped": {
+			clientID: "https://127.0.0.1/",
+			scope:    domain.Scopes{domain.ScopeCreate, domain.ScopeDelete},
+			expRule:  "no-delete",
+			expScope: domain.Scopes{domain.ScopeCreate},
+		},
+		"first match": {
+			clientID: "https://127.0.0.1/",
+			scope:    domain.Scopes{domain.ScopeDelete},
+			expRule:  "shadowed",
+			expScope: domain.Scopes{domain.ScopeDelete},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := domain.PolicyRequest{
+				ClientID:    *domain.TestClientID(t, tc.clientID),
+				RedirectURI: nil,
+				Me:          nil,
+				Scope:       tc.scope,
+			}
+
+			result, err := uc.Evaluate(context.Background(), req)
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Evaluate(%+v) = %v, want %v", req, err, tc.expError)
+			}
+
+			var rule string
+			if result.Rule != nil {
+				rule = result.Rule.Name
+			}
+
+			if rule != tc.expRule {
+				t.Errorf("Evaluate(%+v) matches '%s', want '%s'", req, rule, tc.expRule)
+			}
+
+			if result.Scope.String() != tc.expScope.String() {
+				t.Errorf("Evaluate(%+v) = %s, want %s", req, result.Scope, tc.expScope)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+	"source.toby3d.me/toby3d/auth/internal/urlutil"
+)
+
+type Handler struct {
+	registrations registration.UseCase
+	config        domain.Config
+}
+
+func NewHandler(registrations registration.UseCase, config domain.Config) *Handler {
+	return &Handler{
+		config:        config,
+		registrations: registrations,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.config.Registration.Enabled {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	var head string
+	head, r.URL.Path = urlutil.ShiftPath(r.URL.Path)
+
+	if head == "" {
+		h.handleRegister(w, r)
+
+		return
+	}
+
+	cid, err := domain.ParseClientID(head)
+	if err != nil || !cid.IsOpaque() {
+		h.writeError(w, http.StatusUnauthorized, registration.ErrInvalidToken)
+
+		return
+	}
+
+	switch r.Method {
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	case "", http.MethodGet:
+		h.handleRead(w, r, *cid)
+	case http.MethodPut:
+		h.handleUpdate(w, r, *cid)
+	case http.MethodDelete:
+		h.handleDelete(w, r, *cid)
+	}
+}
+
+func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	// NOTE(toby3d): registration can be limited to trusted developers by
+	// the initial access token.
+	if h.config.Registration.InitialAccessToken != "" && subtle.ConstantTimeCompare(
+		[]byte(bearerToken(r)), []byte(h.config.Registration.InitialAccessToken)) != 1 {
+		h.writeError(w, http.StatusUnauthorized, domain.NewError(domain.ErrorCodeInvalidToken,
+			"valid initial access token is required", "https://www.rfc-editor.org/rfc/rfc7591#section-3"))
+
+		return
+	}
+
+	req := NewRegistrationRequest()
+	if err := req.bind(r); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	client := domain.NewClient(domain.ClientID{})
+	if err := req.populate(client); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	result, creds, err := h.registrations.Register(r.Context(), *client)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	h.writeResponse(w, http.StatusCreated, result, creds)
+}
+
+func (h *Handler) handleRead(w http.ResponseWriter, r *http.Request, cid domain.ClientID) {
+	result, creds, err := h.registrations.Get(r.Context(), cid, bearerToken(r))
+	if err != nil {
+		h.writeError(w, http.StatusUnauthorized, err)
+
+		return
+	}
+
+	h.writeResponse(w, http.StatusOK, result, creds)
+}
+
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request, cid domain.ClientID) {
+	req := NewRegistrationRequest()
+	if err := req.bind(r); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if req.ClientID != cid.String() {
+		h.writeError(w, http.StatusBadRequest, domain.NewError(domain.ErrorCodeInvalidClientMetadata,
+			"client_id MUST match the client identifier in the request URI",
+			"https://www.rfc-editor.org/rfc/rfc7592#section-2.2"))
+
+		return
+	}
+
+	client := domain.NewClient(cid)
+	if err := req.populate(client); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	result, creds, err := h.registrations.Update(r.Context(), registration.UpdateOptions{
+		Client: *client,
+		ID:     cid,
+		Secret: req.ClientSecret,
+		Token:  bearerToken(r),
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, registration.ErrInvalidToken) {
+			status = http.StatusUnauthorized
+		}
+
+		h.writeError(w, status, err)
+
+		return
+	}
+
+	h.writeResponse(w, http.StatusOK, result, creds)
+}
+
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request, cid domain.ClientID) {
+	if err := h.registrations.Delete(r.Context(), cid, bearerToken(r)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeResponse(w http.ResponseWriter, status int, client *domain.Client,
+	creds *registration.Credentials,
+) {
+	registrationClientURI, _ := url.Parse(h.config.Server.GetRootURL())
+	registrationClientURI = registrationClientURI.JoinPath("register", client.ID.String())
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	w.Header().Set(common.HeaderPragma, "no-cache")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(NewRegistrationResponse(client, creds, registrationClientURI))
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, status int, err error) {
+	out, ok := asError(err)
+	if !ok {
+		status = http.StatusInternalServerError
+		out = domain.NewError(domain.ErrorCodeServerError, err.Error(), "")
+	}
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set(common.HeaderWWWAuthenticate, `Bearer error="`+out.Code.String()+`"`)
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get(common.HeaderAuthorization), "Bearer ")
+
+	return token
+}
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+)
+
+type (
+	// RegistrationRequest describes client metadata provided in the
+	// registration or update request.
+	//
+	//nolint:tagliatelle // RFC 7591 section 2
+	RegistrationRequest struct {
+		// The client identifier, used only in update requests.
+		ClientID string `json:"client_id,omitempty"`
+
+		// The client secret, used only in update requests. If present,
+		// it MUST match the currently issued one.
+		ClientSecret string `json:"client_secret,omitempty"`
+
+		ClientName              string   `json:"client_name,omitempty"`
+		ClientURI               string   `json:"client_uri,omitempty"`
+		LogoURI                 string   `json:"logo_uri,omitempty"`
+		JWKSURI                 string   `json:"jwks_uri,omitempty"`
+		Scope                   string   `json:"scope,omitempty"`
+		TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method,omitempty"`
+		RedirectURIs            []string `json:"redirect_uris"`
+		GrantTypes              []string `json:"grant_types,omitempty"`
+		ResponseTypes           []string `json:"response_types,omitempty"`
+		Contacts                []string `json:"contacts,omitempty"`
+	}
+
+	// RegistrationResponse describes the client information response.
+	//
+	//nolint:tagliatelle // RFC 7591 section 3.2.1
+	RegistrationResponse struct {
+		ClientSecretExpiresAt   *int64   `json:"client_secret_expires_at,omitempty"`
+		ClientID                string   `json:"client_id"`
+		ClientSecret            string   `json:"client_secret,omitempty"`
+		RegistrationAccessToken string   `json:"registration_access_token"`
+		RegistrationClientURI   string   `json:"registration_client_uri"`
+		ClientName              string   `json:"client_name,omitempty"`
+		ClientURI               string   `json:"client_uri,omitempty"`
+		LogoURI                 string   `json:"logo_uri,omitempty"`
+		JWKSURI                 string   `json:"jwks_uri,omitempty"`
+		Scope                   string   `json:"scope,omitempty"`
+		TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+		RedirectURIs            []string `json:"redirect_uris"`
+		GrantTypes              []string `json:"grant_types"`
+		ResponseTypes           []string `json:"response_types"`
+		Contacts                []string `json:"contacts,omitempty"`
+		ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+	}
+)
+
+func NewRegistrationRequest() *RegistrationRequest {
+	return &RegistrationRequest{
+		ClientID:                "",
+		ClientName:              "",
+		ClientSecret:            "",
+		ClientURI:               "",
+		Contacts:                make([]string, 0),
+		GrantTypes:              make([]string, 0),
+		JWKSURI:                 "",
+		LogoURI:                 "",
+		RedirectURIs:            make([]string, 0),
+		ResponseTypes:           make([]string, 0),
+		Scope:                   "",
+		TokenEndpointAuthMethod: "",
+	}
+}
+
+func (r *RegistrationRequest) bind(req *http.Request) error {
+	if err := json.NewDecoder(req.Body).Decode(r); err != nil {
+		return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "cannot decode client metadata: "+
+			err.Error(), "https://www.rfc-editor.org/rfc/rfc7591#section-3.1")
+	}
+
+	return nil
+}
+
+// populate converts request metadata into the client.
+//
+//nolint:cyclop
+func (r *RegistrationRequest) populate(dst *domain.Client) error {
+	var err error
+
+	dst.Name = r.ClientName
+	dst.Contacts = append(dst.Contacts, r.Contacts...)
+
+	if r.TokenEndpointAuthMethod != "" {
+		if dst.AuthMethod, err = domain.ParseClientAuthMethod(r.TokenEndpointAuthMethod); err != nil {
+			return err //nolint:wrapcheck // already a domain.Error
+		}
+	}
+
+	for _, v := range r.RedirectURIs {
+		u, err := url.Parse(v)
+		if err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidRedirectURI, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+
+		dst.RedirectURI = append(dst.RedirectURI, u)
+	}
+
+	for _, v := range r.GrantTypes {
+		grantType, err := domain.ParseGrantType(v)
+		if err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+
+		dst.GrantTypes = append(dst.GrantTypes, grantType)
+	}
+
+	for _, v := range r.ResponseTypes {
+		responseType, err := domain.ParseResponseType(v)
+		if err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+
+		dst.ResponseTypes = append(dst.ResponseTypes, responseType)
+	}
+
+	for _, v := range strings.Fields(r.Scope) {
+		scope, err := domain.ParseScope(v)
+		if err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+
+		dst.Scope = append(dst.Scope, scope)
+	}
+
+	if r.ClientURI != "" {
+		if dst.URL, err = url.Parse(r.ClientURI); err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	if r.LogoURI != "" {
+		if dst.Logo, err = url.Parse(r.LogoURI); err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	if r.JWKSURI != "" {
+		if dst.JWKSURI, err = url.Parse(r.JWKSURI); err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, err.Error(),
+				"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	return nil
+}
+
+func NewRegistrationResponse(c *domain.Client, creds *registration.Credentials,
+	registrationClientURI *url.URL,
+) *RegistrationResponse {
+	out := &RegistrationResponse{
+		ClientID:                c.ID.String(),
+		ClientIDIssuedAt:        c.CreatedAt.Unix(),
+		ClientName:              c.Name,
+		ClientSecret:            creds.Secret,
+		ClientSecretExpiresAt:   nil,
+		ClientURI:               "",
+		Contacts:                c.Contacts,
+		GrantTypes:              make([]string, len(c.GrantTypes)),
+		JWKSURI:                 "",
+		LogoURI:                 "",
+		RedirectURIs:            make([]string, len(c.RedirectURI)),
+		RegistrationAccessToken: creds.RegistrationToken,
+		RegistrationClientURI:   registrationClientURI.String(),
+		ResponseTypes:           make([]string, len(c.ResponseTypes)),
+		Scope:                   c.Scope.String(),
+		TokenEndpointAuthMethod: c.AuthMethod.String(),
+	}
+
+	// NOTE(toby3d): REQUIRED if client_secret is issued, 0 means no
+	// expiration.
+	if creds.Secret != "" {
+		var expiresAt int64
+		if !c.SecretExpiresAt.IsZero() {
+			expiresAt = c.SecretExpiresAt.Unix()
+		}
+
+		out.ClientSecretExpiresAt = &expiresAt
+	}
+
+	if c.URL != nil {
+		out.ClientURI = c.URL.String()
+	}
+
+	if c.Logo != nil {
+		out.LogoURI = c.Logo.String()
+	}
+
+	if c.JWKSURI != nil {
+		out.JWKSURI = c.JWKSURI.String()
+	}
+
+	for i := range c.RedirectURI {
+		out.RedirectURIs[i] = c.RedirectURI[i].String()
+	}
+
+	for i := range c.GrantTypes {
+		out.GrantTypes[i] = c.GrantTypes[i].String()
+	}
+
+	for i := range c.ResponseTypes {
+		out.ResponseTypes[i] = c.ResponseTypes[i].String()
+	}
+
+	return out
+}
+
+// asError returns err as *domain.Error, if possible.
+func asError(err error) (*domain.Error, bool) {
+	var target *domain.Error
+
+	return target, errors.As(err, &target)
+}
@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	clientmemoryrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+	delivery "source.toby3d.me/toby3d/auth/internal/registration/delivery/http"
+	ucase "source.toby3d.me/toby3d/auth/internal/registration/usecase"
+)
+
+type Dependencies struct {
+	clients       client.Repository
+	registrations registration.UseCase
+	config        *domain.Config
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	handler := delivery.NewHandler(deps.registrations, *deps.config)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(`{
+		"redirect_uris": ["https://app.example.com/callback"],
+		"client_name": "Example App",
+		"token_endpoint_auth_method": "client_secret_post"
+	}`))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationJSON)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusCreated)
+	}
+
+	result := new(delivery.RegistrationResponse)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ClientID == "" || result.ClientSecret == "" || result.RegistrationAccessToken == "" {
+		t.Errorf("%s %s = %+v, want issued credentials", req.Method, req.RequestURI, result)
+	}
+
+	cid, err := domain.ParseClientID(result.ClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = deps.clients.Get(req.Context(), *cid); err != nil {
+		t.Errorf("%s %s = %v, want stored client", req.Method, req.RequestURI, err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/"+result.ClientID, nil)
+	req.Header.Set(common.HeaderAuthorization, "Bearer "+result.RegistrationAccessToken)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if resp = w.Result(); resp.StatusCode != http.StatusOK {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusOK)
+	}
+
+	// NOTE(toby3d): registration access token is rotated after each read.
+	req = httptest.NewRequest(http.MethodDelete, "https://example.com/"+result.ClientID, nil)
+	req.Header.Set(common.HeaderAuthorization, "Bearer "+result.RegistrationAccessToken)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if resp = w.Result(); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestHandler_ServeHTTP_InvalidRedirectURI(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(`{
+		"redirect_uris": ["http://app.example.com/callback"]
+	}`))
+	w := httptest.NewRecorder()
+
+	delivery.NewHandler(deps.registrations, *deps.config).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusBadRequest)
+	}
+
+	result := make(map[string]string)
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := domain.ErrorCodeInvalidRedirectURI.String(); result["error"] != exp {
+		t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI, result["error"], exp)
+	}
+}
+
+func TestHandler_ServeHTTP_InitialAccessToken(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.Registration.InitialAccessToken = "hackme"
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(`{
+		"redirect_uris": ["https://app.example.com/callback"]
+	}`))
+	w := httptest.NewRecorder()
+
+	delivery.NewHandler(deps.registrations, *deps.config).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	if resp.Header.Get(common.HeaderWWWAuthenticate) == "" {
+		t.Errorf("%s %s = %s, want not empty", req.Method, req.RequestURI, common.HeaderWWWAuthenticate)
+	}
+}
+
+func NewDependencies(tb testing.TB) Dependencies {
+	tb.Helper()
+
+	config := domain.TestConfig(tb)
+	clients := clientmemoryrepo.NewMemoryClientRepository()
+
+	return Dependencies{
+		clients:       clients,
+		config:        config,
+		registrations: ucase.NewRegistrationUseCase(clients, nil, *config),
+	}
+}
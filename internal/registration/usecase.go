@@ -0,0 +1,66 @@
+package registration
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	// Credentials contains plain secrets issued to the registered client.
+	// Server stores only hashes of them, so they must be returned to the
+	// client immediately.
+	Credentials struct {
+		// Issued client secret, if any. Empty if client is public or
+		// secret was not rotated.
+		Secret string
+
+		// Issued registration access token for the client configuration
+		// endpoint. Each time token is issued previous one is revoked.
+		RegistrationToken string
+	}
+
+	UpdateOptions struct {
+		// New metadata of the registered client.
+		Client domain.Client
+
+		ID domain.ClientID
+
+		// Registration access token of the client.
+		Token string
+
+		// Current client secret, if provided by the client. It MUST
+		// match the issued one.
+		Secret string
+	}
+
+	UseCase interface {
+		// Register validates metadata of the new client, issues
+		// client_id with credentials and stores it in the registry.
+		Register(ctx context.Context, client domain.Client) (*domain.Client, *Credentials, error)
+
+		// Get returns registered client by its ID and registration
+		// access token.
+		Get(ctx context.Context, cid domain.ClientID, token string) (*domain.Client, *Credentials, error)
+
+		// Update replaces metadata of the registered client with
+		// provided one.
+		Update(ctx context.Context, opts UpdateOptions) (*domain.Client, *Credentials, error)
+
+		// Delete removes registered client from the registry.
+		Delete(ctx context.Context, cid domain.ClientID, token string) error
+	}
+)
+
+var (
+	ErrInvalidToken error = domain.NewError(
+		domain.ErrorCodeInvalidToken,
+		"registration access token is invalid or client does not exist",
+		"https://www.rfc-editor.org/rfc/rfc7592#section-2",
+	)
+	ErrInvalidSecret error = domain.NewError(
+		domain.ErrorCodeInvalidClientMetadata,
+		"client_secret does not match the issued one",
+		"https://www.rfc-editor.org/rfc/rfc7592#section-2.2",
+	)
+)
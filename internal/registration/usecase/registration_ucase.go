@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"inet.af/netaddr"
+
+	"source.toby3d.me/toby3d/auth/internal/client"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/random"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+)
+
+type registrationUseCase struct {
+	clients client.Repository
+	clock   domain.Clock
+	config  domain.Config
+}
+
+const (
+	clientIDLength          uint8 = 32
+	clientSecretLength      uint8 = 48
+	registrationTokenLength uint8 = 64
+)
+
+//nolint:gochecknoglobals // slices cannot be constants
+var (
+	supportedGrantTypes    = []domain.GrantType{domain.GrantTypeAuthorizationCode, domain.GrantTypeRefreshToken}
+	supportedResponseTypes = []domain.ResponseType{domain.ResponseTypeCode}
+)
+
+// NewRegistrationUseCase creates a new dynamic client registration use case
+// which stores clients in the provided registry. System clock is used if clock
+// is nil.
+func NewRegistrationUseCase(clients client.Repository, clock domain.Clock, config domain.Config,
+) registration.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
+	return &registrationUseCase{
+		clients: clients,
+		clock:   clock,
+		config:  config,
+	}
+}
+
+func (uc *registrationUseCase) Register(ctx context.Context, c domain.Client) (*domain.Client,
+	*registration.Credentials, error,
+) {
+	if err := validate(&c); err != nil {
+		return nil, nil, err
+	}
+
+	id, err := random.String(clientIDLength, random.Alphanumeric)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate client_id: %w", err)
+	}
+
+	cid, err := domain.ParseClientID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse generated client_id: %w", err)
+	}
+
+	c.ID = *cid
+	c.CreatedAt = uc.clock.Now()
+	c.Secret = ""
+	creds := new(registration.Credentials)
+
+	if err = uc.issue(&c, creds); err != nil {
+		return nil, nil, err
+	}
+
+	if err = uc.clients.Create(ctx, c); err != nil {
+		return nil, nil, fmt.Errorf("cannot store registered client: %w", err)
+	}
+
+	return &c, creds, nil
+}
+
+func (uc *registrationUseCase) Get(ctx context.Context, cid domain.ClientID, token string) (*domain.Client,
+	*registration.Credentials, error,
+) {
+	c, err := uc.verify(ctx, cid, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// NOTE(toby3d): server stores only hash of the registration access
+	// token, so rotate it to return in the client information response.
+	creds := new(registration.Credentials)
+	if err = uc.issue(c, creds); err != nil {
+		return nil, nil, err
+	}
+
+	if err = uc.clients.Update(ctx, *c); err != nil {
+		return nil, nil, fmt.Errorf("cannot update registered client: %w", err)
+	}
+
+	return c, creds, nil
+}
+
+func (uc *registrationUseCase) Update(ctx context.Context, opts registration.UpdateOptions) (*domain.Client,
+	*registration.Credentials, error,
+) {
+	current, err := uc.verify(ctx, opts.ID, opts.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Secret != "" && !current.VerifySecret(opts.Secret, uc.clock.Now()) {
+		return nil, nil, registration.ErrInvalidSecret
+	}
+
+	c := opts.Client
+	if err = validate(&c); err != nil {
+		return nil, nil, err
+	}
+
+	c.ID = current.ID
+	c.CreatedAt = current.CreatedAt
+	c.Secret = current.Secret
+	c.SecretExpiresAt = current.SecretExpiresAt
+	creds := new(registration.Credentials)
+
+	if err = uc.issue(&c, creds); err != nil {
+		return nil, nil, err
+	}
+
+	if err = uc.clients.Update(ctx, c); err != nil {
+		return nil, nil, fmt.Errorf("cannot update registered client: %w", err)
+	}
+
+	return &c, creds, nil
+}
+
+func (uc *registrationUseCase) Delete(ctx context.Context, cid domain.ClientID, token string) error {
+	if _, err := uc.verify(ctx, cid, token); err != nil {
+		return err
+	}
+
+	if err := uc.clients.Delete(ctx, cid); err != nil {
+		return fmt.Errorf("cannot delete registered client: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *registrationUseCase) verify(ctx context.Context, cid domain.ClientID, token string) (*domain.Client,
+	error,
+) {
+	if !cid.IsOpaque() {
+		return nil, registration.ErrInvalidToken
+	}
+
+	c, err := uc.clients.Get(ctx, cid)
+	if err != nil {
+		if errors.Is(err, client.ErrNotExist) {
+			return nil, registration.ErrInvalidToken
+		}
+
+		return nil, fmt.Errorf("cannot find registered client: %w", err)
+	}
+
+	if !c.VerifyRegistrationToken(token) {
+		return nil, registration.ErrInvalidToken
+	}
+
+	return c, nil
+}
+
+// issue generates a new registration access token and a client secret, if
+// client requires it and does not have one yet, storing their hashes in c and
+// plain values in creds.
+func (uc *registrationUseCase) issue(c *domain.Client, creds *registration.Credentials) error {
+	var err error
+
+	if creds.RegistrationToken, err = random.String(registrationTokenLength, random.Alphanumeric); err != nil {
+		return fmt.Errorf("cannot generate registration access token: %w", err)
+	}
+
+	c.RegistrationToken = domain.HashSecret(creds.RegistrationToken)
+
+	// NOTE(toby3d): public and private_key_jwt clients do not use a secret.
+	if c.AuthMethod == domain.ClientAuthMethodNone || c.AuthMethod == domain.ClientAuthMethodPrivateKeyJWT {
+		c.Secret = ""
+		c.SecretExpiresAt = time.Time{}
+
+		return nil
+	}
+
+	if c.Secret != "" {
+		return nil
+	}
+
+	if creds.Secret, err = random.String(clientSecretLength, random.Alphanumeric); err != nil {
+		return fmt.Errorf("cannot generate client secret: %w", err)
+	}
+
+	c.Secret = domain.HashSecret(creds.Secret)
+
+	if uc.config.Registration.SecretExpiry > 0 {
+		c.SecretExpiresAt = uc.clock.Now().Add(uc.config.Registration.SecretExpiry)
+	}
+
+	return nil
+}
+
+// validate checks client metadata and fills omitted values with defaults
+// described in RFC 7591 section 2.
+//
+//nolint:cyclop
+func validate(c *domain.Client) error {
+	if c.AuthMethod == domain.ClientAuthMethodUnd {
+		c.AuthMethod = domain.ClientAuthMethodClientSecretBasic
+	}
+
+	if len(c.GrantTypes) == 0 {
+		c.GrantTypes = []domain.GrantType{domain.GrantTypeAuthorizationCode}
+	}
+
+	if len(c.ResponseTypes) == 0 {
+		c.ResponseTypes = []domain.ResponseType{domain.ResponseTypeCode}
+	}
+
+	for i := range c.GrantTypes {
+		if !slices.Contains(supportedGrantTypes, c.GrantTypes[i]) {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "unsupported grant type '"+
+				c.GrantTypes[i].String()+"'", "https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	for i := range c.ResponseTypes {
+		if !slices.Contains(supportedResponseTypes, c.ResponseTypes[i]) {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "unsupported response type '"+
+				c.ResponseTypes[i].String()+"'", "https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	if slices.Contains(c.GrantTypes, domain.GrantTypeAuthorizationCode) !=
+		slices.Contains(c.ResponseTypes, domain.ResponseTypeCode) {
+		return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "'authorization_code' grant type "+
+			"requires 'code' response type and vice versa", "https://www.rfc-editor.org/rfc/rfc7591#section-2.1")
+	}
+
+	if len(c.RedirectURI) == 0 {
+		return domain.NewError(domain.ErrorCodeInvalidRedirectURI, "at least one redirect URI is required",
+			"https://www.rfc-editor.org/rfc/rfc7591#section-2")
+	}
+
+	for i := range c.RedirectURI {
+		if err := validateRedirectURI(c.RedirectURI[i]); err != nil {
+			return err
+		}
+	}
+
+	if c.AuthMethod == domain.ClientAuthMethodPrivateKeyJWT &&
+		(c.JWKSURI == nil || !c.JWKSURI.IsAbs() || c.JWKSURI.Scheme != "https") {
+		return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "'private_key_jwt' authentication "+
+			"method requires HTTPS 'jwks_uri'", "https://www.rfc-editor.org/rfc/rfc7591#section-2")
+	}
+
+	for _, u := range []*url.URL{c.URL, c.Logo} {
+		if u != nil && u.Scheme != "https" && u.Scheme != "http" {
+			return domain.NewError(domain.ErrorCodeInvalidClientMetadata, "client and logo URIs MUST use "+
+				"HTTP scheme", "https://www.rfc-editor.org/rfc/rfc7591#section-2")
+		}
+	}
+
+	return nil
+}
+
+// validateRedirectURI checks that redirect URI is an absolute URL without
+// fragment, which uses https scheme, private-use scheme of the native
+// application, or http scheme on the loopback interface only.
+func validateRedirectURI(u *url.URL) error {
+	if u == nil || !u.IsAbs() || (u.Host == "" && !domain.IsNativeRedirectURI(u)) {
+		return domain.NewError(domain.ErrorCodeInvalidRedirectURI, "redirect URI MUST be an absolute URL",
+			"https://www.rfc-editor.org/rfc/rfc6749#section-3.1.2")
+	}
+
+	if u.Fragment != "" {
+		return domain.NewError(domain.ErrorCodeInvalidRedirectURI, "redirect URI MUST NOT include a "+
+			"fragment component", "https://www.rfc-editor.org/rfc/rfc6749#section-3.1.2")
+	}
+
+	// NOTE(toby3d): RFC 8252 section 7.1: native applications can use
+	// private-use URI schemes.
+	if domain.IsNativeRedirectURI(u) && u.Scheme != "http" {
+		return nil
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if ip, err := netaddr.ParseIP(u.Hostname()); err == nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+
+	return domain.NewError(domain.ErrorCodeInvalidRedirectURI, "redirect URI MUST use https scheme, or http "+
+		"scheme on the loopback interface", "https://www.rfc-editor.org/rfc/rfc8252#section-8.3")
+}
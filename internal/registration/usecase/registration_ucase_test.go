@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	clientmemoryrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+	ucase "source.toby3d.me/toby3d/auth/internal/registration/usecase"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	clients := clientmemoryrepo.NewMemoryClientRepository()
+	registrations := ucase.NewRegistrationUseCase(clients, nil, *config)
+
+	result, creds, err := registrations.Register(context.Background(), *domain.TestClient(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.ID.IsOpaque() {
+		t.Errorf("Register() = %s, want opaque client_id", result.ID)
+	}
+
+	if creds.Secret == "" || !result.VerifySecret(creds.Secret, time.Now()) {
+		t.Errorf("Register() = %+v, want confidential client", creds)
+	}
+
+	stored, err := clients.Get(context.Background(), result.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !stored.VerifyRegistrationToken(creds.RegistrationToken) {
+		t.Errorf("Register() = %+v, want stored registration access token", creds)
+	}
+
+	if _, _, err = registrations.Get(context.Background(), result.ID, "hackme"); !errors.Is(err,
+		registration.ErrInvalidToken) {
+		t.Errorf("Get(%s, %s) = %v, want %v", result.ID, "hackme", err, registration.ErrInvalidToken)
+	}
+
+	if err = registrations.Delete(context.Background(), result.ID, creds.RegistrationToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = clients.Get(context.Background(), result.ID); err == nil {
+		t.Errorf("Get(%s) = %v, want error", result.ID, err)
+	}
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	registrations := ucase.NewRegistrationUseCase(clientmemoryrepo.NewMemoryClientRepository(), nil, *config)
+
+	for name, redirectURI := range map[string]string{
+		"http":     "http://app.example.com/callback",
+		"relative": "/callback",
+		"fragment": "https://app.example.com/callback#top",
+		"scheme":   "myapp:/callback",
+	} {
+		name, redirectURI := name, redirectURI
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := domain.TestClient(t)
+			client.RedirectURI = make([]*url.URL, 1)
+			client.RedirectURI[0], _ = url.Parse(redirectURI)
+
+			_, _, err := registrations.Register(context.Background(), *client)
+
+			var target *domain.Error
+			if !errors.As(err, &target) || target.Code != domain.ErrorCodeInvalidRedirectURI {
+				t.Errorf("Register(%s) = %v, want %s", redirectURI, err, domain.ErrorCodeInvalidRedirectURI)
+			}
+		})
+	}
+}
+
+func TestRegister_PrivateKeyJWT(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	registrations := ucase.NewRegistrationUseCase(clientmemoryrepo.NewMemoryClientRepository(), nil, *config)
+
+	client := domain.TestClient(t)
+	client.AuthMethod = domain.ClientAuthMethodPrivateKeyJWT
+
+	_, _, err := registrations.Register(context.Background(), *client)
+
+	var target *domain.Error
+	if !errors.As(err, &target) || target.Code != domain.ErrorCodeInvalidClientMetadata {
+		t.Errorf("Register(%s) = %v, want %s", client.AuthMethod, err, domain.ErrorCodeInvalidClientMetadata)
+	}
+
+	client.JWKSURI = &url.URL{Scheme: "https", Host: "app.example.com", Path: "/jwks.json"}
+
+	result, creds, err := registrations.Register(context.Background(), *client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if creds.Secret != "" || result.Secret != "" {
+		t.Errorf("Register(%s) = %+v, want empty client secret", client.AuthMethod, creds)
+	}
+}
@@ -0,0 +1,21 @@
+package replay
+
+import (
+	"context"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Create remembers the identifier of the one-time JWT, like client
+	// assertion or DPoP proof, until provided expiry. Identifier which is
+	// already remembered returns ErrExist.
+	Create(ctx context.Context, id string, expiry time.Time) error
+}
+
+var ErrExist error = domain.NewError(
+	domain.ErrorCodeInvalidRequest,
+	"JWT with the specified ID is already used",
+	"https://www.rfc-editor.org/rfc/rfc7519#section-4.1.7",
+)
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/replay"
+)
+
+type memoryReplayRepository struct {
+	mutex *sync.Mutex
+	ids   map[string]time.Time
+}
+
+// NewMemoryReplayRepository creates a new repository of used JWT identifiers.
+// Expired identifiers are collected on every insert, so the repository
+// keeps only the identifiers which can be replayed.
+func NewMemoryReplayRepository() replay.Repository {
+	return &memoryReplayRepository{
+		mutex: new(sync.Mutex),
+		ids:   make(map[string]time.Time),
+	}
+}
+
+func (repo *memoryReplayRepository) Create(_ context.Context, id string, expiry time.Time) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	now := time.Now().UTC()
+
+	for key, val := range repo.ids {
+		if now.After(val) {
+			delete(repo.ids, key)
+		}
+	}
+
+	if _, ok := repo.ids[id]; ok {
+		return replay.ErrExist
+	}
+
+	repo.ids[id] = expiry
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	repository "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+)
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	repo := repository.NewMemoryReplayRepository()
+	now := time.Now().UTC()
+
+	if err := repo.Create(context.Background(), "a1b2c3", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Create(context.Background(), "a1b2c3", now.Add(time.Minute)); !errors.Is(err, replay.ErrExist) {
+		t.Errorf("Create(%s) = %v, want %v", "a1b2c3", err, replay.ErrExist)
+	}
+
+	// NOTE(toby3d): expired JWT is rejected by its exp claim, so its
+	// identifier is forgotten.
+	if err := repo.Create(context.Background(), "d4e5f6", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Create(context.Background(), "d4e5f6", now.Add(time.Minute)); err != nil {
+		t.Errorf("Create(%s) = %v, want %v", "d4e5f6", err, nil)
+	}
+}
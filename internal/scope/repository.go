@@ -0,0 +1,14 @@
+package scope
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Fetch returns all configured scope definitions.
+	Fetch(ctx context.Context) ([]domain.ScopeDefinition, error)
+}
+
+var ErrInvalid error = domain.NewError(domain.ErrorCodeServerError, "invalid scope configuration", "")
@@ -0,0 +1,112 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-json"
+	"golang.org/x/text/language"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+)
+
+type (
+	Scope struct {
+		Title       map[string]string `json:"title,omitempty"`
+		Description map[string]string `json:"description,omitempty"`
+		ID          string            `json:"id"`
+		Sensitivity string            `json:"sensitivity,omitempty"`
+		Implies     []string          `json:"implies,omitempty"`
+	}
+
+	fileScopeRepository struct {
+		path string
+	}
+)
+
+// NewFileScopeRepository creates a new scopes repository which reads the JSON
+// array of scope definitions from the provided file path.
+func NewFileScopeRepository(path string) scope.Repository {
+	return &fileScopeRepository{
+		path: path,
+	}
+}
+
+func (repo *fileScopeRepository) Fetch(_ context.Context) ([]domain.ScopeDefinition, error) {
+	src, err := os.ReadFile(repo.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read scopes file: %w", err)
+	}
+
+	in := make([]Scope, 0)
+	if err = json.Unmarshal(src, &in); err != nil {
+		return nil, fmt.Errorf("cannot decode scopes file: %w", err)
+	}
+
+	out := make([]domain.ScopeDefinition, 0, len(in))
+	ids := make(map[domain.Scope]struct{}, len(in))
+
+	for i := range in {
+		def, err := in[i].populate()
+		if err != nil {
+			return nil, fmt.Errorf("%w: scope #%d: %w", scope.ErrInvalid, i, err)
+		}
+
+		if _, ok := ids[def.Scope]; ok {
+			return nil, fmt.Errorf("%w: duplicated scope '%s'", scope.ErrInvalid, def.Scope)
+		}
+
+		ids[def.Scope] = struct{}{}
+		out = append(out, def)
+	}
+
+	return out, nil
+}
+
+func (s Scope) populate() (domain.ScopeDefinition, error) {
+	id, err := domain.ParseScope(s.ID)
+	if err != nil {
+		return domain.ScopeDefinition{}, fmt.Errorf("cannot parse id: %w", err)
+	}
+
+	out := domain.NewScopeDefinition(id)
+
+	if s.Sensitivity != "" {
+		if out.Sensitivity, err = domain.ParseScopeSensitivity(s.Sensitivity); err != nil {
+			return out, fmt.Errorf("cannot parse sensitivity: %w", err)
+		}
+	}
+
+	for _, raw := range s.Implies {
+		implied, err := domain.ParseScope(raw)
+		if err != nil {
+			return out, fmt.Errorf("cannot parse implied scope: %w", err)
+		}
+
+		out.Implies = append(out.Implies, implied)
+	}
+
+	for dst, src := range map[*domain.ScopeText]map[string]string{
+		&out.Title:       s.Title,
+		&out.Description: s.Description,
+	} {
+		if len(src) == 0 {
+			continue
+		}
+
+		*dst = make(domain.ScopeText, len(src))
+
+		for rawTag, text := range src {
+			tag, err := language.Parse(rawTag)
+			if err != nil {
+				return out, fmt.Errorf("cannot parse language '%s': %w", rawTag, err)
+			}
+
+			(*dst)[tag] = text
+		}
+	}
+
+	return out, nil
+}
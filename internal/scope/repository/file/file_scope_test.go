@@ -0,0 +1,75 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+	repository "source.toby3d.me/toby3d/auth/internal/scope/repository/file"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input    string
+		expError error
+		expCount int
+	}{
+		"valid": {
+			input: `[
+				{"id": "gallery", "sensitivity": "high", "implies": ["media"],
+				 "title": {"en": "Gallery", "ru": "Галерея"}, "description": {"en": "Manage your photos."}},
+				{"id": "profile"}
+			]`,
+			expCount: 2,
+		},
+		"invalid id": {
+			input:    `[{"id": "with space"}]`,
+			expError: scope.ErrInvalid,
+		},
+		"unknown sensitivity": {
+			input:    `[{"id": "gallery", "sensitivity": "extreme"}]`,
+			expError: scope.ErrInvalid,
+		},
+		"duplicated": {
+			input:    `[{"id": "gallery"}, {"id": "gallery"}]`,
+			expError: scope.ErrInvalid,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "scopes.json")
+			if err := os.WriteFile(path, []byte(tc.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := repository.NewFileScopeRepository(path).Fetch(context.Background())
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Fetch() = %v, want %v", err, tc.expError)
+			}
+
+			if len(result) != tc.expCount {
+				t.Errorf("Fetch() = %d scopes, want %d", len(result), tc.expCount)
+			}
+
+			if tc.expCount == 0 {
+				return
+			}
+
+			if title, _ := result[0].Title.Get(language.Russian); title != "Галерея" ||
+				result[0].Sensitivity != domain.ScopeSensitivityHigh || !result[0].Implies.Has(domain.ScopeMedia) {
+				t.Errorf("Fetch() = %+v, want parsed definition", result[0])
+			}
+		})
+	}
+}
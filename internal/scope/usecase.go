@@ -0,0 +1,20 @@
+package scope
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type UseCase interface {
+	// Resolve validates requested scopes by the unknown scopes policy and
+	// returns them along with all implied scopes.
+	Resolve(ctx context.Context, scopes domain.Scopes) (domain.Scopes, error)
+
+	// Describe returns definitions of provided scopes in the same order.
+	// Scopes which are not defined are described by themselves.
+	Describe(ctx context.Context, scopes domain.Scopes) []domain.ScopeDefinition
+
+	// Supported returns all defined scopes.
+	Supported(ctx context.Context) domain.Scopes
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+)
+
+type scopeUseCase struct {
+	definitions map[domain.Scope]domain.ScopeDefinition
+	policy      domain.ScopePolicy
+	supported   domain.Scopes
+}
+
+// NewScopeUseCase creates a new scopes registry of the well-known scopes and
+// provided definitions, which override the well-known ones with the same
+// identifier. Scopes which are not defined are handled by policy.
+func NewScopeUseCase(policy domain.ScopePolicy, definitions ...domain.ScopeDefinition) scope.UseCase {
+	uc := &scopeUseCase{
+		definitions: make(map[domain.Scope]domain.ScopeDefinition),
+		policy:      policy,
+		supported:   make(domain.Scopes, 0),
+	}
+
+	for _, def := range append(domain.DefaultScopeDefinitions(), definitions...) {
+		if _, ok := uc.definitions[def.Scope]; !ok {
+			uc.supported = append(uc.supported, def.Scope)
+		}
+
+		uc.definitions[def.Scope] = def
+	}
+
+	return uc
+}
+
+func (uc *scopeUseCase) Resolve(_ context.Context, scopes domain.Scopes) (domain.Scopes, error) {
+	out := make(domain.Scopes, 0, len(scopes))
+
+	for _, s := range scopes {
+		if _, ok := uc.definitions[s]; !ok && uc.policy != domain.ScopePolicyAllow {
+			return nil, fmt.Errorf("%w: %s", domain.ErrScopeUnknown, s)
+		}
+
+		out = uc.imply(out, s)
+	}
+
+	return out, nil
+}
+
+func (uc *scopeUseCase) Describe(_ context.Context, scopes domain.Scopes) []domain.ScopeDefinition {
+	out := make([]domain.ScopeDefinition, 0, len(scopes))
+
+	for _, s := range scopes {
+		def, ok := uc.definitions[s]
+		if !ok {
+			def = domain.NewScopeDefinition(s)
+		}
+
+		out = append(out, def)
+	}
+
+	return out
+}
+
+func (uc *scopeUseCase) Supported(_ context.Context) domain.Scopes {
+	return append(make(domain.Scopes, 0, len(uc.supported)), uc.supported...)
+}
+
+// imply appends scope and all scopes implied by it recursively, if they are
+// not appended before.
+func (uc *scopeUseCase) imply(dst domain.Scopes, s domain.Scope) domain.Scopes {
+	if dst.Has(s) {
+		return dst
+	}
+
+	dst = append(dst, s)
+
+	for _, implied := range uc.definitions[s].Implies {
+		dst = uc.imply(dst, implied)
+	}
+
+	return dst
+}
@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/scope/usecase"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	custom, _ := domain.ParseScope("https://example.com/gallery")
+	unknown, _ := domain.ParseScope("unknown")
+
+	def := domain.NewScopeDefinition(custom)
+	def.Implies = domain.Scopes{domain.ScopeMedia}
+
+	for name, tc := range map[string]struct {
+		policy    domain.ScopePolicy
+		input     domain.Scopes
+		expResult domain.Scopes
+		expError  error
+	}{
+		"well-known": {
+			policy:    domain.ScopePolicyReject,
+			input:     domain.Scopes{domain.ScopeCreate, domain.ScopeUpdate},
+			expResult: domain.Scopes{domain.ScopeCreate, domain.ScopeUpdate},
+		},
+		"implied": {
+			policy:    domain.ScopePolicyReject,
+			input:     domain.Scopes{domain.ScopeEmail},
+			expResult: domain.Scopes{domain.ScopeEmail, domain.ScopeProfile},
+		},
+		"custom": {
+			policy:    domain.ScopePolicyReject,
+			input:     domain.Scopes{custom, domain.ScopeMedia},
+			expResult: domain.Scopes{custom, domain.ScopeMedia},
+		},
+		"rejected": {
+			policy:   domain.ScopePolicyReject,
+			input:    domain.Scopes{domain.ScopeProfile, unknown},
+			expError: domain.ErrScopeUnknown,
+		},
+		"allowed": {
+			policy:    domain.ScopePolicyAllow,
+			input:     domain.Scopes{unknown},
+			expResult: domain.Scopes{unknown},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := usecase.NewScopeUseCase(tc.policy, def).Resolve(context.Background(), tc.input)
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Resolve(%s) = %v, want %v", tc.input, err, tc.expError)
+			}
+
+			if tc.expError == nil && !reflect.DeepEqual(result, tc.expResult) {
+				t.Errorf("Resolve(%s) = %s, want %s", tc.input, result, tc.expResult)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	t.Parallel()
+
+	custom, _ := domain.ParseScope("gallery")
+	override := domain.NewScopeDefinition(domain.ScopeProfile)
+
+	scopes := usecase.NewScopeUseCase(domain.ScopePolicyReject, override, domain.NewScopeDefinition(custom))
+
+	result := scopes.Supported(context.Background())
+	for _, s := range []domain.Scope{domain.ScopeProfile, domain.ScopeUndelete, custom} {
+		if !result.Has(s) {
+			t.Errorf("Supported() = %s, want %s", result, s)
+		}
+	}
+
+	if len(result) != len(domain.DefaultScopeDefinitions())+1 {
+		t.Errorf("Supported() = %s, want well-known and custom scopes only once", result)
+	}
+
+	if defs := scopes.Describe(context.Background(), domain.Scopes{domain.ScopeProfile}); !reflect.DeepEqual(
+		defs[0], override) {
+		t.Errorf("Describe(%s) = %+v, want %+v", domain.ScopeProfile, defs[0], override)
+	}
+}
@@ -10,7 +10,43 @@ type Repository interface {
 	Get(ctx context.Context, code string) (*domain.Session, error)
 	Create(ctx context.Context, session domain.Session) error
 	GetAndDelete(ctx context.Context, code string) (*domain.Session, error)
-	GC()
+
+	// CreateRedemption records the exchange of the authorization code.
+	// Repeated record of the same redemption returns ErrRedeemed.
+	CreateRedemption(ctx context.Context, redemption domain.Redemption) error
+	GetRedemption(ctx context.Context, id string) (*domain.Redemption, error)
+	UpdateRedemption(ctx context.Context, redemption domain.Redemption) error
+
+	// FetchRedemptions returns all recorded redemptions of codes issued
+	// to the client.
+	FetchRedemptions(ctx context.Context, clientID domain.ClientID) ([]domain.Redemption, error)
+
+	// GC removes expired sessions and redemptions until context is
+	// canceled.
+	GC(ctx context.Context)
 }
 
-var ErrNotExist error = domain.NewError(domain.ErrorCodeServerError, "session with this code not exist", "")
+// PushedPrefix is the key namespace of the authorization requests pushed by
+// the clients. Authorization codes are never stored in it, so request_uri
+// cannot be exchanged as the code.
+const PushedPrefix string = "pushed:"
+
+var (
+	ErrNotExist error = domain.NewError(domain.ErrorCodeServerError, "session with this code not exist", "")
+
+	ErrRedemptionNotExist error = domain.NewError(
+		domain.ErrorCodeServerError,
+		"redemption of this code not exist",
+		"",
+	)
+	ErrRedeemed error = domain.NewError(
+		domain.ErrorCodeInvalidGrant,
+		"authorization code has already been redeemed, all tokens issued based on it are revoked",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-4.1.2",
+	)
+	ErrExpired error = domain.NewError(
+		domain.ErrorCodeInvalidGrant,
+		"authorization code is expired",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-4.1.2",
+	)
+)
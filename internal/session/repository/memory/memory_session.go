@@ -17,17 +17,19 @@ type (
 	}
 
 	memorySessionRepository struct {
-		mutex    *sync.RWMutex
-		sessions map[string]Session
-		config   domain.Config
+		mutex       *sync.RWMutex
+		sessions    map[string]Session
+		redemptions map[string]domain.Redemption
+		config      domain.Config
 	}
 )
 
 func NewMemorySessionRepository(config domain.Config) session.Repository {
 	return &memorySessionRepository{
-		config:   config,
-		mutex:    new(sync.RWMutex),
-		sessions: make(map[string]Session),
+		config:      config,
+		mutex:       new(sync.RWMutex),
+		sessions:    make(map[string]Session),
+		redemptions: make(map[string]domain.Redemption),
 	}
 }
 
@@ -68,17 +70,76 @@ func (repo *memorySessionRepository) GetAndDelete(ctx context.Context, code stri
 	return s, nil
 }
 
-func (repo *memorySessionRepository) GC() {
+func (repo *memorySessionRepository) CreateRedemption(_ context.Context, r domain.Redemption) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.redemptions[r.ID]; ok {
+		return session.ErrRedeemed
+	}
+
+	repo.redemptions[r.ID] = r
+
+	return nil
+}
+
+func (repo *memorySessionRepository) GetRedemption(_ context.Context, id string) (*domain.Redemption, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	if r, ok := repo.redemptions[id]; ok {
+		return &r, nil
+	}
+
+	return nil, session.ErrRedemptionNotExist
+}
+
+func (repo *memorySessionRepository) UpdateRedemption(_ context.Context, r domain.Redemption) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.redemptions[r.ID]; !ok {
+		return session.ErrRedemptionNotExist
+	}
+
+	repo.redemptions[r.ID] = r
+
+	return nil
+}
+
+func (repo *memorySessionRepository) FetchRedemptions(_ context.Context, cid domain.ClientID,
+) ([]domain.Redemption, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	out := make([]domain.Redemption, 0)
+
+	for _, r := range repo.redemptions {
+		if r.ClientID.IsEqual(cid) {
+			out = append(out, r)
+		}
+	}
+
+	return out, nil
+}
+
+func (repo *memorySessionRepository) GC(ctx context.Context) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	for ts := range ticker.C {
-		ts := ts
+	for {
+		var ts time.Time
+
+		select {
+		case <-ctx.Done():
+			return
+		case ts = <-ticker.C:
+		}
 
 		repo.mutex.RLock()
 
 		for code, s := range repo.sessions {
-			if s.CreatedAt.Add(repo.config.Code.Expiry).After(ts) {
+			if s.CreatedAt.Add(repo.config.Code.Expiry).After(ts) && (s.Expiry.IsZero() || s.Expiry.After(ts)) {
 				continue
 			}
 
@@ -89,6 +150,20 @@ func (repo *memorySessionRepository) GC() {
 			repo.mutex.RLock()
 		}
 
+		// NOTE(toby3d): redemptions are kept as long as tokens minted
+		// from them can be used.
+		for id, r := range repo.redemptions {
+			if !r.IsExpired(ts, repo.config.JWT.Expiry) {
+				continue
+			}
+
+			repo.mutex.RUnlock()
+			repo.mutex.Lock()
+			delete(repo.redemptions, id)
+			repo.mutex.Unlock()
+			repo.mutex.RLock()
+		}
+
 		repo.mutex.RUnlock()
 	}
 }
@@ -22,8 +22,15 @@ type (
 		CreatedAt sql.NullTime `db:"created_at"`
 	}
 
+	Redemption struct {
+		ID        string       `db:"id"`
+		Data      string       `db:"data"`
+		CreatedAt sql.NullTime `db:"created_at"`
+	}
+
 	sqlite3SessionRepository struct {
-		db *sqlx.DB
+		db     *sqlx.DB
+		config domain.Config
 	}
 )
 
@@ -43,13 +50,43 @@ const (
 
 	QueryDelete string = `DELETE FROM sessions
 		WHERE code=$1;`
+
+	QueryRedemptionTable string = `CREATE TABLE IF NOT EXISTS redemptions (
+		created_at DATETIME NOT NULL,
+		id TEXT UNIQUE PRIMARY KEY NOT NULL,
+		data TEXT NOT NULL
+	);`
+
+	QueryGetRedemption string = `SELECT *
+		FROM redemptions
+		WHERE id=$1;`
+
+	QueryCreateRedemption string = `INSERT INTO redemptions (created_at, id, data)
+		VALUES (:created_at, :id, :data)
+		ON CONFLICT (id) DO NOTHING;`
+
+	QueryFetchRedemptions string = `SELECT *
+		FROM redemptions;`
+
+	QueryUpdateRedemption string = `UPDATE redemptions
+		SET data=:data
+		WHERE id=:id;`
+
+	QueryDeleteRedemption string = `DELETE FROM redemptions
+		WHERE id=$1;`
 )
 
-func NewSQLite3SessionRepository(db *sqlx.DB) session.Repository {
+// DefaultGCInterval is the interval between collections of expired
+// redemptions.
+const DefaultGCInterval time.Duration = time.Minute
+
+func NewSQLite3SessionRepository(db *sqlx.DB, config domain.Config) session.Repository {
 	db.MustExec(QueryTable)
+	db.MustExec(QueryRedemptionTable)
 
 	return &sqlite3SessionRepository{
-		db: db,
+		db:     db,
+		config: config,
 	}
 }
 
@@ -123,7 +160,133 @@ func (repo *sqlite3SessionRepository) GetAndDelete(ctx context.Context, code str
 	return result, nil
 }
 
-func (repo *sqlite3SessionRepository) GC() {}
+func (repo *sqlite3SessionRepository) CreateRedemption(ctx context.Context, r domain.Redemption) error {
+	src, err := NewRedemption(&r)
+	if err != nil {
+		return fmt.Errorf("cannot encode redemption data for store: %w", err)
+	}
+
+	result, err := repo.db.NamedExecContext(ctx, QueryCreateRedemption, src)
+	if err != nil {
+		return fmt.Errorf("cannot create redemption record in db: %w", err)
+	}
+
+	// NOTE(toby3d): conflict is detected by the database itself, so two
+	// concurrent exchanges of the same code cannot both succeed.
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot check created redemption record in db: %w", err)
+	}
+
+	if count == 0 {
+		return session.ErrRedeemed
+	}
+
+	return nil
+}
+
+func (repo *sqlite3SessionRepository) GetRedemption(ctx context.Context, id string) (*domain.Redemption, error) {
+	r := new(Redemption)
+	if err := repo.db.GetContext(ctx, r, QueryGetRedemption, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, session.ErrRedemptionNotExist
+		}
+
+		return nil, fmt.Errorf("cannot find redemption in db: %w", err)
+	}
+
+	result := new(domain.Redemption)
+	if err := r.Populate([]byte(r.Data), result); err != nil {
+		return nil, fmt.Errorf("cannot decode redemption data from store: %w", err)
+	}
+
+	result.ID = id
+
+	return result, nil
+}
+
+func (repo *sqlite3SessionRepository) UpdateRedemption(ctx context.Context, r domain.Redemption) error {
+	src, err := NewRedemption(&r)
+	if err != nil {
+		return fmt.Errorf("cannot encode redemption data for store: %w", err)
+	}
+
+	if _, err = repo.db.NamedExecContext(ctx, QueryUpdateRedemption, src); err != nil {
+		return fmt.Errorf("cannot update redemption record in db: %w", err)
+	}
+
+	return nil
+}
+
+// FetchRedemptions returns redemptions of the client. Client is stored only
+// inside the encoded data, so all records are decoded and filtered.
+func (repo *sqlite3SessionRepository) FetchRedemptions(ctx context.Context, cid domain.ClientID,
+) ([]domain.Redemption, error) {
+	rows := make([]Redemption, 0)
+	if err := repo.db.SelectContext(ctx, &rows, QueryFetchRedemptions); err != nil {
+		return nil, fmt.Errorf("cannot fetch redemptions from db: %w", err)
+	}
+
+	out := make([]domain.Redemption, 0)
+
+	for i := range rows {
+		r := new(domain.Redemption)
+		if err := rows[i].Populate([]byte(rows[i].Data), r); err != nil {
+			return nil, fmt.Errorf("cannot decode redemption data from store: %w", err)
+		}
+
+		if !r.ClientID.IsEqual(cid) {
+			continue
+		}
+
+		r.ID = rows[i].ID
+		out = append(out, *r)
+	}
+
+	return out, nil
+}
+
+// GC removes redemptions of the expired tokens immediately and then every
+// DefaultGCInterval until context is done. Sessions are removed on exchange
+// and rejected by use cases after expiry.
+func (repo *sqlite3SessionRepository) GC(ctx context.Context) {
+	ticker := time.NewTicker(DefaultGCInterval)
+	defer ticker.Stop()
+
+	for ts := time.Now(); ; {
+		_ = repo.collect(ctx, ts.UTC())
+
+		select {
+		case <-ctx.Done():
+			return
+		case ts = <-ticker.C:
+		}
+	}
+}
+
+func (repo *sqlite3SessionRepository) collect(ctx context.Context, ts time.Time) error {
+	rows := make([]Redemption, 0)
+	if err := repo.db.SelectContext(ctx, &rows, QueryFetchRedemptions); err != nil {
+		return fmt.Errorf("cannot fetch redemptions from db: %w", err)
+	}
+
+	for i := range rows {
+		r := new(domain.Redemption)
+		if err := rows[i].Populate([]byte(rows[i].Data), r); err != nil {
+			return fmt.Errorf("cannot decode redemption data from store: %w", err)
+		}
+
+		if !r.IsExpired(ts, repo.config.JWT.Expiry) {
+			continue
+		}
+
+		if _, err := repo.db.ExecContext(ctx, QueryDeleteRedemption, rows[i].ID); err != nil {
+			return fmt.Errorf("cannot remove redemption from db: %w", err)
+		}
+	}
+
+	return nil
+}
 
 func NewSession(src *domain.Session) (*Session, error) {
 	data, err := json.Marshal(src)
@@ -155,3 +318,34 @@ func (t *Session) Populate(src []byte, dst *domain.Session) error {
 
 	return nil
 }
+
+func NewRedemption(src *domain.Redemption) (*Redemption, error) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode data to JSON: %w", err)
+	}
+
+	return &Redemption{
+		CreatedAt: sql.NullTime{
+			Time:  src.CreatedAt,
+			Valid: !src.CreatedAt.IsZero(),
+		},
+		ID:   src.ID,
+		Data: base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+func (r *Redemption) Populate(src []byte, dst *domain.Redemption) error {
+	tmp := make([]byte, base64.StdEncoding.DecodedLen(len(src)))
+
+	n, err := base64.StdEncoding.Decode(tmp, src)
+	if err != nil {
+		return fmt.Errorf("cannot decode base64 data: %w", err)
+	}
+
+	if err = json.Unmarshal(tmp[:n], dst); err != nil {
+		return fmt.Errorf("cannot decode JSON data: %w", err)
+	}
+
+	return nil
+}
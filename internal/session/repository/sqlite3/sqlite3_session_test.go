@@ -2,12 +2,15 @@ package sqlite3_test
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/session"
 	repository "source.toby3d.me/toby3d/auth/internal/session/repository/sqlite3"
 	"source.toby3d.me/toby3d/auth/internal/testing/sqltest"
 )
@@ -38,7 +41,7 @@ func TestCreate(t *testing.T) {
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	if err := repository.NewSQLite3SessionRepository(db).
+	if err := repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
 		Create(context.Background(), *session); err != nil {
 		t.Error(err)
 	}
@@ -68,7 +71,7 @@ func TestGet(t *testing.T) {
 				model.Data,
 			))
 
-	result, err := repository.NewSQLite3SessionRepository(db).
+	result, err := repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
 		Get(context.Background(), session.Code)
 	if err != nil {
 		t.Fatal(err)
@@ -108,7 +111,7 @@ func TestGetAndDelete(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	result, err := repository.NewSQLite3SessionRepository(db).
+	result, err := repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
 		GetAndDelete(context.Background(), session.Code)
 	if err != nil {
 		t.Fatal(err)
@@ -119,9 +122,179 @@ func TestGetAndDelete(t *testing.T) {
 	}
 }
 
+func TestCreateRedemption(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		expect error
+		count  int64
+	}{
+		"created":  {count: 1, expect: nil},
+		"conflict": {count: 0, expect: session.ErrRedeemed},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			redemption := domain.TestRedemption(t)
+
+			model, err := repository.NewRedemption(redemption)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			db, mock, cleanup := sqltest.Open(t)
+			t.Cleanup(cleanup)
+
+			createTable(t, mock)
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO redemptions`)).
+				WithArgs(sqltest.Time{}, model.ID, model.Data).
+				WillReturnResult(sqlmock.NewResult(tc.count, tc.count))
+
+			if err = repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
+				CreateRedemption(context.Background(), *redemption); !errors.Is(err, tc.expect) {
+				t.Errorf("CreateRedemption(%+v) = %v, want %v", redemption, err, tc.expect)
+			}
+		})
+	}
+}
+
+func TestGetRedemption(t *testing.T) {
+	t.Parallel()
+
+	redemption := domain.TestRedemption(t)
+
+	model, err := repository.NewRedemption(redemption)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM redemptions`)).
+		WithArgs(redemption.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "id", "data"}).
+			AddRow(
+				model.CreatedAt.Time,
+				model.ID,
+				model.Data,
+			))
+
+	result, err := repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
+		GetRedemption(context.Background(), redemption.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ID != redemption.ID || result.Me.String() != redemption.Me.String() ||
+		len(result.TokenIDs) != len(redemption.TokenIDs) {
+		t.Errorf("GetRedemption(%s) = %+v, want %+v", redemption.ID, result, redemption)
+	}
+}
+
+func TestFetchRedemptions(t *testing.T) {
+	t.Parallel()
+
+	redemption := domain.TestRedemption(t)
+
+	model, err := repository.NewRedemption(redemption)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	another := domain.TestRedemption(t)
+	another.ID = domain.NewRedemptionID("another")
+	another.ClientID = *domain.TestClientID(t, "https://localhost/")
+
+	anotherModel, err := repository.NewRedemption(another)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM redemptions`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "id", "data"}).
+			AddRow(model.CreatedAt.Time, model.ID, model.Data).
+			AddRow(anotherModel.CreatedAt.Time, anotherModel.ID, anotherModel.Data))
+
+	result, err := repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).
+		FetchRedemptions(context.Background(), redemption.ClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].ID != redemption.ID {
+		t.Errorf("FetchRedemptions(%s) = %+v, want %+v", redemption.ClientID, result, redemption)
+	}
+}
+
+func TestGC(t *testing.T) {
+	t.Parallel()
+
+	active := domain.TestRedemption(t)
+	active.Expiry = time.Now().UTC().Add(time.Hour)
+
+	activeModel, err := repository.NewRedemption(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := domain.TestRedemption(t)
+	expired.ID = domain.NewRedemptionID("expired")
+	expired.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+	expired.Expiry = time.Now().UTC().Add(-1 * time.Hour)
+
+	expiredModel, err := repository.NewRedemption(expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, cleanup := sqltest.Open(t)
+	t.Cleanup(cleanup)
+
+	createTable(t, mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM redemptions`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "id", "data"}).
+			AddRow(activeModel.CreatedAt.Time, activeModel.ID, activeModel.Data).
+			AddRow(expiredModel.CreatedAt.Time, expiredModel.ID, expiredModel.Data))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM redemptions`)).
+		WithArgs(expired.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		repository.NewSQLite3SessionRepository(db, *domain.TestConfig(t)).GC(ctx)
+	}()
+
+	for deadline := time.Now().Add(time.Second); mock.ExpectationsWereMet() != nil; {
+		if time.Now().After(deadline) {
+			t.Error("GC() did not remove expired redemption")
+
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
 func createTable(tb testing.TB, mock sqlmock.Sqlmock) {
 	tb.Helper()
 
 	mock.ExpectExec(regexp.QuoteMeta(repository.QueryTable)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(repository.QueryRedemptionTable)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 }
@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/session"
+)
+
+type tenantSessionRepository struct {
+	repo      session.Repository
+	namespace string
+}
+
+// NewTenantSessionRepository creates a new sessions repository which stores
+// sessions of the tenant in the provided shared repository under its own
+// namespace, so codes of one tenant cannot be redeemed by another.
+func NewTenantSessionRepository(repo session.Repository, namespace string) session.Repository {
+	return &tenantSessionRepository{
+		namespace: namespace + ":",
+		repo:      repo,
+	}
+}
+
+func (repo *tenantSessionRepository) Create(ctx context.Context, s domain.Session) error {
+	s.Code = repo.namespace + s.Code
+
+	return repo.repo.Create(ctx, s) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantSessionRepository) Get(ctx context.Context, code string) (*domain.Session, error) {
+	return repo.unwrap(repo.repo.Get(ctx, repo.namespace+code))
+}
+
+func (repo *tenantSessionRepository) GetAndDelete(ctx context.Context, code string) (*domain.Session, error) {
+	return repo.unwrap(repo.repo.GetAndDelete(ctx, repo.namespace+code))
+}
+
+func (repo *tenantSessionRepository) CreateRedemption(ctx context.Context, r domain.Redemption) error {
+	r.ID = repo.namespace + r.ID
+
+	return repo.repo.CreateRedemption(ctx, r) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantSessionRepository) GetRedemption(ctx context.Context, id string) (*domain.Redemption, error) {
+	out, err := repo.repo.GetRedemption(ctx, repo.namespace+id)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // decorator returns errors as is
+	}
+
+	out.ID = strings.TrimPrefix(out.ID, repo.namespace)
+
+	return out, nil
+}
+
+func (repo *tenantSessionRepository) UpdateRedemption(ctx context.Context, r domain.Redemption) error {
+	r.ID = repo.namespace + r.ID
+
+	return repo.repo.UpdateRedemption(ctx, r) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantSessionRepository) FetchRedemptions(ctx context.Context, cid domain.ClientID,
+) ([]domain.Redemption, error) {
+	all, err := repo.repo.FetchRedemptions(ctx, cid)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // decorator returns errors as is
+	}
+
+	out := make([]domain.Redemption, 0, len(all))
+
+	for i := range all {
+		if !strings.HasPrefix(all[i].ID, repo.namespace) {
+			continue
+		}
+
+		all[i].ID = strings.TrimPrefix(all[i].ID, repo.namespace)
+		out = append(out, all[i])
+	}
+
+	return out, nil
+}
+
+// GC does nothing: the shared repository is collected by its owner.
+func (tenantSessionRepository) GC(_ context.Context) {}
+
+func (repo *tenantSessionRepository) unwrap(s *domain.Session, err error) (*domain.Session, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	s.Code = strings.TrimPrefix(s.Code, repo.namespace)
+
+	return s, nil
+}
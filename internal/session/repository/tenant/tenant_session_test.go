@@ -0,0 +1,64 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/session"
+	"source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	repository "source.toby3d.me/toby3d/auth/internal/session/repository/tenant"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	shared := memory.NewMemorySessionRepository(*domain.TestConfig(t))
+	alice := repository.NewTenantSessionRepository(shared, "alice")
+	bob := repository.NewTenantSessionRepository(shared, "bob")
+	s := domain.TestSession(t)
+
+	if err := alice.Create(context.Background(), *s); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bob.GetAndDelete(context.Background(), s.Code); !errors.Is(err, session.ErrNotExist) {
+		t.Errorf("GetAndDelete(%s) = %v, want %v", s.Code, err, session.ErrNotExist)
+	}
+
+	result, err := alice.GetAndDelete(context.Background(), s.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Code != s.Code {
+		t.Errorf("GetAndDelete(%s) = %s, want %s", s.Code, result.Code, s.Code)
+	}
+}
+
+func TestFetchRedemptions(t *testing.T) {
+	t.Parallel()
+
+	shared := memory.NewMemorySessionRepository(*domain.TestConfig(t))
+	alice := repository.NewTenantSessionRepository(shared, "alice")
+	bob := repository.NewTenantSessionRepository(shared, "bob")
+	r := domain.TestRedemption(t)
+
+	if err := alice.CreateRedemption(context.Background(), *r); err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := bob.FetchRedemptions(context.Background(), r.ClientID); err != nil || len(result) != 0 {
+		t.Errorf("FetchRedemptions(%s) = %+v, %v, want empty", r.ClientID, result, err)
+	}
+
+	result, err := alice.FetchRedemptions(context.Background(), r.ClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].ID != r.ID {
+		t.Errorf("FetchRedemptions(%s) = %+v, want %+v", r.ClientID, result, r)
+	}
+}
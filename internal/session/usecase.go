@@ -8,4 +8,9 @@ import (
 
 type UseCase interface {
 	Exchange(ctx context.Context, code string) (*domain.Session, error)
+
+	// DetectReplay checks whether the code has already been redeemed. If
+	// so, it revokes the whole family of tokens minted from the code,
+	// records the audit event and returns ErrRedeemed.
+	DetectReplay(ctx context.Context, code string) error
 }
@@ -4,16 +4,27 @@ import (
 	"context"
 	"fmt"
 
+	"source.toby3d.me/toby3d/auth/internal/audit"
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/auth/internal/session"
 )
 
 type sessionUseCase struct {
+	audit    audit.Repository
+	clock    domain.Clock
 	sessions session.Repository
 }
 
-func NewSessionUseCase(sessions session.Repository) session.UseCase {
+// NewSessionUseCase creates a new session use case. Code replays are not
+// recorded if audits repository is nil. System clock is used if clock is nil.
+func NewSessionUseCase(sessions session.Repository, audits audit.Repository, clock domain.Clock) session.UseCase {
+	if clock == nil {
+		clock = domain.SystemClock
+	}
+
 	return &sessionUseCase{
+		audit:    audits,
+		clock:    clock,
 		sessions: sessions,
 	}
 }
@@ -26,3 +37,32 @@ func (useCase *sessionUseCase) Exchange(ctx context.Context, code string) (*doma
 
 	return session, nil
 }
+
+func (useCase *sessionUseCase) DetectReplay(ctx context.Context, code string) error {
+	redemption, err := useCase.sessions.GetRedemption(ctx, domain.NewRedemptionID(code))
+	if err != nil {
+		return nil //nolint:nilerr // code was never redeemed
+	}
+
+	if !redemption.IsRevoked() {
+		redemption.RevokedAt = useCase.clock.Now()
+
+		if err = useCase.sessions.UpdateRedemption(ctx, *redemption); err != nil {
+			return fmt.Errorf("cannot revoke tokens of the redeemed code: %w", err)
+		}
+	}
+
+	if useCase.audit != nil {
+		if err = useCase.audit.Create(ctx, domain.AuditEvent{
+			CreatedAt: redemption.RevokedAt,
+			ClientID:  redemption.ClientID,
+			Me:        redemption.Me,
+			Type:      domain.AuditEventCodeReplay,
+			TokenIDs:  redemption.TokenIDs,
+		}); err != nil {
+			return fmt.Errorf("cannot record code replay: %w", err)
+		}
+	}
+
+	return session.ErrRedeemed
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	// Route describes the handler of all tenant requests.
+	Route struct {
+		Handler http.Handler
+		Tenant  domain.Tenant
+	}
+
+	Handler struct {
+		routes []Route
+	}
+)
+
+// NewHandler creates a new router which passes requests to the tenant handler
+// matched by Host header and path prefix. Matched path prefix is stripped from
+// request.
+func NewHandler(routes ...Route) *Handler {
+	out := &Handler{
+		routes: append(make([]Route, 0, len(routes)), routes...),
+	}
+
+	// NOTE(toby3d): the most specific routes are checked first.
+	sort.SliceStable(out.routes, func(i, j int) bool {
+		a, b := out.routes[i].Tenant, out.routes[j].Tenant
+		if (a.Host != "") != (b.Host != "") {
+			return a.Host != ""
+		}
+
+		return len(a.PathPrefix) > len(b.PathPrefix)
+	})
+
+	return out
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := strings.ToLower(r.Host)
+
+	for i := range h.routes {
+		t := h.routes[i].Tenant
+
+		if t.Host != "" && t.Host != host {
+			continue
+		}
+
+		path, ok := stripPrefix(r.URL.Path, t.PathPrefix)
+		if !ok {
+			continue
+		}
+
+		r.URL.Path, r.URL.RawPath = path, ""
+
+		h.routes[i].Handler.ServeHTTP(w, r)
+
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// stripPrefix returns path without provided prefix segments, if it contains
+// them.
+func stripPrefix(path, prefix string) (string, bool) {
+	if prefix == "" {
+		return path, true
+	}
+
+	prefix = "/" + prefix
+
+	if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return path, false
+	}
+
+	if path = strings.TrimPrefix(path, prefix); path == "" {
+		path = "/"
+	}
+
+	return path, true
+}
@@ -0,0 +1,66 @@
+package http_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	delivery "source.toby3d.me/toby3d/auth/internal/tenant/delivery/http"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewHandler(
+		delivery.Route{Tenant: domain.Tenant{ID: "bob", PathPrefix: "bob"}, Handler: testHandler("bob")},
+		delivery.Route{Tenant: domain.Tenant{ID: "alice", Host: "alice.example"}, Handler: testHandler("alice")},
+		delivery.Route{
+			Tenant:  domain.Tenant{ID: "carol", Host: "alice.example", PathPrefix: "carol"},
+			Handler: testHandler("carol"),
+		},
+	)
+
+	for name, tc := range map[string]struct {
+		target, expBody string
+		expStatus       int
+	}{
+		"host":          {target: "https://alice.example/token", expBody: "alice /token", expStatus: http.StatusOK},
+		"host prefix":   {target: "https://ALICE.example/carol/token", expBody: "carol /token", expStatus: http.StatusOK},
+		"prefix":        {target: "https://example.com/bob/authorize", expBody: "bob /authorize", expStatus: http.StatusOK},
+		"prefix root":   {target: "https://example.com/bob", expBody: "bob /", expStatus: http.StatusOK},
+		"prefix prefix": {target: "https://example.com/bobby/", expBody: "", expStatus: http.StatusNotFound},
+		"unknown":       {target: "https://example.com/", expBody: "", expStatus: http.StatusNotFound},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, tc.target, resp.StatusCode, tc.expStatus)
+			}
+
+			if tc.expStatus != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tc.expBody {
+				t.Errorf("%s %s = %s, want %s", req.Method, tc.target, body, tc.expBody)
+			}
+		})
+	}
+}
+
+func testHandler(id string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, id+" "+r.URL.Path)
+	})
+}
@@ -0,0 +1,14 @@
+package tenant
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type Repository interface {
+	// Fetch returns all hosted tenants.
+	Fetch(ctx context.Context) ([]domain.Tenant, error)
+}
+
+var ErrInvalid error = domain.NewError(domain.ErrorCodeServerError, "invalid tenant configuration", "")
@@ -0,0 +1,88 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/tenant"
+)
+
+type (
+	//nolint:tagliatelle
+	Tenant struct {
+		Logo       domain.URL `json:"logo,omitempty"`
+		ID         string     `json:"id"`
+		Name       string     `json:"name,omitempty"`
+		Host       string     `json:"host,omitempty"`
+		PathPrefix string     `json:"path_prefix,omitempty"`
+		Username   string     `json:"username"`
+		Password   string     `json:"password"`
+		JWTSecret  string     `json:"jwt_secret,omitempty"`
+		TOTPSecret string     `json:"totp_secret,omitempty"`
+		Identities []string   `json:"identities,omitempty"`
+	}
+
+	fileTenantRepository struct {
+		path string
+	}
+)
+
+// NewFileTenantRepository creates a new tenants repository which reads the
+// JSON array of tenants from the provided file path.
+func NewFileTenantRepository(path string) tenant.Repository {
+	return &fileTenantRepository{
+		path: path,
+	}
+}
+
+func (repo *fileTenantRepository) Fetch(_ context.Context) ([]domain.Tenant, error) {
+	src, err := os.ReadFile(repo.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read tenants file: %w", err)
+	}
+
+	in := make([]Tenant, 0)
+	if err = json.Unmarshal(src, &in); err != nil {
+		return nil, fmt.Errorf("cannot decode tenants file: %w", err)
+	}
+
+	out := make([]domain.Tenant, 0, len(in))
+	ids := make(map[string]struct{}, len(in))
+
+	for i := range in {
+		t := in[i].populate()
+
+		if t.ID == "" || (t.Host == "" && t.PathPrefix == "") {
+			return nil, fmt.Errorf("%w: tenant #%d must have id and host or path prefix", tenant.ErrInvalid, i)
+		}
+
+		if _, ok := ids[t.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicated tenant id '%s'", tenant.ErrInvalid, t.ID)
+		}
+
+		ids[t.ID] = struct{}{}
+		out = append(out, t)
+	}
+
+	return out, nil
+}
+
+func (t Tenant) populate() domain.Tenant {
+	return domain.Tenant{
+		Logo:       t.Logo.URL,
+		ID:         t.ID,
+		Name:       t.Name,
+		Host:       strings.ToLower(t.Host),
+		PathPrefix: strings.Trim(t.PathPrefix, "/"),
+		Username:   t.Username,
+		Password:   t.Password,
+		JWTSecret:  t.JWTSecret,
+		TOTPSecret: t.TOTPSecret,
+		Identities: t.Identities,
+	}
+}
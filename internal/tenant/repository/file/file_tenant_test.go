@@ -0,0 +1,67 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/tenant"
+	repository "source.toby3d.me/toby3d/auth/internal/tenant/repository/file"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input    string
+		expError error
+		expCount int
+	}{
+		"valid": {
+			input: `[
+				{"id": "alice", "host": "Auth.Alice.example", "username": "alice", "password": "secret",
+				 "logo": "https://alice.example/logo.png", "identities": ["https://alice.example/"]},
+				{"id": "bob", "path_prefix": "/bob/", "username": "bob", "password": "secret"}
+			]`,
+			expCount: 2,
+		},
+		"without route": {
+			input:    `[{"id": "alice", "username": "alice", "password": "secret"}]`,
+			expError: tenant.ErrInvalid,
+		},
+		"duplicated": {
+			input:    `[{"id": "alice", "host": "a.example"}, {"id": "alice", "host": "b.example"}]`,
+			expError: tenant.ErrInvalid,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "tenants.json")
+			if err := os.WriteFile(path, []byte(tc.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := repository.NewFileTenantRepository(path).Fetch(context.Background())
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Fetch() = %v, want %v", err, tc.expError)
+			}
+
+			if len(result) != tc.expCount {
+				t.Errorf("Fetch() = %d tenants, want %d", len(result), tc.expCount)
+			}
+
+			if tc.expCount == 0 {
+				return
+			}
+
+			if result[0].Host != "auth.alice.example" || result[0].Logo == nil || result[1].PathPrefix != "bob" {
+				t.Errorf("Fetch() = %+v, want normalized tenants", result)
+			}
+		})
+	}
+}
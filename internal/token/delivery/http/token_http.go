@@ -1,45 +1,61 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/goccy/go-json"
-	"github.com/lestrrat-go/jwx/v2/jwa"
 
+	"source.toby3d.me/toby3d/auth/internal/auth"
+	"source.toby3d.me/toby3d/auth/internal/client"
 	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
-	"source.toby3d.me/toby3d/auth/internal/middleware"
+	"source.toby3d.me/toby3d/auth/internal/oidc"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	"source.toby3d.me/toby3d/auth/internal/scope"
 	"source.toby3d.me/toby3d/auth/internal/token"
 	"source.toby3d.me/toby3d/auth/internal/urlutil"
 )
 
 type Handler struct {
-	config domain.Config
-	tokens token.UseCase
+	auth    auth.UseCase
+	clients client.UseCase
+	oidc    oidc.UseCase
+	scopes  scope.UseCase
+	replays replay.Repository
+	config  domain.Config
+	tokens  token.UseCase
 }
 
-func NewHandler(tokens token.UseCase, config domain.Config) *Handler {
+// NewHandler creates a new token endpoint handler. ID Tokens are issued only
+// if oidc use case is provided. Used DPoP proofs are remembered in replays.
+func NewHandler(tokens token.UseCase, auths auth.UseCase, clients client.UseCase, oidcs oidc.UseCase,
+	scopes scope.UseCase, replays replay.Repository, config domain.Config,
+) *Handler {
 	return &Handler{
-		config: config,
-		tokens: tokens,
+		auth:    auths,
+		clients: clients,
+		config:  config,
+		oidc:    oidcs,
+		replays: replays,
+		scopes:  scopes,
+		tokens:  tokens,
 	}
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	chain := middleware.Chain{
-		//nolint:exhaustivestruct
-		middleware.JWTWithConfig(middleware.JWTConfig{
-			Skipper: func(_ http.ResponseWriter, r *http.Request) bool {
-				head, _ := urlutil.ShiftPath(r.URL.Path)
-
-				return head == "token"
-			},
-			SigningKey:    []byte(h.config.JWT.Secret),
-			SigningMethod: jwa.SignatureAlgorithm(h.config.JWT.Algorithm),
-			ContextKey:    "token",
-			TokenLookup:   "form:token," + "header:" + common.HeaderAuthorization + ":Bearer ",
-			AuthScheme:    "Bearer",
-		}),
+	// NOTE(toby3d): keep the path as is, it's used as an audience of the
+	// client assertions.
+	head, _ := urlutil.ShiftPath(r.URL.Path)
+
+	if head == "token" && r.Method == http.MethodGet && h.config.Legacy.TokenVerification {
+		h.handleVerification(w, r)
+
+		return
 	}
 
 	if r.Method != http.MethodPost {
@@ -48,18 +64,17 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var head string
-	head, r.URL.Path = urlutil.ShiftPath(r.URL.Path)
-
 	switch head {
 	default:
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 	case "token":
-		chain.Handler(h.handleAction).ServeHTTP(w, r)
+		h.handleAction(w, r)
 	case "introspect":
-		chain.Handler(h.handleIntrospect).ServeHTTP(w, r)
+		h.handleIntrospect(w, r)
 	case "revocation":
-		chain.Handler(h.handleRevokation).ServeHTTP(w, r)
+		h.handleRevokation(w, r)
+	case "par":
+		h.handlePushedAuthorization(w, r)
 	}
 }
 
@@ -70,15 +85,30 @@ func (h *Handler) handleIntrospect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c, creds, err := h.authenticate(r)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	// NOTE(toby3d): resource servers authorize themselves by any active
+	// access token, clients by one of the credentials-based methods.
+	if c == nil || creds.Method == domain.ClientAuthMethodNone {
+		if _, err = h.verifyAccessToken(r); err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+	}
+
 	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
 
 	encoder := json.NewEncoder(w)
 
 	req := new(TokenIntrospectRequest)
 	if err := req.bind(r); err != nil {
-		_ = encoder.Encode(err)
-
-		w.WriteHeader(http.StatusBadRequest)
+		h.writeError(w, r, err)
 
 		return
 	}
@@ -89,21 +119,69 @@ func (h *Handler) handleIntrospect(w http.ResponseWriter, r *http.Request) {
 		// MUST return a 200 Response.
 		_ = encoder.Encode(&TokenInvalidIntrospectResponse{Active: false})
 
-		w.WriteHeader(http.StatusOK)
-
 		return
 	}
 
+	var authTime, exp int64
+	if !tkn.AuthTime.IsZero() {
+		authTime = tkn.AuthTime.Unix()
+	}
+
+	// NOTE(toby3d): token granted forever never expires.
+	if !tkn.Expiry.IsZero() {
+		exp = tkn.Expiry.Unix()
+	}
+
+	var cnf *TokenConfirmation
+	if tkn.JKT != "" {
+		cnf = &TokenConfirmation{JKT: tkn.JKT}
+	}
+
 	_ = encoder.Encode(&TokenIntrospectResponse{
-		Active:   true,
-		ClientID: tkn.ClientID.String(),
-		Exp:      tkn.Expiry.Unix(),
-		Iat:      tkn.CreatedAt.Unix(),
+		Active:    true,
+		ACR:       tkn.ACR,
+		AMR:       tkn.AMR,
+		AuthTime:  authTime,
+		ClientID:  tkn.ClientID.String(),
+		Cnf:       cnf,
+		Exp:       exp,
+		Iat:       tkn.CreatedAt.Unix(),
+		Me:        tkn.Me.String(),
+		Scope:     tkn.Scope.String(),
+		TokenType: tkn.Type(),
+	})
+}
+
+// handleVerification answers the access token verification request of the
+// legacy resource servers by JSON or form-encoded body, see:
+// https://indieauth.spec.indieweb.org/20201126/#access-token-verification
+func (h *Handler) handleVerification(w http.ResponseWriter, r *http.Request) {
+	tkn, err := h.verifyAccessToken(r)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	resp := &TokenVerificationResponse{
 		Me:       tkn.Me.String(),
+		ClientID: tkn.ClientID.String(),
 		Scope:    tkn.Scope.String(),
-	})
+	}
+
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+
+	if !acceptsJSON(r.Header.Get(common.HeaderAccept)) {
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationForm)
+
+		_, _ = io.WriteString(w, resp.Encode())
 
-	w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (h *Handler) handleAction(w http.ResponseWriter, r *http.Request) {
@@ -117,18 +195,18 @@ func (h *Handler) handleAction(w http.ResponseWriter, r *http.Request) {
 
 	encoder := json.NewEncoder(w)
 
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		_ = encoder.Encode(domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), ""))
+
+		return
+	}
+
 	switch {
 	case r.PostForm.Has("grant_type"):
 		h.handleExchange(w, r)
 	case r.PostForm.Has("action"):
-		if err := r.ParseForm(); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-
-			_ = encoder.Encode(domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), ""))
-
-			return
-		}
-
 		action, err := domain.ParseAction(r.PostForm.Get("action"))
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
@@ -152,45 +230,133 @@ func (h *Handler) handleExchange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c, creds, err := h.authenticate(r)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if c == nil {
+		h.writeError(w, r, client.ErrInvalidCredentials)
+
+		return
+	}
+
+	if err = h.config.Security.GetProfile().ValidateClientAuthMethod(creds.Method); err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
 	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
 
 	encoder := json.NewEncoder(w)
 
 	req := new(TokenExchangeRequest)
 	if err := req.bind(r); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		h.writeError(w, r, err)
+
+		return
+	}
 
-		_ = encoder.Encode(err)
+	// NOTE(toby3d): RFC 9449 section 5: token is bound to the key of the
+	// DPoP proof, if provided.
+	var jkt string
+
+	if proof := r.Header.Get(common.HeaderDPoP); proof != "" {
+		endpoint, err := h.endpoint(r)
+		if err != nil {
+			h.writeError(w, r, domain.NewError(domain.ErrorCodeServerError, err.Error(), ""))
+
+			return
+		}
+
+		result, err := domain.ParseDPoPProof(proof, domain.DPoPProofOptions{
+			URL:         endpoint,
+			Method:      r.Method,
+			AccessToken: "",
+		})
+		if err == nil {
+			err = h.rememberProof(r, result)
+		}
+
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		jkt = result.JKT
+	} else if h.config.Security.GetProfile().RequireSenderConstrainedTokens() {
+		h.writeError(w, r, token.ErrDPoPRequired)
 
 		return
 	}
 
-	token, profile, err := h.tokens.Exchange(r.Context(), token.ExchangeOptions{
-		ClientID:     req.ClientID,
-		RedirectURI:  req.RedirectURI.URL,
-		Code:         req.Code,
-		CodeVerifier: req.CodeVerifier,
-	})
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	var (
+		tkn     *domain.Token
+		profile *domain.Profile
+	)
+
+	if req.GrantType == domain.GrantTypeRefreshToken {
+		refreshReq := new(TokenRefreshRequest)
+		if err = refreshReq.bind(r); err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		tkn, profile, err = h.tokens.Refresh(r.Context(), token.RefreshOptions{
+			ClientID:     c.ID,
+			RefreshToken: refreshReq.RefreshToken,
+			Scope:        refreshReq.Scope,
+			JKT:          jkt,
+		})
+	} else {
+		tkn, profile, err = h.tokens.Exchange(r.Context(), token.ExchangeOptions{
+			ClientID:     c.ID,
+			RedirectURI:  req.RedirectURI.URL,
+			Code:         req.Code,
+			CodeVerifier: req.CodeVerifier,
+			JKT:          jkt,
+		})
+	}
 
-		_ = encoder.Encode(domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
-			"https://indieauth.net/source/#request"))
+	if err != nil {
+		h.writeError(w, r, grantError(err))
 
 		return
 	}
 
-	resp := &TokenExchangeResponse{
-		AccessToken:  token.AccessToken,
-		ExpiresIn:    token.Expiry.Unix(),
-		Me:           token.Me.String(),
-		Profile:      NewTokenProfileResponse(profile),
-		RefreshToken: "", // TODO(toby3d)
+	var idToken string
+
+	if h.oidc != nil && tkn.Scope.Has(domain.ScopeOpenID) {
+		if idToken, err = h.oidc.IDToken(r.Context(), *tkn); err != nil {
+			h.writeError(w, r, domain.NewError(domain.ErrorCodeServerError, err.Error(), ""))
+
+			return
+		}
+	}
+
+	var expiresIn int64
+	if !tkn.Expiry.IsZero() {
+		expiresIn = int64(tkn.Expiry.Sub(tkn.CreatedAt).Seconds())
 	}
 
-	_ = encoder.Encode(resp)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	w.Header().Set(common.HeaderPragma, "no-cache")
 
-	w.WriteHeader(http.StatusOK)
+	_ = encoder.Encode(&TokenExchangeResponse{
+		AccessToken:  tkn.AccessToken,
+		TokenType:    tkn.Type(),
+		ExpiresIn:    expiresIn,
+		Scope:        tkn.Scope.String(),
+		Me:           tkn.Me.String(),
+		Profile:      NewTokenProfileResponse(profile),
+		RefreshToken: tkn.RefreshToken,
+		IDToken:      idToken,
+	})
 }
 
 func (h *Handler) handleRevokation(w http.ResponseWriter, r *http.Request) {
@@ -200,28 +366,304 @@ func (h *Handler) handleRevokation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c, creds, err := h.authenticate(r)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
 	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
 
 	encoder := json.NewEncoder(w)
 
 	req := NewTokenRevocationRequest()
 	if err := req.bind(r); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-
-		_ = encoder.Encode(err)
+		h.writeError(w, r, err)
 
 		return
 	}
 
-	if err := h.tokens.Revoke(r.Context(), req.Token); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	opts := token.RevokeOptions{Token: req.Token}
+	if c != nil && creds.Method != domain.ClientAuthMethodNone {
+		opts.ClientID = &c.ID
+	}
 
-		_ = encoder.Encode(domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), ""))
+	if err := h.tokens.Revoke(r.Context(), opts); err != nil {
+		if errors.Is(err, token.ErrForeignToken) {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeError(w, r, domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), ""))
 
 		return
 	}
 
 	_ = encoder.Encode(&TokenRevocationResponse{})
+}
+
+func (h *Handler) handlePushedAuthorization(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	c, creds, err := h.authenticate(r)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if c == nil {
+		h.writeError(w, r, client.ErrInvalidCredentials)
+
+		return
+	}
+
+	if err = h.config.Security.GetProfile().ValidateClientAuthMethod(creds.Method); err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	req := NewTokenPushedAuthorizationRequest()
+	if err = req.bind(r); err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if !req.ClientID.IsEqual(c.ID) {
+		h.writeError(w, r, domain.NewError(domain.ErrorCodeInvalidRequest,
+			"client_id does not match the authenticated client", "https://www.rfc-editor.org/rfc/rfc9126#section-2.1"))
+
+		return
+	}
+
+	profile := h.config.Security.GetProfile()
+
+	if err = profile.ValidateResponseType(req.ResponseType); err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if err = profile.ValidateCodeChallenge(req.CodeChallengeMethod, req.CodeChallenge); err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	scopes, err := h.scopes.Resolve(r.Context(), req.Scope)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	requestURI, err := h.auth.Push(r.Context(), domain.Session{
+		ClientID:            c.ID,
+		RedirectURI:         req.RedirectURI.URL,
+		Me:                  req.Me,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		CodeChallenge:       req.CodeChallenge,
+		ResponseMode:        req.ResponseMode,
+		State:               req.State,
+		Nonce:               req.Nonce,
+		Scope:               scopes,
+		Resource:            req.Resource,
+		Prompt:              req.Prompt,
+		MaxAge:              req.MaxAge,
+		LoginHint:           req.LoginHint,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	w.WriteHeader(http.StatusCreated)
+
+	_ = json.NewEncoder(w).Encode(&TokenPushedAuthorizationResponse{
+		RequestURI: requestURI,
+		ExpiresIn:  int64(h.config.PAR.Expiry.Seconds()),
+	})
+}
+
+// authenticate verifies client credentials provided with the request. Client
+// is nil if request does not contain any client credentials or client_id.
+func (h *Handler) authenticate(r *http.Request) (*domain.Client, *ClientCredentialsRequest, error) {
+	creds := NewClientCredentialsRequest()
+	if err := creds.bind(r); err != nil {
+		return nil, creds, err
+	}
+
+	if creds.Method == domain.ClientAuthMethodUnd {
+		return nil, creds, nil
+	}
+
+	cid, err := domain.ParseClientID(creds.ClientID)
+	if err != nil {
+		return nil, creds, fmt.Errorf("%w: %w", client.ErrInvalidCredentials, err)
+	}
+
+	endpoint, err := h.endpoint(r)
+	if err != nil {
+		return nil, creds, err
+	}
+
+	c, err := h.clients.Authenticate(r.Context(), client.AuthenticateOptions{
+		ClientID:  *cid,
+		Method:    creds.Method,
+		Secret:    creds.ClientSecret,
+		Assertion: creds.ClientAssertion,
+		Audience:  []string{h.config.Server.GetRootURL(), endpoint.String()},
+	})
+	if err != nil {
+		return nil, creds, fmt.Errorf("cannot authenticate client: %w", err)
+	}
+
+	return c, creds, nil
+}
+
+// verifyAccessToken verifies access token provided with the request by Bearer
+// or DPoP authorization scheme.
+func (h *Handler) verifyAccessToken(r *http.Request) (*domain.Token, error) {
+	scheme, accessToken, _ := strings.Cut(r.Header.Get(common.HeaderAuthorization), " ")
+	if !strings.EqualFold(scheme, "Bearer") && !strings.EqualFold(scheme, "DPoP") {
+		return nil, client.ErrInvalidCredentials
+	}
+
+	tkn, _, err := h.tokens.Verify(r.Context(), accessToken)
+	if err != nil {
+		return nil, domain.NewError(domain.ErrorCodeInvalidToken, err.Error(),
+			"https://indieauth.net/source/#access-token-verification")
+	}
+
+	endpoint, err := h.endpoint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := tkn.Confirm(scheme, r.Header.Get(common.HeaderDPoP), domain.DPoPProofOptions{
+		URL:         endpoint,
+		Method:      r.Method,
+		AccessToken: "",
+	})
+	if err == nil && proof != nil {
+		err = h.rememberProof(r, proof)
+	}
+
+	if err != nil {
+		return nil, domain.NewError(domain.ErrorCodeInvalidToken, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc9449#section-7.1")
+	}
+
+	return tkn, nil
+}
+
+// rememberProof rejects DPoP proof which is already used, see RFC 9449
+// section 11.1.
+func (h *Handler) rememberProof(r *http.Request, proof *domain.DPoPProof) error {
+	if err := h.replays.Create(r.Context(), "dpop:"+proof.JKT+":"+proof.ID, proof.Expiry()); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrDPoPProof, err)
+	}
+
+	return nil
+}
+
+// endpoint returns the public URL of the requested endpoint.
+func (h *Handler) endpoint(r *http.Request) (*url.URL, error) {
+	head, _ := urlutil.ShiftPath(r.URL.Path)
+
+	endpoint, err := url.Parse(h.config.Server.GetRootURL())
+	if err != nil {
+		return nil, fmt.Errorf("cannot build endpoint URL: %w", err)
+	}
+
+	return endpoint.JoinPath(head), nil
+}
+
+// acceptsJSON reports whether JSON is listed in the Accept header before the
+// form-encoded type. Legacy resource servers which do not send any of them
+// expect form-encoded body.
+//
+// NOTE(toby3d): quality values are ignored, legacy servers do not send them.
+func acceptsJSON(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case common.MIMEApplicationJSON:
+			return true
+		case common.MIMEApplicationForm:
+			return false
+		}
+	}
+
+	return false
+}
+
+// grantError keeps errors of the client, scope and proof as is and reports
+// any other problem of the exchange as the invalid grant.
+//
+// See: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+func grantError(err error) error {
+	var out *domain.Error
+	if errors.As(err, &out) {
+		switch out.Code {
+		case domain.ErrorCodeAccessDenied, domain.ErrorCodeInvalidClient, domain.ErrorCodeInvalidDPoPProof,
+			domain.ErrorCodeInvalidGrant, domain.ErrorCodeInvalidScope, domain.ErrorCodeUnauthorizedClient:
+			return out
+		}
+	}
+
+	return domain.NewError(domain.ErrorCodeInvalidGrant, err.Error(),
+		"https://www.rfc-editor.org/rfc/rfc6749#section-5.2")
+}
+
+// writeError writes error response described in RFC 6749 section 5.2.
+// invalid_client and invalid_token errors are returned with HTTP 401 and
+// WWW-Authenticate header matching the used authentication scheme.
+//
+// See: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var out *domain.Error
+	if !errors.As(err, &out) {
+		out = domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(), "")
+	}
+
+	status := http.StatusBadRequest
+
+	switch out.Code {
+	case domain.ErrorCodeServerError:
+		status = http.StatusInternalServerError
+	case domain.ErrorCodeInvalidClient:
+		status = http.StatusUnauthorized
+
+		w.Header().Set(common.HeaderWWWAuthenticate, `Basic realm="`+h.config.Server.GetRootURL()+`"`)
+	case domain.ErrorCodeInvalidToken:
+		status = http.StatusUnauthorized
+
+		w.Header().Set(common.HeaderWWWAuthenticate, `Bearer error="`+out.Code.String()+`"`)
+	}
+
+	// NOTE(toby3d): the client has used Bearer or DPoP scheme, so respond
+	// with it.
+	scheme, _, _ := strings.Cut(r.Header.Get(common.HeaderAuthorization), " ")
+	if status == http.StatusUnauthorized && (scheme == "Bearer" || scheme == "DPoP") {
+		w.Header().Set(common.HeaderWWWAuthenticate, scheme+` error="`+out.Code.String()+`"`)
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	w.WriteHeader(status)
 
-	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(out)
 }
@@ -3,6 +3,10 @@ package http
 import (
 	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/form"
@@ -41,6 +45,53 @@ type (
 		Token string `form:"token"`
 	}
 
+	// TokenPushedAuthorizationRequest contains parameters of the
+	// authorization request pushed by the client, see RFC 9126 section 2.1.
+	TokenPushedAuthorizationRequest struct {
+		ClientID            domain.ClientID            `form:"client_id"`
+		RedirectURI         domain.URL                 `form:"redirect_uri"`
+		Me                  domain.Me                  `form:"me,omitempty"`
+		CodeChallengeMethod domain.CodeChallengeMethod `form:"code_challenge_method,omitempty"`
+		ResponseType        domain.ResponseType        `form:"response_type"`
+		ResponseMode        domain.ResponseMode        `form:"response_mode,omitempty"`
+		State               string                     `form:"state"`
+		CodeChallenge       string                     `form:"code_challenge,omitempty"`
+		Nonce               string                     `form:"nonce,omitempty"`
+		LoginHint           string                     `form:"login_hint,omitempty"`
+		MaxAge              string                     `form:"max_age,omitempty"`
+		RequestURI          string                     `form:"request_uri,omitempty"`
+		Prompt              domain.Prompts             `form:"prompt,omitempty"`
+		Scope               domain.Scopes              `form:"scope,omitempty"`
+		Resource            []string                   `form:"resource,omitempty"`
+	}
+
+	// TokenPushedAuthorizationResponse is the response of the pushed
+	// authorization request endpoint, see RFC 9126 section 2.2.
+	//
+	//nolint:tagliatelle // RFC 9126 section 2.2
+	TokenPushedAuthorizationResponse struct {
+		// The request URI corresponding to the authorization request
+		// posted.
+		RequestURI string `json:"request_uri"`
+
+		// The lifetime of the request URI in seconds.
+		ExpiresIn int64 `json:"expires_in"`
+	}
+
+	// ClientCredentialsRequest contains client authentication parameters
+	// described in RFC 6749 section 2.3 and RFC 7523 section 2.2.
+	//
+	//nolint:tagliatelle // RFC 6749 section 2.3.1
+	ClientCredentialsRequest struct {
+		ClientID            string `form:"client_id"`
+		ClientSecret        string `form:"client_secret"`
+		ClientAssertionType string `form:"client_assertion_type"`
+		ClientAssertion     string `form:"client_assertion"`
+
+		// Authentication method detected from the provided parameters.
+		Method domain.ClientAuthMethod `form:"-"`
+	}
+
 	//nolint:tagliatelle // https://indieauth.net/source/#access-token-response
 	TokenExchangeResponse struct {
 		// The user's profile information.
@@ -49,13 +100,24 @@ type (
 		// The OAuth 2.0 Bearer Token RFC6750.
 		AccessToken string `json:"access_token"`
 
+		// The type of the access token, always "Bearer".
+		TokenType string `json:"token_type"`
+
+		// The scopes granted by the owner, which may be fewer than
+		// requested by the client.
+		Scope string `json:"scope,omitempty"`
+
 		// The canonical user profile URL for the user this access token
 		// corresponds to.
 		Me string `json:"me"`
 
 		// The refresh token, which can be used to obtain new access
 		// tokens.
-		RefreshToken string `json:"refresh_token"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+
+		// The ID Token about the authentication event, issued only if
+		// the openid scope is granted.
+		IDToken string `json:"id_token,omitempty"`
 
 		// The lifetime in seconds of the access token.
 		ExpiresIn int64 `json:"expires_in,omitempty"`
@@ -96,16 +158,47 @@ type (
 		// issued.
 		Iat int64 `json:"iat,omitempty"`
 
+		// Integer timestamp, measured in the number of seconds since
+		// January 1 1970 UTC, indicating when the owner was
+		// authenticated.
+		AuthTime int64 `json:"auth_time,omitempty"`
+
+		// Confirmation of the key which the token is bound to by
+		// DPoP, see RFC 9449 section 6.2.
+		Cnf *TokenConfirmation `json:"cnf,omitempty"`
+
+		// Type of the token: Bearer or DPoP.
+		TokenType string `json:"token_type,omitempty"`
+
+		// Authentication context class reference and methods of the
+		// owner, so resource servers can require step-up
+		// authentication.
+		ACR string   `json:"acr,omitempty"`
+		AMR []string `json:"amr,omitempty"`
+
 		// Boolean indicator of whether or not the presented token is
 		// currently active.
 		Active bool `json:"active"`
 	}
 
+	TokenConfirmation struct {
+		// The thumbprint of the client key.
+		JKT string `json:"jkt"`
+	}
+
 	TokenInvalidIntrospectResponse struct {
 		Active bool `json:"active"`
 	}
 
 	TokenRevocationResponse struct{}
+
+	// TokenVerificationResponse describes the access token for the legacy
+	// resource servers.
+	TokenVerificationResponse struct {
+		Me       string `json:"me"`
+		ClientID string `json:"client_id"`
+		Scope    string `json:"scope"`
+	}
 )
 
 func NewTokenProfileResponse(in *domain.Profile) *TokenProfileResponse {
@@ -137,10 +230,27 @@ func NewTokenProfileResponse(in *domain.Profile) *TokenProfileResponse {
 	return out
 }
 
+// Encode encodes response into form-encoded body.
+func (r TokenVerificationResponse) Encode() string {
+	return url.Values{
+		"me":        []string{r.Me},
+		"client_id": []string{r.ClientID},
+		"scope":     []string{r.Scope},
+	}.Encode()
+}
+
 func (r *TokenExchangeRequest) bind(req *http.Request) error {
 	indieAuthError := new(domain.Error)
 
-	if err := form.Unmarshal([]byte(req.URL.Query().Encode()), r); err != nil {
+	if err := req.ParseForm(); err != nil {
+		return domain.NewError(
+			domain.ErrorCodeInvalidRequest,
+			err.Error(),
+			"https://indieauth.net/source/#request",
+		)
+	}
+
+	if err := form.Unmarshal([]byte(req.PostForm.Encode()), r); err != nil {
 		if errors.As(err, indieAuthError) {
 			return indieAuthError
 		}
@@ -155,6 +265,40 @@ func (r *TokenExchangeRequest) bind(req *http.Request) error {
 	return nil
 }
 
+func (r *TokenRefreshRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := req.ParseForm(); err != nil {
+		return domain.NewError(
+			domain.ErrorCodeInvalidRequest,
+			err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc6749#section-6",
+		)
+	}
+
+	if err := form.Unmarshal([]byte(req.PostForm.Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(
+			domain.ErrorCodeInvalidRequest,
+			err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc6749#section-6",
+		)
+	}
+
+	if r.RefreshToken == "" {
+		return domain.NewError(
+			domain.ErrorCodeInvalidRequest,
+			"refresh_token is required",
+			"https://www.rfc-editor.org/rfc/rfc6749#section-6",
+		)
+	}
+
+	return nil
+}
+
 func NewTokenRevocationRequest() *TokenRevocationRequest {
 	return &TokenRevocationRequest{
 		Action: domain.ActionRevoke,
@@ -214,3 +358,167 @@ func (r *TokenIntrospectRequest) bind(req *http.Request) error {
 
 	return nil
 }
+
+func NewTokenPushedAuthorizationRequest() *TokenPushedAuthorizationRequest {
+	return &TokenPushedAuthorizationRequest{
+		ClientID:            domain.ClientID{},
+		CodeChallenge:       "",
+		CodeChallengeMethod: domain.CodeChallengeMethodUnd,
+		LoginHint:           "",
+		MaxAge:              "",
+		Me:                  domain.Me{},
+		Nonce:               "",
+		Prompt:              make(domain.Prompts, 0),
+		RedirectURI:         domain.URL{},
+		RequestURI:          "",
+		Resource:            nil,
+		ResponseMode:        domain.ResponseModeUnd,
+		ResponseType:        domain.ResponseTypeUnd,
+		Scope:               make(domain.Scopes, 0),
+		State:               "",
+	}
+}
+
+func (r *TokenPushedAuthorizationRequest) bind(req *http.Request) error {
+	indieAuthError := new(domain.Error)
+
+	if err := req.ParseForm(); err != nil {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc9126#section-2.1")
+	}
+
+	if err := form.Unmarshal([]byte(req.PostForm.Encode()), r); err != nil {
+		if errors.As(err, indieAuthError) {
+			return indieAuthError
+		}
+
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc9126#section-2.1")
+	}
+
+	// NOTE(toby3d): RFC 9126 section 2.1: the request_uri authorization
+	// request parameter is one exception, and it MUST NOT be provided.
+	if r.RequestURI != "" {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "request_uri cannot be pushed",
+			"https://www.rfc-editor.org/rfc/rfc9126#section-2.1")
+	}
+
+	// NOTE(toby3d): backwards-compatible response_type=id is accepted
+	// only if security profile allows it.
+	if r.ResponseType != domain.ResponseTypeCode && r.ResponseType != domain.ResponseTypeID {
+		return domain.NewError(domain.ErrorCodeUnsupportedResponseType, "only code response type is supported",
+			"https://www.rfc-editor.org/rfc/rfc9126#section-2.1")
+	}
+
+	if err := domain.ValidateResources(r.Resource); err != nil {
+		return err //nolint:wrapcheck // domain error
+	}
+
+	if _, err := strconv.ParseUint(r.MaxAge, 10, 32); r.MaxAge != "" && err != nil {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, "max_age must be a number of seconds",
+			"https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest")
+	}
+
+	return nil
+}
+
+// AssertionTypeJWTBearer is the only supported client_assertion_type value.
+const AssertionTypeJWTBearer string = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+func NewClientCredentialsRequest() *ClientCredentialsRequest {
+	return &ClientCredentialsRequest{
+		ClientID:            "",
+		ClientSecret:        "",
+		ClientAssertionType: "",
+		ClientAssertion:     "",
+		Method:              domain.ClientAuthMethodUnd,
+	}
+}
+
+// bind parses client credentials from the Authorization header or request
+// body. Method stays ClientAuthMethodUnd if no client credentials or client_id
+// are provided at all.
+//
+//nolint:cyclop
+func (r *ClientCredentialsRequest) bind(req *http.Request) error {
+	if err := req.ParseForm(); err != nil {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc6749#section-2.3")
+	}
+
+	if err := form.Unmarshal([]byte(req.PostForm.Encode()), r); err != nil {
+		return domain.NewError(domain.ErrorCodeInvalidRequest, err.Error(),
+			"https://www.rfc-editor.org/rfc/rfc6749#section-2.3")
+	}
+
+	methods := make([]domain.ClientAuthMethod, 0, 1)
+
+	if id, secret, ok := req.BasicAuth(); ok {
+		// NOTE(toby3d): RFC 6749 section 2.3.1: client identifier and
+		// password are encoded using "application/x-www-form-urlencoded"
+		// encoding algorithm.
+		var err error
+		if id, err = url.QueryUnescape(id); err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClient, "cannot decode client_id", "")
+		}
+
+		if secret, err = url.QueryUnescape(secret); err != nil {
+			return domain.NewError(domain.ErrorCodeInvalidClient, "cannot decode client_secret", "")
+		}
+
+		if r.ClientID != "" && r.ClientID != id {
+			return domain.NewError(domain.ErrorCodeInvalidRequest,
+				"client_id in request body does not match the Authorization header",
+				"https://www.rfc-editor.org/rfc/rfc6749#section-2.3.1")
+		}
+
+		r.ClientID = id
+		methods = append(methods, domain.ClientAuthMethodClientSecretBasic)
+
+		if req.PostForm.Has("client_secret") {
+			methods = append(methods, domain.ClientAuthMethodClientSecretPost)
+		}
+
+		r.ClientSecret = secret
+	} else if req.PostForm.Has("client_secret") {
+		methods = append(methods, domain.ClientAuthMethodClientSecretPost)
+	}
+
+	if r.ClientAssertionType != "" || r.ClientAssertion != "" {
+		if r.ClientAssertionType != AssertionTypeJWTBearer || r.ClientAssertion == "" {
+			return domain.NewError(domain.ErrorCodeInvalidClient, "unsupported client_assertion_type",
+				"https://www.rfc-editor.org/rfc/rfc7523#section-2.2")
+		}
+
+		// NOTE(toby3d): client_id is OPTIONAL for assertions, the sub
+		// claim identifies the client instead. Signature of the
+		// assertion is verified later by the client use case.
+		if r.ClientID == "" {
+			assertion, err := jwt.ParseString(r.ClientAssertion, jwt.WithVerify(false),
+				jwt.WithValidate(false))
+			if err != nil {
+				return domain.NewError(domain.ErrorCodeInvalidClient, "cannot parse client_assertion",
+					"https://www.rfc-editor.org/rfc/rfc7523#section-2.2")
+			}
+
+			r.ClientID = assertion.Subject()
+		}
+
+		methods = append(methods, domain.ClientAuthMethodPrivateKeyJWT)
+	}
+
+	switch len(methods) {
+	case 0:
+		if r.ClientID != "" {
+			r.Method = domain.ClientAuthMethodNone
+		}
+	case 1:
+		r.Method = methods[0]
+	default:
+		return domain.NewError(domain.ErrorCodeInvalidRequest,
+			"request utilizes more than one mechanism for authenticating the client",
+			"https://www.rfc-editor.org/rfc/rfc6749#section-2.3")
+	}
+
+	return nil
+}
@@ -6,15 +6,31 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 
+	"source.toby3d.me/toby3d/auth/internal/auth"
+	authucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
+	"source.toby3d.me/toby3d/auth/internal/client"
+	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
 	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/oidc"
+	oidcucase "source.toby3d.me/toby3d/auth/internal/oidc/usecase"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	replayrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
 	"source.toby3d.me/toby3d/auth/internal/session"
 	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/token"
@@ -24,20 +40,267 @@ import (
 )
 
 type Dependencies struct {
-	client       *http.Client
-	config       *domain.Config
-	profiles     profile.Repository
-	sessions     session.Repository
-	token        *domain.Token
-	tokens       token.Repository
-	tokenService token.UseCase
+	authService   auth.UseCase
+	client        *http.Client
+	clientService client.UseCase
+	config        *domain.Config
+	oidcService   oidc.UseCase
+	profiles      profile.Repository
+	registered    *domain.Client
+	replays       replay.Repository
+	scopeService  scope.UseCase
+	sessions      session.Repository
+	token         *domain.Token
+	tokens        token.Repository
+	tokenService  token.UseCase
 }
 
-/* TODO(toby3d)
+const testClientSecret string = "5up3r-53cr3t"
+
 func TestExchange(t *testing.T) {
 	t.Parallel()
+
+	deps := NewDependencies(t)
+	session := domain.TestSession(t)
+	session.ClientID = deps.registered.ID
+	session.RedirectURI = deps.registered.RedirectURI[0]
+	session.Scope = domain.Scopes{domain.ScopeOpenID, domain.ScopeProfile}
+	session.AuthTime = time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	session.Nonce = "n-0S6_WzA2Mj"
+	session.ACR = auth.ACRSecondFactor
+	session.AMR = []string{auth.AMRPassword, auth.AMROneTimePassword}
+
+	if err := deps.sessions.Create(context.Background(), *session); err != nil {
+		t.Fatal(err)
+	}
+
+	body := url.Values{
+		"grant_type":    {domain.GrantTypeAuthorizationCode.String()},
+		"client_id":     {session.ClientID.String()},
+		"code":          {session.Code},
+		"redirect_uri":  {session.RedirectURI.String()},
+		"code_verifier": {session.CodeChallenge},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/token", strings.NewReader(body.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+	req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusOK)
+	}
+
+	result := new(delivery.TokenExchangeResponse)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.AccessToken == "" || result.TokenType != "Bearer" || result.Me != session.Me.String() {
+		t.Errorf("%s %s = %+v, want Bearer token for %s", req.Method, req.RequestURI, result, session.Me)
+	}
+
+	if result.Scope != session.Scope.String() ||
+		result.ExpiresIn != int64(deps.config.JWT.Expiry.Seconds()) {
+		t.Errorf("%s %s = %+v, want scope '%s' for %s", req.Method, req.RequestURI, result, session.Scope,
+			deps.config.JWT.Expiry)
+	}
+
+	keys, err := deps.oidcService.KeySet(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idToken, err := jwt.ParseString(result.IDToken, jwt.WithKeySet(keys), jwt.WithValidate(true),
+		jwt.WithAudience(session.ClientID.String()), jwt.WithSubject(session.Me.String()))
+	if err != nil {
+		t.Fatalf("%s %s = %+v, cannot verify ID Token: %s", req.Method, req.RequestURI, result, err)
+	}
+
+	for key, expect := range map[string]any{
+		"nonce":     session.Nonce,
+		"acr":       session.ACR,
+		"auth_time": float64(session.AuthTime.Unix()),
+	} {
+		if actual, _ := idToken.Get(key); actual != expect {
+			t.Errorf("%s %s = %s %v, want %v", req.Method, req.RequestURI, key, actual, expect)
+		}
+	}
+
+	// NOTE(toby3d): resource servers learn how the owner was
+	// authenticated by introspection.
+	req = httptest.NewRequest(http.MethodPost, "https://example.com/introspect",
+		strings.NewReader("token="+result.AccessToken))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAuthorization, "Bearer "+result.AccessToken)
+
+	w = httptest.NewRecorder()
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
+		ServeHTTP(w, req)
+
+	introspection := new(delivery.TokenIntrospectResponse)
+	if err = json.NewDecoder(w.Result().Body).Decode(introspection); err != nil {
+		t.Fatal(err)
+	}
+
+	if introspection.ACR != session.ACR || !reflect.DeepEqual(introspection.AMR, session.AMR) {
+		t.Errorf("%s %s = %+v, want acr %s and amr %v", req.Method, req.RequestURI, introspection,
+			session.ACR, session.AMR)
+	}
+}
+
+func TestExchange_InvalidGrant(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	handler := delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config)
+
+	session := domain.TestSession(t)
+	session.ClientID = deps.registered.ID
+	session.RedirectURI = deps.registered.RedirectURI[0]
+
+	if err := deps.sessions.Create(context.Background(), *session); err != nil {
+		t.Fatal(err)
+	}
+
+	exchange := func(code string) (int, string) {
+		body := url.Values{
+			"grant_type":    {domain.GrantTypeAuthorizationCode.String()},
+			"client_id":     {session.ClientID.String()},
+			"code":          {code},
+			"redirect_uri":  {session.RedirectURI.String()},
+			"code_verifier": {session.CodeChallenge},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/token",
+			strings.NewReader(body.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		result := struct {
+			Error string `json:"error"`
+		}{}
+		_ = json.NewDecoder(w.Result().Body).Decode(&result)
+
+		return w.Result().StatusCode, result.Error
+	}
+
+	if status, _ := exchange(session.Code); status != http.StatusOK {
+		t.Fatalf("POST /token = %d, want %d", status, http.StatusOK)
+	}
+
+	// NOTE(toby3d): replayed and unknown codes are both invalid grants.
+	for _, code := range []string{session.Code, "unknown"} {
+		if status, code := exchange(code); status != http.StatusBadRequest ||
+			code != domain.ErrorCodeInvalidGrant.String() {
+			t.Errorf("POST /token = %d %s, want %d %s", status, code, http.StatusBadRequest,
+				domain.ErrorCodeInvalidGrant)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	handler := delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config)
+
+	session := domain.TestSession(t)
+	session.ClientID = deps.registered.ID
+	session.RedirectURI = deps.registered.RedirectURI[0]
+	session.Scope = domain.Scopes{domain.ScopeCreate, domain.ScopeUpdate}
+
+	if err := deps.sessions.Create(context.Background(), *session); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body url.Values) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/token",
+			strings.NewReader(body.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		result := make(map[string]any)
+		_ = json.NewDecoder(w.Result().Body).Decode(&result)
+
+		return w.Result().StatusCode, result
+	}
+
+	status, exchanged := post(url.Values{
+		"grant_type":    {domain.GrantTypeAuthorizationCode.String()},
+		"client_id":     {session.ClientID.String()},
+		"code":          {session.Code},
+		"redirect_uri":  {session.RedirectURI.String()},
+		"code_verifier": {session.CodeChallenge},
+	})
+
+	refreshToken, _ := exchanged["refresh_token"].(string)
+	if status != http.StatusOK || refreshToken == "" {
+		t.Fatalf("POST /token = %d %+v, want refresh token", status, exchanged)
+	}
+
+	// NOTE(toby3d): refresh token is never accepted as the access token.
+	if _, _, err := deps.tokenService.Verify(context.Background(), refreshToken); err == nil {
+		t.Error("Verify(refresh_token) = nil, want error")
+	}
+
+	refresh := func(refreshToken, scope string) (int, map[string]any) {
+		return post(url.Values{
+			"grant_type":    {domain.GrantTypeRefreshToken.String()},
+			"client_id":     {session.ClientID.String()},
+			"refresh_token": {refreshToken},
+			"scope":         {scope},
+		})
+	}
+
+	if status, result := refresh(refreshToken, "create delete"); status != http.StatusBadRequest ||
+		result["error"] != domain.ErrorCodeInvalidScope.String() {
+		t.Errorf("POST /token = %d %+v, want %s", status, result, domain.ErrorCodeInvalidScope)
+	}
+
+	status, refreshed := refresh(refreshToken, "create")
+	if status != http.StatusOK || refreshed["scope"] != "create" || refreshed["access_token"] == "" ||
+		refreshed["refresh_token"] == "" || refreshed["refresh_token"] == refreshToken {
+		t.Fatalf("POST /token = %d %+v, want rotated tokens with 'create' scope", status, refreshed)
+	}
+
+	accessToken, _ := refreshed["access_token"].(string)
+	if _, _, err := deps.tokenService.Verify(context.Background(), accessToken); err != nil {
+		t.Errorf("Verify(%s) = %v, want nil", accessToken, err)
+	}
+
+	// NOTE(toby3d): reuse of the rotated refresh token revokes all tokens
+	// of the grant.
+	if status, result := refresh(refreshToken, ""); status != http.StatusBadRequest ||
+		result["error"] != domain.ErrorCodeInvalidGrant.String() {
+		t.Errorf("POST /token = %d %+v, want %s", status, result, domain.ErrorCodeInvalidGrant)
+	}
+
+	if _, _, err := deps.tokenService.Verify(context.Background(), accessToken); err == nil {
+		t.Errorf("Verify(%s) = nil, want error after refresh token reuse", accessToken)
+	}
+
+	latest, _ := refreshed["refresh_token"].(string)
+	if status, result := refresh(latest, ""); status != http.StatusBadRequest {
+		t.Errorf("POST /token = %d %+v, want %d", status, result, http.StatusBadRequest)
+	}
 }
-*/
 
 func TestIntrospection(t *testing.T) {
 	t.Parallel()
@@ -48,9 +311,11 @@ func TestIntrospection(t *testing.T) {
 		strings.NewReader("token="+deps.token.AccessToken))
 	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
 	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAuthorization, "Bearer "+deps.token.AccessToken)
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(deps.tokenService, *deps.config).
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
 		ServeHTTP(w, req)
 
 	resp := w.Result()
@@ -73,6 +338,70 @@ func TestIntrospection(t *testing.T) {
 	}
 }
 
+func TestIntrospection_IDToken(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	key := domain.TestSigningKey(t)
+	tokenService := tokenucase.NewTokenUseCase(tokenucase.Config{
+		Config:     *deps.config,
+		Profiles:   deps.profiles,
+		Sessions:   deps.sessions,
+		SigningKey: key,
+		Tokens:     deps.tokens,
+	})
+
+	accessToken, err := domain.NewToken(domain.NewTokenOptions{
+		ClientID:   deps.token.ClientID,
+		Subject:    deps.token.Me,
+		Issuer:     deps.config.Server.GetRootURL(),
+		Key:        key,
+		Scope:      domain.Scopes{domain.ScopeProfile},
+		Expiration: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE(toby3d): ID token is signed by the same key, but it's addressed
+	// to the client.
+	idToken, err := oidcucase.NewOIDCUseCase(key, nil, *deps.config).IDToken(context.Background(), *accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := delivery.NewHandler(tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config)
+
+	for name, tc := range map[string]struct {
+		token     string
+		expActive bool
+	}{
+		"access token": {token: accessToken.AccessToken, expActive: true},
+		"ID token":     {token: idToken, expActive: false},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "https://app.example.com/introspect",
+			strings.NewReader("token="+tc.token))
+		req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.Header.Set(common.HeaderAuthorization, "Bearer "+accessToken.AccessToken)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		result := struct {
+			Active bool `json:"active"`
+		}{}
+		if err = json.NewDecoder(w.Result().Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Active != tc.expActive {
+			t.Errorf("%s: %s %s = %t, want %t", name, req.Method, req.RequestURI, result.Active, tc.expActive)
+		}
+	}
+}
+
 func TestRevocation(t *testing.T) {
 	t.Parallel()
 
@@ -84,7 +413,8 @@ func TestRevocation(t *testing.T) {
 	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(deps.tokenService, *deps.config).
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
 		ServeHTTP(w, req)
 
 	resp := w.Result()
@@ -113,6 +443,553 @@ func TestRevocation(t *testing.T) {
 	}
 }
 
+func TestVerification(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.Legacy.TokenVerification = true
+
+	tkn, err := domain.NewToken(domain.NewTokenOptions{
+		Expiration: time.Hour,
+		ClientID:   *domain.TestClientID(t),
+		Subject:    *domain.TestMe(t, "https://example.com/"),
+		Scope:      domain.Scopes{domain.ScopeCreate, domain.ScopeUpdate, domain.ScopeDelete},
+		Secret:     []byte(deps.config.JWT.Secret),
+		Algorithm:  deps.config.JWT.Algorithm,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonBody := `{"me":"https://example.com/","client_id":"` + tkn.ClientID.String() +
+		`","scope":"create update delete"}`
+	formBody := url.Values{
+		"me":        {"https://example.com/"},
+		"client_id": {tkn.ClientID.String()},
+		"scope":     {"create update delete"},
+	}.Encode()
+
+	for name, tc := range map[string]struct {
+		accept        string
+		authorization string
+		expType       string
+		expBody       string
+		expStatus     int
+	}{
+		"json": {
+			accept:        common.MIMEApplicationJSON,
+			authorization: "Bearer " + tkn.AccessToken,
+			expStatus:     http.StatusOK,
+			expType:       common.MIMEApplicationJSONCharsetUTF8,
+			expBody:       jsonBody,
+		},
+		"form": {
+			accept:        common.MIMEApplicationForm,
+			authorization: "Bearer " + tkn.AccessToken,
+			expStatus:     http.StatusOK,
+			expType:       common.MIMEApplicationForm,
+			expBody:       formBody,
+		},
+		"json preferred": {
+			accept:        "text/html, " + common.MIMEApplicationJSON + ";q=0.9, " + common.MIMEApplicationForm,
+			authorization: "Bearer " + tkn.AccessToken,
+			expStatus:     http.StatusOK,
+			expType:       common.MIMEApplicationJSONCharsetUTF8,
+			expBody:       jsonBody,
+		},
+		"any": {
+			accept:        "*/*",
+			authorization: "Bearer " + tkn.AccessToken,
+			expStatus:     http.StatusOK,
+			expType:       common.MIMEApplicationForm,
+			expBody:       formBody,
+		},
+		"invalid token": {
+			accept:        common.MIMEApplicationJSON,
+			authorization: "Bearer xxxxxxxx",
+			expStatus:     http.StatusUnauthorized,
+			expType:       common.MIMEApplicationJSONCharsetUTF8,
+		},
+		"missing token": {
+			accept:    common.MIMEApplicationJSON,
+			expStatus: http.StatusUnauthorized,
+			expType:   common.MIMEApplicationJSONCharsetUTF8,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/token", nil)
+			req.Header.Set(common.HeaderAccept, tc.accept)
+
+			if tc.authorization != "" {
+				req.Header.Set(common.HeaderAuthorization, tc.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+				deps.scopeService, deps.replays, *deps.config).
+				ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if result := resp.Header.Get(common.HeaderContentType); result != tc.expType {
+				t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI, result, tc.expType)
+			}
+
+			if result := string(bytes.TrimSpace(body)); tc.expBody != "" && result != tc.expBody {
+				t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI, result, tc.expBody)
+			}
+		})
+	}
+}
+
+func TestVerification_Disabled(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/token", nil)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+	req.Header.Set(common.HeaderAuthorization, "Bearer "+deps.token.AccessToken)
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
+		ServeHTTP(w, req)
+
+	if result := w.Result().StatusCode; result != http.StatusMethodNotAllowed {
+		t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, result, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	cid := url.QueryEscape(deps.registered.ID.String())
+
+	for name, tc := range map[string]struct {
+		body       url.Values
+		username   string
+		password   string
+		expHeader  string
+		expStatus  int
+		authHeader bool
+	}{
+		"client_secret_basic": {
+			authHeader: true,
+			username:   cid,
+			password:   testClientSecret,
+			expStatus:  http.StatusOK,
+		},
+		"client_secret_post": {
+			body:      url.Values{"client_id": {cid}, "client_secret": {testClientSecret}},
+			expStatus: http.StatusOK,
+		},
+		"invalid secret": {
+			authHeader: true,
+			username:   cid,
+			password:   "wrong",
+			expStatus:  http.StatusUnauthorized,
+			expHeader:  `Basic realm="` + deps.config.Server.GetRootURL() + `"`,
+		},
+		"multiple methods": {
+			authHeader: true,
+			username:   cid,
+			password:   testClientSecret,
+			body:       url.Values{"client_secret": {testClientSecret}},
+			expStatus:  http.StatusBadRequest,
+		},
+		"no authentication": {
+			expStatus: http.StatusUnauthorized,
+			expHeader: `Basic realm="` + deps.config.Server.GetRootURL() + `"`,
+		},
+		"confidential client without secret": {
+			body:      url.Values{"client_id": {cid}},
+			expStatus: http.StatusUnauthorized,
+			expHeader: `Basic realm="` + deps.config.Server.GetRootURL() + `"`,
+		},
+		"unsupported assertion type": {
+			body: url.Values{
+				"client_id":             {cid},
+				"client_assertion_type": {"urn:example:unknown"},
+				"client_assertion":      {"eyJ"},
+			},
+			expStatus: http.StatusUnauthorized,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body := url.Values{"token": {deps.token.AccessToken}}
+			for k, v := range tc.body {
+				body[k] = v
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/introspect",
+				strings.NewReader(body.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+			if tc.authHeader {
+				req.SetBasicAuth(tc.username, tc.password)
+			}
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+				deps.scopeService, deps.replays, *deps.config).
+				ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if result := resp.Header.Get(common.HeaderWWWAuthenticate); tc.expHeader != "" &&
+				result != tc.expHeader {
+				t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI, result, tc.expHeader)
+			}
+
+			if tc.expStatus != http.StatusUnauthorized {
+				return
+			}
+
+			result := struct {
+				Error string `json:"error"`
+			}{}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Error != domain.ErrorCodeInvalidClient.String() {
+				t.Errorf("%s %s = %s, want %s", req.Method, req.RequestURI, result.Error,
+					domain.ErrorCodeInvalidClient)
+			}
+		})
+	}
+}
+
+func TestPushedAuthorization(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	body := url.Values{
+		"client_id":             {deps.registered.ID.String()},
+		"redirect_uri":          {deps.registered.RedirectURI[0].String()},
+		"response_type":         {domain.ResponseTypeCode.String()},
+		"state":                 {"1234567890"},
+		"code_challenge":        {"hackme"},
+		"code_challenge_method": {domain.CodeChallengeMethodPLAIN.String()},
+		"scope":                 {"create profile"},
+		"prompt":                {"login consent"},
+		"max_age":               {"300"},
+		"login_hint":            {"https://user.example.net/"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/par", strings.NewReader(body.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+	req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config).
+		ServeHTTP(w, req)
+
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, http.StatusCreated)
+	}
+
+	result := new(delivery.TokenPushedAuthorizationResponse)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(result.RequestURI, auth.RequestURIPrefix) ||
+		result.ExpiresIn != int64(deps.config.PAR.Expiry.Seconds()) {
+		t.Errorf("%s %s = %+v, want %s request_uri which expires in %s", req.Method, req.RequestURI, result,
+			auth.RequestURIPrefix, deps.config.PAR.Expiry)
+	}
+
+	pushed, err := deps.authService.Pull(context.Background(), deps.registered.ID, result.RequestURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pushed.State != body.Get("state") || pushed.Scope.String() != body.Get("scope") ||
+		pushed.RedirectURI.String() != body.Get("redirect_uri") || pushed.Prompt.String() != body.Get("prompt") ||
+		pushed.MaxAge != body.Get("max_age") || pushed.LoginHint != body.Get("login_hint") {
+		t.Errorf("Pull(%s) = %+v, want %+v", result.RequestURI, pushed, body)
+	}
+
+	if _, err = deps.authService.Pull(context.Background(), *domain.TestClientID(t),
+		result.RequestURI); err == nil {
+		t.Errorf("Pull(%s) = nil, want %v", result.RequestURI, auth.ErrInvalidRequestURI)
+	}
+}
+
+// TestStrictProfile checks the token endpoint rules of the strict security
+// profile.
+//
+//nolint:funlen
+func TestStrictProfile(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.config.Security.Profile = domain.SecurityProfileStrict.String()
+	deps.authService = authucase.NewAuthUseCase(deps.sessions, deps.profiles, nil, nil, *deps.config)
+	deps.tokenService = tokenucase.NewTokenUseCase(tokenucase.Config{
+		Config:   *deps.config,
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	})
+	handler := delivery.NewHandler(deps.tokenService, deps.authService, deps.clientService, deps.oidcService,
+		deps.scopeService, deps.replays, *deps.config)
+	key := domain.TestDPoPKey(t)
+	tokenEndpoint, _ := url.Parse(deps.config.Server.GetRootURL() + "token")
+
+	exchange := func(t *testing.T, proofURL *url.URL) *http.Response {
+		t.Helper()
+
+		session := domain.TestSession(t)
+		session.ClientID = deps.registered.ID
+		session.RedirectURI = deps.registered.RedirectURI[0]
+		session.CodeChallengeMethod = domain.CodeChallengeMethodS256
+		session.CodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+		if err := deps.sessions.Create(context.Background(), *session); err != nil {
+			t.Fatal(err)
+		}
+
+		body := url.Values{
+			"grant_type":    {domain.GrantTypeAuthorizationCode.String()},
+			"client_id":     {session.ClientID.String()},
+			"code":          {session.Code},
+			"redirect_uri":  {session.RedirectURI.String()},
+			"code_verifier": {"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/token",
+			strings.NewReader(body.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+		req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+		if proofURL != nil {
+			proof, err := domain.NewDPoPProof(key, jwa.ES256, session.Code, domain.DPoPProofOptions{
+				URL:         proofURL,
+				Method:      http.MethodPost,
+				AccessToken: "",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set(common.HeaderDPoP, proof)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w.Result()
+	}
+
+	for name, proofURL := range map[string]*url.URL{
+		"without dpop proof": nil,
+		"with proof of another endpoint": {
+			Scheme: tokenEndpoint.Scheme,
+			Host:   tokenEndpoint.Host,
+			Path:   "/introspect",
+		},
+	} {
+		name, proofURL := name, proofURL
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := exchange(t, proofURL)
+
+			result := struct {
+				Error string `json:"error"`
+			}{}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != http.StatusBadRequest ||
+				result.Error != domain.ErrorCodeInvalidDPoPProof.String() {
+				t.Errorf("POST /token = %d %s, want %d %s", resp.StatusCode, result.Error,
+					http.StatusBadRequest, domain.ErrorCodeInvalidDPoPProof)
+			}
+		})
+	}
+
+	t.Run("with dpop proof", func(t *testing.T) {
+		t.Parallel()
+
+		resp := exchange(t, tokenEndpoint)
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("POST /token = %d %s, want %d", resp.StatusCode, body, http.StatusOK)
+		}
+
+		result := new(delivery.TokenExchangeResponse)
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result.TokenType != "DPoP" {
+			t.Errorf("POST /token = %s token, want DPoP", result.TokenType)
+		}
+
+		tkn, _, err := deps.tokenService.Verify(context.Background(), result.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		introspectEndpoint, _ := url.Parse(deps.config.Server.GetRootURL() + "introspect")
+
+		proof, err := domain.NewDPoPProof(key, jwa.ES256, "introspect", domain.DPoPProofOptions{
+			URL:         introspectEndpoint,
+			Method:      http.MethodPost,
+			AccessToken: result.AccessToken,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, tc := range map[string]struct {
+			scheme    string
+			expStatus int
+		}{
+			"bearer scheme": {scheme: "Bearer", expStatus: http.StatusUnauthorized},
+			"dpop scheme":   {scheme: "DPoP", expStatus: http.StatusOK},
+		} {
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/introspect",
+				strings.NewReader("token="+result.AccessToken))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.Header.Set(common.HeaderAuthorization, tc.scheme+" "+result.AccessToken)
+			req.Header.Set(common.HeaderDPoP, proof)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s: POST /introspect = %d, want %d", name, resp.StatusCode, tc.expStatus)
+
+				continue
+			}
+
+			if tc.expStatus != http.StatusOK {
+				continue
+			}
+
+			introspection := new(delivery.TokenIntrospectResponse)
+			if err = json.NewDecoder(resp.Body).Decode(introspection); err != nil {
+				t.Fatal(err)
+			}
+
+			if introspection.Cnf == nil || introspection.Cnf.JKT != tkn.JKT || introspection.TokenType != "DPoP" {
+				t.Errorf("%s: POST /introspect = %+v, want DPoP token bound to %s", name, introspection,
+					tkn.JKT)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/introspect",
+			strings.NewReader("token="+result.AccessToken))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+		req.Header.Set(common.HeaderAuthorization, "DPoP "+result.AccessToken)
+		req.Header.Set(common.HeaderDPoP, proof)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if resp := w.Result(); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("replayed proof: POST /introspect = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		body    url.Values
+		expCode domain.ErrorCode
+	}{
+		"par with legacy response type": {
+			body: url.Values{
+				"response_type":         {domain.ResponseTypeID.String()},
+				"code_challenge_method": {"S256"},
+			},
+			expCode: domain.ErrorCodeUnsupportedResponseType,
+		},
+		"par with plain pkce": {
+			body:    url.Values{"code_challenge_method": {domain.CodeChallengeMethodPLAIN.String()}},
+			expCode: domain.ErrorCodeInvalidRequest,
+		},
+		"par without pkce": {
+			body:    url.Values{"code_challenge": {""}},
+			expCode: domain.ErrorCodeInvalidRequest,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body := url.Values{
+				"client_id":             {deps.registered.ID.String()},
+				"redirect_uri":          {deps.registered.RedirectURI[0].String()},
+				"response_type":         {domain.ResponseTypeCode.String()},
+				"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+				"code_challenge_method": {"S256"},
+				"scope":                 {"profile"},
+			}
+			for k, v := range tc.body {
+				body[k] = v
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/par",
+				strings.NewReader(body.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req.SetBasicAuth(deps.registered.ID.String(), testClientSecret)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			result := struct {
+				Error string `json:"error"`
+			}{}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != http.StatusBadRequest || result.Error != tc.expCode.String() {
+				t.Errorf("POST /par = %d %s, want %d %s", resp.StatusCode, result.Error,
+					http.StatusBadRequest, tc.expCode)
+			}
+		})
+	}
+}
+
 func NewDependencies(tb testing.TB) Dependencies {
 	tb.Helper()
 
@@ -129,13 +1006,34 @@ func NewDependencies(tb testing.TB) Dependencies {
 		Tokens:   tokens,
 	})
 
+	cid, err := domain.ParseClientID("Zs8H1y3_mPq-4kXw2nLc")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	registered := domain.TestClient(tb)
+	registered.ID = *cid
+	registered.AuthMethod = domain.ClientAuthMethodClientSecretBasic
+	registered.Secret = domain.HashSecret(testClientSecret)
+
+	registry := clientrepo.NewMemoryClientRepository()
+	if err = registry.Create(context.Background(), *registered); err != nil {
+		tb.Fatal(err)
+	}
+
 	return Dependencies{
-		client:       client,
-		config:       config,
-		profiles:     profiles,
-		sessions:     sessions,
-		token:        token,
-		tokens:       tokens,
-		tokenService: tokenService,
+		authService:   authucase.NewAuthUseCase(sessions, profiles, nil, nil, *config),
+		client:        client,
+		clientService: clientucase.NewClientUseCase(clientrepo.NewMemoryClientRepository(), registry, nil, nil, nil, nil),
+		config:        config,
+		oidcService:   oidcucase.NewOIDCUseCase(domain.TestSigningKey(tb), nil, *config),
+		profiles:      profiles,
+		registered:    registered,
+		replays:       replayrepo.NewMemoryReplayRepository(),
+		scopeService:  scopeucase.NewScopeUseCase(domain.ScopePolicyReject),
+		sessions:      sessions,
+		token:         token,
+		tokens:        tokens,
+		tokenService:  tokenService,
 	}
 }
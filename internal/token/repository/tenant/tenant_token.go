@@ -0,0 +1,40 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/token"
+)
+
+type tenantTokenRepository struct {
+	repo      token.Repository
+	namespace string
+}
+
+// NewTenantTokenRepository creates a new tokens repository which stores tokens
+// of the tenant in the provided shared repository under its own namespace.
+func NewTenantTokenRepository(repo token.Repository, namespace string) token.Repository {
+	return &tenantTokenRepository{
+		namespace: namespace + ":",
+		repo:      repo,
+	}
+}
+
+func (repo *tenantTokenRepository) Create(ctx context.Context, t domain.Token) error {
+	t.AccessToken = repo.namespace + t.AccessToken
+
+	return repo.repo.Create(ctx, t) //nolint:wrapcheck // decorator returns errors as is
+}
+
+func (repo *tenantTokenRepository) Get(ctx context.Context, accessToken string) (*domain.Token, error) {
+	out, err := repo.repo.Get(ctx, repo.namespace+accessToken)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // decorator returns errors as is
+	}
+
+	out.AccessToken = strings.TrimPrefix(out.AccessToken, repo.namespace)
+
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/token"
+	"source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	repository "source.toby3d.me/toby3d/auth/internal/token/repository/tenant"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	shared := memory.NewMemoryTokenRepository()
+	alice := repository.NewTenantTokenRepository(shared, "alice")
+	bob := repository.NewTenantTokenRepository(shared, "bob")
+	tkn := domain.TestToken(t)
+
+	if err := alice.Create(context.Background(), *tkn); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bob.Get(context.Background(), tkn.AccessToken); !errors.Is(err, token.ErrNotExist) {
+		t.Errorf("Get(%s) = %v, want %v", tkn.AccessToken, err, token.ErrNotExist)
+	}
+
+	result, err := alice.Get(context.Background(), tkn.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.AccessToken != tkn.AccessToken {
+		t.Errorf("Get(%s) = %s, want %s", tkn.AccessToken, result.AccessToken, tkn.AccessToken)
+	}
+}
@@ -13,16 +13,46 @@ type (
 		RedirectURI  *url.URL
 		Code         string
 		CodeVerifier string
+		// JKT is the thumbprint of the client key proven by DPoP
+		// proof, which the token is bound to. Empty for the bearer
+		// tokens.
+		JKT string
+	}
+
+	RefreshOptions struct {
+		ClientID     domain.ClientID
+		RefreshToken string
+		// Scope is the same or fewer scopes than originally granted,
+		// empty if the same.
+		Scope domain.Scopes
+		// JKT is the thumbprint of the client key proven by DPoP
+		// proof. It must match the key which the refresh token is bound
+		// to.
+		JKT string
+	}
+
+	RevokeOptions struct {
+		// ClientID is the authenticated client which requests the
+		// revocation, nil for the public clients. Authenticated
+		// client can revoke only tokens issued to itself.
+		ClientID *domain.ClientID
+		Token    string
 	}
 
 	UseCase interface {
 		Exchange(ctx context.Context, opts ExchangeOptions) (*domain.Token, *domain.Profile, error)
 
+		// Refresh exchanges the refresh token for a new access token
+		// and a new refresh token. Each refresh token can be used only
+		// once: reuse revokes all tokens of the same grant.
+		Refresh(ctx context.Context, opts RefreshOptions) (*domain.Token, *domain.Profile, error)
+
 		// Verify checks the AccessToken and returns the associated information.
 		Verify(ctx context.Context, accessToken string) (*domain.Token, *domain.Profile, error)
 
-		// Revoke revokes the AccessToken and blocks its further use.
-		Revoke(ctx context.Context, accessToken string) error
+		// Revoke revokes the access token and blocks its further use,
+		// or all tokens of the same grant for the refresh token.
+		Revoke(ctx context.Context, opts RevokeOptions) error
 	}
 )
 
@@ -33,12 +63,17 @@ var (
 		"",
 	)
 	ErrMismatchClientID error = domain.NewError(
-		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidGrant,
 		"client's URL MUST match the client_id used in the authentication request",
 		"https://indieauth.net/source/#request",
 	)
+	ErrForeignToken error = domain.NewError(
+		domain.ErrorCodeUnauthorizedClient,
+		"token was issued to another client",
+		"https://www.rfc-editor.org/rfc/rfc7009#section-2.1",
+	)
 	ErrMismatchRedirectURI error = domain.NewError(
-		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidGrant,
 		"client's redirect URL MUST match the initial authentication request",
 		"https://indieauth.net/source/#request",
 	)
@@ -47,8 +82,33 @@ var (
 		"empty scopes are invalid",
 		"",
 	)
+	ErrDPoPRequired error = domain.NewError(
+		domain.ErrorCodeInvalidDPoPProof,
+		"access token must be bound to the client key by DPoP proof",
+		"https://www.rfc-editor.org/rfc/rfc9449#section-5",
+	)
+	ErrInvalidRefreshToken error = domain.NewError(
+		domain.ErrorCodeInvalidGrant,
+		"refresh token is invalid, expired, revoked or was issued to another client",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-6",
+	)
+	ErrRefreshToken error = domain.NewError(
+		domain.ErrorCodeInvalidToken,
+		"refresh token cannot be used as the access token",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-1.5",
+	)
+	ErrMismatchJKT error = domain.NewError(
+		domain.ErrorCodeInvalidGrant,
+		"refresh token is bound to another key by DPoP proof",
+		"https://www.rfc-editor.org/rfc/rfc9449#section-5",
+	)
+	ErrScopeExceeded error = domain.NewError(
+		domain.ErrorCodeInvalidScope,
+		"requested scope MUST NOT include any scope not originally granted by the resource owner",
+		"https://www.rfc-editor.org/rfc/rfc6749#section-6",
+	)
 	ErrMismatchPKCE error = domain.NewError(
-		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidGrant,
 		"code_verifier is not hashes to the same value as given in the code_challenge in the original "+
 			"authorization request",
 		"https://indieauth.net/source/#request",
@@ -4,37 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 
+	"source.toby3d.me/toby3d/auth/internal/audit"
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	"source.toby3d.me/toby3d/auth/internal/session"
+	sessionucase "source.toby3d.me/toby3d/auth/internal/session/usecase"
 	"source.toby3d.me/toby3d/auth/internal/token"
 )
 
 type (
 	Config struct {
+		Audit    audit.Repository
+		Policies policy.UseCase
 		Profiles profile.Repository
 		Sessions session.Repository
 		Tokens   token.Repository
-		Config   domain.Config
+		// SigningKey is the optional private key which signs tokens
+		// instead of the JWT secret, so resource servers can verify
+		// them by the published key set.
+		SigningKey jwk.Key
+		// Clock provides the current time. System clock is used if
+		// nil.
+		Clock  domain.Clock
+		Config domain.Config
 	}
 
 	tokenUseCase struct {
+		audit    audit.Repository
+		clock    domain.Clock
+		key      jwk.Key
+		replays  session.UseCase
+		policies policy.UseCase
 		profiles profile.Repository
 		sessions session.Repository
 		tokens   token.Repository
 		config   domain.Config
+		// mutex guards rotation of refresh tokens, so the same refresh
+		// token cannot be exchanged twice.
+		mutex sync.Mutex
 	}
 )
 
 func NewTokenUseCase(config Config) token.UseCase {
 	jwt.RegisterCustomField("scope", make(domain.Scopes, 0))
 
+	if config.Clock == nil {
+		config.Clock = domain.SystemClock
+	}
+
 	return &tokenUseCase{
+		audit:    config.Audit,
+		clock:    config.Clock,
+		key:      config.SigningKey,
+		replays:  sessionucase.NewSessionUseCase(config.Sessions, config.Audit, config.Clock),
 		config:   config.Config,
+		policies: config.Policies,
 		profiles: config.Profiles,
 		sessions: config.Sessions,
 		tokens:   config.Tokens,
@@ -45,51 +80,277 @@ func NewTokenUseCase(config Config) token.UseCase {
 func (uc *tokenUseCase) Exchange(ctx context.Context, opts token.ExchangeOptions) (*domain.Token, *domain.Profile,
 	error,
 ) {
-	session, err := uc.sessions.GetAndDelete(ctx, opts.Code)
+	if strings.HasPrefix(opts.Code, session.PushedPrefix) {
+		return nil, nil, fmt.Errorf("cannot get session from store: %w", session.ErrNotExist)
+	}
+
+	s, err := uc.sessions.GetAndDelete(ctx, opts.Code)
 	if err != nil {
+		if replayErr := uc.replays.DetectReplay(ctx, opts.Code); replayErr != nil {
+			return nil, nil, replayErr
+		}
+
 		return nil, nil, fmt.Errorf("cannot get session from store: %w", err)
 	}
 
-	if opts.ClientID.String() != session.ClientID.String() {
+	// NOTE(toby3d): pushed authorization request is stored with the codes,
+	// but it's never approved by the owner.
+	if s.Pushed {
+		return nil, nil, fmt.Errorf("cannot get session from store: %w", session.ErrNotExist)
+	}
+
+	// NOTE(toby3d): store can keep expired codes until the next garbage
+	// collection.
+	if !s.Expiry.IsZero() && uc.clock.Now().After(s.Expiry) {
+		return nil, nil, session.ErrExpired
+	}
+
+	if opts.ClientID.String() != s.ClientID.String() {
 		return nil, nil, token.ErrMismatchClientID
 	}
 
-	if opts.RedirectURI.String() != session.RedirectURI.String() {
+	if opts.RedirectURI.String() != s.RedirectURI.String() {
 		return nil, nil, token.ErrMismatchRedirectURI
 	}
 
-	if session.CodeChallenge != "" && session.CodeChallengeMethod != domain.CodeChallengeMethodUnd &&
-		!session.CodeChallengeMethod.Validate(session.CodeChallenge, opts.CodeVerifier) {
+	if s.CodeChallenge != "" && s.CodeChallengeMethod != domain.CodeChallengeMethodUnd &&
+		!s.CodeChallengeMethod.Validate(s.CodeChallenge, opts.CodeVerifier) {
 		return nil, nil, token.ErrMismatchPKCE
 	}
 
+	profile := uc.config.Security.GetProfile()
+
+	// NOTE(toby3d): profile can be changed after the code is issued.
+	if err = profile.ValidateCodeChallenge(s.CodeChallengeMethod, s.CodeChallenge); err != nil {
+		return nil, nil, err //nolint:wrapcheck // domain error
+	}
+
+	if profile.RequireSenderConstrainedTokens() && opts.JKT == "" {
+		return nil, nil, token.ErrDPoPRequired
+	}
+
+	// NOTE(toby3d): policy can be changed after the code is issued, so it
+	// is evaluated again before any token is minted.
+	decision := &domain.PolicyDecision{Rule: nil, Scope: s.Scope, Stripped: nil}
+
+	if uc.policies != nil {
+		if decision, err = uc.policies.Evaluate(ctx, domain.PolicyRequest{
+			ClientID:    s.ClientID,
+			RedirectURI: s.RedirectURI,
+			Me:          &s.Me,
+			Scope:       s.Scope,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("cannot evaluate policy: %w", err)
+		}
+
+		if decision.RequirePKCE() && s.CodeChallenge == "" {
+			return nil, nil, policy.ErrPKCERequired
+		}
+
+		s.Scope = decision.Scope
+	}
+
 	// NOTE(toby3d): If the authorization code was issued with no scope, the
 	// token endpoint MUST NOT issue an access token, as empty scopes are
 	// invalid (RFC 6749 section 3.3).
-	if session.Scope.IsEmpty() {
+	if s.Scope.IsEmpty() {
 		return nil, nil, token.ErrEmptyScope
 	}
 
-	if !session.Scope.Has(domain.ScopeProfile) {
-		session.Profile = nil
-	} else if !session.Scope.Has(domain.ScopeEmail) {
-		session.Profile.Email = nil
+	// NOTE(toby3d): profile can be unavailable even if the profile scope
+	// is granted.
+	if !s.Scope.Has(domain.ScopeProfile) {
+		s.Profile = nil
+	} else if s.Profile != nil && !s.Scope.Has(domain.ScopeEmail) {
+		s.Profile.Email = nil
+	}
+
+	expiration := decision.Expiration(s.GrantExpiry, uc.config.JWT.Expiry)
+
+	// NOTE(toby3d): refresh token is useless if the access token never
+	// expires or outlives it.
+	refreshExpiration := decision.RefreshExpiration(s.GrantExpiry, uc.config.JWT.RefreshExpiry)
+	if expiration == 0 || refreshExpiration <= expiration {
+		refreshExpiration = 0
 	}
 
 	tkn, err := domain.NewToken(domain.NewTokenOptions{
-		Expiration:  uc.config.JWT.Expiry,
-		Issuer:      session.ClientID,
-		Subject:     session.Me,
-		Scope:       session.Scope,
-		Secret:      []byte(uc.config.JWT.Secret),
-		Algorithm:   uc.config.JWT.Algorithm,
-		NonceLength: uc.config.JWT.NonceLength,
+		Expiration:        expiration,
+		RefreshExpiration: refreshExpiration,
+		ClientID:          s.ClientID,
+		Issuer:            uc.config.Server.GetRootURL(),
+		Key:               uc.key,
+		Subject:           s.Me,
+		Family:            domain.NewRedemptionID(opts.Code),
+		Audience:          s.Resource,
+		AuthTime:          s.AuthTime,
+		IssuedAt:          uc.clock.Now(),
+		Scope:             s.Scope,
+		Secret:            []byte(uc.config.JWT.Secret),
+		Algorithm:         uc.config.JWT.Algorithm,
+		JKT:               opts.JKT,
+		ACR:               s.ACR,
+		AMR:               s.AMR,
+		NonceLength:       uc.config.JWT.NonceLength,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot generate a new access token: %w", err)
 	}
 
-	return tkn, session.Profile, nil
+	tkn.Nonce = s.Nonce
+
+	// NOTE(toby3d): token issue time is truncated to seconds, but the
+	// order of redemptions is important to revoke the oldest tokens first.
+	if err = uc.sessions.CreateRedemption(ctx, domain.Redemption{
+		CreatedAt:    uc.clock.Now(),
+		Expiry:       familyExpiry(*tkn),
+		ClientID:     s.ClientID,
+		Me:           s.Me,
+		ID:           tkn.Family,
+		RefreshToken: refreshTokenHash(tkn.RefreshToken),
+		TokenIDs:     []string{tkn.ID},
+	}); err != nil {
+		return nil, nil, fmt.Errorf("cannot record redemption of the code: %w", err)
+	}
+
+	if err = uc.capTokens(ctx, s.ClientID, tkn.Family, decision.MaxTokens()); err != nil {
+		return nil, nil, err
+	}
+
+	return tkn, s.Profile, nil
+}
+
+//nolint:cyclop,funlen
+func (uc *tokenUseCase) Refresh(ctx context.Context, opts token.RefreshOptions) (*domain.Token, *domain.Profile,
+	error,
+) {
+	old, refresh, err := uc.parse(opts.RefreshToken)
+	if err != nil || !refresh || old.Family == "" || !old.ClientID.IsEqual(opts.ClientID) {
+		return nil, nil, token.ErrInvalidRefreshToken
+	}
+
+	// NOTE(toby3d): RFC 9449 section 5: refresh token bound to the key can
+	// be used only with the proof of the same key.
+	if old.JKT != "" && opts.JKT != old.JKT {
+		return nil, nil, token.ErrMismatchJKT
+	}
+
+	if old.JKT == "" {
+		old.JKT = opts.JKT
+	}
+
+	if old.JKT == "" && uc.config.Security.GetProfile().RequireSenderConstrainedTokens() {
+		return nil, nil, token.ErrDPoPRequired
+	}
+
+	scope := old.Scope
+
+	if !opts.Scope.IsEmpty() {
+		for i := range opts.Scope {
+			if !old.Scope.Has(opts.Scope[i]) {
+				return nil, nil, token.ErrScopeExceeded
+			}
+		}
+
+		scope = opts.Scope
+	}
+
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	redemption, err := uc.sessions.GetRedemption(ctx, old.Family)
+	if err != nil || redemption.IsRevoked() {
+		return nil, nil, token.ErrInvalidRefreshToken
+	}
+
+	// NOTE(toby3d): each refresh token is rotated on use, so the reuse of
+	// the old one means that it has been leaked.
+	if redemption.RefreshToken != refreshTokenHash(opts.RefreshToken) {
+		if err = uc.revokeFamily(ctx, *redemption); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, token.ErrInvalidRefreshToken
+	}
+
+	// NOTE(toby3d): policy can be changed after the grant, so it is
+	// evaluated again before any token is minted.
+	decision := &domain.PolicyDecision{Rule: nil, Scope: scope, Stripped: nil}
+
+	if uc.policies != nil {
+		if decision, err = uc.policies.Evaluate(ctx, domain.PolicyRequest{
+			RedirectURI: nil,
+			ClientID:    old.ClientID,
+			Me:          &old.Me,
+			Scope:       scope,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("cannot evaluate policy: %w", err)
+		}
+	}
+
+	if decision.Scope.IsEmpty() {
+		return nil, nil, token.ErrEmptyScope
+	}
+
+	// NOTE(toby3d): the grant is not extended by the rotation: neither the
+	// access token nor the next refresh token outlives the used one.
+	remaining := old.Expiry.Sub(uc.clock.Now().Truncate(time.Second))
+	expiration := decision.Expiration(domain.GrantExpiryUnd, uc.config.JWT.Expiry)
+
+	if expiration == 0 || expiration > remaining {
+		expiration = remaining
+	}
+
+	refreshExpiration := remaining
+	if refreshExpiration <= expiration {
+		refreshExpiration = 0
+	}
+
+	tkn, err := domain.NewToken(domain.NewTokenOptions{
+		Expiration:        expiration,
+		RefreshExpiration: refreshExpiration,
+		ClientID:          old.ClientID,
+		Issuer:            uc.config.Server.GetRootURL(),
+		Key:               uc.key,
+		Subject:           old.Me,
+		Family:            old.Family,
+		Audience:          old.Audience,
+		AuthTime:          old.AuthTime,
+		IssuedAt:          uc.clock.Now(),
+		Scope:             decision.Scope,
+		Secret:            []byte(uc.config.JWT.Secret),
+		Algorithm:         uc.config.JWT.Algorithm,
+		JKT:               old.JKT,
+		ACR:               old.ACR,
+		AMR:               old.AMR,
+		NonceLength:       uc.config.JWT.NonceLength,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate a new access token: %w", err)
+	}
+
+	redemption.Expiry = familyExpiry(*tkn)
+	redemption.RefreshToken = refreshTokenHash(tkn.RefreshToken)
+	redemption.TokenIDs = append(redemption.TokenIDs, tkn.ID)
+
+	if err = uc.sessions.UpdateRedemption(ctx, *redemption); err != nil {
+		return nil, nil, fmt.Errorf("cannot rotate refresh token: %w", err)
+	}
+
+	if !tkn.Scope.Has(domain.ScopeProfile) {
+		return tkn, nil, nil
+	}
+
+	profile, err := uc.profiles.Get(ctx, tkn.Me)
+	if err != nil {
+		return tkn, nil, nil //nolint:nilerr // it's okay to return token without profile
+	}
+
+	if !tkn.Scope.Has(domain.ScopeEmail) && profile.Email != nil {
+		profile.Email = nil
+	}
+
+	return tkn, profile, nil
 }
 
 func (uc *tokenUseCase) Verify(ctx context.Context, accessToken string) (*domain.Token, *domain.Profile, error) {
@@ -97,30 +358,27 @@ func (uc *tokenUseCase) Verify(ctx context.Context, accessToken string) (*domain
 		return nil, nil, fmt.Errorf("cannot check token in store: %w", err)
 	}
 
-	tkn, err := jwt.ParseString(accessToken, jwt.WithKey(jwa.SignatureAlgorithm(uc.config.JWT.Algorithm),
-		[]byte(uc.config.JWT.Secret)), jwt.WithVerify(true))
+	result, refresh, err := uc.parse(accessToken)
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot parse JWT token: %w", err)
+		return nil, nil, err
 	}
 
-	if err = jwt.Validate(tkn); err != nil {
-		return nil, nil, fmt.Errorf("cannot validate JWT token: %w", err)
+	if refresh {
+		return nil, nil, token.ErrRefreshToken
 	}
 
-	cid, _ := domain.ParseClientID(tkn.Issuer())
-	me, _ := domain.ParseMe(tkn.Subject())
-	result := &domain.Token{
-		CreatedAt:    tkn.IssuedAt(),
-		Expiry:       tkn.Expiration(),
-		ClientID:     *cid,
-		Me:           *me,
-		Scope:        nil,
-		AccessToken:  accessToken,
-		RefreshToken: "", // TODO(toby3d)
+	// NOTE(toby3d): bearer tokens issued before the strict profile is
+	// enabled are not accepted anymore.
+	if result.JKT == "" && uc.config.Security.GetProfile().RequireSenderConstrainedTokens() {
+		return nil, nil, token.ErrDPoPRequired
 	}
 
-	if scope, ok := tkn.Get("scope"); ok {
-		result.Scope, _ = scope.(domain.Scopes)
+	// NOTE(toby3d): all tokens minted from the code are revoked at once
+	// after the code replay.
+	if result.Family != "" {
+		if redemption, err := uc.sessions.GetRedemption(ctx, result.Family); err == nil && redemption.IsRevoked() {
+			return nil, nil, token.ErrRevoke
+		}
 	}
 
 	if !result.Scope.Has(domain.ScopeProfile) {
@@ -139,9 +397,36 @@ func (uc *tokenUseCase) Verify(ctx context.Context, accessToken string) (*domain
 	return result, profile, nil
 }
 
-func (uc *tokenUseCase) Revoke(ctx context.Context, accessToken string) error {
-	tkn, _, err := uc.Verify(ctx, accessToken)
+func (uc *tokenUseCase) Revoke(ctx context.Context, opts token.RevokeOptions) error {
+	tkn, refresh, err := uc.parse(opts.Token)
 	if err != nil {
+		return nil //nolint:nilerr // RFC 7009 section 2.2: invalid tokens do not cause an error
+	}
+
+	// NOTE(toby3d): RFC 7009 section 2.1: authenticated client can revoke
+	// only tokens issued to itself.
+	if opts.ClientID != nil && !tkn.ClientID.IsEqual(*opts.ClientID) {
+		return token.ErrForeignToken
+	}
+
+	// NOTE(toby3d): RFC 7009 section 2.1: revocation of the refresh token
+	// revokes all tokens of the same grant.
+	if refresh {
+		redemption, err := uc.sessions.GetRedemption(ctx, tkn.Family)
+		if err != nil || redemption.IsRevoked() {
+			return nil //nolint:nilerr // RFC 7009 section 2.2: invalid tokens do not cause an error
+		}
+
+		redemption.RevokedAt = uc.clock.Now()
+
+		if err = uc.sessions.UpdateRedemption(ctx, *redemption); err != nil {
+			return fmt.Errorf("cannot revoke tokens of the refresh token: %w", err)
+		}
+
+		return nil
+	}
+
+	if tkn, _, err = uc.Verify(ctx, opts.Token); err != nil {
 		if errors.Is(err, token.ErrNotExist) {
 			return nil
 		}
@@ -155,3 +440,190 @@ func (uc *tokenUseCase) Revoke(ctx context.Context, accessToken string) error {
 
 	return nil
 }
+
+// parse verifies the signature and the expiry of the signed token and returns
+// its claims. Refresh reports whether the token is the refresh token.
+//
+//nolint:cyclop
+func (uc *tokenUseCase) parse(raw string) (*domain.Token, bool, error) {
+	key := jwt.WithKey(jwa.SignatureAlgorithm(uc.config.JWT.Algorithm), []byte(uc.config.JWT.Secret))
+
+	if uc.key != nil {
+		public, err := uc.key.PublicKey()
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot get public key: %w", err)
+		}
+
+		key = jwt.WithKey(jwa.SignatureAlgorithm(uc.key.Algorithm().String()), public)
+	}
+
+	tkn, err := jwt.ParseString(raw, key, jwt.WithVerify(true), jwt.WithClock(uc.clock))
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse JWT token: %w", err)
+	}
+
+	// NOTE(toby3d): ID tokens and authorization responses can be signed by
+	// the same key, but they are addressed to the client by the aud claim
+	// and does not contain the client_id claim.
+	issuer := uc.config.Server.GetRootURL()
+	if err = jwt.Validate(tkn, jwt.WithClock(uc.clock), jwt.WithRequiredClaim("client_id"),
+		jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, tkn jwt.Token) jwt.ValidationError {
+			if len(tkn.Audience()) > 0 && !slices.Contains(tkn.Audience(), issuer) {
+				return jwt.ErrInvalidAudience()
+			}
+
+			return nil
+		}))); err != nil {
+		return nil, false, fmt.Errorf("cannot validate JWT token: %w", err)
+	}
+
+	clientID, _ := tkn.PrivateClaims()["client_id"].(string)
+
+	cid, err := domain.ParseClientID(clientID)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse JWT token client: %w", err)
+	}
+
+	me, err := domain.ParseMe(tkn.Subject())
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse JWT token subject: %w", err)
+	}
+
+	result := &domain.Token{
+		CreatedAt:    tkn.IssuedAt(),
+		Expiry:       tkn.Expiration(),
+		ClientID:     *cid,
+		Me:           *me,
+		ID:           tkn.JwtID(),
+		Audience:     slices.DeleteFunc(slices.Clone(tkn.Audience()), func(aud string) bool { return aud == issuer }),
+		Scope:        nil,
+		AccessToken:  raw,
+		RefreshToken: "",
+	}
+
+	use, _ := tkn.Get("token_use")
+	refresh := use == domain.TokenUseRefresh
+
+	if refresh {
+		result.AccessToken, result.RefreshToken = "", raw
+	}
+
+	if scope, ok := tkn.Get("scope"); ok {
+		result.Scope, _ = scope.(domain.Scopes)
+	}
+
+	if family, ok := tkn.Get("family"); ok {
+		result.Family, _ = family.(string)
+	}
+
+	if cnf, ok := tkn.Get("cnf"); ok {
+		if confirmation, ok := cnf.(map[string]any); ok {
+			result.JKT, _ = confirmation["jkt"].(string)
+		}
+	}
+
+	if authTime, ok := tkn.Get("auth_time"); ok {
+		if sec, ok := authTime.(float64); ok {
+			result.AuthTime = time.Unix(int64(sec), 0).UTC()
+		}
+	}
+
+	if acr, ok := tkn.Get("acr"); ok {
+		result.ACR, _ = acr.(string)
+	}
+
+	if amr, ok := tkn.Get("amr"); ok {
+		methods, _ := amr.([]any)
+
+		for i := range methods {
+			if method, ok := methods[i].(string); ok {
+				result.AMR = append(result.AMR, method)
+			}
+		}
+	}
+
+	return result, refresh, nil
+}
+
+// revokeFamily revokes all tokens minted from the same grant after the reuse
+// of the rotated refresh token and records it.
+func (uc *tokenUseCase) revokeFamily(ctx context.Context, redemption domain.Redemption) error {
+	redemption.RevokedAt = uc.clock.Now()
+
+	if err := uc.sessions.UpdateRedemption(ctx, redemption); err != nil {
+		return fmt.Errorf("cannot revoke tokens of the reused refresh token: %w", err)
+	}
+
+	if uc.audit == nil {
+		return nil
+	}
+
+	if err := uc.audit.Create(ctx, domain.AuditEvent{
+		CreatedAt: redemption.RevokedAt,
+		ClientID:  redemption.ClientID,
+		Me:        redemption.Me,
+		Type:      domain.AuditEventRefreshTokenReplay,
+		TokenIDs:  redemption.TokenIDs,
+	}); err != nil {
+		return fmt.Errorf("cannot record refresh token replay: %w", err)
+	}
+
+	return nil
+}
+
+// familyExpiry returns the time after which all tokens of the family are
+// expired, zero if they never expire.
+func familyExpiry(tkn domain.Token) time.Time {
+	if tkn.Expiry.IsZero() || tkn.RefreshExpiry.After(tkn.Expiry) {
+		return tkn.RefreshExpiry
+	}
+
+	return tkn.Expiry
+}
+
+// refreshTokenHash returns the hash of the refresh token stored in the
+// redemption, empty if there is no refresh token.
+func refreshTokenHash(refreshToken string) string {
+	if refreshToken == "" {
+		return ""
+	}
+
+	return domain.NewRedemptionID(refreshToken)
+}
+
+// capTokens revokes the oldest tokens of the client until the number of
+// valid ones, including just issued family, is not greater than limit. Zero
+// limit means no cap.
+func (uc *tokenUseCase) capTokens(ctx context.Context, cid domain.ClientID, family string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	redemptions, err := uc.sessions.FetchRedemptions(ctx, cid)
+	if err != nil {
+		return fmt.Errorf("cannot fetch tokens of the client: %w", err)
+	}
+
+	now := uc.clock.Now()
+	active := make([]domain.Redemption, 0, len(redemptions))
+
+	for i := range redemptions {
+		if redemptions[i].ID != family && redemptions[i].IsActive(now) {
+			active = append(active, redemptions[i])
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].CreatedAt.After(active[j].CreatedAt)
+	})
+
+	for i := limit - 1; i < len(active); i++ {
+		active[i].RevokedAt = now
+
+		if err = uc.sessions.UpdateRedemption(ctx, active[i]); err != nil {
+			return fmt.Errorf("cannot revoke tokens over the limit: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -2,9 +2,13 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+	policyucase "source.toby3d.me/toby3d/auth/internal/policy/usecase"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/session"
@@ -63,6 +67,394 @@ func TestExchange(t *testing.T) {
 	}
 }
 
+func TestExchange_GrantExpiry(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		grantExpiry domain.GrantExpiry
+		expForever  bool
+	}{
+		"default": {grantExpiry: domain.GrantExpiryUnd},
+		"week":    {grantExpiry: domain.GrantExpiryWeek},
+		"forever": {grantExpiry: domain.GrantExpiryForever, expForever: true},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deps := NewDependencies(t)
+			deps.session.GrantExpiry = tc.grantExpiry
+
+			if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+				t.Fatal(err)
+			}
+
+			tokens := usecase.NewTokenUseCase(usecase.Config{
+				Config:   *deps.config,
+				Profiles: deps.profiles,
+				Sessions: deps.sessions,
+				Tokens:   deps.tokens,
+			})
+
+			tkn, _, err := tokens.Exchange(context.Background(), token.ExchangeOptions{
+				ClientID:     deps.session.ClientID,
+				Code:         deps.session.Code,
+				CodeVerifier: deps.session.CodeChallenge,
+				RedirectURI:  deps.session.RedirectURI,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tkn.Expiry.IsZero() != tc.expForever {
+				t.Fatalf("Exchange() = token expired at %s, want forever %t", tkn.Expiry, tc.expForever)
+			}
+
+			if expiration := tc.grantExpiry.Expiration(deps.config.JWT.Expiry); !tc.expForever &&
+				tkn.Expiry.Sub(tkn.CreatedAt) != expiration {
+				t.Errorf("Exchange() = token expired in %s, want %s", tkn.Expiry.Sub(tkn.CreatedAt),
+					expiration)
+			}
+
+			result, _, err := tokens.Verify(context.Background(), tkn.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !result.Expiry.Equal(tkn.Expiry) {
+				t.Errorf("Verify() = token expired at %s, want %s", result.Expiry, tkn.Expiry)
+			}
+		})
+	}
+}
+
+func TestExchange_Policy(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	tokens := usecase.NewTokenUseCase(usecase.Config{
+		Config: *deps.config,
+		Policies: policyucase.NewPolicyUseCase(domain.PolicyRule{
+			Name:               "limited",
+			Action:             domain.PolicyActionAllow,
+			Strip:              domain.Scopes{domain.ScopeEmail},
+			AccessTokenExpiry:  30 * time.Minute,
+			RefreshTokenExpiry: 2 * time.Hour,
+			MaxTokens:          2,
+		}),
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	})
+
+	results := make([]*domain.Token, 0, 3)
+
+	for i := 0; i < 3; i++ {
+		s := domain.TestSession(t)
+		s.GrantExpiry = domain.GrantExpiryForever
+
+		if err := deps.sessions.Create(context.Background(), *s); err != nil {
+			t.Fatal(err)
+		}
+
+		tkn, _, err := tokens.Exchange(context.Background(), token.ExchangeOptions{
+			ClientID:     s.ClientID,
+			Code:         s.Code,
+			CodeVerifier: s.CodeChallenge,
+			RedirectURI:  s.RedirectURI,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tkn.Expiry.Sub(tkn.CreatedAt) != 30*time.Minute {
+			t.Errorf("Exchange() = token expired in %s, want %s", tkn.Expiry.Sub(tkn.CreatedAt), 30*time.Minute)
+		}
+
+		if tkn.RefreshExpiry.Sub(tkn.CreatedAt) != 2*time.Hour {
+			t.Errorf("Exchange() = refresh token expired in %s, want %s", tkn.RefreshExpiry.Sub(tkn.CreatedAt),
+				2*time.Hour)
+		}
+
+		if tkn.Scope.Has(domain.ScopeEmail) {
+			t.Errorf("Exchange() = token with %s scope, want stripped %s", tkn.Scope, domain.ScopeEmail)
+		}
+
+		results = append(results, tkn)
+	}
+
+	for i, tkn := range results {
+		var expError error
+		if i == 0 {
+			expError = token.ErrRevoke
+		}
+
+		if _, _, err := tokens.Verify(context.Background(), tkn.AccessToken); !errors.Is(err, expError) {
+			t.Errorf("Verify(#%d) = %v, want %v", i, err, expError)
+		}
+	}
+}
+
+func TestExchange_PolicyDenied(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := usecase.NewTokenUseCase(usecase.Config{
+		Config: *deps.config,
+		Policies: policyucase.NewPolicyUseCase(domain.PolicyRule{
+			Name:   "block",
+			Action: domain.PolicyActionDeny,
+			Match:  domain.PolicyMatch{ClientHost: []string{deps.session.ClientID.URL().Hostname()}},
+		}),
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	}).Exchange(context.Background(), token.ExchangeOptions{
+		ClientID:     deps.session.ClientID,
+		Code:         deps.session.Code,
+		CodeVerifier: deps.session.CodeChallenge,
+		RedirectURI:  deps.session.RedirectURI,
+	})
+	if !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("Exchange() = %v, want %v", err, policy.ErrDenied)
+	}
+}
+
+func TestExchange_Replay(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	audits := new(auditRecorder)
+
+	if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+		t.Fatal(err)
+	}
+
+	ucase := usecase.NewTokenUseCase(usecase.Config{
+		Audit:    audits,
+		Config:   *deps.config,
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	})
+	opts := token.ExchangeOptions{
+		ClientID:     deps.session.ClientID,
+		Code:         deps.session.Code,
+		CodeVerifier: deps.session.CodeChallenge,
+		RedirectURI:  deps.session.RedirectURI,
+	}
+
+	tkn, _, err := ucase.Exchange(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = ucase.Verify(context.Background(), tkn.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = ucase.Exchange(context.Background(), opts); !errors.Is(err, session.ErrRedeemed) {
+		t.Errorf("Exchange(ctx, %v) = %v, want %v", opts, err, session.ErrRedeemed)
+	}
+
+	if _, _, err = ucase.Verify(context.Background(), tkn.AccessToken); !errors.Is(err, token.ErrRevoke) {
+		t.Errorf("Verify(%s) = %v, want %v", tkn.AccessToken, err, token.ErrRevoke)
+	}
+
+	if len(audits.events) != 1 || audits.events[0].Type != domain.AuditEventCodeReplay ||
+		len(audits.events[0].TokenIDs) != 1 || audits.events[0].TokenIDs[0] != tkn.ID {
+		t.Errorf("Exchange(ctx, %v) recorded %+v, want single %s event for %s token", opts, audits.events,
+			domain.AuditEventCodeReplay, tkn.ID)
+	}
+}
+
+func TestExchange_Pushed(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	deps.session.Code = session.PushedPrefix + "urn:ietf:params:oauth:request_uri:hackme"
+	deps.session.Pushed = true
+
+	if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := token.ExchangeOptions{
+		ClientID:     deps.session.ClientID,
+		Code:         deps.session.Code,
+		CodeVerifier: deps.session.CodeChallenge,
+		RedirectURI:  deps.session.RedirectURI,
+	}
+
+	if _, _, err := usecase.NewTokenUseCase(usecase.Config{
+		Config:   *deps.config,
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	}).Exchange(context.Background(), opts); !errors.Is(err, session.ErrNotExist) {
+		t.Errorf("Exchange(ctx, %v) = %v, want %v", opts, err, session.ErrNotExist)
+	}
+
+	// NOTE(toby3d): pushed request is not consumed by the exchange attempt.
+	if _, err := deps.sessions.Get(context.Background(), deps.session.Code); err != nil {
+		t.Errorf("Get(%s) = %v, want nil", deps.session.Code, err)
+	}
+}
+
+func TestExchange_Strict(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		prepare   func(s *domain.Session, opts *token.ExchangeOptions)
+		expectErr error
+	}{
+		"bound": {
+			prepare:   func(*domain.Session, *token.ExchangeOptions) {},
+			expectErr: nil,
+		},
+		"without dpop": {
+			prepare:   func(_ *domain.Session, opts *token.ExchangeOptions) { opts.JKT = "" },
+			expectErr: token.ErrDPoPRequired,
+		},
+		"without pkce": {
+			prepare: func(s *domain.Session, _ *token.ExchangeOptions) {
+				s.CodeChallenge, s.CodeChallengeMethod = "", domain.CodeChallengeMethodUnd
+			},
+			expectErr: domain.ErrStrictCodeChallenge,
+		},
+		"plain pkce": {
+			prepare: func(s *domain.Session, opts *token.ExchangeOptions) {
+				s.CodeChallengeMethod, opts.CodeVerifier = domain.CodeChallengeMethodPLAIN, s.CodeChallenge
+			},
+			expectErr: domain.ErrStrictCodeChallenge,
+		},
+		"expired code": {
+			prepare:   func(s *domain.Session, _ *token.ExchangeOptions) { s.Expiry = time.Now().Add(-time.Second) },
+			expectErr: session.ErrExpired,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deps := NewDependencies(t)
+			deps.config.Security.Profile = domain.SecurityProfileStrict.String()
+			deps.session.CodeChallengeMethod = domain.CodeChallengeMethodS256
+			deps.session.CodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+			opts := token.ExchangeOptions{
+				ClientID:     deps.session.ClientID,
+				Code:         deps.session.Code,
+				CodeVerifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+				RedirectURI:  deps.session.RedirectURI,
+				JKT:          "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I",
+			}
+
+			tc.prepare(deps.session, &opts)
+
+			if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+				t.Fatal(err)
+			}
+
+			ucase := usecase.NewTokenUseCase(usecase.Config{
+				Config:   *deps.config,
+				Profiles: deps.profiles,
+				Sessions: deps.sessions,
+				Tokens:   deps.tokens,
+			})
+
+			tkn, _, err := ucase.Exchange(context.Background(), opts)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("Exchange(ctx, %v) = %v, want %v", opts, err, tc.expectErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, _, err := ucase.Verify(context.Background(), tkn.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.JKT != opts.JKT || result.Type() != "DPoP" {
+				t.Errorf("Verify(%s) = %+v, want token bound to %s", tkn.AccessToken, result, opts.JKT)
+			}
+
+			if _, _, err = ucase.Verify(context.Background(), deps.token.AccessToken); !errors.Is(err,
+				token.ErrDPoPRequired) {
+				t.Errorf("Verify(%s) = %v, want %v", deps.token.AccessToken, err, token.ErrDPoPRequired)
+			}
+		})
+	}
+}
+
+func TestExchange_Clock(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+		t.Fatal(err)
+	}
+
+	past := domain.TestClock(t, time.Now().Add(-24*time.Hour))
+	tkn, _, err := usecase.NewTokenUseCase(usecase.Config{
+		Clock:    past,
+		Config:   *deps.config,
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	}).Exchange(context.Background(), token.ExchangeOptions{
+		ClientID:     deps.session.ClientID,
+		Code:         deps.session.Code,
+		CodeVerifier: deps.session.CodeChallenge,
+		RedirectURI:  deps.session.RedirectURI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := past.Now().Add(deps.config.JWT.Expiry).Truncate(time.Second); !tkn.Expiry.Equal(expect) {
+		t.Errorf("Exchange() = token expires at %s, want %s", tkn.Expiry, expect)
+	}
+
+	for name, tc := range map[string]struct {
+		clock     domain.Clock
+		expectErr bool
+	}{
+		"issue time": {clock: past},
+		"now":        {clock: domain.SystemClock, expectErr: true},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := usecase.NewTokenUseCase(usecase.Config{
+				Clock:    tc.clock,
+				Config:   *deps.config,
+				Profiles: deps.profiles,
+				Sessions: deps.sessions,
+				Tokens:   deps.tokens,
+			}).Verify(context.Background(), tkn.AccessToken)
+			if (err != nil) != tc.expectErr {
+				t.Errorf("Verify(%s) = %v, want error %t", tkn.AccessToken, err, tc.expectErr)
+			}
+		})
+	}
+}
+
 func TestVerify(t *testing.T) {
 	t.Parallel()
 
@@ -119,7 +511,7 @@ func TestRevoke(t *testing.T) {
 		Profiles: deps.profiles,
 		Sessions: deps.sessions,
 		Tokens:   deps.tokens,
-	}).Revoke(context.Background(), deps.token.AccessToken); err != nil {
+	}).Revoke(context.Background(), token.RevokeOptions{Token: deps.token.AccessToken}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -133,6 +525,66 @@ func TestRevoke(t *testing.T) {
 	}
 }
 
+func TestRevoke_ForeignClient(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+
+	if err := deps.sessions.Create(context.Background(), *deps.session); err != nil {
+		t.Fatal(err)
+	}
+
+	ucase := usecase.NewTokenUseCase(usecase.Config{
+		Config:   *deps.config,
+		Profiles: deps.profiles,
+		Sessions: deps.sessions,
+		Tokens:   deps.tokens,
+	})
+
+	tkn, _, err := ucase.Exchange(context.Background(), token.ExchangeOptions{
+		ClientID:     deps.session.ClientID,
+		Code:         deps.session.Code,
+		CodeVerifier: deps.session.CodeChallenge,
+		RedirectURI:  deps.session.RedirectURI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tkn.RefreshToken == "" {
+		t.Fatal("Exchange() returns token without refresh token")
+	}
+
+	other := domain.TestClientID(t, "https://other.example.net/")
+
+	for name, raw := range map[string]string{
+		"access token":  tkn.AccessToken,
+		"refresh token": tkn.RefreshToken,
+	} {
+		if err = ucase.Revoke(context.Background(), token.RevokeOptions{
+			ClientID: other,
+			Token:    raw,
+		}); !errors.Is(err, token.ErrForeignToken) {
+			t.Errorf("Revoke(%s) = %v, want %v", name, err, token.ErrForeignToken)
+		}
+	}
+
+	if _, _, err = ucase.Verify(context.Background(), tkn.AccessToken); err != nil {
+		t.Errorf("Verify() after foreign revocation = %v, want nil", err)
+	}
+
+	if err = ucase.Revoke(context.Background(), token.RevokeOptions{
+		ClientID: &deps.session.ClientID,
+		Token:    tkn.RefreshToken,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = ucase.Verify(context.Background(), tkn.AccessToken); !errors.Is(err, token.ErrRevoke) {
+		t.Errorf("Verify() after revocation = %v, want %v", err, token.ErrRevoke)
+	}
+}
+
 func NewDependencies(tb testing.TB) Dependencies {
 	tb.Helper()
 
@@ -148,3 +600,13 @@ func NewDependencies(tb testing.TB) Dependencies {
 		tokens:   tokenrepo.NewMemoryTokenRepository(),
 	}
 }
+
+type auditRecorder struct {
+	events []domain.AuditEvent
+}
+
+func (r *auditRecorder) Create(_ context.Context, event domain.AuditEvent) error {
+	r.events = append(r.events, event)
+
+	return nil
+}
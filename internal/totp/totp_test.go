@@ -0,0 +1,70 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/totp"
+)
+
+// secret is the SHA-1 seed of the RFC 6238 test vectors.
+//
+//nolint:gochecknoglobals // test constant
+var secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	// NOTE(toby3d): RFC 6238 appendix B vectors truncated to 6 digits.
+	for in, expect := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1234567890:  "005924",
+		20000000000: "353130",
+	} {
+		in, expect := in, expect
+
+		t.Run(expect, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := totp.Generate(secret, time.Unix(in, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != expect {
+				t.Errorf("Generate(%s, %d) = %s, want %s", secret, in, result, expect)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Unix(59, 0)
+
+	for name, tc := range map[string]struct {
+		secret   string
+		password string
+		ts       time.Time
+		expect   bool
+	}{
+		"valid":        {secret: secret, password: "287082", ts: ts, expect: true},
+		"previous":     {secret: secret, password: "287082", ts: ts.Add(totp.Period), expect: true},
+		"expired":      {secret: secret, password: "287082", ts: ts.Add(2 * totp.Period), expect: false},
+		"wrong":        {secret: secret, password: "123456", ts: ts, expect: false},
+		"empty secret": {secret: "", password: "287082", ts: ts, expect: false},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := totp.Validate(tc.secret, tc.password, tc.ts); result != tc.expect {
+				t.Errorf("Validate(%s, %s, %s) = %t, want %t", tc.secret, tc.password, tc.ts, result, tc.expect)
+			}
+		})
+	}
+}
@@ -2,39 +2,66 @@ package http
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 
 	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/auth/internal/middleware"
+	"source.toby3d.me/toby3d/auth/internal/replay"
 	"source.toby3d.me/toby3d/auth/internal/token"
 )
 
 type Handler struct {
-	config domain.Config
-	tokens token.UseCase
+	key     jwk.Key
+	replays replay.Repository
+	config  domain.Config
+	tokens  token.UseCase
 }
 
-func NewHandler(tokens token.UseCase, config domain.Config) *Handler {
+// NewHandler creates a new userinfo handler. Access tokens are verified by the
+// public part of the optional key which signs them, by the JWT secret
+// otherwise. Used DPoP proofs are remembered in replays.
+func NewHandler(tokens token.UseCase, replays replay.Repository, key jwk.Key, config domain.Config) *Handler {
 	return &Handler{
-		tokens: tokens,
-		config: config,
+		key:     key,
+		replays: replays,
+		tokens:  tokens,
+		config:  config,
 	}
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		signingKey    any = []byte(h.config.JWT.Secret)
+		signingMethod     = jwa.SignatureAlgorithm(h.config.JWT.Algorithm)
+	)
+
+	if h.key != nil {
+		public, err := h.key.PublicKey()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		signingKey, signingMethod = public, jwa.SignatureAlgorithm(h.key.Algorithm().String())
+	}
+
 	chain := middleware.Chain{
 		//nolint:exhaustivestruct
 		middleware.JWTWithConfig(middleware.JWTConfig{
 			AuthScheme:    "Bearer",
 			ContextKey:    "token",
-			SigningKey:    []byte(h.config.JWT.Secret),
-			SigningMethod: jwa.SignatureAlgorithm(h.config.JWT.Algorithm),
+			SigningKey:    signingKey,
+			SigningMethod: signingMethod,
 			Skipper:       middleware.DefaultSkipper,
-			TokenLookup:   "header:" + common.HeaderAuthorization + ":Bearer ",
+			TokenLookup: "header:" + common.HeaderAuthorization + ":Bearer ," +
+				"header:" + common.HeaderAuthorization + ":DPoP ",
 		}),
 	}
 
@@ -52,8 +79,9 @@ func (h *Handler) handleFunc(w http.ResponseWriter, r *http.Request) {
 
 	encoder := json.NewEncoder(w)
 
-	tkn, userInfo, err := h.tokens.Verify(r.Context(),
-		strings.TrimPrefix(r.Header.Get(common.HeaderAuthorization), "Bearer "))
+	scheme, accessToken, _ := strings.Cut(r.Header.Get(common.HeaderAuthorization), " ")
+
+	tkn, userInfo, err := h.tokens.Verify(r.Context(), accessToken)
 	if err != nil || tkn == nil {
 		// WARN(toby3d): If the token is not valid, the endpoint still
 		// MUST return a 200 Response.
@@ -64,7 +92,36 @@ func (h *Handler) handleFunc(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !tkn.Scope.Has(domain.ScopeProfile) {
+	endpoint, err := url.Parse(h.config.Server.GetRootURL())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	// NOTE(toby3d): token bound to the client key can be used only with the
+	// proof of possession of this key, see RFC 9449 section 7.1.
+	proof, err := tkn.Confirm(scheme, r.Header.Get(common.HeaderDPoP), domain.DPoPProofOptions{
+		URL:         endpoint.JoinPath("userinfo"),
+		Method:      http.MethodGet,
+		AccessToken: "",
+	})
+	if err == nil && proof != nil {
+		err = h.replays.Create(r.Context(), "dpop:"+proof.JKT+":"+proof.ID, proof.Expiry())
+	}
+
+	if err != nil {
+		w.Header().Set(common.HeaderWWWAuthenticate, tkn.Type()+` error="`+domain.ErrorCodeInvalidToken.String()+`"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		_ = encoder.Encode(err) //nolint:errchkjson
+
+		return
+	}
+
+	// NOTE(toby3d): token with the openid scope only provides the subject
+	// claim, see https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+	if !tkn.Scope.Has(domain.ScopeProfile) && !tkn.Scope.Has(domain.ScopeOpenID) {
 		//nolint:errchkjson
 		_ = encoder.Encode(domain.NewError(
 			domain.ErrorCodeInsufficientScope,
@@ -77,8 +134,12 @@ func (h *Handler) handleFunc(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//nolint:errchkjson
-	_ = encoder.Encode(NewUserInformationResponse(userInfo, tkn.Scope.Has(domain.ScopeEmail)))
+	out := NewUserInformationResponse(userInfo, tkn.Scope.Has(domain.ScopeEmail))
+	if tkn.Scope.Has(domain.ScopeOpenID) {
+		out.setStandardClaims(tkn.Me)
+	}
+
+	_ = encoder.Encode(out) //nolint:errchkjson
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -3,6 +3,12 @@ package http
 import "source.toby3d.me/toby3d/auth/internal/domain"
 
 type UserInformationResponse struct {
+	// NOTE(toby3d): standard claims of the OpenID Connect, see
+	// https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
+	Sub     string `json:"sub,omitempty"`
+	Picture string `json:"picture,omitempty"`
+	Website string `json:"website,omitempty"`
+
 	URL   string `json:"url,omitempty"`
 	Photo string `json:"photo,omitempty"`
 	Email string `json:"email,omitempty"`
@@ -32,3 +38,11 @@ func NewUserInformationResponse(in *domain.Profile, hasEmail bool) *UserInformat
 
 	return out
 }
+
+// setStandardClaims maps profile properties onto standard claims of the
+// provided subject.
+func (r *UserInformationResponse) setStandardClaims(sub domain.Me) {
+	r.Sub = sub.String()
+	r.Picture = r.Photo
+	r.Website = r.URL
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/goccy/go-json"
 	"github.com/google/go-cmp/cmp"
+	"github.com/lestrrat-go/jwx/v2/jwa"
 
 	"source.toby3d.me/toby3d/auth/internal/common"
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/auth/internal/profile"
 	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	replayrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/session"
 	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
 	"source.toby3d.me/toby3d/auth/internal/token"
@@ -25,6 +29,7 @@ type Dependencies struct {
 	config       *domain.Config
 	profile      *domain.Profile
 	profiles     profile.Repository
+	replays      replay.Repository
 	sessions     session.Repository
 	token        *domain.Token
 	tokens       token.Repository
@@ -43,7 +48,7 @@ func TestUserInfo(t *testing.T) {
 	req.Header.Set(common.HeaderAuthorization, "Bearer "+deps.token.AccessToken)
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(deps.tokenService, *deps.config).
+	delivery.NewHandler(deps.tokenService, deps.replays, nil, *deps.config).
 		ServeHTTP(w, req)
 
 	resp := w.Result()
@@ -62,6 +67,157 @@ func TestUserInfo(t *testing.T) {
 	}
 }
 
+func TestUserInfo_OpenID(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	if err := deps.profiles.Create(context.Background(), deps.token.Me, *deps.profile); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		scope  domain.Scopes
+		expect *delivery.UserInformationResponse
+	}{
+		"openid": {
+			scope:  domain.Scopes{domain.ScopeOpenID},
+			expect: &delivery.UserInformationResponse{Sub: deps.token.Me.String()},
+		},
+		"profile": {
+			scope: domain.Scopes{domain.ScopeOpenID, domain.ScopeProfile},
+			expect: &delivery.UserInformationResponse{
+				Sub:     deps.token.Me.String(),
+				Name:    deps.profile.Name,
+				URL:     deps.profile.URL.String(),
+				Website: deps.profile.URL.String(),
+				Photo:   deps.profile.Photo.String(),
+				Picture: deps.profile.Photo.String(),
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tkn, err := domain.NewToken(domain.NewTokenOptions{
+				Expiration:  deps.config.JWT.Expiry,
+				ClientID:    deps.token.ClientID,
+				Subject:     deps.token.Me,
+				Scope:       tc.scope,
+				Secret:      []byte(deps.config.JWT.Secret),
+				Algorithm:   deps.config.JWT.Algorithm,
+				NonceLength: deps.config.JWT.NonceLength,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/userinfo", nil)
+			req.Header.Set(common.HeaderAuthorization, "Bearer "+tkn.AccessToken)
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(deps.tokenService, deps.replays, nil, *deps.config).
+				ServeHTTP(w, req)
+
+			resp := w.Result()
+			if exp := http.StatusOK; resp.StatusCode != exp {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, exp)
+			}
+
+			result := new(delivery.UserInformationResponse)
+			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(result, tc.expect); diff != "" {
+				t.Errorf("%s %s = %+v, want %+v", req.Method, req.RequestURI, result, tc.expect)
+			}
+		})
+	}
+}
+
+func TestUserInfo_DPoP(t *testing.T) {
+	t.Parallel()
+
+	deps := NewDependencies(t)
+	if err := deps.profiles.Create(context.Background(), deps.token.Me, *deps.profile); err != nil {
+		t.Fatal(err)
+	}
+
+	key := domain.TestDPoPKey(t)
+	endpoint, _ := url.Parse(deps.config.Server.GetRootURL() + "userinfo")
+
+	binding, err := domain.NewDPoPProof(key, jwa.ES256, "binding", domain.DPoPProofOptions{
+		URL:         endpoint,
+		Method:      http.MethodGet,
+		AccessToken: "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proven, err := domain.ParseDPoPProof(binding, domain.DPoPProofOptions{URL: endpoint, Method: http.MethodGet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tkn, err := domain.NewToken(domain.NewTokenOptions{
+		Expiration:  deps.config.JWT.Expiry,
+		ClientID:    deps.token.ClientID,
+		Subject:     deps.token.Me,
+		Scope:       domain.Scopes{domain.ScopeProfile},
+		Secret:      []byte(deps.config.JWT.Secret),
+		Algorithm:   deps.config.JWT.Algorithm,
+		NonceLength: deps.config.JWT.NonceLength,
+		JKT:         proven.JKT,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := domain.NewDPoPProof(key, jwa.ES256, "userinfo", domain.DPoPProofOptions{
+		URL:         endpoint,
+		Method:      http.MethodGet,
+		AccessToken: tkn.AccessToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		scheme    string
+		proof     string
+		expStatus int
+	}{
+		"dpop":               {scheme: "DPoP", proof: proof, expStatus: http.StatusOK},
+		"bearer":             {scheme: "Bearer", proof: proof, expStatus: http.StatusUnauthorized},
+		"without proof":      {scheme: "DPoP", proof: "", expStatus: http.StatusUnauthorized},
+		"proof without hash": {scheme: "DPoP", proof: binding, expStatus: http.StatusUnauthorized},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/userinfo", nil)
+			req.Header.Set(common.HeaderAuthorization, tc.scheme+" "+tkn.AccessToken)
+
+			if tc.proof != "" {
+				req.Header.Set(common.HeaderDPoP, tc.proof)
+			}
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(deps.tokenService, deps.replays, nil, *deps.config).
+				ServeHTTP(w, req)
+
+			if resp := w.Result(); resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+		})
+	}
+}
+
 func NewDependencies(tb testing.TB) Dependencies {
 	tb.Helper()
 
@@ -74,6 +230,7 @@ func NewDependencies(tb testing.TB) Dependencies {
 		config:   config,
 		profile:  domain.TestProfile(tb),
 		profiles: profiles,
+		replays:  replayrepo.NewMemoryReplayRepository(),
 		sessions: sessions,
 		token:    domain.TestToken(tb),
 		tokens:   tokens,
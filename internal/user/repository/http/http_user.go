@@ -58,18 +58,19 @@ func (httpUserRepository) Create(_ context.Context, _ domain.User) error {
 	return nil
 }
 
-//nolint:funlen
+//nolint:funlen,cyclop
 func (repo *httpUserRepository) Get(ctx context.Context, me domain.Me) (*domain.User, error) {
-	resp, err := repo.client.Get(me.String())
+	resp, canonical, err := repo.fetch(ctx, me)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch user by me: %w", err)
 	}
+	defer resp.Body.Close()
 
 	out := &domain.User{
 		Profile: new(domain.Profile),
+		// NOTE(toby3d): resolved Me may be different from user-provided Me
+		Me: canonical,
 	}
-	// NOTE(toby3d): resolved Me may be different from user-provided Me
-	out.Me, _ = domain.ParseMe(resp.Request.URL.String())
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -141,9 +142,21 @@ func (repo *httpUserRepository) Get(ctx context.Context, me domain.Me) (*domain.
 	}
 
 	// NOTE(toby3d): fetch endpoints from metadata payload
-	if resp, err = repo.client.Get(out.IndieAuthMetadata.String()); err != nil {
+	if out.IndieAuthMetadata, err = resp.Request.URL.Parse(out.IndieAuthMetadata.String()); err != nil {
+		return out, fmt.Errorf("cannot resolve metadata URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, out.IndieAuthMetadata.String(), nil)
+	if err != nil {
+		return out, fmt.Errorf("cannot build metadata request: %w", err)
+	}
+
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+	if resp, err = repo.client.Do(req); err != nil {
 		return out, fmt.Errorf("cannot fetch endpoints from provided metadata URL: %w", err)
 	}
+	defer resp.Body.Close()
 
 	metadata := new(MetadataResponse)
 	if err = json.NewDecoder(resp.Body).Decode(metadata); err != nil {
@@ -151,11 +164,14 @@ func (repo *httpUserRepository) Get(ctx context.Context, me domain.Me) (*domain.
 	}
 
 	for src, dst := range map[domain.URL]**url.URL{
+		metadata.Issuer:                &out.Issuer,
 		metadata.AuthorizationEndpoint: &out.AuthorizationEndpoint,
 		metadata.Micropub:              &out.Micropub,
 		metadata.Microsub:              &out.Microsub,
 		metadata.TicketEndpoint:        &out.TicketEndpoint,
 		metadata.TokenEndpoint:         &out.TokenEndpoint,
+		metadata.IntrospectionEndpoint: &out.IntrospectionEndpoint,
+		metadata.RevocationEndpoint:    &out.RevocationEndpoint,
 	} {
 		if src.URL == nil {
 			continue
@@ -167,6 +183,54 @@ func (repo *httpUserRepository) Get(ctx context.Context, me domain.Me) (*domain.
 	return out, nil
 }
 
+// fetch requests the user profile URL following the redirects manually as
+// described in IndieAuth spec: permanent redirects change the canonical profile
+// URL, temporary ones keep the previous one.
+func (repo *httpUserRepository) fetch(ctx context.Context, me domain.Me) (*http.Response, *domain.Me, error) {
+	client := *repo.client
+	client.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	canonical, permanent, u := &me, true, me.URL()
+
+	for i := 0; i <= DefaultMaxRedirectsCount; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot build request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot do request: %w", err)
+		}
+
+		switch resp.StatusCode {
+		default:
+			return resp, canonical, nil
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		case http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect:
+			permanent = false
+		}
+
+		resp.Body.Close()
+
+		if u, err = resp.Location(); err != nil {
+			return nil, nil, fmt.Errorf("cannot parse redirect location: %w", err)
+		}
+
+		if !permanent {
+			continue
+		}
+
+		if canonical, err = domain.ParseMe(u.String()); err != nil {
+			return nil, nil, fmt.Errorf("invalid permanent redirect location: %w", err)
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: stopped after %d redirects", user.ErrNotExist, DefaultMaxRedirectsCount)
+}
+
 func parseProfile(src map[string][]any, dst *domain.Profile) {
 	for _, val := range src[common.PropertyName] {
 		v, ok := val.(string)
@@ -3,6 +3,7 @@ package http_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -40,11 +41,11 @@ func TestGet(t *testing.T) {
 	t.Parallel()
 
 	user := domain.TestUser(t)
-	user.Me = nil
 
 	srv := httptest.NewServer(testHandler(t, user))
 	t.Cleanup(srv.Close)
 
+	user.Me = domain.TestMe(t, srv.URL+"/")
 	user.IndieAuthMetadata, _ = url.Parse(srv.URL + user.IndieAuthMetadata.Path)
 
 	result, err := repository.NewHTTPUserRepository(srv.Client()).
@@ -58,6 +59,59 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGet_Redirect(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	user.IndieAuthMetadata.Scheme = "http"
+	mux := http.NewServeMux()
+	mux.Handle("/", testHandler(t, user))
+	mux.Handle("/moved", http.RedirectHandler("/permanent", http.StatusMovedPermanently))
+	mux.Handle("/permanent", http.RedirectHandler("/", http.StatusPermanentRedirect))
+	mux.Handle("/temporary", http.RedirectHandler("/", http.StatusFound))
+	mux.Handle("/mixed", http.RedirectHandler("/temporary", http.StatusMovedPermanently))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	// NOTE(toby3d): profile URL cannot contain a port, so route any host
+	// to the test server.
+	client := srv.Client()
+	client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		in, expMe string
+	}{
+		"permanent": {in: "http://user.example.net/moved", expMe: "http://user.example.net/"},
+		"temporary": {in: "http://user.example.net/temporary", expMe: "http://user.example.net/temporary"},
+		"mixed":     {in: "http://user.example.net/mixed", expMe: "http://user.example.net/temporary"},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := repository.NewHTTPUserRepository(client).
+				Get(context.Background(), *domain.TestMe(t, tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Me.String() != tc.expMe {
+				t.Errorf("Get(%s) = %s, want %s", tc.in, result.Me, tc.expMe)
+			}
+
+			if result.Issuer.String() != user.Issuer.String() {
+				t.Errorf("Get(%s) = %s, want %s", tc.in, result.Issuer, user.Issuer)
+			}
+		})
+	}
+}
+
 func testHandler(tb testing.TB, user *domain.User) http.Handler {
 	tb.Helper()
 
@@ -77,7 +131,7 @@ func testHandler(tb testing.TB, user *domain.User) http.Handler {
 	mux.HandleFunc(user.IndieAuthMetadata.Path, func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
 		fmt.Fprint(w, `{
-			"issuer": "https://auth.example.com/",
+			"issuer": "`+user.Issuer.String()+`",
 			"authorization_endpoint": "`+user.AuthorizationEndpoint.String()+`",
 			"token_endpoint": "`+user.TokenEndpoint.String()+`"
 		}`)
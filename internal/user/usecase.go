@@ -2,11 +2,34 @@ package user
 
 import (
 	"context"
+	"net/url"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 )
 
-type UseCase interface {
-	// Fetch discovery all available endpoints and Profile info on Me URL.
-	Fetch(ctx context.Context, me domain.Me) (*domain.User, error)
-}
+type (
+	VerifyOptions struct {
+		Me domain.Me
+		// Issuer is the issuer identifier of this server.
+		Issuer *url.URL
+		// AuthorizationEndpoint is the authorization endpoint of this
+		// server, used if profile does not provide metadata endpoint.
+		AuthorizationEndpoint *url.URL
+	}
+
+	UseCase interface {
+		// Fetch discovery all available endpoints and Profile info on Me URL.
+		Fetch(ctx context.Context, me domain.Me) (*domain.User, error)
+
+		// Verify fetches user by Me and checks that it delegates
+		// authorization to this server. Returned user contains the
+		// canonical Me after the redirects.
+		Verify(ctx context.Context, opts VerifyOptions) (*domain.User, error)
+	}
+)
+
+var ErrNotDelegated error = domain.NewError(
+	domain.ErrorCodeAccessDenied,
+	"profile URL does not delegate authorization to this server",
+	"https://indieauth.net/source/#discovery-by-clients",
+)
@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
 	"source.toby3d.me/toby3d/auth/internal/user"
@@ -26,3 +28,44 @@ func (useCase *userUseCase) Fetch(ctx context.Context, me domain.Me) (*domain.Us
 
 	return user, nil
 }
+
+func (useCase *userUseCase) Verify(ctx context.Context, opts user.VerifyOptions) (*domain.User, error) {
+	out, err := useCase.Fetch(ctx, opts.Me)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Me == nil {
+		out.Me = &opts.Me
+	}
+
+	// NOTE(toby3d): issuer from the metadata is preferred, legacy profiles
+	// provide authorization endpoint only.
+	switch {
+	case out.Issuer != nil:
+		if !isSameURL(out.Issuer, opts.Issuer) {
+			return nil, fmt.Errorf("%w: issuer is '%s'", user.ErrNotDelegated, out.Issuer)
+		}
+	case out.IndieAuthMetadata == nil && out.AuthorizationEndpoint != nil:
+		if !isSameURL(out.AuthorizationEndpoint, opts.AuthorizationEndpoint) {
+			return nil, fmt.Errorf("%w: authorization endpoint is '%s'", user.ErrNotDelegated,
+				out.AuthorizationEndpoint)
+		}
+	default:
+		return nil, user.ErrNotDelegated
+	}
+
+	return out, nil
+}
+
+// isSameURL compares URLs ignoring scheme and host case and trailing slash of
+// the path.
+func isSameURL(a, b *url.URL) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host) &&
+		strings.TrimSuffix(a.EscapedPath(), "/") == strings.TrimSuffix(b.EscapedPath(), "/") &&
+		a.RawQuery == b.RawQuery
+}
@@ -2,10 +2,13 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
+	"net/url"
 	"reflect"
 	"testing"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/user"
 	repository "source.toby3d.me/toby3d/auth/internal/user/repository/memory"
 	ucase "source.toby3d.me/toby3d/auth/internal/user/usecase"
 )
@@ -30,3 +33,70 @@ func TestFetch(t *testing.T) {
 		t.Errorf("Fetch(%s) = %+v, want %+v", user.Me, result, user)
 	}
 }
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	issuer := &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/"}
+
+	for name, tc := range map[string]struct {
+		modify   func(u *domain.User)
+		expError error
+	}{
+		"issuer": {
+			modify: func(u *domain.User) {
+				u.Issuer = &url.URL{Scheme: "https", Host: "AUTH.example.com", Path: ""}
+			},
+		},
+		"legacy": {
+			modify: func(u *domain.User) {
+				u.Issuer, u.IndieAuthMetadata = nil, nil
+				u.AuthorizationEndpoint = issuer.JoinPath("authorize")
+			},
+		},
+		"foreign issuer": {
+			modify:   func(_ *domain.User) {},
+			expError: user.ErrNotDelegated,
+		},
+		"foreign endpoint": {
+			modify: func(u *domain.User) {
+				u.Issuer, u.IndieAuthMetadata = nil, nil
+			},
+			expError: user.ErrNotDelegated,
+		},
+		"metadata without issuer": {
+			modify: func(u *domain.User) {
+				u.Issuer = nil
+				u.AuthorizationEndpoint = issuer.JoinPath("authorize")
+			},
+			expError: user.ErrNotDelegated,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := domain.TestUser(t)
+			tc.modify(u)
+
+			users := repository.NewMemoryUserRepository()
+			if err := users.Create(context.Background(), *u); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := ucase.NewUserUseCase(users).Verify(context.Background(), user.VerifyOptions{
+				Me:                    *u.Me,
+				Issuer:                issuer,
+				AuthorizationEndpoint: issuer.JoinPath("authorize"),
+			})
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Verify(%s) = %v, want %v", u.Me, err, tc.expError)
+			}
+
+			if tc.expError == nil && result.Me.String() != u.Me.String() {
+				t.Errorf("Verify(%s) = %s, want %s", u.Me, result.Me, u.Me)
+			}
+		})
+	}
+}
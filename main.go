@@ -1,80 +1,31 @@
 //go:generate go install github.com/valyala/quicktemplate/qtc@latest
 //go:generate qtc -dir=./web
-//go:generate go install golang.org/x/text/cmd/gotext@master
-//go:generate gotext -srclang=en update -out=catalog_gen.go -lang=en,ru
 package main
 
 import (
 	"context"
-	"embed"
 	"errors"
 	"flag"
-	"io/fs"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
-	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/caarlos0/env/v9"
 	"github.com/jmoiron/sqlx"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 	_ "modernc.org/sqlite"
 
-	"source.toby3d.me/toby3d/auth/internal/auth"
-	authhttpdelivery "source.toby3d.me/toby3d/auth/internal/auth/delivery/http"
-	authucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
-	"source.toby3d.me/toby3d/auth/internal/client"
-	clienthttpdelivery "source.toby3d.me/toby3d/auth/internal/client/delivery/http"
-	clienthttprepo "source.toby3d.me/toby3d/auth/internal/client/repository/http"
-	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	clientsqlite3repo "source.toby3d.me/toby3d/auth/internal/client/repository/sqlite3"
+	consentsqlite3repo "source.toby3d.me/toby3d/auth/internal/consent/repository/sqlite3"
 	"source.toby3d.me/toby3d/auth/internal/domain"
-	healthhttpdelivery "source.toby3d.me/toby3d/auth/internal/health/delivery/http"
-	metadatahttpdelivery "source.toby3d.me/toby3d/auth/internal/metadata/delivery/http"
-	"source.toby3d.me/toby3d/auth/internal/middleware"
-	"source.toby3d.me/toby3d/auth/internal/profile"
-	profilehttprepo "source.toby3d.me/toby3d/auth/internal/profile/repository/http"
-	profileucase "source.toby3d.me/toby3d/auth/internal/profile/usecase"
-	"source.toby3d.me/toby3d/auth/internal/session"
-	sessionmemoryrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
 	sessionsqlite3repo "source.toby3d.me/toby3d/auth/internal/session/repository/sqlite3"
-	sessionucase "source.toby3d.me/toby3d/auth/internal/session/usecase"
-	"source.toby3d.me/toby3d/auth/internal/token"
-	tokenhttpdelivery "source.toby3d.me/toby3d/auth/internal/token/delivery/http"
-	tokenmemoryrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
 	tokensqlite3repo "source.toby3d.me/toby3d/auth/internal/token/repository/sqlite3"
-	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
-	"source.toby3d.me/toby3d/auth/internal/urlutil"
-	userhttpdelivery "source.toby3d.me/toby3d/auth/internal/user/delivery/http"
-)
-
-type (
-	App struct {
-		auth     auth.UseCase
-		clients  client.UseCase
-		matcher  language.Matcher
-		sessions session.UseCase
-		profiles profile.UseCase
-		tokens   token.UseCase
-		static   fs.FS
-	}
-
-	NewAppOptions struct {
-		Client   *http.Client
-		Clients  client.Repository
-		Sessions session.Repository
-		Tokens   token.Repository
-		Profiles profile.Repository
-		Static   fs.FS
-	}
+	"source.toby3d.me/toby3d/auth/server"
 )
 
 const (
@@ -92,85 +43,75 @@ var (
 
 //nolint:gochecknoglobals
 var (
-	indieAuthClient                *domain.Client
 	cpuProfilePath, memProfilePath string
+	// policyDryRun contains the query of the request to explain by policy.
+	policyDryRun string
 )
 
-//go:embed web/static/*
-var static embed.FS
-
 //nolint:gochecknoinits
 func init() {
 	flag.StringVar(&cpuProfilePath, "cpuprofile", "", "set path to saving CPU memory profile")
 	flag.StringVar(&memProfilePath, "memprofile", "", "set path to saving pprof memory profile")
+	flag.StringVar(&policyDryRun, "policy-dry-run", "", "explain which policy rule matches the request, "+
+		"like 'client_id=https://app.example.com/&redirect_uri=https://app.example.com/callback&"+
+		"me=https://example.com/&scope=create+update'")
 	flag.Parse()
 
-	if err := env.ParseWithOptions(config, env.Options{Prefix: "AUTH_"}); err != nil {
-		logger.Fatalln(err)
-	}
-
-	// NOTE(toby3d): The server instance itself can be as a client.
-	rootUrl, err := url.Parse(config.Server.GetRootURL())
-	if err != nil {
+	var err error
+	if config, err = server.NewConfig("AUTH_"); err != nil {
 		logger.Fatalln(err)
 	}
-
-	cid, err := domain.ParseClientID(rootUrl.String())
-	if err != nil {
-		logger.Fatalln("fail to read config:", err)
-	}
-
-	indieAuthClient = &domain.Client{
-		Logo:        rootUrl.JoinPath("icon.svg"),
-		URL:         rootUrl,
-		ID:          *cid,
-		Name:        config.Name,
-		RedirectURI: []*url.URL{rootUrl.JoinPath("callback")},
-	}
 }
 
-//nolint:funlen,cyclop // "god object" and the entry point of all modules
+//nolint:funlen,cyclop // the entry point of all modules
 func main() {
 	ctx := context.Background()
 
-	var opts NewAppOptions
+	if policyDryRun != "" {
+		if err := server.DryRunPolicy(ctx, os.Stdout, *config, policyDryRun); err != nil {
+			logger.Fatalln(err)
+		}
 
-	var err error
-	if opts.Static, err = fs.Sub(static, filepath.Join("web", "static")); err != nil {
-		logger.Fatalln(err)
+		return
+	}
+
+	opts := server.Options{
+		Logger: logger,
+		Config: *config,
 	}
 
-	switch strings.ToLower(config.Database.Type) {
-	default:
-		opts.Tokens = tokenmemoryrepo.NewMemoryTokenRepository()
-		opts.Sessions = sessionmemoryrepo.NewMemorySessionRepository(*config)
-	case "sqlite3":
+	if strings.EqualFold(config.Database.Type, "sqlite3") {
 		store, err := sqlx.Open("sqlite", config.Database.Path)
 		if err != nil {
 			logger.Fatalln(err)
 		}
+		defer store.Close()
 
 		if err = store.Ping(); err != nil {
 			logger.Fatalf("cannot ping %s database: %v", "sqlite3", err)
 		}
 
 		opts.Tokens = tokensqlite3repo.NewSQLite3TokenRepository(store)
-		opts.Sessions = sessionsqlite3repo.NewSQLite3SessionRepository(store)
+		opts.Sessions = sessionsqlite3repo.NewSQLite3SessionRepository(store, *config)
+		opts.Registry = clientsqlite3repo.NewSQLite3ClientRepository(store)
+		opts.Consents = consentsqlite3repo.NewSQLite3ConsentRepository(store)
+	}
+
+	srv, err := server.New(ctx, opts)
+	if err != nil {
+		logger.Fatalln(err)
 	}
 
-	go opts.Sessions.GC()
+	srv.Start()
+	defer srv.Close()
 
-	opts.Client = new(http.Client)
-	opts.Clients = clienthttprepo.NewHTTPClientRepository(opts.Client)
-	opts.Profiles = profilehttprepo.NewHTPPClientRepository(opts.Client)
-	app := NewApp(opts)
-	server := &http.Server{
+	httpServer := &http.Server{
 		Addr:              config.Server.GetAddress(),
 		BaseContext:       nil,
 		ConnContext:       nil,
 		ConnState:         nil,
 		ErrorLog:          logger,
-		Handler:           app.Handler(),
+		Handler:           srv,
 		IdleTimeout:       0,
 		MaxHeaderBytes:    0,
 		ReadHeaderTimeout: 0,
@@ -201,9 +142,9 @@ func main() {
 			config.Server.GetRootURL())
 
 		if config.Server.CertificateFile != "" && config.Server.KeyFile != "" {
-			err = server.ListenAndServeTLS(config.Server.CertificateFile, config.Server.KeyFile)
+			err = httpServer.ListenAndServeTLS(config.Server.CertificateFile, config.Server.KeyFile)
 		} else {
-			err = server.ListenAndServe()
+			err = httpServer.ListenAndServe()
 		}
 
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -213,7 +154,7 @@ func main() {
 
 	<-done
 
-	if err = server.Shutdown(ctx); err != nil {
+	if err = httpServer.Shutdown(ctx); err != nil {
 		logger.Fatalln("failed shutdown of server:", err)
 	}
 
@@ -233,125 +174,3 @@ func main() {
 		logger.Fatalln("could not write memory profile:", err)
 	}
 }
-
-func NewApp(opts NewAppOptions) *App {
-	return &App{
-		static:   opts.Static,
-		auth:     authucase.NewAuthUseCase(opts.Sessions, opts.Profiles, *config),
-		clients:  clientucase.NewClientUseCase(opts.Clients),
-		matcher:  language.NewMatcher(message.DefaultCatalog.Languages()),
-		profiles: profileucase.NewProfileUseCase(opts.Profiles),
-		sessions: sessionucase.NewSessionUseCase(opts.Sessions),
-		tokens: tokenucase.NewTokenUseCase(tokenucase.Config{
-			Config:   *config,
-			Profiles: opts.Profiles,
-			Sessions: opts.Sessions,
-			Tokens:   opts.Tokens,
-		}),
-	}
-}
-
-// TODO(toby3d): move module middlewares to here.
-//
-//nolint:funlen
-func (app *App) Handler() http.Handler {
-	//nolint:exhaustivestruct
-	metadata := metadatahttpdelivery.NewHandler(&domain.Metadata{
-		Issuer:                indieAuthClient.ID.URL(),
-		AuthorizationEndpoint: indieAuthClient.ID.URL().JoinPath("authorize"),
-		TokenEndpoint:         indieAuthClient.ID.URL().JoinPath("token"),
-		TicketEndpoint:        indieAuthClient.ID.URL().JoinPath("ticket"),
-		MicropubEndpoint:      nil,
-		MicrosubEndpoint:      nil,
-		IntrospectionEndpoint: indieAuthClient.ID.URL().JoinPath("introspect"),
-		RevocationEndpoint:    indieAuthClient.ID.URL().JoinPath("revocation"),
-		UserinfoEndpoint:      indieAuthClient.ID.URL().JoinPath("userinfo"),
-		ServiceDocumentation: &url.URL{
-			Scheme: "https",
-			Host:   "indieauth.net",
-			Path:   "/source/",
-		},
-		IntrospectionEndpointAuthMethodsSupported: []string{"Bearer"},
-		RevocationEndpointAuthMethodsSupported:    []string{"none"},
-		ScopesSupported: domain.Scopes{
-			domain.ScopeBlock,
-			domain.ScopeChannels,
-			domain.ScopeCreate,
-			domain.ScopeDelete,
-			domain.ScopeDraft,
-			domain.ScopeEmail,
-			domain.ScopeFollow,
-			domain.ScopeMedia,
-			domain.ScopeMute,
-			domain.ScopeProfile,
-			domain.ScopeRead,
-			domain.ScopeUpdate,
-		},
-		ResponseTypesSupported: []domain.ResponseType{
-			domain.ResponseTypeCode,
-			domain.ResponseTypeID,
-		},
-		GrantTypesSupported: []domain.GrantType{
-			domain.GrantTypeAuthorizationCode,
-			domain.GrantTypeTicket,
-		},
-		CodeChallengeMethodsSupported: []domain.CodeChallengeMethod{
-			domain.CodeChallengeMethodMD5,
-			domain.CodeChallengeMethodPLAIN,
-			domain.CodeChallengeMethodS1,
-			domain.CodeChallengeMethodS256,
-			domain.CodeChallengeMethodS512,
-		},
-		AuthorizationResponseIssParameterSupported: true,
-	})
-	health := healthhttpdelivery.NewHandler()
-	auth := authhttpdelivery.NewHandler(authhttpdelivery.NewHandlerOptions{
-		Auth:     app.auth,
-		Clients:  app.clients,
-		Config:   *config,
-		Matcher:  app.matcher,
-		Profiles: app.profiles,
-	})
-	token := tokenhttpdelivery.NewHandler(app.tokens, *config)
-	client := clienthttpdelivery.NewHandler(clienthttpdelivery.NewHandlerOptions{
-		Client:  *indieAuthClient,
-		Config:  *config,
-		Matcher: app.matcher,
-		Tokens:  app.tokens,
-	})
-	user := userhttpdelivery.NewHandler(app.tokens, *config)
-	staticHandler := http.FileServer(http.FS(app.static))
-
-	return http.HandlerFunc(middleware.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		head, tail := urlutil.ShiftPath(r.URL.Path)
-
-		switch head {
-		default: // NOTE(toby3d): static or 404
-			staticHandler.ServeHTTP(w, r)
-		case "", "callback": // NOTE(toby3d): self-client
-			client.ServeHTTP(w, r)
-		case "token", "introspect", "revocation":
-			token.ServeHTTP(w, r)
-		case ".well-known": // NOTE(toby3d): public server config
-			r.URL.Path = tail
-
-			if head, _ = urlutil.ShiftPath(r.URL.Path); head == "oauth-authorization-server" {
-				metadata.ServeHTTP(w, r)
-			} else {
-				http.NotFound(w, r)
-			}
-		case "authorize":
-			r.URL.Path = tail
-
-			auth.ServeHTTP(w, r)
-		case "health":
-			r.URL.Path = tail
-
-			health.ServeHTTP(w, r)
-		case "userinfo":
-			r.URL.Path = tail
-
-			user.ServeHTTP(w, r)
-		}
-	}).Intercept(middleware.LogFmt()))
-}
@@ -0,0 +1,237 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+)
+
+type (
+	IntrospectionOptions struct {
+		// Client requests the introspection endpoint. By default
+		// http.DefaultClient is used.
+		Client *http.Client
+
+		// Endpoint is the URL of the introspection endpoint of the
+		// issuer, such as https://auth.example.com/introspect.
+		Endpoint string
+
+		// ClientID and ClientSecret authenticate the resource server
+		// by HTTP Basic scheme. Without them the verified token
+		// authorizes its own introspection, which is not possible for
+		// DPoP-bound tokens.
+		ClientID     string
+		ClientSecret string
+
+		// CacheTTL is the time for which the introspection result is
+		// cached, but not longer than the token lifetime. Default to
+		// one minute.
+		CacheTTL time.Duration
+	}
+
+	introspectionVerifier struct {
+		client   *http.Client
+		entries  map[string]introspectionEntry
+		endpoint string
+		clientID string
+		secret   string
+		ttl      time.Duration
+		mutex    sync.Mutex
+	}
+
+	introspectionEntry struct {
+		expiry time.Time
+		// token is nil for inactive token.
+		token *Token
+	}
+
+	//nolint:tagliatelle // RFC 7662 section 2.2
+	introspectionResponse struct {
+		Cnf *struct {
+			JKT string `json:"jkt"`
+		} `json:"cnf,omitempty"`
+		Me       string   `json:"me"`
+		Subject  string   `json:"sub"`
+		ClientID string   `json:"client_id"`
+		Scope    string   `json:"scope"`
+		ACR      string   `json:"acr"`
+		AMR      []string `json:"amr"`
+		Exp      int64    `json:"exp"`
+		AuthTime int64    `json:"auth_time"`
+		Active   bool     `json:"active"`
+	}
+)
+
+// DefaultIntrospectionCacheTTL is the default lifetime of the cached
+// introspection result.
+const DefaultIntrospectionCacheTTL time.Duration = time.Minute
+
+// maxIntrospectionEntries limits the number of cached results, so random
+// tokens cannot exhaust memory.
+const maxIntrospectionEntries int = 1024
+
+// NewIntrospectionVerifier creates a new verifier which asks the issuer
+// whether token is active, see RFC 7662. Results are cached for CacheTTL, so
+// revoked tokens are rejected after this time at most.
+func NewIntrospectionVerifier(opts IntrospectionOptions) Verifier {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultIntrospectionCacheTTL
+	}
+
+	return &introspectionVerifier{
+		client:   opts.Client,
+		clientID: opts.ClientID,
+		endpoint: opts.Endpoint,
+		entries:  make(map[string]introspectionEntry),
+		secret:   opts.ClientSecret,
+		ttl:      opts.CacheTTL,
+	}
+}
+
+func (v *introspectionVerifier) Verify(ctx context.Context, accessToken string) (*Token, error) {
+	hash := sha256.Sum256([]byte(accessToken))
+	key := hex.EncodeToString(hash[:])
+
+	if tkn, ok := v.load(key); ok {
+		if tkn == nil {
+			return nil, ErrInvalidToken
+		}
+
+		return tkn, nil
+	}
+
+	tkn, err := v.introspect(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	v.store(key, tkn)
+
+	if tkn == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return tkn, nil
+}
+
+// introspect returns claims of active token, or nil if token is inactive.
+func (v *introspectionVerifier) introspect(ctx context.Context, accessToken string) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint,
+		strings.NewReader(url.Values{"token": []string{accessToken}}.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create introspection request: %w", err)
+	}
+
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+	if v.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(v.clientID), url.QueryEscape(v.secret))
+	} else {
+		req.Header.Set(common.HeaderAuthorization, SchemeBearer+" "+accessToken)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// NOTE(toby3d): issuer rejects self-authorized introspection of the
+	// invalid token.
+	if resp.StatusCode != http.StatusOK {
+		if v.clientID == "" && resp.StatusCode < http.StatusInternalServerError {
+			return nil, nil //nolint:nilnil // inactive token
+		}
+
+		return nil, fmt.Errorf("cannot introspect token: unexpected status %d", resp.StatusCode)
+	}
+
+	out := new(introspectionResponse)
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("cannot decode introspection response: %w", err)
+	}
+
+	if !out.Active {
+		return nil, nil //nolint:nilnil // inactive token
+	}
+
+	tkn := &Token{
+		Me:       out.Me,
+		ClientID: out.ClientID,
+		ACR:      out.ACR,
+		AMR:      out.AMR,
+		Scope:    strings.Fields(out.Scope),
+	}
+
+	if tkn.Me == "" {
+		tkn.Me = out.Subject
+	}
+
+	if out.Exp != 0 {
+		tkn.Expiry = time.Unix(out.Exp, 0).UTC()
+	}
+
+	if out.AuthTime != 0 {
+		tkn.AuthTime = time.Unix(out.AuthTime, 0).UTC()
+	}
+
+	if out.Cnf != nil {
+		tkn.JKT = out.Cnf.JKT
+	}
+
+	return tkn, nil
+}
+
+func (v *introspectionVerifier) load(key string) (*Token, bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	entry, ok := v.entries[key]
+	if !ok || time.Now().After(entry.expiry) {
+		return nil, false
+	}
+
+	return entry.token, true
+}
+
+func (v *introspectionVerifier) store(key string, tkn *Token) {
+	now := time.Now()
+	expiry := now.Add(v.ttl)
+
+	if tkn != nil && !tkn.Expiry.IsZero() && tkn.Expiry.Before(expiry) {
+		expiry = tkn.Expiry
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if len(v.entries) >= maxIntrospectionEntries {
+		for k, entry := range v.entries {
+			if now.After(entry.expiry) {
+				delete(v.entries, k)
+			}
+		}
+	}
+
+	// NOTE(toby3d): all results are still fresh, forget them at once
+	// rather than track usage.
+	if len(v.entries) >= maxIntrospectionEntries {
+		clear(v.entries)
+	}
+
+	v.entries[key] = introspectionEntry{expiry: expiry, token: tkn}
+}
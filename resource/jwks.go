@@ -0,0 +1,217 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/domain"
+)
+
+type (
+	JWKSOptions struct {
+		// Client requests the key set. By default http.DefaultClient
+		// is used.
+		Client *http.Client
+
+		// URL of the JSON Web Key Set of the issuer, such as
+		// https://auth.example.com/.well-known/jwks.json.
+		URL string
+
+		// Issuer and Audience are checked against iss and aud claims,
+		// if provided.
+		Issuer   string
+		Audience string
+
+		// RefreshInterval is the time after which the key set is
+		// requested again. Default to one hour.
+		RefreshInterval time.Duration
+	}
+
+	jwksVerifier struct {
+		fetched time.Time
+		set     jwk.Set
+		client  *http.Client
+		options []jwt.ParseOption
+		url     string
+		refresh time.Duration
+		mutex   sync.Mutex
+	}
+)
+
+var (
+	errTokenType = errors.New(`typ header must be "` + domain.AccessTokenType + `"`)
+	errTokenUse  = errors.New("token_use claim is not allowed in access token")
+)
+
+// DefaultJWKSRefreshInterval is the default lifetime of the cached key set.
+const DefaultJWKSRefreshInterval time.Duration = time.Hour
+
+// minJWKSRefreshInterval limits refetching of the key set by tokens with
+// unknown keys.
+const minJWKSRefreshInterval time.Duration = time.Minute
+
+// NewJWKSVerifier creates a new verifier of JWT access tokens signed by one of
+// the keys published by the issuer. Tokens are validated offline, the key set
+// is refetched once RefreshInterval passed or token is signed by unknown key,
+// so the rotated keys are picked up.
+//
+// NOTE(toby3d): the IndieAuth server signs access tokens by the published key
+// only if OpenID Connect is enabled, otherwise use NewIntrospectionVerifier.
+// Revoked tokens are accepted until expiration, use NewIntrospectionVerifier
+// if revocation must be respected.
+func NewJWKSVerifier(opts JWKSOptions) Verifier {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	// NOTE(toby3d): the service can register its own type of the scope
+	// claim globally, the verifier always reads it as is. Refresh tokens
+	// are signed by the same key, but carry the token_use claim.
+	options := []jwt.ParseOption{
+		jwt.WithValidate(true),
+		jwt.WithTypedClaim("scope", ""),
+		jwt.WithRequiredClaim("client_id"),
+		jwt.WithRequiredClaim("scope"),
+		jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, tkn jwt.Token) jwt.ValidationError {
+			if _, ok := tkn.Get("token_use"); ok {
+				return jwt.NewValidationError(errTokenUse)
+			}
+
+			return nil
+		})),
+	}
+
+	if opts.Issuer != "" {
+		options = append(options, jwt.WithIssuer(opts.Issuer))
+	}
+
+	if opts.Audience != "" {
+		options = append(options, jwt.WithAudience(opts.Audience))
+	}
+
+	return &jwksVerifier{
+		client:  opts.Client,
+		options: options,
+		refresh: opts.RefreshInterval,
+		url:     opts.URL,
+	}
+}
+
+func (v *jwksVerifier) Verify(ctx context.Context, accessToken string) (*Token, error) {
+	// NOTE(toby3d): ID tokens and authorization responses are signed by
+	// the same keys, so the token must be typed as the access token, see
+	// RFC 9068 section 4.
+	msg, err := jws.ParseString(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if len(msg.Signatures()) != 1 ||
+		!strings.EqualFold(msg.Signatures()[0].ProtectedHeaders().Type(), domain.AccessTokenType) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, errTokenType)
+	}
+
+	set, err := v.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tkn, err := v.parse(set, accessToken)
+	if err != nil {
+		// NOTE(toby3d): token can be signed by the new key of the
+		// rotated set.
+		if set, err = v.keySet(ctx, true); err != nil {
+			return nil, err
+		}
+
+		if tkn, err = v.parse(set, accessToken); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+	}
+
+	return newTokenFromJWT(tkn), nil
+}
+
+func (v *jwksVerifier) parse(set jwk.Set, accessToken string) (jwt.Token, error) {
+	//nolint:wrapcheck // wrapped by caller
+	return jwt.ParseString(accessToken, append([]jwt.ParseOption{jwt.WithKeySet(set,
+		jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false))}, v.options...)...)
+}
+
+// keySet returns cached key set, refetching it if cache is expired or force
+// is requested and enough time has passed since last fetch.
+func (v *jwksVerifier) keySet(ctx context.Context, force bool) (jwk.Set, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	age := time.Since(v.fetched)
+	if v.set != nil && age < v.refresh && (!force || age < minJWKSRefreshInterval) {
+		return v.set, nil
+	}
+
+	set, err := jwk.Fetch(ctx, v.url, jwk.WithHTTPClient(v.client))
+	if err != nil {
+		// NOTE(toby3d): stale keys are better than none while issuer
+		// is unavailable.
+		if v.set != nil {
+			return v.set, nil
+		}
+
+		return nil, fmt.Errorf("cannot fetch key set: %w", err)
+	}
+
+	v.set, v.fetched = set, time.Now()
+
+	return v.set, nil
+}
+
+// newTokenFromJWT returns claims of the parsed JWT access token.
+func newTokenFromJWT(tkn jwt.Token) *Token {
+	out := &Token{
+		Expiry: tkn.Expiration(),
+		Me:     tkn.Subject(),
+	}
+
+	claims := tkn.PrivateClaims()
+
+	// NOTE(toby3d): RFC 9068 section 2.2: iss claim describes the
+	// authorization server, client is described by the separate claim.
+	out.ClientID, _ = claims["client_id"].(string)
+
+	if scope, ok := claims["scope"].(string); ok {
+		out.Scope = strings.Fields(scope)
+	}
+
+	if cnf, ok := claims["cnf"].(map[string]any); ok {
+		out.JKT, _ = cnf["jkt"].(string)
+	}
+
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		out.AuthTime = time.Unix(int64(authTime), 0).UTC()
+	}
+
+	out.ACR, _ = claims["acr"].(string)
+
+	if amr, ok := claims["amr"].([]any); ok {
+		for i := range amr {
+			if method, ok := amr[i].(string); ok {
+				out.AMR = append(out.AMR, method)
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,241 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	replaymemoryrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+)
+
+type (
+	Options struct {
+		// BaseURL is the public URL of the resource server, used to
+		// check the htu claim of DPoP proofs behind reverse proxies.
+		// By default URL is built from the request host.
+		BaseURL *url.URL
+
+		// Realm is the protection space of the resource server sent
+		// in WWW-Authenticate header.
+		Realm string
+	}
+
+	// Middleware authorizes requests to the protected handlers.
+	Middleware struct {
+		verifier Verifier
+		replays  replay.Repository
+		baseURL  *url.URL
+		realm    string
+	}
+)
+
+// Schemes of the Authorization header, see RFC 6750 section 2.1 and RFC 9449
+// section 7.1.
+const (
+	SchemeBearer string = "Bearer"
+	SchemeDPoP   string = "DPoP"
+)
+
+var (
+	ErrMissingToken error = domain.NewError(
+		domain.ErrorCodeAccessDenied,
+		"access token is missing",
+		"https://www.rfc-editor.org/rfc/rfc6750#section-3.1",
+	)
+	ErrInvalidRequest error = domain.NewError(
+		domain.ErrorCodeInvalidRequest,
+		"access token must be sent by single method in Authorization header, form body or query",
+		"https://www.rfc-editor.org/rfc/rfc6750#section-2",
+	)
+	ErrInvalidToken error = domain.NewError(
+		domain.ErrorCodeInvalidToken,
+		"access token is expired, revoked, malformed or invalid",
+		"https://www.rfc-editor.org/rfc/rfc6750#section-3.1",
+	)
+	ErrInsufficientScope error = domain.NewError(
+		domain.ErrorCodeInsufficientScope,
+		"access token does not grant required scopes",
+		"https://www.rfc-editor.org/rfc/rfc6750#section-3.1",
+	)
+)
+
+// New creates a new middleware which validates access tokens by provided
+// verifier. Used DPoP proofs are remembered in memory of the middleware.
+func New(verifier Verifier, opts Options) *Middleware {
+	return &Middleware{
+		verifier: verifier,
+		replays:  replaymemoryrepo.NewMemoryReplayRepository(),
+		baseURL:  opts.BaseURL,
+		realm:    opts.Realm,
+	}
+}
+
+// Require returns middleware which passes requests to the next handler only
+// with the valid access token which grants all of provided scopes. The token
+// is available to the next handler by FromContext.
+func (m *Middleware) Require(scopes ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tkn, scheme, err := m.authorize(r)
+			if err != nil {
+				m.writeError(w, scheme, scopes, err)
+
+				return
+			}
+
+			if !tkn.HasScope(scopes...) {
+				m.writeError(w, scheme, scopes, ErrInsufficientScope)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), tkn)))
+		})
+	}
+}
+
+func (m *Middleware) authorize(r *http.Request) (*Token, string, error) {
+	scheme, accessToken, err := extractToken(r)
+	if err != nil {
+		return nil, scheme, err
+	}
+
+	tkn, err := m.verifier.Verify(r.Context(), accessToken)
+	if err != nil {
+		return nil, scheme, err
+	}
+
+	// NOTE(toby3d): token bound to the client key can be used only with
+	// the proof of possession of this key, see RFC 9449 section 7.1.
+	proof, err := (domain.Token{AccessToken: accessToken, JKT: tkn.JKT}).Confirm(scheme,
+		r.Header.Get(common.HeaderDPoP), domain.DPoPProofOptions{
+			URL:         m.requestURL(r),
+			Method:      r.Method,
+			AccessToken: "",
+		})
+	if err == nil && proof != nil {
+		err = m.replays.Create(r.Context(), "dpop:"+proof.JKT+":"+proof.ID, proof.Expiry())
+	}
+
+	if err != nil {
+		return nil, scheme, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return tkn, scheme, nil
+}
+
+// requestURL returns URL of the request without query to check DPoP proof.
+func (m *Middleware) requestURL(r *http.Request) *url.URL {
+	if m.baseURL != nil {
+		return m.baseURL.JoinPath(r.URL.Path)
+	}
+
+	out := &url.URL{Scheme: "https", Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath}
+	if r.TLS == nil {
+		out.Scheme = "http"
+	}
+
+	return out
+}
+
+// writeError writes error response with WWW-Authenticate challenge, see RFC
+// 6750 section 3.
+func (m *Middleware) writeError(w http.ResponseWriter, scheme string, scopes []string, err error) {
+	// NOTE(toby3d): verifier cannot reach the issuer, token is neither
+	// valid nor invalid.
+	out := new(domain.Error)
+	if !errors.As(err, &out) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	status := http.StatusUnauthorized
+
+	params := make([]string, 0, 4) //nolint:gomnd // realm, error, error_description and scope
+	if m.realm != "" {
+		params = append(params, "realm="+strconv.Quote(m.realm))
+	}
+
+	// NOTE(toby3d): request without any authentication information
+	// must not contain error code in challenge, see RFC 6750 section 3.1.
+	if !errors.Is(err, ErrMissingToken) {
+		params = append(params, "error="+strconv.Quote(out.Code.String()),
+			"error_description="+strconv.Quote(out.Description))
+	}
+
+	switch out.Code {
+	case domain.ErrorCodeInvalidRequest:
+		status = http.StatusBadRequest
+	case domain.ErrorCodeInsufficientScope:
+		status = http.StatusForbidden
+	}
+
+	if len(scopes) > 0 {
+		params = append(params, "scope="+strconv.Quote(strings.Join(scopes, " ")))
+	}
+
+	if !strings.EqualFold(scheme, SchemeDPoP) {
+		scheme = SchemeBearer
+	} else {
+		scheme = SchemeDPoP
+		params = append(params, "algs="+strconv.Quote(strings.Join(domain.DPoPSigningAlgorithms, " ")))
+	}
+
+	challenge := scheme
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	w.Header().Set(common.HeaderWWWAuthenticate, challenge)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// extractToken returns access token sent by one of the methods described in
+// RFC 6750 section 2.
+func extractToken(r *http.Request) (string, string, error) {
+	var scheme, accessToken string
+
+	methods := 0
+
+	if header := r.Header.Get(common.HeaderAuthorization); header != "" {
+		methods++
+
+		scheme, accessToken, _ = strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, SchemeBearer) && !strings.EqualFold(scheme, SchemeDPoP) {
+			return scheme, "", ErrInvalidRequest
+		}
+	}
+
+	// NOTE(toby3d): form body is parsed only for the form-encoded
+	// requests, so JSON and multipart bodies are kept for the handler.
+	if r.Method != http.MethodGet && strings.HasPrefix(r.Header.Get(common.HeaderContentType),
+		common.MIMEApplicationForm) {
+		if err := r.ParseForm(); err == nil && r.PostForm.Has("access_token") {
+			methods++
+			scheme, accessToken = SchemeBearer, r.PostForm.Get("access_token")
+		}
+	}
+
+	if r.URL.Query().Has("access_token") {
+		methods++
+		scheme, accessToken = SchemeBearer, r.URL.Query().Get("access_token")
+	}
+
+	switch {
+	case methods == 0:
+		return SchemeBearer, "", ErrMissingToken
+	case methods > 1:
+		return scheme, "", ErrInvalidRequest
+	case accessToken == "":
+		return scheme, "", ErrInvalidToken
+	}
+
+	return scheme, accessToken, nil
+}
@@ -0,0 +1,562 @@
+package resource_test
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"source.toby3d.me/toby3d/auth/internal/auth"
+	authucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
+	clientrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	oidchttpdelivery "source.toby3d.me/toby3d/auth/internal/oidc/delivery/http"
+	oidcucase "source.toby3d.me/toby3d/auth/internal/oidc/usecase"
+	profilerepo "source.toby3d.me/toby3d/auth/internal/profile/repository/memory"
+	replayrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
+	sessionrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	tokenhttpdelivery "source.toby3d.me/toby3d/auth/internal/token/delivery/http"
+	tokenrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
+	"source.toby3d.me/toby3d/auth/resource"
+)
+
+type verifierFunc func(ctx context.Context, accessToken string) (*resource.Token, error)
+
+func TestRequire(t *testing.T) {
+	t.Parallel()
+
+	verifier := verifierFunc(func(_ context.Context, accessToken string) (*resource.Token, error) {
+		switch accessToken {
+		default:
+			return nil, resource.ErrInvalidToken
+		case "create":
+			return &resource.Token{
+				Me:       "https://user.example.net/",
+				ClientID: "https://app.example.com/",
+				Scope:    []string{"create", "update"},
+			}, nil
+		case "read":
+			return &resource.Token{Me: "https://user.example.net/", Scope: []string{"read"}}, nil
+		case "bound":
+			return &resource.Token{Me: "https://user.example.net/", Scope: []string{"create"}, JKT: "hackme"}, nil
+		case "down":
+			return nil, errors.New("connection refused")
+		}
+	})
+
+	handler := resource.New(verifier, resource.Options{Realm: "micropub"}).Require("create")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if resource.Me(r.Context()) != "https://user.example.net/" ||
+				resource.ClientID(r.Context()) != "https://app.example.com/" {
+				w.WriteHeader(http.StatusTeapot)
+			}
+		}))
+
+	for name, tc := range map[string]struct {
+		target    string
+		header    string
+		expStatus int
+		expHeader string
+	}{
+		"header": {
+			target:    "/micropub",
+			header:    "Bearer create",
+			expStatus: http.StatusOK,
+		},
+		"query": {
+			target:    "/micropub?access_token=create",
+			expStatus: http.StatusOK,
+		},
+		"missing": {
+			target:    "/micropub",
+			expStatus: http.StatusUnauthorized,
+			expHeader: `Bearer realm="micropub", scope="create"`,
+		},
+		"invalid": {
+			target:    "/micropub",
+			header:    "Bearer hackme",
+			expStatus: http.StatusUnauthorized,
+			expHeader: `Bearer realm="micropub", error="invalid_token"`,
+		},
+		"insufficient scope": {
+			target:    "/micropub",
+			header:    "Bearer read",
+			expStatus: http.StatusForbidden,
+			expHeader: `Bearer realm="micropub", error="insufficient_scope"`,
+		},
+		"multiple methods": {
+			target:    "/micropub?access_token=create",
+			header:    "Bearer create",
+			expStatus: http.StatusBadRequest,
+			expHeader: `Bearer realm="micropub", error="invalid_request"`,
+		},
+		"unknown scheme": {
+			target:    "/micropub",
+			header:    "Basic create",
+			expStatus: http.StatusBadRequest,
+		},
+		"bound without proof": {
+			target:    "/micropub",
+			header:    "Bearer bound",
+			expStatus: http.StatusUnauthorized,
+			expHeader: `Bearer realm="micropub", error="invalid_token"`,
+		},
+		"bound with invalid proof": {
+			target:    "/micropub",
+			header:    "DPoP bound",
+			expStatus: http.StatusUnauthorized,
+			expHeader: `DPoP realm="micropub", error="invalid_token"`,
+		},
+		"issuer is down": {
+			target:    "/micropub",
+			header:    "Bearer down",
+			expStatus: http.StatusInternalServerError,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://media.example.com"+tc.target, nil)
+			if tc.header != "" {
+				req.Header.Set(common.HeaderAuthorization, tc.header)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expStatus {
+				t.Errorf("%s %s = %d, want %d", req.Method, req.RequestURI, resp.StatusCode, tc.expStatus)
+			}
+
+			if challenge := resp.Header.Get(common.HeaderWWWAuthenticate); !strings.HasPrefix(challenge,
+				tc.expHeader) {
+				t.Errorf("%s %s returns %s header %q, want prefix %q", req.Method, req.RequestURI,
+					common.HeaderWWWAuthenticate, challenge, tc.expHeader)
+			}
+		})
+	}
+}
+
+func TestRequire_DPoP(t *testing.T) {
+	t.Parallel()
+
+	key := domain.TestDPoPKey(t)
+
+	public, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	verifier := verifierFunc(func(_ context.Context, _ string) (*resource.Token, error) {
+		return &resource.Token{Me: "https://user.example.net/", JKT: jkt}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "https://media.example.com/media?q=source", nil)
+
+	proof, err := domain.NewDPoPProof(key, "ES256", "1", domain.DPoPProofOptions{
+		URL:         req.URL,
+		Method:      req.Method,
+		AccessToken: "bound",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set(common.HeaderAuthorization, "DPoP bound")
+	req.Header.Set(common.HeaderDPoP, proof)
+
+	w := httptest.NewRecorder()
+	resource.New(verifier, resource.Options{}).Require()(http.HandlerFunc(func(http.ResponseWriter,
+		*http.Request) {
+	})).ServeHTTP(w, req)
+
+	if resp := w.Result(); resp.StatusCode != http.StatusOK {
+		t.Errorf("%s %s = %d, want %d: %s", req.Method, req.RequestURI, resp.StatusCode, http.StatusOK,
+			resp.Header.Get(common.HeaderWWWAuthenticate))
+	}
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	t.Parallel()
+
+	key := domain.TestSigningKey(t)
+
+	set, err := jwk.PublicSetOf(func() jwk.Set {
+		set := jwk.NewSet()
+		_ = set.AddKey(key)
+
+		return set
+	}())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationJWKSet)
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	verifier := resource.NewJWKSVerifier(resource.JWKSOptions{
+		Client: srv.Client(),
+		URL:    srv.URL,
+		Issuer: "https://app.example.com/",
+	})
+
+	accessClaims := map[string]any{
+		jwt.IssuerKey:  "https://app.example.com/",
+		jwt.SubjectKey: "https://user.example.net/",
+		"client_id":    "https://client.example.org/",
+		"scope":        "create update",
+	}
+
+	for name, tc := range map[string]struct {
+		key      jwk.Key
+		claims   map[string]any
+		typ      string
+		expiry   time.Duration
+		expError error
+	}{
+		"valid": {
+			key: key, claims: accessClaims, typ: domain.AccessTokenType, expiry: time.Hour,
+		},
+		"expired": {
+			key: key, claims: accessClaims, typ: domain.AccessTokenType, expiry: -time.Hour,
+			expError: resource.ErrInvalidToken,
+		},
+		"other iss": {
+			key: key, typ: domain.AccessTokenType, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:  "https://evil.example.com/",
+				jwt.SubjectKey: "https://user.example.net/",
+				"client_id":    "https://client.example.org/",
+				"scope":        "create update",
+			},
+		},
+		"unknown key": {
+			key: domain.TestSigningKey(t), claims: accessClaims, typ: domain.AccessTokenType, expiry: time.Hour,
+			expError: resource.ErrInvalidToken,
+		},
+		"untyped": {
+			key: key, claims: accessClaims, expiry: time.Hour, expError: resource.ErrInvalidToken,
+		},
+		"refresh token": {
+			key: key, typ: domain.AccessTokenType, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:  "https://app.example.com/",
+				jwt.SubjectKey: "https://user.example.net/",
+				"client_id":    "https://client.example.org/",
+				"scope":        "create update",
+				"token_use":    domain.TokenUseRefresh,
+			},
+		},
+		"id token": {
+			key: key, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:   "https://app.example.com/",
+				jwt.SubjectKey:  "https://user.example.net/",
+				jwt.AudienceKey: "https://client.example.org/",
+				"nonce":         "n-0S6_WzA2Mj",
+			},
+		},
+		"authorization response": {
+			key: key, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:   "https://app.example.com/",
+				jwt.AudienceKey: "https://client.example.org/",
+				"code":          "SplxlOBeZQQYbYS6WxSbIA",
+				"state":         "af0ifjsldkj",
+			},
+		},
+		"without scope": {
+			key: key, typ: domain.AccessTokenType, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:  "https://app.example.com/",
+				jwt.SubjectKey: "https://user.example.net/",
+				"client_id":    "https://client.example.org/",
+			},
+		},
+		"without client_id": {
+			key: key, typ: domain.AccessTokenType, expiry: time.Hour, expError: resource.ErrInvalidToken,
+			claims: map[string]any{
+				jwt.IssuerKey:  "https://app.example.com/",
+				jwt.SubjectKey: "https://user.example.net/",
+				"scope":        "create update",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tkn := jwt.New()
+			_ = tkn.Set(jwt.ExpirationKey, time.Now().Add(tc.expiry))
+
+			for k, v := range tc.claims {
+				_ = tkn.Set(k, v)
+			}
+
+			headers := jws.NewHeaders()
+			if tc.typ != "" {
+				_ = headers.Set(jws.TypeKey, tc.typ)
+			}
+
+			accessToken, err := jwt.Sign(tkn, jwt.WithKey(tc.key.Algorithm(), tc.key,
+				jws.WithProtectedHeaders(headers)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := verifier.Verify(context.Background(), string(accessToken))
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Verify() = %v, want %v", err, tc.expError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Me != "https://user.example.net/" || result.ClientID != "https://client.example.org/" ||
+				!result.HasScope("create", "update") {
+				t.Errorf("Verify() = %+v, want token of https://user.example.net/", result)
+			}
+		})
+	}
+
+	// NOTE(toby3d): key set is cached, unknown keys refetch it once per
+	// minute at most.
+	if count := requests.Load(); count > 2 {
+		t.Errorf("key set is requested %d times, want 2 at most", count)
+	}
+}
+
+func TestJWKSVerifier_Server(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.Server.RootURL = "https://auth.example.com/"
+	config.JWT.Expiry = time.Hour
+	config.JWT.RefreshExpiry = 24 * time.Hour
+	key := domain.TestSigningKey(t)
+
+	cid, err := domain.ParseClientID("http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirectURI := &url.URL{Scheme: "http", Host: "localhost", Path: "/callback"}
+	clients := clientrepo.NewMemoryClientRepository()
+
+	if err = clients.Create(context.Background(), domain.Client{
+		ID:          *cid,
+		RedirectURI: []*url.URL{redirectURI},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := profilerepo.NewMemoryProfileRepository()
+	sessions := sessionrepo.NewMemorySessionRepository(*config)
+	authService := authucase.NewAuthUseCase(sessions, profiles, nil, nil, *config)
+	tokens := tokenucase.NewTokenUseCase(tokenucase.Config{
+		Config:     *config,
+		Profiles:   profiles,
+		Sessions:   sessions,
+		SigningKey: key,
+		Tokens:     tokenrepo.NewMemoryTokenRepository(),
+	})
+	tokenHandler := tokenhttpdelivery.NewHandler(tokens, authService,
+		clientucase.NewClientUseCase(clients, clientrepo.NewMemoryClientRepository(), nil, nil, nil, nil), nil,
+		scopeucase.NewScopeUseCase(domain.ScopePolicyReject), replayrepo.NewMemoryReplayRepository(), *config)
+	oidcService := oidcucase.NewOIDCUseCase(key, nil, *config)
+	jwksHandler := oidchttpdelivery.NewHandler(oidcService)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		default:
+			http.NotFound(w, r)
+		case "/token":
+			tokenHandler.ServeHTTP(w, r)
+		case "/.well-known/jwks.json":
+			jwksHandler.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	code, err := authService.Generate(context.Background(), auth.GenerateOptions{
+		ClientID:            *cid,
+		Me:                  *domain.TestMe(t, "https://user.example.net/"),
+		RedirectURI:         redirectURI,
+		CodeChallengeMethod: domain.CodeChallengeMethodS256,
+		CodeChallenge:       "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		Scope:               domain.Scopes{domain.ScopeCreate, domain.ScopeUpdate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/token",
+		strings.NewReader(url.Values{
+			"client_id":     []string{cid.String()},
+			"code":          []string{code},
+			"code_verifier": []string{verifier},
+			"grant_type":    []string{domain.GrantTypeAuthorizationCode.String()},
+			"redirect_uri":  []string{redirectURI.String()},
+		}.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.AccessToken == "" || response.RefreshToken == "" {
+		t.Fatalf("POST /token = %d, want access and refresh tokens", resp.StatusCode)
+	}
+
+	jwks := resource.NewJWKSVerifier(resource.JWKSOptions{
+		Client: srv.Client(),
+		URL:    srv.URL + "/.well-known/jwks.json",
+		Issuer: config.Server.GetRootURL(),
+	})
+
+	result, err := jwks.Verify(context.Background(), response.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Me != "https://user.example.net/" || result.ClientID != cid.String() ||
+		!result.HasScope("create", "update") {
+		t.Errorf("Verify() = %+v, want token of %s issued to %s", result, "https://user.example.net/", cid)
+	}
+
+	idToken, err := oidcService.IDToken(context.Background(), domain.Token{
+		ClientID: *cid,
+		Me:       *domain.TestMe(t, "https://user.example.net/"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE(toby3d): refresh and ID tokens are signed by the same key.
+	for name, tkn := range map[string]string{
+		"refresh token": response.RefreshToken,
+		"ID token":      idToken,
+	} {
+		if _, err = jwks.Verify(context.Background(), tkn); !errors.Is(err, resource.ErrInvalidToken) {
+			t.Errorf("Verify(%s) = %v, want %v", name, err, resource.ErrInvalidToken)
+		}
+	}
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		// NOTE(toby3d): token authorizes its own introspection.
+		if r.Header.Get(common.HeaderAuthorization) != "Bearer "+r.PostForm.Get("token") {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+
+		switch r.PostForm.Get("token") {
+		default:
+			_, _ = w.Write([]byte(`{"active":false}`))
+		case "active":
+			_, _ = w.Write([]byte(`{"active":true,"me":"https://user.example.net/",` +
+				`"client_id":"https://app.example.com/","scope":"create media","amr":["pwd","otp"]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	verifier := resource.NewIntrospectionVerifier(resource.IntrospectionOptions{
+		Client:   srv.Client(),
+		Endpoint: srv.URL,
+	})
+
+	for i := 0; i < 2; i++ {
+		result, err := verifier.Verify(context.Background(), "active")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Me != "https://user.example.net/" || !result.HasScope("media") || len(result.AMR) != 2 {
+			t.Errorf("Verify() = %+v, want active token of https://user.example.net/", result)
+		}
+
+		if _, err = verifier.Verify(context.Background(), "inactive"); !errors.Is(err, resource.ErrInvalidToken) {
+			t.Errorf("Verify() = %v, want %v", err, resource.ErrInvalidToken)
+		}
+	}
+
+	if count := requests.Load(); count != 2 {
+		t.Errorf("introspection endpoint is requested %d times, want %d", count, 2)
+	}
+}
+
+func (f verifierFunc) Verify(ctx context.Context, accessToken string) (*resource.Token, error) {
+	return f(ctx, accessToken)
+}
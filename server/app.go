@@ -0,0 +1,567 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"source.toby3d.me/toby3d/auth/internal/account"
+	accountucase "source.toby3d.me/toby3d/auth/internal/account/usecase"
+	"source.toby3d.me/toby3d/auth/internal/auth"
+	authhttpdelivery "source.toby3d.me/toby3d/auth/internal/auth/delivery/http"
+	authucase "source.toby3d.me/toby3d/auth/internal/auth/usecase"
+	"source.toby3d.me/toby3d/auth/internal/client"
+	clienthttpdelivery "source.toby3d.me/toby3d/auth/internal/client/delivery/http"
+	clientucase "source.toby3d.me/toby3d/auth/internal/client/usecase"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	consenthttpdelivery "source.toby3d.me/toby3d/auth/internal/consent/delivery/http"
+	consentucase "source.toby3d.me/toby3d/auth/internal/consent/usecase"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/forwardauth"
+	forwardauthhttpdelivery "source.toby3d.me/toby3d/auth/internal/forwardauth/delivery/http"
+	forwardauthfilerepo "source.toby3d.me/toby3d/auth/internal/forwardauth/repository/file"
+	forwardauthucase "source.toby3d.me/toby3d/auth/internal/forwardauth/usecase"
+	healthhttpdelivery "source.toby3d.me/toby3d/auth/internal/health/delivery/http"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+	imageproxyhttpdelivery "source.toby3d.me/toby3d/auth/internal/imageproxy/delivery/http"
+	imageproxyucase "source.toby3d.me/toby3d/auth/internal/imageproxy/usecase"
+	metadatahttpdelivery "source.toby3d.me/toby3d/auth/internal/metadata/delivery/http"
+	"source.toby3d.me/toby3d/auth/internal/middleware"
+	"source.toby3d.me/toby3d/auth/internal/oidc"
+	oidchttpdelivery "source.toby3d.me/toby3d/auth/internal/oidc/delivery/http"
+	oidcucase "source.toby3d.me/toby3d/auth/internal/oidc/usecase"
+	"source.toby3d.me/toby3d/auth/internal/policy"
+	policyfilerepo "source.toby3d.me/toby3d/auth/internal/policy/repository/file"
+	policyucase "source.toby3d.me/toby3d/auth/internal/policy/usecase"
+	"source.toby3d.me/toby3d/auth/internal/profile"
+	profileucase "source.toby3d.me/toby3d/auth/internal/profile/usecase"
+	"source.toby3d.me/toby3d/auth/internal/registration"
+	registrationhttpdelivery "source.toby3d.me/toby3d/auth/internal/registration/delivery/http"
+	registrationucase "source.toby3d.me/toby3d/auth/internal/registration/usecase"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	"source.toby3d.me/toby3d/auth/internal/scope"
+	scopefilerepo "source.toby3d.me/toby3d/auth/internal/scope/repository/file"
+	scopeucase "source.toby3d.me/toby3d/auth/internal/scope/usecase"
+	"source.toby3d.me/toby3d/auth/internal/session"
+	sessionucase "source.toby3d.me/toby3d/auth/internal/session/usecase"
+	"source.toby3d.me/toby3d/auth/internal/token"
+	tokenhttpdelivery "source.toby3d.me/toby3d/auth/internal/token/delivery/http"
+	tokenucase "source.toby3d.me/toby3d/auth/internal/token/usecase"
+	"source.toby3d.me/toby3d/auth/internal/urlutil"
+	userhttpdelivery "source.toby3d.me/toby3d/auth/internal/user/delivery/http"
+	userucase "source.toby3d.me/toby3d/auth/internal/user/usecase"
+	"source.toby3d.me/toby3d/auth/web"
+)
+
+// app is the server of the single tenant.
+type app struct {
+	accounts account.UseCase
+	auth     auth.UseCase
+	clients  client.UseCase
+	consents consent.UseCase
+	// forwardAuth is nil if forward-auth is disabled.
+	forwardAuth   forwardauth.UseCase
+	images        imageproxy.UseCase
+	matcher       language.Matcher
+	oidc          oidc.UseCase
+	policies      policy.UseCase
+	registrations registration.UseCase
+	// replays remembers used DPoP proofs.
+	replays  replay.Repository
+	scopes   scope.UseCase
+	sessions session.UseCase
+	profiles profile.UseCase
+	tokens   token.UseCase
+	static   fs.FS
+	clock    domain.Clock
+	renderer web.Renderer
+	// signingKey signs ID Tokens, if OpenID Connect is enabled.
+	signingKey jwk.Key
+	// self is the server instance itself as a client.
+	self   *domain.Client
+	config domain.Config
+	logger *log.Logger
+}
+
+func newApp(opts Options) (*app, error) {
+	// NOTE(toby3d): The server instance itself can be as a client.
+	rootURL, err := url.Parse(opts.Config.Server.GetRootURL())
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse root URL: %w", err)
+	}
+
+	cid, err := domain.ParseClientID(rootURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("fail to read config: %w", err)
+	}
+
+	self := &domain.Client{
+		Logo:        rootURL.JoinPath("icon.svg"),
+		URL:         rootURL,
+		ID:          *cid,
+		Name:        opts.Config.Name,
+		RedirectURI: []*url.URL{rootURL.JoinPath("callback")},
+	}
+
+	if opts.Logo != nil {
+		self.Logo = opts.Logo
+	}
+
+	clients := clientucase.NewClientUseCase(opts.Clients, opts.Registry, opts.Keys, opts.Requests,
+		opts.Replays, opts.Clock)
+	users := userucase.NewUserUseCase(opts.Users)
+
+	var (
+		signingKey jwk.Key
+		oidcs      oidc.UseCase
+	)
+
+	if opts.Config.OIDC.Enabled {
+		if signingKey, err = newSigningKey(opts.Config.OIDC.KeyFile); err != nil {
+			return nil, err
+		}
+
+		oidcs = oidcucase.NewOIDCUseCase(signingKey, opts.Clock, opts.Config)
+	}
+
+	scopes, err := newScopes(opts.Config.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := newPolicies(opts.Config.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := tokenucase.NewTokenUseCase(tokenucase.Config{
+		Audit:      opts.Audit,
+		Clock:      opts.Clock,
+		Config:     opts.Config,
+		Policies:   policies,
+		Profiles:   opts.Profiles,
+		Sessions:   opts.Sessions,
+		SigningKey: signingKey,
+		Tokens:     opts.Tokens,
+	})
+
+	forwardAuth, err := newForwardAuth(opts, tokens, scopes, *self)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		config:      opts.Config,
+		logger:      opts.Logger,
+		self:        self,
+		accounts:    accountucase.NewAccountUseCase(opts.Accounts, users, opts.Config),
+		static:      opts.Static,
+		clock:       opts.Clock,
+		renderer:    opts.Renderer,
+		auth:        authucase.NewAuthUseCase(opts.Sessions, opts.Profiles, opts.Audit, opts.Clock, opts.Config),
+		clients:     clients,
+		consents:    consentucase.NewConsentUseCase(clients, opts.Consents, opts.Clock),
+		forwardAuth: forwardAuth,
+		images: imageproxyucase.NewImageProxyUseCase(imageproxyucase.Config{
+			Client: opts.ImageClient,
+			Images: opts.Images,
+			Config: opts.Config,
+		}),
+		matcher:       language.NewMatcher(message.DefaultCatalog.Languages()),
+		oidc:          oidcs,
+		policies:      policies,
+		signingKey:    signingKey,
+		profiles:      profileucase.NewProfileUseCase(opts.Profiles),
+		registrations: registrationucase.NewRegistrationUseCase(opts.Registry, opts.Clock, opts.Config),
+		replays:       opts.Replays,
+		scopes:        scopes,
+		sessions:      sessionucase.NewSessionUseCase(opts.Sessions, opts.Audit, opts.Clock),
+		tokens:        tokens,
+	}, nil
+}
+
+// newSigningKey reads the private key of ID Tokens from the provided PEM file.
+// Without a persistent key a new one is generated, so all issued ID Tokens
+// cannot be verified after restart.
+func newSigningKey(path string) (jwk.Key, error) {
+	if path == "" {
+		key, err := domain.NewSigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create OpenID Connect signing key: %w", err)
+		}
+
+		return key, nil
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read OpenID Connect signing key: %w", err)
+	}
+
+	key, err := domain.ParseSigningKey(src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read OpenID Connect signing key: %w", err)
+	}
+
+	return key, nil
+}
+
+// newScopes creates registry of the well-known scopes and scopes defined in
+// the optional scopes file.
+func newScopes(config domain.ConfigScopes) (scope.UseCase, error) {
+	policy, err := domain.ParseScopePolicy(config.Unknown)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read unknown scopes policy: %w", err)
+	}
+
+	if config.Path == "" {
+		return scopeucase.NewScopeUseCase(policy), nil
+	}
+
+	definitions, err := scopefilerepo.NewFileScopeRepository(config.Path).Fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read scopes: %w", err)
+	}
+
+	return scopeucase.NewScopeUseCase(policy, definitions...), nil
+}
+
+// newPolicies creates policy of the rules defined in the optional policy file.
+func newPolicies(config domain.ConfigPolicy) (policy.UseCase, error) {
+	if config.Path == "" {
+		return policyucase.NewPolicyUseCase(), nil
+	}
+
+	rules, err := policyfilerepo.NewFilePolicyRepository(config.Path).Fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy: %w", err)
+	}
+
+	return policyucase.NewPolicyUseCase(rules...), nil
+}
+
+// newForwardAuth creates forward-auth of the upstream rules defined in the
+// optional forward-auth file, nil if file is not provided.
+func newForwardAuth(opts Options, tokens token.UseCase, scopes scope.UseCase, self domain.Client,
+) (forwardauth.UseCase, error) {
+	if opts.Config.ForwardAuth.Path == "" {
+		return nil, nil //nolint:nilnil // forward-auth is disabled
+	}
+
+	rules, err := forwardauthfilerepo.NewFileForwardAuthRepository(opts.Config.ForwardAuth.Path, scopes).
+		Fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read forward-auth rules: %w", err)
+	}
+
+	return forwardauthucase.NewForwardAuthUseCase(forwardauthucase.Config{
+		Clock:    opts.Clock,
+		Tokens:   tokens,
+		Sessions: opts.Sessions,
+		Client:   self,
+		Rules:    rules,
+		Config:   opts.Config,
+	}), nil
+}
+
+// DryRunPolicy writes explanation of the policy decision for the request
+// described by the URL-encoded query, without any side effects.
+func DryRunPolicy(ctx context.Context, w io.Writer, config domain.Config, query string) error {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("cannot parse dry-run request: %w", err)
+	}
+
+	policies, err := newPolicies(config.Policy)
+	if err != nil {
+		return err
+	}
+
+	req := domain.PolicyRequest{
+		ClientID:    domain.ClientID{},
+		RedirectURI: nil,
+		Me:          nil,
+		Scope:       make(domain.Scopes, 0),
+	}
+
+	cid, err := domain.ParseClientID(values.Get("client_id"))
+	if err != nil {
+		return fmt.Errorf("cannot parse client_id: %w", err)
+	}
+
+	req.ClientID = *cid
+
+	if values.Has("redirect_uri") {
+		if req.RedirectURI, err = url.Parse(values.Get("redirect_uri")); err != nil {
+			return fmt.Errorf("cannot parse redirect_uri: %w", err)
+		}
+	}
+
+	if values.Has("me") {
+		if req.Me, err = domain.ParseMe(values.Get("me")); err != nil {
+			return fmt.Errorf("cannot parse me: %w", err)
+		}
+	}
+
+	if err = req.Scope.UnmarshalForm([]byte(values.Get("scope"))); err != nil {
+		return fmt.Errorf("cannot parse scope: %w", err)
+	}
+
+	decision, err := policies.Evaluate(ctx, req)
+	if err != nil && decision == nil {
+		return fmt.Errorf("cannot evaluate policy: %w", err)
+	}
+
+	if _, err = io.WriteString(w, decision.Explain()); err != nil {
+		return fmt.Errorf("cannot write policy decision: %w", err)
+	}
+
+	return nil
+}
+
+// createOwner creates the owner account in provided repository and adds
+// configured identities to it.
+func (app *app) createOwner(ctx context.Context, accounts account.Repository) error {
+	if err := accounts.Create(ctx, domain.Account{
+		CreatedAt:  time.Now().UTC(),
+		Username:   app.config.IndieAuth.Username,
+		Identities: make([]*domain.Me, 0),
+	}); err != nil {
+		return fmt.Errorf("cannot create owner account: %w", err)
+	}
+
+	// NOTE(toby3d): each identity is verified by discovery of its
+	// authorization server, so unverified ones are skipped.
+	for _, raw := range app.config.IndieAuth.Identities {
+		me, err := domain.ParseMe(strings.TrimSpace(raw))
+		if err != nil {
+			app.logger.Printf("cannot parse identity %s: %v", raw, err)
+
+			continue
+		}
+
+		if _, err = app.accounts.AddIdentity(ctx, app.config.IndieAuth.Username, *me); err != nil {
+			app.logger.Printf("cannot add identity %s: %v", raw, err)
+		}
+	}
+
+	return nil
+}
+
+// TODO(toby3d): move module middlewares to here.
+//
+//nolint:funlen
+func (app *app) handler() http.Handler {
+	var registrationEndpoint *url.URL
+	if app.config.Registration.Enabled {
+		registrationEndpoint = app.self.ID.URL().JoinPath("register")
+	}
+
+	// NOTE(toby3d): openid scope is always known, but ID Tokens are
+	// issued only if OpenID Connect is enabled.
+	scopes := make(domain.Scopes, 0)
+
+	for _, s := range app.scopes.Supported(context.Background()) {
+		if s == domain.ScopeOpenID && app.oidc == nil {
+			continue
+		}
+
+		scopes = append(scopes, s)
+	}
+
+	var (
+		jwksURI                                           *url.URL
+		subjectTypes, idTokenSigningAlgs, claimsSupported []string
+		responseSigningAlgs                               []string
+		responseModes                                     = make([]domain.ResponseMode, 0)
+	)
+
+	// NOTE(toby3d): JWT-secured authorization responses are signed by the
+	// same key as ID Tokens.
+	for _, mode := range domain.ResponseModesSupported {
+		if !mode.IsJWT() || app.oidc != nil {
+			responseModes = append(responseModes, mode)
+		}
+	}
+
+	if app.oidc != nil {
+		jwksURI = app.self.ID.URL().JoinPath(".well-known", "jwks.json")
+		subjectTypes = []string{"public"}
+		idTokenSigningAlgs = []string{app.signingKey.Algorithm().String()}
+		responseSigningAlgs = idTokenSigningAlgs
+		claimsSupported = []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "acr", "amr", "name", "picture", "website",
+			"email",
+		}
+	}
+
+	profile := app.config.Security.GetProfile()
+	authMethods := make([]string, 0)
+
+	for _, method := range profile.ClientAuthMethods() {
+		authMethods = append(authMethods, method.String())
+	}
+
+	var ticketEndpoint *url.URL
+	if slices.Contains(profile.GrantTypes(), domain.GrantTypeTicket) {
+		ticketEndpoint = app.self.ID.URL().JoinPath("ticket")
+	}
+
+	//nolint:exhaustivestruct
+	metadata := metadatahttpdelivery.NewHandler(&domain.Metadata{
+		Issuer:                app.self.ID.URL(),
+		AuthorizationEndpoint: app.self.ID.URL().JoinPath("authorize"),
+		TokenEndpoint:         app.self.ID.URL().JoinPath("token"),
+		TicketEndpoint:        ticketEndpoint,
+		MicropubEndpoint:      nil,
+		MicrosubEndpoint:      nil,
+		IntrospectionEndpoint: app.self.ID.URL().JoinPath("introspect"),
+		RevocationEndpoint:    app.self.ID.URL().JoinPath("revocation"),
+		UserinfoEndpoint:      app.self.ID.URL().JoinPath("userinfo"),
+		RegistrationEndpoint:  registrationEndpoint,
+		ServiceDocumentation: &url.URL{
+			Scheme: "https",
+			Host:   "indieauth.net",
+			Path:   "/source/",
+		},
+		TokenEndpointAuthMethodsSupported:          authMethods,
+		TokenEndpointAuthSigningAlgValuesSupported: clientucase.AssertionAlgorithms,
+		IntrospectionEndpointAuthMethodsSupported: []string{
+			"Bearer",
+			domain.ClientAuthMethodClientSecretBasic.String(),
+			domain.ClientAuthMethodClientSecretPost.String(),
+			domain.ClientAuthMethodPrivateKeyJWT.String(),
+		},
+		RevocationEndpointAuthMethodsSupported:     authMethods,
+		ScopesSupported:                            scopes,
+		ResponseTypesSupported:                     profile.ResponseTypes(),
+		GrantTypesSupported:                        profile.GrantTypes(),
+		CodeChallengeMethodsSupported:              profile.CodeChallengeMethods(),
+		AuthorizationResponseIssParameterSupported: true,
+		PushedAuthorizationRequestEndpoint:         app.self.ID.URL().JoinPath("par"),
+		RequirePushedAuthorizationRequests:         app.config.PAR.Required,
+		RequestObjectSigningAlgValuesSupported:     clientucase.AssertionAlgorithms,
+		RequestParameterSupported:                  !app.config.PAR.Required,
+		RequestURIParameterSupported:               !app.config.PAR.Required,
+		ResponseModesSupported:                     responseModes,
+		AuthorizationSigningAlgValuesSupported:     responseSigningAlgs,
+		JWKSURI:                                    jwksURI,
+		SubjectTypesSupported:                      subjectTypes,
+		IDTokenSigningAlgValuesSupported:           idTokenSigningAlgs,
+		ClaimsSupported:                            claimsSupported,
+		DPoPSigningAlgValuesSupported:              domain.DPoPSigningAlgorithms,
+	})
+	health := healthhttpdelivery.NewHandler()
+	auth := authhttpdelivery.NewHandler(authhttpdelivery.NewHandlerOptions{
+		Accounts:   app.accounts,
+		Auth:       app.auth,
+		Clients:    app.clients,
+		Clock:      app.clock,
+		Consents:   app.consents,
+		Config:     app.config,
+		Images:     app.images,
+		Matcher:    app.matcher,
+		Profiles:   app.profiles,
+		Policies:   app.policies,
+		Renderer:   app.renderer,
+		Scopes:     app.scopes,
+		SigningKey: app.signingKey,
+	})
+	token := tokenhttpdelivery.NewHandler(app.tokens, app.auth, app.clients, app.oidc, app.scopes, app.replays,
+		app.config)
+	client := clienthttpdelivery.NewHandler(clienthttpdelivery.NewHandlerOptions{
+		Client:      *app.self,
+		Config:      app.config,
+		ForwardAuth: app.forwardAuth,
+		Images:      app.images,
+		Matcher:     app.matcher,
+		Renderer:    app.renderer,
+		Tokens:      app.tokens,
+	})
+	user := userhttpdelivery.NewHandler(app.tokens, app.replays, app.signingKey, app.config)
+	img := imageproxyhttpdelivery.NewHandler(app.images, app.config)
+	register := registrationhttpdelivery.NewHandler(app.registrations, app.config)
+	consents := consenthttpdelivery.NewHandler(app.consents, app.config)
+	jwks := oidchttpdelivery.NewHandler(app.oidc)
+	forwardAuth := forwardauthhttpdelivery.NewHandler(app.forwardAuth, app.config)
+	staticHandler := http.FileServer(http.FS(app.static))
+
+	return http.HandlerFunc(middleware.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		head, tail := urlutil.ShiftPath(r.URL.Path)
+
+		switch head {
+		default: // NOTE(toby3d): static or 404
+			staticHandler.ServeHTTP(w, r)
+		case "", "callback": // NOTE(toby3d): self-client
+			client.ServeHTTP(w, r)
+		case "token", "introspect", "revocation", "par":
+			token.ServeHTTP(w, r)
+		case ".well-known": // NOTE(toby3d): public server config
+			r.URL.Path = tail
+
+			switch head, _ = urlutil.ShiftPath(r.URL.Path); {
+			case head == "oauth-authorization-server":
+				metadata.ServeHTTP(w, r)
+			case head == "openid-configuration" && app.oidc != nil:
+				metadata.ServeHTTP(w, r)
+			case head == "jwks.json" && app.oidc != nil:
+				jwks.ServeHTTP(w, r)
+			default:
+				http.NotFound(w, r)
+			}
+		case "authorize":
+			r.URL.Path = tail
+
+			auth.ServeHTTP(w, r)
+		case "health":
+			r.URL.Path = tail
+
+			health.ServeHTTP(w, r)
+		case "userinfo":
+			r.URL.Path = tail
+
+			user.ServeHTTP(w, r)
+		case "img":
+			r.URL.Path = tail
+
+			img.ServeHTTP(w, r)
+		case "register":
+			r.URL.Path = tail
+
+			register.ServeHTTP(w, r)
+		case "forward-auth": // NOTE(toby3d): reverse proxy subrequests
+			if app.forwardAuth == nil {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			r.URL.Path = tail
+
+			forwardAuth.ServeHTTP(w, r)
+		case "admin": // NOTE(toby3d): owner-only API
+			r.URL.Path = tail
+
+			if head, r.URL.Path = urlutil.ShiftPath(r.URL.Path); head == "consent" {
+				consents.ServeHTTP(w, r)
+			} else {
+				http.NotFound(w, r)
+			}
+		}
+	}).Intercept(middleware.LogFmtWithConfig(middleware.LogFmtConfig{
+		Skipper: middleware.DefaultSkipper,
+		Output:  app.logger.Writer(),
+	})))
+}
@@ -0,0 +1,265 @@
+// Code generated by running "go generate" in golang.org/x/text. DO NOT EDIT.
+
+package server
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+type dictionary struct {
+	index []uint32
+	data  string
+}
+
+func (d *dictionary) Lookup(key string) (data string, ok bool) {
+	p, ok := messageKeyToIndex[key]
+	if !ok {
+		return "", false
+	}
+	start, end := d.index[p], d.index[p+1]
+	if start == end {
+		return "", false
+	}
+	return d.data[start:end], true
+}
+
+func init() {
+	dict := map[string]catalog.Dictionary{
+		"en": &dictionary{index: enIndex, data: enData},
+		"ru": &dictionary{index: ruIndex, data: ruData},
+	}
+	fallback := language.MustParse("en")
+	cat, err := catalog.NewFromMap(dict, catalog.Fallback(fallback))
+	if err != nil {
+		panic(err)
+	}
+	message.DefaultCatalog = cat
+}
+
+var messageKeyToIndex = map[string]int{
+	"%sProof of Key Code Exchange%s is a mechanism that protects against attackers in the middle hijacking your application's authentication process. You can still authorize this application without this protection, but you must independently verify the security of this connection. If you have any doubts - stop the process  and contact the developers.": 4,
+	"1 day":  64,
+	"1 week": 65,
+	"After authorization you will be redirected to the address below, which does not belong to the client's own site. Phishing sites often pretend to be well-known applications this way, so make sure you trust this address.":                                                  24,
+	"After authorization you will be redirected to the address below, which is handled by an application on your device rather than a website. Any application on this device can claim such an address, so make sure you have installed this application from a trusted source.": 18,
+	"Allow":                    8,
+	"Authorize %s":             0,
+	"Authorize application":    1,
+	"Block":                    58,
+	"Block and unblock users.": 59,
+	"Channels":                 60,
+	"Confirm sensitive scopes by the one-time password below or uncheck them.": 70,
+	"Confirm your identity to the application.":                                39,
+	"Continue":                        33,
+	"Could not load the client page.": 22,
+	"Create":                          40,
+	"Create drafts on your site.":     43,
+	"Default period":                  63,
+	"Delete":                          46,
+	"Delete posts on your site.":      47,
+	"Deny":                            7,
+	"Draft":                           42,
+	"Edit posts on your site.":        45,
+	"Email":                           36,
+	"Error":                           10,
+	"Follow":                          54,
+	"Follow and unfollow feeds.":      55,
+	"Forever":                         66,
+	"Grant access for":                62,
+	"How do I fix it?":                11,
+	"JavaScript is disabled in your browser, so press the button below to continue.":                                                                     32,
+	"Make sure you have opened this page yourself from the application you want to sign in to, and the application address above is the one you expect.": 25,
+	"Manage your channels.":  61,
+	"Media":                  50,
+	"Mute":                   56,
+	"Mute and unmute users.": 57,
+	"No scopes is requested: the application will only get your profile URL.": 6,
+	"One-time password":                   67,
+	"OpenID":                              38,
+	"Password":                            71,
+	"Profile":                             34,
+	"Publish new posts on your site.":     41,
+	"Read":                                52,
+	"Read your feeds and channels.":       53,
+	"Recipient":                           14,
+	"Redirecting":                         31,
+	"Resource":                            15,
+	"Restore deleted posts on your site.": 49,
+	"Scopes":                              5,
+	"Send":                                16,
+	"Sensitive scopes":                    68,
+	"Sign In":                             12,
+	"Sign in as":                          29,
+	"The access will be limited to the following resources:": 30,
+	"The client address contains look-alike characters.":     21,
+	"The client address uses internationalized characters which may imitate another well-known address. Check the address carefully letter by letter.":                    26,
+	"The client or redirect address uses plain HTTP, so the authorization code can be intercepted by anyone on the network.":                                              28,
+	"The name, logo and allowed redirect addresses of this client are unknown, so it can only redirect back to its own address. Continue only if you trust this address.": 27,
+	"These scopes allow the application to destroy your content or read your private data. Grant them only if you really trust this application.":                         69,
+	"This client does not use %sPKCE%s!":                      3,
+	"This client has never been authorized before.":           20,
+	"This client is an application installed on your device.": 17,
+	"This client redirects to another site.":                  19,
+	"This client uses %sPKCE%s with the %s%s%s method.":       2,
+	"This client uses an insecure connection.":                23,
+	"TicketAuth":                             13,
+	"Undelete":                               48,
+	"Update":                                 44,
+	"Upload files to your site.":             51,
+	"View your email address.":               37,
+	"View your name, photo and profile URL.": 35,
+	"You will be redirected to %s%s%s":       9,
+}
+
+var enIndex = []uint32{ // 73 elements
+	// Entry 0 - 1F
+	0x00000000, 0x00000010, 0x00000026, 0x00000067,
+	0x00000090, 0x000001f3, 0x000001fa, 0x00000242,
+	0x00000247, 0x0000024d, 0x00000277, 0x0000027d,
+	0x0000028e, 0x00000296, 0x000002a1, 0x000002ab,
+	0x000002b4, 0x000002b9, 0x000002f1, 0x000003fd,
+	0x00000424, 0x00000452, 0x00000485, 0x000004a5,
+	0x000004ce, 0x000005a9, 0x0000063c, 0x000006cd,
+	0x00000771, 0x000007e8, 0x000007f3, 0x0000082a,
+	// Entry 20 - 3F
+	0x00000836, 0x00000885, 0x0000088e, 0x00000896,
+	0x000008bd, 0x000008c3, 0x000008dc, 0x000008e3,
+	0x0000090d, 0x00000914, 0x00000934, 0x0000093a,
+	0x00000956, 0x0000095d, 0x00000976, 0x0000097d,
+	0x00000998, 0x000009a1, 0x000009c5, 0x000009cb,
+	0x000009e6, 0x000009eb, 0x00000a09, 0x00000a10,
+	0x00000a2b, 0x00000a30, 0x00000a47, 0x00000a4d,
+	0x00000a66, 0x00000a6f, 0x00000a85, 0x00000a96,
+	// Entry 40 - 5F
+	0x00000aa5, 0x00000aab, 0x00000ab2, 0x00000aba,
+	0x00000acc, 0x00000add, 0x00000b69, 0x00000bb2,
+	0x00000bbb,
+} // Size: 316 bytes
+
+const enData string = "" + // Size: 3003 bytes
+	"\x02Authorize %[1]s\x02Authorize application\x02This client uses %[1]sPK" +
+	"CE%[2]s with the %[3]s%[4]s%[5]s method.\x02This client does not use %[1" +
+	"]sPKCE%[2]s!\x02%[1]sProof of Key Code Exchange%[2]s is a mechanism that" +
+	" protects against attackers in the middle hijacking your application's a" +
+	"uthentication process. You can still authorize this application without " +
+	"this protection, but you must independently verify the security of this " +
+	"connection. If you have any doubts - stop the process  and contact the d" +
+	"evelopers.\x02Scopes\x02No scopes is requested: the application will onl" +
+	"y get your profile URL.\x02Deny\x02Allow\x02You will be redirected to %[" +
+	"1]s%[2]s%[3]s\x02Error\x02How do I fix it?\x02Sign In\x02TicketAuth\x02R" +
+	"ecipient\x02Resource\x02Send\x02This client is an application installed " +
+	"on your device.\x02After authorization you will be redirected to the add" +
+	"ress below, which is handled by an application on your device rather tha" +
+	"n a website. Any application on this device can claim such an address, s" +
+	"o make sure you have installed this application from a trusted source." +
+	"\x02This client redirects to another site.\x02This client has never been" +
+	" authorized before.\x02The client address contains look-alike characters" +
+	".\x02Could not load the client page.\x02This client uses an insecure con" +
+	"nection.\x02After authorization you will be redirected to the address be" +
+	"low, which does not belong to the client's own site. Phishing sites ofte" +
+	"n pretend to be well-known applications this way, so make sure you trust" +
+	" this address.\x02Make sure you have opened this page yourself from the " +
+	"application you want to sign in to, and the application address above is" +
+	" the one you expect.\x02The client address uses internationalized charac" +
+	"ters which may imitate another well-known address. Check the address car" +
+	"efully letter by letter.\x02The name, logo and allowed redirect addresse" +
+	"s of this client are unknown, so it can only redirect back to its own ad" +
+	"dress. Continue only if you trust this address.\x02The client or redirec" +
+	"t address uses plain HTTP, so the authorization code can be intercepted " +
+	"by anyone on the network.\x02Sign in as\x02The access will be limited to" +
+	" the following resources:\x02Redirecting\x02JavaScript is disabled in yo" +
+	"ur browser, so press the button below to continue.\x02Continue\x02Profil" +
+	"e\x02View your name, photo and profile URL.\x02Email\x02View your email " +
+	"address.\x02OpenID\x02Confirm your identity to the application.\x02Creat" +
+	"e\x02Publish new posts on your site.\x02Draft\x02Create drafts on your s" +
+	"ite.\x02Update\x02Edit posts on your site.\x02Delete\x02Delete posts on " +
+	"your site.\x02Undelete\x02Restore deleted posts on your site.\x02Media" +
+	"\x02Upload files to your site.\x02Read\x02Read your feeds and channels." +
+	"\x02Follow\x02Follow and unfollow feeds.\x02Mute\x02Mute and unmute user" +
+	"s.\x02Block\x02Block and unblock users.\x02Channels\x02Manage your chann" +
+	"els.\x02Grant access for\x02Default period\x021 day\x021 week\x02Forever" +
+	"\x02One-time password\x02Sensitive scopes\x02These scopes allow the appl" +
+	"ication to destroy your content or read your private data. Grant them on" +
+	"ly if you really trust this application.\x02Confirm sensitive scopes by " +
+	"the one-time password below or uncheck them.\x02Password"
+
+var ruIndex = []uint32{ // 73 elements
+	// Entry 0 - 1F
+	0x00000000, 0x0000001f, 0x0000004d, 0x000000a1,
+	0x000000d8, 0x00000343, 0x00000352, 0x000003e9,
+	0x000003fa, 0x0000040d, 0x00000451, 0x0000045e,
+	0x00000480, 0x0000048b, 0x00000496, 0x000004ab,
+	0x000004b8, 0x000004cb, 0x0000054b, 0x0000074e,
+	0x0000079d, 0x000007ec, 0x0000084f, 0x00000897,
+	0x000008f1, 0x00000a70, 0x00000b63, 0x00000c6b,
+	0x00000dd4, 0x00000eb3, 0x00000ec5, 0x00000f19,
+	// Entry 20 - 3F
+	0x00000f38, 0x00000fca, 0x00000fdf, 0x00000fee,
+	0x00001041, 0x00001052, 0x0000109e, 0x000010a5,
+	0x000010f9, 0x0000110a, 0x00001155, 0x00001168,
+	0x000011aa, 0x000011bd, 0x00001205, 0x00001216,
+	0x00001252, 0x0000126f, 0x000012ca, 0x000012df,
+	0x00001313, 0x00001320, 0x00001354, 0x00001365,
+	0x000013a5, 0x000013b4, 0x000013fe, 0x00001413,
+	0x00001462, 0x0000146f, 0x000014a3, 0x000014ce,
+	// Entry 40 - 5F
+	0x000014ee, 0x000014f9, 0x00001508, 0x0000151b,
+	0x0000153f, 0x00001571, 0x00001696, 0x0000173e,
+	0x0000174b,
+} // Size: 316 bytes
+
+const ruData string = "" + // Size: 5963 bytes
+	"\x02Авторизовать %[1]s\x02Авторизовать приложение\x02Клиент использует %" +
+	"[1]sPKCE%[2]s с методом %[3]s%[4]s%[5]s.\x02Клиент не использует %[1]sPK" +
+	"CE%[2]s!\x02%[1]sProof of Key Code Exchange%[2]s это механизм, защищающи" +
+	"й от злоумышленников, перехватывающих процесс аутентификации вашего при" +
+	"ложения. Вы можете авторизовать данное приложение и без этой защиты, но" +
+	" при этом вы должны самостоятельно проверить безопасность этого соединен" +
+	"ия. Если у вас есть сомнения - прервите процесс и свяжитесь с разработч" +
+	"иками.\x02Области\x02Никакие разрешения не запрашиваются: приложение по" +
+	"лучит только URL вашего профиля.\x02Отказать\x02Разрешить\x02Вы будете " +
+	"перенаправлены на %[1]s%[2]s%[3]s\x02Ошибка\x02Как исправить это?\x02Во" +
+	"йти\x02TicketAuth\x02Получатель\x02Ресурс\x02Отправить\x02Этот клиент я" +
+	"вляется приложением, установленным на вашем устройстве.\x02После автори" +
+	"зации вы будете перенаправлены по адресу ниже, который обрабатывается п" +
+	"риложением на вашем устройстве, а не веб-сайтом. Любое приложение на эт" +
+	"ом устройстве может заявить права на такой адрес, поэтому убедитесь, чт" +
+	"о вы установили это приложение из надёжного источника.\x02Этот клиент п" +
+	"еренаправляет на другой сайт.\x02Этот клиент ещё ни разу не был авториз" +
+	"ован.\x02Адрес клиента содержит похожие друг на друга символы.\x02Не уд" +
+	"алось загрузить страницу клиента.\x02Этот клиент использует небезопасно" +
+	"е соединение.\x02После авторизации вы будете перенаправлены по адресу н" +
+	"иже, который не принадлежит сайту клиента. Так фишинговые сайты часто в" +
+	"ыдают себя за известные приложения, поэтому убедитесь, что доверяете эт" +
+	"ому адресу.\x02Убедитесь, что вы сами открыли эту страницу из приложени" +
+	"я, в которое хотите войти, и что адрес приложения выше совпадает с ожид" +
+	"аемым.\x02Адрес клиента использует национальные символы, которые могут " +
+	"имитировать другой известный адрес. Внимательно проверьте адрес буква з" +
+	"а буквой.\x02Название, логотип и разрешённые адреса перенаправления это" +
+	"го клиента неизвестны, поэтому он может перенаправить только на свой со" +
+	"бственный адрес. Продолжайте, только если доверяете этому адресу.\x02Ад" +
+	"рес клиента или перенаправления использует обычный HTTP, поэтому код ав" +
+	"торизации может перехватить любой участник сети.\x02Войти как\x02Доступ" +
+	" будет ограничен следующими ресурсами:\x02Перенаправление\x02В вашем бра" +
+	"узере отключён JavaScript, поэтому нажмите кнопку ниже, чтобы продолжит" +
+	"ь.\x02Продолжить\x02Профиль\x02Просмотр вашего имени, фото и адреса про" +
+	"филя.\x02Эл. почта\x02Просмотр адреса вашей электронной почты.\x02OpenI" +
+	"D\x02Подтверждение вашей личности для приложения.\x02Создание\x02Публика" +
+	"ция новых записей на вашем сайте.\x02Черновики\x02Создание черновиков н" +
+	"а вашем сайте.\x02Изменение\x02Редактирование записей на вашем сайте." +
+	"\x02Удаление\x02Удаление записей на вашем сайте.\x02Восстановление\x02Во" +
+	"сстановление удалённых записей на вашем сайте.\x02Медиафайлы\x02Загрузк" +
+	"а файлов на ваш сайт.\x02Чтение\x02Чтение ваших лент и каналов.\x02Подп" +
+	"иски\x02Подписка на ленты и отписка от них.\x02Скрытие\x02Скрытие польз" +
+	"ователей и отмена скрытия.\x02Блокировка\x02Блокировка и разблокировка " +
+	"пользователей.\x02Каналы\x02Управление вашими каналами.\x02Предоставить" +
+	" доступ на\x02Стандартный срок\x021 день\x021 неделю\x02Бессрочно\x02Одн" +
+	"оразовый пароль\x02Чувствительные разрешения\x02Эти разрешения позволяю" +
+	"т приложению уничтожать ваш контент или читать ваши личные данные. Выда" +
+	"вайте их, только если действительно доверяете этому приложению.\x02Подт" +
+	"вердите чувствительные разрешения одноразовым паролем ниже или снимите " +
+	"с них отметку.\x02Пароль"
+
+	// Total table size 9598 bytes (9KiB); checksum: 9CCB9C9F
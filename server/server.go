@@ -0,0 +1,380 @@
+//go:generate go install golang.org/x/text/cmd/gotext@master
+//go:generate gotext -srclang=en update -out=catalog_gen.go -lang=en,ru -dir=../locales
+
+// Package server provides the IndieAuth server which can be embedded into any
+// binary as a http.Handler.
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/caarlos0/env/v9"
+
+	"source.toby3d.me/toby3d/auth/internal/account"
+	accountmemoryrepo "source.toby3d.me/toby3d/auth/internal/account/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/audit"
+	auditloggerrepo "source.toby3d.me/toby3d/auth/internal/audit/repository/logger"
+	"source.toby3d.me/toby3d/auth/internal/client"
+	clienthttprepo "source.toby3d.me/toby3d/auth/internal/client/repository/http"
+	clientmemoryrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	clienttenantrepo "source.toby3d.me/toby3d/auth/internal/client/repository/tenant"
+	"source.toby3d.me/toby3d/auth/internal/consent"
+	consentmemoryrepo "source.toby3d.me/toby3d/auth/internal/consent/repository/memory"
+	consenttenantrepo "source.toby3d.me/toby3d/auth/internal/consent/repository/tenant"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/fetcher"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
+	imageproxydiskrepo "source.toby3d.me/toby3d/auth/internal/imageproxy/repository/disk"
+	"source.toby3d.me/toby3d/auth/internal/profile"
+	profilehttprepo "source.toby3d.me/toby3d/auth/internal/profile/repository/http"
+	"source.toby3d.me/toby3d/auth/internal/random"
+	"source.toby3d.me/toby3d/auth/internal/replay"
+	replaymemoryrepo "source.toby3d.me/toby3d/auth/internal/replay/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/session"
+	sessionmemoryrepo "source.toby3d.me/toby3d/auth/internal/session/repository/memory"
+	sessiontenantrepo "source.toby3d.me/toby3d/auth/internal/session/repository/tenant"
+	tenanthttpdelivery "source.toby3d.me/toby3d/auth/internal/tenant/delivery/http"
+	tenantfilerepo "source.toby3d.me/toby3d/auth/internal/tenant/repository/file"
+	"source.toby3d.me/toby3d/auth/internal/token"
+	tokenmemoryrepo "source.toby3d.me/toby3d/auth/internal/token/repository/memory"
+	tokentenantrepo "source.toby3d.me/toby3d/auth/internal/token/repository/tenant"
+	"source.toby3d.me/toby3d/auth/internal/user"
+	userhttprepo "source.toby3d.me/toby3d/auth/internal/user/repository/http"
+	"source.toby3d.me/toby3d/auth/web"
+)
+
+type (
+	// Config describes the server and all of its modules.
+	Config = domain.Config
+
+	// Clock provides the current time to the server.
+	Clock = domain.Clock
+
+	// ClockFunc is an adapter to use ordinary function as a Clock.
+	ClockFunc = domain.ClockFunc
+
+	// Options describes dependencies of the server. Every nil dependency is
+	// replaced by the default one: in-memory storages, HTTP repositories
+	// and the logger into stdout.
+	Options struct {
+		// Accounts stores the owner account of the single tenant. Each
+		// tenant always has its own in-memory accounts.
+		Accounts account.Repository
+		Audit    audit.Repository
+		// Client fetches client, profile and user pages. It must not
+		// follow requests to private networks.
+		Client *http.Client
+		// ImageClient fetches third-party images, key sets and request
+		// objects. It must not follow requests to private networks.
+		ImageClient *http.Client
+		Clients     client.Repository
+		Consents    consent.Repository
+		Images      imageproxy.Repository
+		Keys        client.KeySetRepository
+		Registry    client.Repository
+		Requests    client.RequestObjectRepository
+		// Replays remembers identifiers of used JWTs until their expiry.
+		// Each tenant always has its own in-memory replays.
+		Replays replay.Repository
+		// Sessions is collected in background after Start call.
+		Sessions session.Repository
+		Tokens   token.Repository
+		Profiles profile.Repository
+		Users    user.Repository
+		Logger   *log.Logger
+		// Static contains assets of the web interface, served at the
+		// root of the server.
+		Static fs.FS
+		// Renderer writes pages of the web interface by built-in
+		// templates or custom ones.
+		Renderer web.Renderer
+		// Clock provides the current time to expire codes, sessions
+		// and tokens. System clock is used by default.
+		Clock  Clock
+		Logo   *url.URL
+		Config Config
+	}
+
+	// Server is the IndieAuth server of the single tenant described by
+	// configuration, or of the tenants described in the tenants file.
+	Server struct {
+		handler  http.Handler
+		sessions session.Repository
+		cancel   context.CancelFunc
+		done     chan struct{}
+		mutex    sync.Mutex
+	}
+)
+
+var ErrStepUpSecret error = domain.NewError(
+	domain.ErrorCodeServerError,
+	"step-up authentication requires TOTP secret of the owner",
+	"",
+)
+
+// NewConfig reads configuration from environment variables with provided
+// prefix, like "AUTH_". Missing variables are replaced by defaults.
+func NewConfig(prefix string) (*Config, error) {
+	config := new(Config)
+	if err := env.ParseWithOptions(config, env.Options{Prefix: prefix}); err != nil {
+		return nil, fmt.Errorf("cannot read configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// New creates a new server with provided options and owner account(s) of
+// configured tenant(s).
+//
+//nolint:funlen,cyclop
+func New(ctx context.Context, opts Options) (*Server, error) {
+	if err := opts.setDefaults(); err != nil {
+		return nil, err
+	}
+
+	out := &Server{
+		sessions: opts.Sessions,
+	}
+
+	if opts.Config.Tenants.Path == "" {
+		if opts.Accounts == nil {
+			opts.Accounts = accountmemoryrepo.NewMemoryAccountRepository()
+		}
+
+		app, err := newApp(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = app.createOwner(ctx, opts.Accounts); err != nil {
+			return nil, err
+		}
+
+		out.handler = app.handler()
+
+		return out, nil
+	}
+
+	tenants, err := tenantfilerepo.NewFileTenantRepository(opts.Config.Tenants.Path).Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read tenants: %w", err)
+	}
+
+	routes := make([]tenanthttpdelivery.Route, 0, len(tenants))
+
+	for i := range tenants {
+		tenantOpts := opts
+		tenantOpts.Config = tenants[i].Config(opts.Config)
+		tenantOpts.Logo = tenants[i].Logo
+		tenantOpts.Accounts = accountmemoryrepo.NewMemoryAccountRepository()
+		tenantOpts.Replays = replaymemoryrepo.NewMemoryReplayRepository()
+		tenantOpts.Sessions = sessiontenantrepo.NewTenantSessionRepository(opts.Sessions, tenants[i].ID)
+		tenantOpts.Tokens = tokentenantrepo.NewTenantTokenRepository(opts.Tokens, tenants[i].ID)
+		tenantOpts.Consents = consenttenantrepo.NewTenantConsentRepository(opts.Consents, tenants[i].ID)
+		tenantOpts.Clients = clienttenantrepo.NewTenantClientRepository(opts.Clients, tenants[i].ID)
+		tenantOpts.Registry = clienttenantrepo.NewTenantClientRepository(opts.Registry, tenants[i].ID)
+
+		// NOTE(toby3d): tenants never share signing keys, so tokens of
+		// one tenant cannot be verified by another. Without a persistent
+		// secret all tokens are invalidated after every restart.
+		if tenants[i].JWTSecret == "" {
+			if tenantOpts.Config.JWT.Secret, err = random.String(32); err != nil { //nolint:gomnd
+				return nil, fmt.Errorf("cannot generate %s tenant JWT secret: %w", tenants[i].ID, err)
+			}
+		}
+
+		// NOTE(toby3d): so ID Tokens of each tenant are signed by its
+		// own key generated on start.
+		tenantOpts.Config.OIDC.KeyFile = ""
+
+		app, err := newApp(tenantOpts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create %s tenant: %w", tenants[i].ID, err)
+		}
+
+		if err = app.createOwner(ctx, tenantOpts.Accounts); err != nil {
+			return nil, fmt.Errorf("cannot create %s tenant: %w", tenants[i].ID, err)
+		}
+
+		opts.Logger.Printf("hosting %s tenant at %s", tenants[i].ID, tenantOpts.Config.Server.GetRootURL())
+
+		routes = append(routes, tenanthttpdelivery.Route{
+			Handler: app.handler(),
+			Tenant:  tenants[i],
+		})
+	}
+
+	out.handler = tenanthttpdelivery.NewHandler(routes...)
+
+	return out, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Handler returns the root handler of the server.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Start runs collection of expired sessions in background until Close call.
+// Repeated calls do nothing.
+func (s *Server) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go func(done chan<- struct{}) {
+		defer close(done)
+
+		s.sessions.GC(ctx)
+	}(s.done)
+}
+
+// Close stops background jobs of the server and waits for them to finish. It
+// does not close provided repositories.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+
+	s.cancel, s.done = nil, nil
+
+	return nil
+}
+
+//nolint:cyclop,funlen
+func (opts *Options) setDefaults() error {
+	if _, err := domain.ParseSecurityProfile(opts.Config.Security.Profile); err != nil {
+		return fmt.Errorf("cannot parse security profile: %w", err)
+	}
+
+	// NOTE(toby3d): sensitive scopes cannot be granted at all without the
+	// second factor.
+	if len(opts.Config.StepUp.Scopes) > 0 && opts.Config.IndieAuth.TOTPSecret == "" {
+		return ErrStepUpSecret
+	}
+
+	// NOTE(toby3d): without a persistent secret all signed image URLs are
+	// invalidated after every restart.
+	if opts.Config.ImageProxy.Secret == "" {
+		var err error
+		if opts.Config.ImageProxy.Secret, err = random.String(32); err != nil { //nolint:gomnd
+			return fmt.Errorf("cannot generate image proxy secret: %w", err)
+		}
+	}
+
+	if opts.Logger == nil {
+		// NOTE(toby3d): write logs in stdout, see: https://12factor.net/logs
+		opts.Logger = log.New(os.Stdout, "IndieAuth\t", log.Lmsgprefix|log.LstdFlags|log.LUTC)
+	}
+
+	if opts.Audit == nil {
+		opts.Audit = auditloggerrepo.NewLoggerAuditRepository(opts.Logger)
+	}
+
+	if opts.Client == nil {
+		opts.Client = fetcher.New()
+	}
+
+	if opts.ImageClient == nil {
+		opts.ImageClient = fetcher.New()
+	}
+
+	if opts.Clients == nil {
+		opts.Clients = clienthttprepo.NewHTTPClientRepository(opts.Client)
+	}
+
+	if opts.Profiles == nil {
+		opts.Profiles = profilehttprepo.NewHTPPClientRepository(opts.Client)
+	}
+
+	if opts.Users == nil {
+		opts.Users = userhttprepo.NewHTTPUserRepository(opts.Client)
+	}
+
+	if opts.Keys == nil {
+		opts.Keys = clienthttprepo.NewHTTPKeySetRepository(opts.ImageClient)
+	}
+
+	if opts.Requests == nil {
+		opts.Requests = clienthttprepo.NewHTTPRequestObjectRepository(opts.ImageClient)
+	}
+
+	if opts.Replays == nil {
+		opts.Replays = replaymemoryrepo.NewMemoryReplayRepository()
+	}
+
+	if opts.Tokens == nil {
+		opts.Tokens = tokenmemoryrepo.NewMemoryTokenRepository()
+	}
+
+	if opts.Sessions == nil {
+		opts.Sessions = sessionmemoryrepo.NewMemorySessionRepository(opts.Config)
+	}
+
+	if opts.Registry == nil {
+		opts.Registry = clientmemoryrepo.NewMemoryClientRepository()
+	}
+
+	if opts.Consents == nil {
+		opts.Consents = consentmemoryrepo.NewMemoryConsentRepository()
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = domain.SystemClock
+	}
+
+	if opts.Renderer == nil {
+		opts.Renderer = web.DefaultRenderer
+	}
+
+	if opts.Static == nil {
+		static, err := fs.Sub(web.Static, "static")
+		if err != nil {
+			return fmt.Errorf("cannot read static assets: %w", err)
+		}
+
+		opts.Static = static
+	}
+
+	if opts.Images != nil {
+		return nil
+	}
+
+	path := opts.Config.ImageProxy.CachePath
+	if path == "" {
+		path = filepath.Join(os.TempDir(), "auth-images")
+	}
+
+	var err error
+	if opts.Images, err = imageproxydiskrepo.NewDiskImageProxyRepository(path,
+		opts.Config.ImageProxy.CacheExpiry); err != nil {
+		return fmt.Errorf("cannot create image proxy cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	clientmemoryrepo "source.toby3d.me/toby3d/auth/internal/client/repository/memory"
+	"source.toby3d.me/toby3d/auth/internal/common"
+	"source.toby3d.me/toby3d/auth/internal/domain"
+	usermemoryrepo "source.toby3d.me/toby3d/auth/internal/user/repository/memory"
+	"source.toby3d.me/toby3d/auth/server"
+	"source.toby3d.me/toby3d/auth/web"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.ImageProxy.CachePath = t.TempDir()
+
+	srv, err := server.New(context.Background(), server.Options{
+		Logger: log.New(io.Discard, "", 0),
+		Config: *config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Start()
+	srv.Start()
+
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		target string
+		expect int
+	}{
+		"health":   {target: "/health", expect: http.StatusOK},
+		"static":   {target: "/icon.svg", expect: http.StatusOK},
+		"metadata": {target: "/.well-known/oauth-authorization-server", expect: http.StatusOK},
+		"unknown":  {target: "/.well-known/unknown", expect: http.StatusNotFound},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com"+tc.target, nil))
+
+			if resp := w.Result(); resp.StatusCode != tc.expect {
+				t.Errorf("GET %s = %d, want %d", tc.target, resp.StatusCode, tc.expect)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"https://example.com/.well-known/oauth-authorization-server", nil))
+
+	var result struct {
+		Issuer string `json:"issuer"`
+	}
+
+	if err = json.NewDecoder(w.Result().Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := config.Server.GetRootURL(); result.Issuer != expect {
+		t.Errorf("issuer = %s, want %s", result.Issuer, expect)
+	}
+}
+
+func TestServer_Renderer(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.ImageProxy.CachePath = t.TempDir()
+
+	srv, err := server.New(context.Background(), server.Options{
+		Logger: log.New(io.Discard, "", 0),
+		Config: *config,
+		Renderer: web.RendererFunc(func(w io.Writer, page web.Page) {
+			_, _ = io.WriteString(w, "<!-- custom -->")
+
+			web.DefaultRenderer.Render(w, page)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+
+	body, _ := io.ReadAll(w.Result().Body)
+	if !strings.HasPrefix(string(body), "<!-- custom -->") || !strings.Contains(string(body), "</html>") {
+		t.Errorf("GET / = %s, want page written by custom renderer", body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.ImageProxy.CachePath = t.TempDir()
+	config.StepUp.Scopes = []string{domain.ScopeDelete.String()}
+	config.IndieAuth.TOTPSecret = ""
+
+	if _, err := server.New(context.Background(), server.Options{
+		Logger: log.New(io.Discard, "", 0),
+		Config: *config,
+	}); !errors.Is(err, server.ErrStepUpSecret) {
+		t.Errorf("New() = %v, want %v", err, server.ErrStepUpSecret)
+	}
+}
+
+func TestServer_Close(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.ImageProxy.CachePath = t.TempDir()
+
+	srv, err := server.New(context.Background(), server.Options{
+		Logger: log.New(io.Discard, "", 0),
+		Config: *config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE(toby3d): closing of the server without started background
+	// jobs or closing it twice does nothing.
+	for i := 0; i < 2; i++ {
+		if err = srv.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv.Start()
+
+	if err = srv.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServer_Tenant(t *testing.T) {
+	t.Parallel()
+
+	config := domain.TestConfig(t)
+	config.ImageProxy.CachePath = t.TempDir()
+	config.Tenants.Path = filepath.Join(t.TempDir(), "tenants.json")
+
+	if err := os.WriteFile(config.Tenants.Path, []byte(`[{"id": "alice", "path_prefix": "/alice/",
+		"username": "alice", "password": "secret", "identities": ["https://alice.example.net/"]}]`),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cid := domain.TestClientID(t, "https://localhost/")
+	redirectURI := &url.URL{Scheme: "https", Host: "localhost", Path: "/redirect"}
+	clients := clientmemoryrepo.NewMemoryClientRepository()
+
+	if err := clients.Create(context.Background(), domain.Client{
+		ID:          *cid,
+		Name:        "Example App",
+		RedirectURI: []*url.URL{redirectURI},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	root := domain.Tenant{PathPrefix: "alice"}.RootURL(config.Server)
+	owner := domain.TestUser(t)
+	owner.Me = domain.TestMe(t, "https://alice.example.net/")
+	owner.Issuer, _ = url.Parse(root)
+	users := usermemoryrepo.NewMemoryUserRepository()
+
+	if err := users.Create(context.Background(), *owner); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := server.New(context.Background(), server.Options{
+		Logger:  log.New(io.Discard, "", 0),
+		Config:  *config,
+		Clients: clients,
+		Users:   users,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	u, _ := url.Parse(root + "authorize")
+	u.RawQuery = url.Values{
+		"client_id":             []string{cid.String()},
+		"code_challenge":        []string{"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		"code_challenge_method": []string{domain.CodeChallengeMethodS256.String()},
+		"me":                    []string{"https://alice.example.net/"},
+		"redirect_uri":          []string{redirectURI.String()},
+		"response_type":         []string{domain.ResponseTypeCode.String()},
+		"scope":                 []string{"create"},
+		"state":                 []string{"1234567890"},
+	}.Encode()
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, u.String(), nil))
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %d, want %d: %s", u, resp.StatusCode, http.StatusOK, body)
+	}
+
+	form := url.Values{
+		"authorize":             []string{"allow"},
+		"client_id":             []string{cid.String()},
+		"code_challenge":        []string{"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		"code_challenge_method": []string{domain.CodeChallengeMethodS256.String()},
+		"me":                    []string{"https://alice.example.net/"},
+		"redirect_uri":          []string{redirectURI.String()},
+		"response_type":         []string{domain.ResponseTypeCode.String()},
+		"scope[]":               []string{"create"},
+		"state":                 []string{"1234567890"},
+	}
+
+	match := regexp.MustCompile(`name="consent_token"\s+value="([^"]+)"`).FindSubmatch(body)
+	if match == nil {
+		t.Fatalf("GET %s = %s, want consent_token field", u, body)
+	}
+
+	form.Set("consent_token", html.UnescapeString(string(match[1])))
+
+	req := httptest.NewRequest(http.MethodPost, root+"authorize/verify", strings.NewReader(form.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.SetBasicAuth("alice", "secret")
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	location, err := w.Result().Location()
+	if err != nil {
+		t.Fatalf("POST %s = %d, want redirect: %v", req.RequestURI, w.Result().StatusCode, err)
+	}
+
+	// NOTE(toby3d): cookies of the tenant are never sent to other tenants
+	// hosted on the same domain.
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Path != "/alice/authorize" {
+			t.Errorf("POST %s sets %s cookie at %s, want %s", req.RequestURI, cookie.Name, cookie.Path,
+				"/alice/authorize")
+		}
+	}
+
+	if location.Query().Get("iss") != root {
+		t.Errorf("POST %s redirects with issuer %s, want %s", req.RequestURI, location.Query().Get("iss"),
+			root)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, root+"token", strings.NewReader(url.Values{
+		"client_id":     []string{cid.String()},
+		"code":          []string{location.Query().Get("code")},
+		"code_verifier": []string{verifier},
+		"grant_type":    []string{domain.GrantTypeAuthorizationCode.String()},
+		"redirect_uri":  []string{redirectURI.String()},
+	}.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+	req.Header.Set(common.HeaderAccept, common.MIMEApplicationJSON)
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		Me          string `json:"me"`
+	}
+
+	if err = json.NewDecoder(w.Result().Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.AccessToken == "" || result.Me != "https://alice.example.net/" {
+		t.Errorf("POST %s = %d %+v, want access token of %s", req.RequestURI, w.Result().StatusCode, result,
+			"https://alice.example.net/")
+	}
+}
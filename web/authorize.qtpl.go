@@ -25,86 +25,451 @@ var (
 //line web/authorize.qtpl:5
 type AuthorizePage struct {
 	BaseOf
-	Scope               domain.Scopes
+	Scope               []domain.ScopeDefinition
+	Sensitive           []domain.ScopeDefinition
 	CodeChallengeMethod domain.CodeChallengeMethod
 	ResponseType        domain.ResponseType
+	ResponseMode        domain.ResponseMode
 	Client              *domain.Client
 	Me                  *domain.Me
 	RedirectURI         *domain.URL
 	Providers           []*domain.Provider
+	Identities          []*domain.Me
+	Warnings            []domain.ConsentWarning
+	Resource            []string
 	CSRF                []byte
+	ConsentToken        string
 	CodeChallenge       string
 	State               string
+	Nonce               string
+	SecondFactor        bool
+	StepUp              bool
+	Reauthenticate      bool
 }
 
-//line web/authorize.qtpl:19
+//line web/authorize.qtpl:29
 func (p *AuthorizePage) streamtitle(qw422016 *qt422016.Writer) {
-//line web/authorize.qtpl:19
+//line web/authorize.qtpl:29
 	qw422016.N().S(`
 `)
-//line web/authorize.qtpl:20
+//line web/authorize.qtpl:30
 	if p.Client.Name != "" {
-//line web/authorize.qtpl:20
+//line web/authorize.qtpl:30
 		qw422016.N().S(`
 `)
-//line web/authorize.qtpl:21
+//line web/authorize.qtpl:31
 		p.streamt(qw422016, "Authorize %s", p.Client.Name)
-//line web/authorize.qtpl:21
+//line web/authorize.qtpl:31
 		qw422016.N().S(`
 `)
-//line web/authorize.qtpl:22
+//line web/authorize.qtpl:32
 	} else {
-//line web/authorize.qtpl:22
+//line web/authorize.qtpl:32
 		qw422016.N().S(`
 `)
-//line web/authorize.qtpl:23
+//line web/authorize.qtpl:33
 		p.streamt(qw422016, "Authorize application")
-//line web/authorize.qtpl:23
+//line web/authorize.qtpl:33
 		qw422016.N().S(`
 `)
-//line web/authorize.qtpl:24
+//line web/authorize.qtpl:34
 	}
-//line web/authorize.qtpl:24
+//line web/authorize.qtpl:34
 	qw422016.N().S(`
 `)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 }
 
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 func (p *AuthorizePage) writetitle(qq422016 qtio422016.Writer) {
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 	p.streamtitle(qw422016)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 	qt422016.ReleaseWriter(qw422016)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 }
 
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 func (p *AuthorizePage) title() string {
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
 	p.writetitle(qb422016)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:35
+	qs422016 := string(qb422016.B)
+//line web/authorize.qtpl:35
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/authorize.qtpl:35
+	return qs422016
+//line web/authorize.qtpl:35
+}
+
+//line web/authorize.qtpl:37
+func (p *AuthorizePage) streamwarningSummary(qw422016 *qt422016.Writer, warning domain.ConsentWarning) {
+//line web/authorize.qtpl:37
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:38
+	switch warning {
+//line web/authorize.qtpl:39
+	case domain.ConsentWarningRedirectMismatch:
+//line web/authorize.qtpl:39
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:40
+		p.streamt(qw422016, `This client redirects to another site.`)
+//line web/authorize.qtpl:40
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:41
+	case domain.ConsentWarningNewClient:
+//line web/authorize.qtpl:41
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:42
+		p.streamt(qw422016, `This client has never been authorized before.`)
+//line web/authorize.qtpl:42
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:43
+	case domain.ConsentWarningHomoglyph:
+//line web/authorize.qtpl:43
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:44
+		p.streamt(qw422016, `The client address contains look-alike characters.`)
+//line web/authorize.qtpl:44
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:45
+	case domain.ConsentWarningUnreachable:
+//line web/authorize.qtpl:45
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:46
+		p.streamt(qw422016, `Could not load the client page.`)
+//line web/authorize.qtpl:46
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:47
+	case domain.ConsentWarningInsecure:
+//line web/authorize.qtpl:47
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:48
+		p.streamt(qw422016, `This client uses an insecure connection.`)
+//line web/authorize.qtpl:48
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:49
+	case domain.ConsentWarningNativeApp:
+//line web/authorize.qtpl:49
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:50
+		p.streamt(qw422016, `This client is an application installed on your device.`)
+//line web/authorize.qtpl:50
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:51
+	}
+//line web/authorize.qtpl:51
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:52
+}
+
+//line web/authorize.qtpl:52
+func (p *AuthorizePage) writewarningSummary(qq422016 qtio422016.Writer, warning domain.ConsentWarning) {
+//line web/authorize.qtpl:52
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/authorize.qtpl:52
+	p.streamwarningSummary(qw422016, warning)
+//line web/authorize.qtpl:52
+	qt422016.ReleaseWriter(qw422016)
+//line web/authorize.qtpl:52
+}
+
+//line web/authorize.qtpl:52
+func (p *AuthorizePage) warningSummary(warning domain.ConsentWarning) string {
+//line web/authorize.qtpl:52
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/authorize.qtpl:52
+	p.writewarningSummary(qb422016, warning)
+//line web/authorize.qtpl:52
 	qs422016 := string(qb422016.B)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:52
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:52
 	return qs422016
-//line web/authorize.qtpl:25
+//line web/authorize.qtpl:52
+}
+
+//line web/authorize.qtpl:54
+func (p *AuthorizePage) streamwarningDescription(qw422016 *qt422016.Writer, warning domain.ConsentWarning) {
+//line web/authorize.qtpl:54
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:55
+	switch warning {
+//line web/authorize.qtpl:56
+	case domain.ConsentWarningRedirectMismatch:
+//line web/authorize.qtpl:56
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:57
+		p.streamt(qw422016, `After authorization you will be redirected to the address below, which does not belong to the client's `+
+			`own site. Phishing sites often pretend to be well-known applications this way, so make sure you trust this `+
+			`address.`)
+//line web/authorize.qtpl:59
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:60
+	case domain.ConsentWarningNewClient:
+//line web/authorize.qtpl:60
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:61
+		p.streamt(qw422016, `Make sure you have opened this page yourself from the application you want to sign in to, and the `+
+			`application address above is the one you expect.`)
+//line web/authorize.qtpl:62
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:63
+	case domain.ConsentWarningHomoglyph:
+//line web/authorize.qtpl:63
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:64
+		p.streamt(qw422016, `The client address uses internationalized characters which may imitate another well-known address. `+
+			`Check the address carefully letter by letter.`)
+//line web/authorize.qtpl:65
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:66
+	case domain.ConsentWarningUnreachable:
+//line web/authorize.qtpl:66
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:67
+		p.streamt(qw422016, `The name, logo and allowed redirect addresses of this client are unknown, so it can only redirect back `+
+			`to its own address. Continue only if you trust this address.`)
+//line web/authorize.qtpl:68
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:69
+	case domain.ConsentWarningInsecure:
+//line web/authorize.qtpl:69
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:70
+		p.streamt(qw422016, `The client or redirect address uses plain HTTP, so the authorization code can be intercepted by anyone `+
+			`on the network.`)
+//line web/authorize.qtpl:71
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:72
+	case domain.ConsentWarningNativeApp:
+//line web/authorize.qtpl:72
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:73
+		p.streamt(qw422016, `After authorization you will be redirected to the address below, which is handled by an application `+
+			`on your device rather than a website. Any application on this device can claim such an address, so make `+
+			`sure you have installed this application from a trusted source.`)
+//line web/authorize.qtpl:75
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:76
+	}
+//line web/authorize.qtpl:76
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:77
 }
 
-//line web/authorize.qtpl:27
+//line web/authorize.qtpl:77
+func (p *AuthorizePage) writewarningDescription(qq422016 qtio422016.Writer, warning domain.ConsentWarning) {
+//line web/authorize.qtpl:77
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/authorize.qtpl:77
+	p.streamwarningDescription(qw422016, warning)
+//line web/authorize.qtpl:77
+	qt422016.ReleaseWriter(qw422016)
+//line web/authorize.qtpl:77
+}
+
+//line web/authorize.qtpl:77
+func (p *AuthorizePage) warningDescription(warning domain.ConsentWarning) string {
+//line web/authorize.qtpl:77
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/authorize.qtpl:77
+	p.writewarningDescription(qb422016, warning)
+//line web/authorize.qtpl:77
+	qs422016 := string(qb422016.B)
+//line web/authorize.qtpl:77
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/authorize.qtpl:77
+	return qs422016
+//line web/authorize.qtpl:77
+}
+
+//line web/authorize.qtpl:79
+func (p *AuthorizePage) streamscopeText(qw422016 *qt422016.Writer, text domain.ScopeText) {
+//line web/authorize.qtpl:79
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:80
+	localized, ok := text.Get(p.Language)
+
+//line web/authorize.qtpl:80
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:81
+	if ok {
+//line web/authorize.qtpl:81
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:82
+		qw422016.E().S(localized)
+//line web/authorize.qtpl:82
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:83
+	} else {
+//line web/authorize.qtpl:83
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:84
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:85
+		p.streamt(qw422016, localized)
+//line web/authorize.qtpl:85
+		qw422016.N().S(`
+`)
+//line web/authorize.qtpl:86
+	}
+//line web/authorize.qtpl:86
+	qw422016.N().S(`
+`)
+//line web/authorize.qtpl:87
+}
+
+//line web/authorize.qtpl:87
+func (p *AuthorizePage) writescopeText(qq422016 qtio422016.Writer, text domain.ScopeText) {
+//line web/authorize.qtpl:87
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/authorize.qtpl:87
+	p.streamscopeText(qw422016, text)
+//line web/authorize.qtpl:87
+	qt422016.ReleaseWriter(qw422016)
+//line web/authorize.qtpl:87
+}
+
+//line web/authorize.qtpl:87
+func (p *AuthorizePage) scopeText(text domain.ScopeText) string {
+//line web/authorize.qtpl:87
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/authorize.qtpl:87
+	p.writescopeText(qb422016, text)
+//line web/authorize.qtpl:87
+	qs422016 := string(qb422016.B)
+//line web/authorize.qtpl:87
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/authorize.qtpl:87
+	return qs422016
+//line web/authorize.qtpl:87
+}
+
+//line web/authorize.qtpl:89
+func (p *AuthorizePage) streamscope(qw422016 *qt422016.Writer, scope domain.ScopeDefinition) {
+//line web/authorize.qtpl:89
+	qw422016.N().S(`
+<div class="scope scope_sensitivity_`)
+//line web/authorize.qtpl:90
+	qw422016.E().S(scope.Sensitivity.String())
+//line web/authorize.qtpl:90
+	qw422016.N().S(`">
+  <label>
+    <input type="checkbox"
+           name="scope[]"
+           value="`)
+//line web/authorize.qtpl:94
+	qw422016.E().S(scope.Scope.String())
+//line web/authorize.qtpl:94
+	qw422016.N().S(`"
+           checked>
+
+    `)
+//line web/authorize.qtpl:97
+	p.streamscopeText(qw422016, scope.Title)
+//line web/authorize.qtpl:97
+	qw422016.N().S(`
+    <code>`)
+//line web/authorize.qtpl:98
+	qw422016.E().S(scope.Scope.String())
+//line web/authorize.qtpl:98
+	qw422016.N().S(`</code>
+  </label>
+
+  `)
+//line web/authorize.qtpl:101
+	if len(scope.Description) > 0 {
+//line web/authorize.qtpl:101
+		qw422016.N().S(`
+  <p>`)
+//line web/authorize.qtpl:102
+		p.streamscopeText(qw422016, scope.Description)
+//line web/authorize.qtpl:102
+		qw422016.N().S(`</p>
+  `)
+//line web/authorize.qtpl:103
+	}
+//line web/authorize.qtpl:103
+	qw422016.N().S(`
+</div>
+`)
+//line web/authorize.qtpl:105
+}
+
+//line web/authorize.qtpl:105
+func (p *AuthorizePage) writescope(qq422016 qtio422016.Writer, scope domain.ScopeDefinition) {
+//line web/authorize.qtpl:105
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/authorize.qtpl:105
+	p.streamscope(qw422016, scope)
+//line web/authorize.qtpl:105
+	qt422016.ReleaseWriter(qw422016)
+//line web/authorize.qtpl:105
+}
+
+//line web/authorize.qtpl:105
+func (p *AuthorizePage) scope(scope domain.ScopeDefinition) string {
+//line web/authorize.qtpl:105
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/authorize.qtpl:105
+	p.writescope(qb422016, scope)
+//line web/authorize.qtpl:105
+	qs422016 := string(qb422016.B)
+//line web/authorize.qtpl:105
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/authorize.qtpl:105
+	return qs422016
+//line web/authorize.qtpl:105
+}
+
+//line web/authorize.qtpl:107
 func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
-//line web/authorize.qtpl:27
+//line web/authorize.qtpl:107
 	qw422016.N().S(`
 <header>
   `)
-//line web/authorize.qtpl:29
+//line web/authorize.qtpl:109
 	if p.Client.Logo != nil {
-//line web/authorize.qtpl:29
+//line web/authorize.qtpl:109
 		qw422016.N().S(`
   <img class=""
        crossorigin="anonymous"
@@ -114,73 +479,73 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
        loading="lazy"
        referrerpolicy="no-referrer-when-downgrade"
        src="`)
-//line web/authorize.qtpl:37
-		qw422016.E().S(p.Client.Logo.String())
-//line web/authorize.qtpl:37
+//line web/authorize.qtpl:117
+		p.streamimg(qw422016, p.Client.Logo, 140, 140)
+//line web/authorize.qtpl:117
 		qw422016.N().S(`"
        alt="`)
-//line web/authorize.qtpl:38
+//line web/authorize.qtpl:118
 		qw422016.E().S(p.Client.Name)
-//line web/authorize.qtpl:38
+//line web/authorize.qtpl:118
 		qw422016.N().S(`"
        width="140">
   `)
-//line web/authorize.qtpl:40
+//line web/authorize.qtpl:120
 	}
-//line web/authorize.qtpl:40
+//line web/authorize.qtpl:120
 	qw422016.N().S(`
 
   <h2>
     `)
-//line web/authorize.qtpl:43
+//line web/authorize.qtpl:123
 	if p.Client.URL != nil {
-//line web/authorize.qtpl:43
+//line web/authorize.qtpl:123
 		qw422016.N().S(`
     <a href="`)
-//line web/authorize.qtpl:44
+//line web/authorize.qtpl:124
 		qw422016.E().S(p.Client.URL.String())
-//line web/authorize.qtpl:44
+//line web/authorize.qtpl:124
 		qw422016.N().S(`">
       `)
-//line web/authorize.qtpl:45
+//line web/authorize.qtpl:125
 	}
-//line web/authorize.qtpl:45
+//line web/authorize.qtpl:125
 	qw422016.N().S(`
       `)
-//line web/authorize.qtpl:46
+//line web/authorize.qtpl:126
 	if p.Client.Name != "" {
-//line web/authorize.qtpl:46
+//line web/authorize.qtpl:126
 		qw422016.N().S(`
       `)
-//line web/authorize.qtpl:47
+//line web/authorize.qtpl:127
 		qw422016.E().S(p.Client.Name)
-//line web/authorize.qtpl:47
+//line web/authorize.qtpl:127
 		qw422016.N().S(`
       `)
-//line web/authorize.qtpl:48
+//line web/authorize.qtpl:128
 	} else {
-//line web/authorize.qtpl:48
+//line web/authorize.qtpl:128
 		qw422016.N().S(`
       `)
-//line web/authorize.qtpl:49
+//line web/authorize.qtpl:129
 		qw422016.E().S(p.Client.ID.String())
-//line web/authorize.qtpl:49
+//line web/authorize.qtpl:129
 		qw422016.N().S(`
       `)
-//line web/authorize.qtpl:50
+//line web/authorize.qtpl:130
 	}
-//line web/authorize.qtpl:50
+//line web/authorize.qtpl:130
 	qw422016.N().S(`
       `)
-//line web/authorize.qtpl:51
+//line web/authorize.qtpl:131
 	if p.Client.URL != nil {
-//line web/authorize.qtpl:51
+//line web/authorize.qtpl:131
 		qw422016.N().S(`
     </a>
     `)
-//line web/authorize.qtpl:53
+//line web/authorize.qtpl:133
 	}
-//line web/authorize.qtpl:53
+//line web/authorize.qtpl:133
 	qw422016.N().S(`
   </h2>
 </header>
@@ -188,9 +553,9 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
 <main>
   <aside>
     `)
-//line web/authorize.qtpl:59
+//line web/authorize.qtpl:139
 	if p.CodeChallengeMethod != domain.CodeChallengeMethodUnd && p.CodeChallenge != "" {
-//line web/authorize.qtpl:59
+//line web/authorize.qtpl:139
 		qw422016.N().S(`
     <p class="with-icon">
       <span class="icon"
@@ -198,16 +563,16 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
             aria-label="closed lock with key">🔐</span>
 
       `)
-//line web/authorize.qtpl:65
+//line web/authorize.qtpl:145
 		p.streamt(qw422016, `This client uses %sPKCE%s with the %s%s%s method.`, `<abbr title="Proof of Key Code Exchange">`,
 			`</abbr>`, `<code>`, p.CodeChallengeMethod, `</code>`)
-//line web/authorize.qtpl:66
+//line web/authorize.qtpl:146
 		qw422016.N().S(`
     </p>
     `)
-//line web/authorize.qtpl:68
+//line web/authorize.qtpl:148
 	} else {
-//line web/authorize.qtpl:68
+//line web/authorize.qtpl:148
 		qw422016.N().S(`
     <details>
       <summary class="with-icon">
@@ -216,32 +581,82 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
               aria-label="unlock">🔓</span>
 
         `)
-//line web/authorize.qtpl:75
+//line web/authorize.qtpl:155
 		p.streamt(qw422016, `This client does not use %sPKCE%s!`, `<abbr title="Proof of Key Code Exchange">`, `</abbr>`)
-//line web/authorize.qtpl:75
+//line web/authorize.qtpl:155
 		qw422016.N().S(`
       </summary>
       <p>
         `)
-//line web/authorize.qtpl:78
+//line web/authorize.qtpl:158
 		p.streamt(qw422016, `%sProof of Key Code Exchange%s is a mechanism that protects against attackers in the middle hijacking `+
 			`your application's authentication process. You can still authorize this application without this protection, `+
 			`but you must independently verify the security of this connection. If you have any doubts - stop the process `+
 			` and contact the developers.`, `<dfn id="PKCE">`, `</dfn>`)
-//line web/authorize.qtpl:81
+//line web/authorize.qtpl:161
 		qw422016.N().S(`
       </p>
     </details>
     `)
-//line web/authorize.qtpl:84
+//line web/authorize.qtpl:164
 	}
-//line web/authorize.qtpl:84
+//line web/authorize.qtpl:164
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:166
+	for _, warning := range p.Warnings {
+//line web/authorize.qtpl:166
+		qw422016.N().S(`
+    <details>
+      <summary class="with-icon">
+        <span class="icon"
+              role="img"
+              aria-label="warning">⚠️</span>
+
+        `)
+//line web/authorize.qtpl:173
+		p.streamwarningSummary(qw422016, warning)
+//line web/authorize.qtpl:173
+		qw422016.N().S(`
+      </summary>
+      <p>
+        `)
+//line web/authorize.qtpl:176
+		p.streamwarningDescription(qw422016, warning)
+//line web/authorize.qtpl:176
+		qw422016.N().S(`
+      </p>
+      `)
+//line web/authorize.qtpl:178
+		if warning == domain.ConsentWarningNativeApp || warning == domain.ConsentWarningRedirectMismatch {
+//line web/authorize.qtpl:178
+			qw422016.N().S(`
+      <p><code>`)
+//line web/authorize.qtpl:179
+			qw422016.E().S(p.RedirectURI.String())
+//line web/authorize.qtpl:179
+			qw422016.N().S(`</code></p>
+      `)
+//line web/authorize.qtpl:180
+		}
+//line web/authorize.qtpl:180
+		qw422016.N().S(`
+    </details>
+    `)
+//line web/authorize.qtpl:182
+	}
+//line web/authorize.qtpl:182
 	qw422016.N().S(`
   </aside>
 
   <form class=""
         accept-charset="utf-8"
-        action="/authorize/verify"
+        action="`)
+//line web/authorize.qtpl:187
+	p.streamurl(qw422016, "/authorize/verify")
+//line web/authorize.qtpl:187
+	qw422016.N().S(`"
         autocomplete="off"
         enctype="application/x-www-form-urlencoded"
         method="post"
@@ -249,215 +664,516 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
         target="_self">
 
     `)
-//line web/authorize.qtpl:96
+//line web/authorize.qtpl:194
 	if p.CSRF != nil {
-//line web/authorize.qtpl:96
+//line web/authorize.qtpl:194
 		qw422016.N().S(`
     <input type="hidden"
            name="_csrf"
            value="`)
-//line web/authorize.qtpl:99
+//line web/authorize.qtpl:197
 		qw422016.E().Z(p.CSRF)
-//line web/authorize.qtpl:99
+//line web/authorize.qtpl:197
 		qw422016.N().S(`">
     `)
-//line web/authorize.qtpl:100
+//line web/authorize.qtpl:198
 	}
-//line web/authorize.qtpl:100
+//line web/authorize.qtpl:198
 	qw422016.N().S(`
 
+    <input type="hidden"
+           name="consent_token"
+           value="`)
+//line web/authorize.qtpl:202
+	qw422016.E().S(p.ConsentToken)
+//line web/authorize.qtpl:202
+	qw422016.N().S(`">
+
     `)
-//line web/authorize.qtpl:102
+//line web/authorize.qtpl:204
 	for key, val := range map[string]string{
 		"client_id":     p.Client.ID.String(),
 		"redirect_uri":  p.RedirectURI.String(),
 		"response_type": p.ResponseType.String(),
 		"state":         p.State,
 	} {
-//line web/authorize.qtpl:107
+//line web/authorize.qtpl:209
 		qw422016.N().S(`
     <input type="hidden"
            name="`)
-//line web/authorize.qtpl:109
+//line web/authorize.qtpl:211
 		qw422016.E().S(key)
-//line web/authorize.qtpl:109
+//line web/authorize.qtpl:211
 		qw422016.N().S(`"
            value="`)
-//line web/authorize.qtpl:110
+//line web/authorize.qtpl:212
 		qw422016.E().S(val)
-//line web/authorize.qtpl:110
+//line web/authorize.qtpl:212
+		qw422016.N().S(`">
+    `)
+//line web/authorize.qtpl:213
+	}
+//line web/authorize.qtpl:213
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:215
+	if p.ResponseMode != domain.ResponseModeUnd {
+//line web/authorize.qtpl:215
+		qw422016.N().S(`
+    <input type="hidden"
+           name="response_mode"
+           value="`)
+//line web/authorize.qtpl:218
+		qw422016.E().S(p.ResponseMode.String())
+//line web/authorize.qtpl:218
 		qw422016.N().S(`">
     `)
-//line web/authorize.qtpl:111
+//line web/authorize.qtpl:219
 	}
-//line web/authorize.qtpl:111
+//line web/authorize.qtpl:219
 	qw422016.N().S(`
 
     `)
-//line web/authorize.qtpl:113
-	if len(p.Scope) > 0 {
-//line web/authorize.qtpl:113
+//line web/authorize.qtpl:221
+	if p.Nonce != "" {
+//line web/authorize.qtpl:221
 		qw422016.N().S(`
+    <input type="hidden"
+           name="nonce"
+           value="`)
+//line web/authorize.qtpl:224
+		qw422016.E().S(p.Nonce)
+//line web/authorize.qtpl:224
+		qw422016.N().S(`">
+    `)
+//line web/authorize.qtpl:225
+	}
+//line web/authorize.qtpl:225
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:227
+	if len(p.Scope) > 0 || len(p.Sensitive) > 0 {
+//line web/authorize.qtpl:227
+		qw422016.N().S(`
+    `)
+//line web/authorize.qtpl:228
+		if len(p.Scope) > 0 {
+//line web/authorize.qtpl:228
+			qw422016.N().S(`
     <fieldset>
       <legend>`)
-//line web/authorize.qtpl:115
-		p.streamt(qw422016, "Scopes")
-//line web/authorize.qtpl:115
-		qw422016.N().S(`</legend>
+//line web/authorize.qtpl:230
+			p.streamt(qw422016, "Scopes")
+//line web/authorize.qtpl:230
+			qw422016.N().S(`</legend>
 
       `)
-//line web/authorize.qtpl:117
-		for _, scope := range p.Scope {
-//line web/authorize.qtpl:117
+//line web/authorize.qtpl:232
+			for _, scope := range p.Scope {
+//line web/authorize.qtpl:232
+				qw422016.N().S(`
+      `)
+//line web/authorize.qtpl:233
+				p.streamscope(qw422016, scope)
+//line web/authorize.qtpl:233
+				qw422016.N().S(`
+      `)
+//line web/authorize.qtpl:234
+			}
+//line web/authorize.qtpl:234
 			qw422016.N().S(`
-      <div>
-        <label>
-          <input type="checkbox"
-                 name="scope[]"
-                 value="`)
-//line web/authorize.qtpl:122
-			qw422016.E().S(scope.String())
-//line web/authorize.qtpl:122
-			qw422016.N().S(`"
-                 checked>
+    </fieldset>
+    `)
+//line web/authorize.qtpl:236
+		}
+//line web/authorize.qtpl:236
+		qw422016.N().S(`
 
-          `)
-//line web/authorize.qtpl:125
-			qw422016.E().S(scope.String())
-//line web/authorize.qtpl:125
+    `)
+//line web/authorize.qtpl:238
+		if len(p.Sensitive) > 0 {
+//line web/authorize.qtpl:238
 			qw422016.N().S(`
-        </label>
-      </div>
+    <fieldset class="scopes scopes_sensitive">
+      <legend>`)
+//line web/authorize.qtpl:240
+			p.streamt(qw422016, "Sensitive scopes")
+//line web/authorize.qtpl:240
+			qw422016.N().S(`</legend>
+
+      <p>`)
+//line web/authorize.qtpl:242
+			p.streamt(qw422016, `These scopes allow the application to destroy your content or read your private data. `+
+				`Grant them only if you really trust this application.`)
+//line web/authorize.qtpl:243
+			qw422016.N().S(`</p>
+
       `)
-//line web/authorize.qtpl:128
+//line web/authorize.qtpl:245
+			for _, scope := range p.Sensitive {
+//line web/authorize.qtpl:245
+				qw422016.N().S(`
+      `)
+//line web/authorize.qtpl:246
+				p.streamscope(qw422016, scope)
+//line web/authorize.qtpl:246
+				qw422016.N().S(`
+      `)
+//line web/authorize.qtpl:247
+			}
+//line web/authorize.qtpl:247
+			qw422016.N().S(`
+
+      `)
+//line web/authorize.qtpl:249
+			if p.StepUp {
+//line web/authorize.qtpl:249
+				qw422016.N().S(`
+      <p>`)
+//line web/authorize.qtpl:250
+				p.streamt(qw422016, `Confirm sensitive scopes by the one-time password below or uncheck them.`)
+//line web/authorize.qtpl:250
+				qw422016.N().S(`</p>
+      `)
+//line web/authorize.qtpl:251
+			}
+//line web/authorize.qtpl:251
+			qw422016.N().S(`
+    </fieldset>
+    `)
+//line web/authorize.qtpl:253
 		}
-//line web/authorize.qtpl:128
+//line web/authorize.qtpl:253
 		qw422016.N().S(`
-    </fieldset>
     `)
-//line web/authorize.qtpl:130
+//line web/authorize.qtpl:254
 	} else {
-//line web/authorize.qtpl:130
+//line web/authorize.qtpl:254
 		qw422016.N().S(`
     <aside>
       <p>`)
-//line web/authorize.qtpl:132
+//line web/authorize.qtpl:256
 		p.streamt(qw422016, `No scopes is requested: the application will only get your profile URL.`)
-//line web/authorize.qtpl:132
+//line web/authorize.qtpl:256
+		qw422016.N().S(`</p>
+    </aside>
+    `)
+//line web/authorize.qtpl:258
+	}
+//line web/authorize.qtpl:258
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:260
+	if len(p.Scope) > 0 || len(p.Sensitive) > 0 {
+//line web/authorize.qtpl:260
+		qw422016.N().S(`
+    <label>
+      `)
+//line web/authorize.qtpl:262
+		p.streamt(qw422016, "Grant access for")
+//line web/authorize.qtpl:262
+		qw422016.N().S(`
+
+      <select name="grant_expiry">
+        `)
+//line web/authorize.qtpl:265
+		for _, expiry := range []struct{ value, title string }{
+			{"", "Default period"},
+			{domain.GrantExpiryDay.String(), "1 day"},
+			{domain.GrantExpiryWeek.String(), "1 week"},
+			{domain.GrantExpiryForever.String(), "Forever"},
+		} {
+//line web/authorize.qtpl:270
+			qw422016.N().S(`
+        <option value="`)
+//line web/authorize.qtpl:271
+			qw422016.E().S(expiry.value)
+//line web/authorize.qtpl:271
+			qw422016.N().S(`">`)
+//line web/authorize.qtpl:271
+			p.streamt(qw422016, expiry.title)
+//line web/authorize.qtpl:271
+			qw422016.N().S(`</option>
+        `)
+//line web/authorize.qtpl:272
+		}
+//line web/authorize.qtpl:272
+		qw422016.N().S(`
+      </select>
+    </label>
+    `)
+//line web/authorize.qtpl:275
+	}
+//line web/authorize.qtpl:275
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:277
+	if len(p.Resource) > 0 {
+//line web/authorize.qtpl:277
+		qw422016.N().S(`
+    <aside>
+      <p>`)
+//line web/authorize.qtpl:279
+		p.streamt(qw422016, `The access will be limited to the following resources:`)
+//line web/authorize.qtpl:279
 		qw422016.N().S(`</p>
+      <ul>
+        `)
+//line web/authorize.qtpl:281
+		for _, resource := range p.Resource {
+//line web/authorize.qtpl:281
+			qw422016.N().S(`
+        <li>
+          <code>`)
+//line web/authorize.qtpl:283
+			qw422016.E().S(resource)
+//line web/authorize.qtpl:283
+			qw422016.N().S(`</code>
+          <input type="hidden"
+                 name="resource"
+                 value="`)
+//line web/authorize.qtpl:286
+			qw422016.E().S(resource)
+//line web/authorize.qtpl:286
+			qw422016.N().S(`">
+        </li>
+        `)
+//line web/authorize.qtpl:288
+		}
+//line web/authorize.qtpl:288
+		qw422016.N().S(`
+      </ul>
     </aside>
     `)
-//line web/authorize.qtpl:134
+//line web/authorize.qtpl:291
 	}
-//line web/authorize.qtpl:134
+//line web/authorize.qtpl:291
 	qw422016.N().S(`
 
     `)
-//line web/authorize.qtpl:136
+//line web/authorize.qtpl:293
 	if p.CodeChallenge != "" {
-//line web/authorize.qtpl:136
+//line web/authorize.qtpl:293
 		qw422016.N().S(`
     `)
-//line web/authorize.qtpl:137
+//line web/authorize.qtpl:294
 		for key, val := range map[string]string{
 			"code_challenge":        p.CodeChallenge,
 			"code_challenge_method": p.CodeChallengeMethod.String(),
 		} {
-//line web/authorize.qtpl:140
+//line web/authorize.qtpl:297
 			qw422016.N().S(`
     <input type="hidden"
            name="`)
-//line web/authorize.qtpl:142
+//line web/authorize.qtpl:299
 			qw422016.E().S(key)
-//line web/authorize.qtpl:142
+//line web/authorize.qtpl:299
 			qw422016.N().S(`"
            value="`)
-//line web/authorize.qtpl:143
+//line web/authorize.qtpl:300
 			qw422016.E().S(val)
-//line web/authorize.qtpl:143
+//line web/authorize.qtpl:300
 			qw422016.N().S(`">
     `)
-//line web/authorize.qtpl:144
+//line web/authorize.qtpl:301
 		}
-//line web/authorize.qtpl:144
+//line web/authorize.qtpl:301
 		qw422016.N().S(`
     `)
-//line web/authorize.qtpl:145
+//line web/authorize.qtpl:302
 	}
-//line web/authorize.qtpl:145
+//line web/authorize.qtpl:302
 	qw422016.N().S(`
 
     `)
-//line web/authorize.qtpl:147
-	if p.Me != nil {
-//line web/authorize.qtpl:147
+//line web/authorize.qtpl:304
+	if len(p.Identities) > 0 {
+//line web/authorize.qtpl:304
+		qw422016.N().S(`
+    <fieldset>
+      <legend>`)
+//line web/authorize.qtpl:306
+		p.streamt(qw422016, "Sign in as")
+//line web/authorize.qtpl:306
+		qw422016.N().S(`</legend>
+
+      `)
+//line web/authorize.qtpl:308
+		for i, identity := range p.Identities {
+//line web/authorize.qtpl:308
+			qw422016.N().S(`
+      <div>
+        <label>
+          <input type="radio"
+                 name="me"
+                 value="`)
+//line web/authorize.qtpl:313
+			qw422016.E().S(identity.String())
+//line web/authorize.qtpl:313
+			qw422016.N().S(`"
+                 `)
+//line web/authorize.qtpl:314
+			if i == 0 {
+//line web/authorize.qtpl:314
+				qw422016.N().S(`required`)
+//line web/authorize.qtpl:314
+			}
+//line web/authorize.qtpl:314
+			qw422016.N().S(`
+                 `)
+//line web/authorize.qtpl:315
+			if p.Me != nil && p.Me.String() == identity.String() {
+//line web/authorize.qtpl:315
+				qw422016.N().S(`checked`)
+//line web/authorize.qtpl:315
+			}
+//line web/authorize.qtpl:315
+			qw422016.N().S(`>
+
+          `)
+//line web/authorize.qtpl:317
+			qw422016.E().S(identity.String())
+//line web/authorize.qtpl:317
+			qw422016.N().S(`
+        </label>
+      </div>
+      `)
+//line web/authorize.qtpl:320
+		}
+//line web/authorize.qtpl:320
+		qw422016.N().S(`
+    </fieldset>
+    `)
+//line web/authorize.qtpl:322
+	} else if p.Me != nil {
+//line web/authorize.qtpl:322
 		qw422016.N().S(`
     <input type="hidden"
            name="me"
            value="`)
-//line web/authorize.qtpl:150
+//line web/authorize.qtpl:325
 		qw422016.E().S(p.Me.String())
-//line web/authorize.qtpl:150
+//line web/authorize.qtpl:325
 		qw422016.N().S(`">
     `)
-//line web/authorize.qtpl:151
+//line web/authorize.qtpl:326
 	}
-//line web/authorize.qtpl:151
+//line web/authorize.qtpl:326
 	qw422016.N().S(`
 
     `)
-//line web/authorize.qtpl:153
+//line web/authorize.qtpl:328
+	if p.Reauthenticate {
+//line web/authorize.qtpl:328
+		qw422016.N().S(`
+    <label>
+      `)
+//line web/authorize.qtpl:330
+		p.streamt(qw422016, "Password")
+//line web/authorize.qtpl:330
+		qw422016.N().S(`
+
+      <input type="password"
+             name="password"
+             autocomplete="current-password"
+             required>
+    </label>
+    `)
+//line web/authorize.qtpl:337
+	}
+//line web/authorize.qtpl:337
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:339
+	if p.SecondFactor || p.StepUp {
+//line web/authorize.qtpl:339
+		qw422016.N().S(`
+    <label>
+      `)
+//line web/authorize.qtpl:341
+		p.streamt(qw422016, "One-time password")
+//line web/authorize.qtpl:341
+		qw422016.N().S(`
+
+      <input type="text"
+             name="otp"
+             inputmode="numeric"
+             autocomplete="one-time-code"
+             pattern="[0-9]{6}"
+             `)
+//line web/authorize.qtpl:348
+		if p.SecondFactor {
+//line web/authorize.qtpl:348
+			qw422016.N().S(`required`)
+//line web/authorize.qtpl:348
+		}
+//line web/authorize.qtpl:348
+		qw422016.N().S(`>
+    </label>
+    `)
+//line web/authorize.qtpl:350
+	}
+//line web/authorize.qtpl:350
+	qw422016.N().S(`
+
+    `)
+//line web/authorize.qtpl:352
 	if len(p.Providers) > 0 {
-//line web/authorize.qtpl:153
+//line web/authorize.qtpl:352
 		qw422016.N().S(`
     <select name="provider"
             autocomplete
             required>
 
       `)
-//line web/authorize.qtpl:158
+//line web/authorize.qtpl:357
 		for _, provider := range p.Providers {
-//line web/authorize.qtpl:158
+//line web/authorize.qtpl:357
 			qw422016.N().S(`
       <option value="`)
-//line web/authorize.qtpl:159
+//line web/authorize.qtpl:358
 			qw422016.E().S(provider.UID)
-//line web/authorize.qtpl:159
+//line web/authorize.qtpl:358
 			qw422016.N().S(`"
               `)
-//line web/authorize.qtpl:160
+//line web/authorize.qtpl:359
 			if provider.UID == "mastodon" {
-//line web/authorize.qtpl:160
+//line web/authorize.qtpl:359
 				qw422016.N().S(`selected`)
-//line web/authorize.qtpl:160
+//line web/authorize.qtpl:359
 			}
-//line web/authorize.qtpl:160
+//line web/authorize.qtpl:359
 			qw422016.N().S(`>
 
         `)
-//line web/authorize.qtpl:162
+//line web/authorize.qtpl:361
 			qw422016.E().S(provider.Name)
-//line web/authorize.qtpl:162
+//line web/authorize.qtpl:361
 			qw422016.N().S(`
       </option>
       `)
-//line web/authorize.qtpl:164
+//line web/authorize.qtpl:363
 		}
-//line web/authorize.qtpl:164
+//line web/authorize.qtpl:363
 		qw422016.N().S(`
     </select>
     `)
-//line web/authorize.qtpl:166
+//line web/authorize.qtpl:365
 	} else {
-//line web/authorize.qtpl:166
+//line web/authorize.qtpl:365
 		qw422016.N().S(`
     <input type="hidden"
            name="provider"
            value="direct">
     `)
-//line web/authorize.qtpl:170
+//line web/authorize.qtpl:369
 	}
-//line web/authorize.qtpl:170
+//line web/authorize.qtpl:369
 	qw422016.N().S(`
 
     <button type="submit"
@@ -465,9 +1181,9 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
             value="deny">
 
       `)
-//line web/authorize.qtpl:176
+//line web/authorize.qtpl:375
 	p.streamt(qw422016, "Deny")
-//line web/authorize.qtpl:176
+//line web/authorize.qtpl:375
 	qw422016.N().S(`
     </button>
 
@@ -476,47 +1192,47 @@ func (p *AuthorizePage) streambody(qw422016 *qt422016.Writer) {
             value="allow">
 
       `)
-//line web/authorize.qtpl:183
+//line web/authorize.qtpl:382
 	p.streamt(qw422016, "Allow")
-//line web/authorize.qtpl:183
+//line web/authorize.qtpl:382
 	qw422016.N().S(`
     </button>
 
     <aside>
       <p>`)
-//line web/authorize.qtpl:187
+//line web/authorize.qtpl:386
 	p.streamt(qw422016, `You will be redirected to %s%s%s`, `<code>`, p.RedirectURI, `</code>`)
-//line web/authorize.qtpl:187
+//line web/authorize.qtpl:386
 	qw422016.N().S(`</p>
     </aside>
   </form>
 </main>
 `)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 }
 
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 func (p *AuthorizePage) writebody(qq422016 qtio422016.Writer) {
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	p.streambody(qw422016)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	qt422016.ReleaseWriter(qw422016)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 }
 
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 func (p *AuthorizePage) body() string {
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	p.writebody(qb422016)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	qs422016 := string(qb422016.B)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 	return qs422016
-//line web/authorize.qtpl:191
+//line web/authorize.qtpl:390
 }
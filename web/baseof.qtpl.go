@@ -6,234 +6,254 @@ package web
 
 //line web/baseof.qtpl:1
 import (
+	"net/url"
 	"runtime/debug"
+	"strings"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
 	"source.toby3d.me/toby3d/auth/internal/domain"
+	"source.toby3d.me/toby3d/auth/internal/imageproxy"
 )
 
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 import (
 	qtio422016 "io"
 
 	qt422016 "github.com/valyala/quicktemplate"
 )
 
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 var (
 	_ = qtio422016.Copy
 	_ = qt422016.AcquireByteBuffer
 )
 
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 type Page interface {
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	body() string
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	streambody(qw422016 *qt422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	writebody(qq422016 qtio422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	head() string
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	streamhead(qw422016 *qt422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	writehead(qq422016 qtio422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	lang() string
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	streamlang(qw422016 *qt422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	writelang(qq422016 qtio422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	t(format message.Reference, args ...any) string
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	streamt(qw422016 *qt422016.Writer, format message.Reference, args ...any)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	writet(qq422016 qtio422016.Writer, format message.Reference, args ...any)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	title() string
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	streamtitle(qw422016 *qt422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 	writetitle(qq422016 qtio422016.Writer)
-//line web/baseof.qtpl:10
+//line web/baseof.qtpl:13
 }
 
-//line web/baseof.qtpl:18
+//line web/baseof.qtpl:21
 type BaseOf struct {
 	Config   *domain.Config
+	Images   imageproxy.UseCase
 	Language language.Tag
 	Printer  *message.Printer
 }
 
-//line web/baseof.qtpl:25
+//line web/baseof.qtpl:29
 func (p *BaseOf) streamlang(qw422016 *qt422016.Writer) {
-//line web/baseof.qtpl:26
+//line web/baseof.qtpl:30
 	if p.Language != language.Und {
-//line web/baseof.qtpl:27
+//line web/baseof.qtpl:31
 		qw422016.E().S(p.Language.String())
-//line web/baseof.qtpl:28
+//line web/baseof.qtpl:32
 	} else {
-//line web/baseof.qtpl:28
+//line web/baseof.qtpl:32
 		qw422016.N().S(`en`)
-//line web/baseof.qtpl:30
+//line web/baseof.qtpl:34
 	}
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 }
 
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 func (p *BaseOf) writelang(qq422016 qtio422016.Writer) {
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	p.streamlang(qw422016)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 }
 
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 func (p *BaseOf) lang() string {
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	p.writelang(qb422016)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 	return qs422016
-//line web/baseof.qtpl:31
+//line web/baseof.qtpl:35
 }
 
-//line web/baseof.qtpl:35
+//line web/baseof.qtpl:39
 func (p *BaseOf) streamtitle(qw422016 *qt422016.Writer) {
-//line web/baseof.qtpl:35
+//line web/baseof.qtpl:39
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:36
+//line web/baseof.qtpl:40
 	qw422016.E().S(p.Config.Name)
-//line web/baseof.qtpl:36
+//line web/baseof.qtpl:40
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 }
 
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 func (p *BaseOf) writetitle(qq422016 qtio422016.Writer) {
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	p.streamtitle(qw422016)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 }
 
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 func (p *BaseOf) title() string {
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	p.writetitle(qb422016)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 	return qs422016
-//line web/baseof.qtpl:37
+//line web/baseof.qtpl:41
 }
 
-//line web/baseof.qtpl:39
+//line web/baseof.qtpl:43
 func (p *BaseOf) streamhead(qw422016 *qt422016.Writer) {
-//line web/baseof.qtpl:39
+//line web/baseof.qtpl:43
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:40
-	qw422016.N().S(` <link rel="icon" href="/favicon.ico" sizes="any"> <link rel="icon" href="/icon.svg" type="image/svg+xml"> <link rel="apple-touch-icon" href="/apple-touch-icon.png"> <link rel="manifest" href="/manifest.webmanifest"> `)
+//line web/baseof.qtpl:44
+	qw422016.N().S(` <link rel="icon" href="`)
+//line web/baseof.qtpl:46
+	p.streamurl(qw422016, "/favicon.ico")
+//line web/baseof.qtpl:46
+	qw422016.N().S(`" sizes="any"> <link rel="icon" href="`)
+//line web/baseof.qtpl:50
+	p.streamurl(qw422016, "/icon.svg")
+//line web/baseof.qtpl:50
+	qw422016.N().S(`" type="image/svg+xml"> <link rel="apple-touch-icon" href="`)
 //line web/baseof.qtpl:54
+	p.streamurl(qw422016, "/apple-touch-icon.png")
+//line web/baseof.qtpl:54
+	qw422016.N().S(`"> <link rel="manifest" href="`)
+//line web/baseof.qtpl:57
+	p.streamurl(qw422016, "/manifest.webmanifest")
+//line web/baseof.qtpl:57
+	qw422016.N().S(`"> `)
+//line web/baseof.qtpl:58
 }
 
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 func (p *BaseOf) writehead(qq422016 qtio422016.Writer) {
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	p.streamhead(qw422016)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 }
 
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 func (p *BaseOf) head() string {
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	p.writehead(qb422016)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 	return qs422016
-//line web/baseof.qtpl:54
+//line web/baseof.qtpl:58
 }
 
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 func (p *BaseOf) streambody(qw422016 *qt422016.Writer) {
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 }
 
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 func (p *BaseOf) writebody(qq422016 qtio422016.Writer) {
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	p.streambody(qw422016)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 }
 
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 func (p *BaseOf) body() string {
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	p.writebody(qb422016)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 	return qs422016
-//line web/baseof.qtpl:56
+//line web/baseof.qtpl:60
 }
 
-//line web/baseof.qtpl:58
+//line web/baseof.qtpl:62
 func StreamTemplate(qw422016 *qt422016.Writer, p Page) {
-//line web/baseof.qtpl:58
+//line web/baseof.qtpl:62
 	qw422016.N().S(` <!DOCTYPE html> <html class="page" lang="`)
-//line web/baseof.qtpl:61
+//line web/baseof.qtpl:65
 	p.streamlang(qw422016)
-//line web/baseof.qtpl:61
+//line web/baseof.qtpl:65
 	qw422016.N().S(`"> <head> <meta charset="utf-8"> <meta name="viewport" content="width=device-width, initial-scale=1.0"> `)
-//line web/baseof.qtpl:68
+//line web/baseof.qtpl:72
 	p.streamhead(qw422016)
-//line web/baseof.qtpl:68
+//line web/baseof.qtpl:72
 	qw422016.N().S(` <title>`)
-//line web/baseof.qtpl:70
+//line web/baseof.qtpl:74
 	p.streamtitle(qw422016)
-//line web/baseof.qtpl:70
-	qw422016.N().S(`</title> </head> <body class="page__body body"> `)
 //line web/baseof.qtpl:74
+	qw422016.N().S(`</title> </head> <body class="page__body body"> `)
+//line web/baseof.qtpl:78
 	p.streambody(qw422016)
-//line web/baseof.qtpl:74
+//line web/baseof.qtpl:78
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:77
+//line web/baseof.qtpl:81
 	var path, vcsRevision string
 
 	if bi, ok := debug.ReadBuildInfo(); ok {
@@ -248,94 +268,168 @@ func StreamTemplate(qw422016 *qt422016.Writer, p Page) {
 		}
 	}
 
-//line web/baseof.qtpl:90
+//line web/baseof.qtpl:94
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:92
+//line web/baseof.qtpl:96
 	if vcsRevision != "" {
-//line web/baseof.qtpl:92
+//line web/baseof.qtpl:96
 		qw422016.N().S(` <footer> <small> `)
-//line web/baseof.qtpl:95
+//line web/baseof.qtpl:99
 		p.streamt(qw422016, "version")
-//line web/baseof.qtpl:95
+//line web/baseof.qtpl:99
 		qw422016.N().S(` <a href="https://`)
-//line web/baseof.qtpl:96
+//line web/baseof.qtpl:100
 		qw422016.E().S(path)
-//line web/baseof.qtpl:96
+//line web/baseof.qtpl:100
 		qw422016.N().S(`/commit/`)
-//line web/baseof.qtpl:96
+//line web/baseof.qtpl:100
 		qw422016.E().S(vcsRevision)
-//line web/baseof.qtpl:96
+//line web/baseof.qtpl:100
 		qw422016.N().S(`" target="_blank"> `)
-//line web/baseof.qtpl:98
+//line web/baseof.qtpl:102
 		qw422016.E().S(vcsRevision[:7])
-//line web/baseof.qtpl:98
-		qw422016.N().S(`</a> </small> </footer> `)
 //line web/baseof.qtpl:102
+		qw422016.N().S(`</a> </small> </footer> `)
+//line web/baseof.qtpl:106
 	}
-//line web/baseof.qtpl:102
+//line web/baseof.qtpl:106
 	qw422016.N().S(` </body> </html> `)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 }
 
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 func WriteTemplate(qq422016 qtio422016.Writer, p Page) {
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 	StreamTemplate(qw422016, p)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 }
 
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 func Template(p Page) string {
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
 	WriteTemplate(qb422016, p)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:109
+	qs422016 := string(qb422016.B)
+//line web/baseof.qtpl:109
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/baseof.qtpl:109
+	return qs422016
+//line web/baseof.qtpl:109
+}
+
+//line web/baseof.qtpl:113
+func (p BaseOf) streamimg(qw422016 *qt422016.Writer, u *url.URL, width, height int) {
+//line web/baseof.qtpl:114
+	if p.Images != nil {
+//line web/baseof.qtpl:115
+		qw422016.E().S(p.Images.URL(u, width, height).String())
+//line web/baseof.qtpl:116
+	} else {
+//line web/baseof.qtpl:117
+		qw422016.E().S(u.String())
+//line web/baseof.qtpl:118
+	}
+//line web/baseof.qtpl:119
+}
+
+//line web/baseof.qtpl:119
+func (p BaseOf) writeimg(qq422016 qtio422016.Writer, u *url.URL, width, height int) {
+//line web/baseof.qtpl:119
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/baseof.qtpl:119
+	p.streamimg(qw422016, u, width, height)
+//line web/baseof.qtpl:119
+	qt422016.ReleaseWriter(qw422016)
+//line web/baseof.qtpl:119
+}
+
+//line web/baseof.qtpl:119
+func (p BaseOf) img(u *url.URL, width, height int) string {
+//line web/baseof.qtpl:119
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/baseof.qtpl:119
+	p.writeimg(qb422016, u, width, height)
+//line web/baseof.qtpl:119
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:119
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:119
 	return qs422016
-//line web/baseof.qtpl:105
+//line web/baseof.qtpl:119
 }
 
-//line web/baseof.qtpl:107
+//line web/baseof.qtpl:123
+func (p BaseOf) streamurl(qw422016 *qt422016.Writer, path string) {
+//line web/baseof.qtpl:123
+	qw422016.E().S(strings.TrimSuffix(p.Config.Server.GetRootURL(), "/") + path)
+//line web/baseof.qtpl:123
+}
+
+//line web/baseof.qtpl:123
+func (p BaseOf) writeurl(qq422016 qtio422016.Writer, path string) {
+//line web/baseof.qtpl:123
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line web/baseof.qtpl:123
+	p.streamurl(qw422016, path)
+//line web/baseof.qtpl:123
+	qt422016.ReleaseWriter(qw422016)
+//line web/baseof.qtpl:123
+}
+
+//line web/baseof.qtpl:123
+func (p BaseOf) url(path string) string {
+//line web/baseof.qtpl:123
+	qb422016 := qt422016.AcquireByteBuffer()
+//line web/baseof.qtpl:123
+	p.writeurl(qb422016, path)
+//line web/baseof.qtpl:123
+	qs422016 := string(qb422016.B)
+//line web/baseof.qtpl:123
+	qt422016.ReleaseByteBuffer(qb422016)
+//line web/baseof.qtpl:123
+	return qs422016
+//line web/baseof.qtpl:123
+}
+
+//line web/baseof.qtpl:125
 func (p BaseOf) streamt(qw422016 *qt422016.Writer, format message.Reference, args ...any) {
-//line web/baseof.qtpl:107
+//line web/baseof.qtpl:125
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:108
+//line web/baseof.qtpl:126
 	qw422016.N().S(p.Printer.Sprintf(format, args...))
-//line web/baseof.qtpl:108
+//line web/baseof.qtpl:126
 	qw422016.N().S(` `)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 }
 
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 func (p BaseOf) writet(qq422016 qtio422016.Writer, format message.Reference, args ...any) {
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	p.streamt(qw422016, format, args...)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	qt422016.ReleaseWriter(qw422016)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 }
 
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 func (p BaseOf) t(format message.Reference, args ...any) string {
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	p.writet(qb422016, format, args...)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	qs422016 := string(qb422016.B)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 	return qs422016
-//line web/baseof.qtpl:109
+//line web/baseof.qtpl:127
 }
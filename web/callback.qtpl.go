@@ -25,54 +25,71 @@ var (
 //line web/callback.qtpl:5
 type CallbackPage struct {
 	BaseOf
-	Token *domain.Token
+	Token   *domain.Token
+	Profile *domain.Profile
 }
 
-//line web/callback.qtpl:11
+//line web/callback.qtpl:12
 func (p *CallbackPage) streambody(qw422016 *qt422016.Writer) {
-//line web/callback.qtpl:11
-	qw422016.N().S(` `)
 //line web/callback.qtpl:12
+	qw422016.N().S(` `)
+//line web/callback.qtpl:13
 	if p.Token != nil {
-//line web/callback.qtpl:12
-		qw422016.N().S(` <h1>`)
 //line web/callback.qtpl:13
+		qw422016.N().S(` `)
+//line web/callback.qtpl:14
+		if p.Profile != nil && p.Profile.Photo != nil && p.Profile.Photo.String() != "" {
+//line web/callback.qtpl:14
+			qw422016.N().S(` <img src="`)
+//line web/callback.qtpl:15
+			p.streamimg(qw422016, p.Profile.Photo, 96, 96)
+//line web/callback.qtpl:15
+			qw422016.N().S(`" alt="`)
+//line web/callback.qtpl:16
+			qw422016.E().S(p.Profile.Name)
+//line web/callback.qtpl:16
+			qw422016.N().S(`" decoding="async" height="96" width="96"> `)
+//line web/callback.qtpl:20
+		}
+//line web/callback.qtpl:20
+		qw422016.N().S(` <h1>`)
+//line web/callback.qtpl:21
 		qw422016.E().S(p.Token.Me.String())
-//line web/callback.qtpl:13
+//line web/callback.qtpl:21
 		qw422016.N().S(`</h1> <small>`)
-//line web/callback.qtpl:14
+//line web/callback.qtpl:22
 		qw422016.E().S(p.Token.AccessToken)
-//line web/callback.qtpl:14
+//line web/callback.qtpl:22
 		qw422016.N().S(`</small> `)
-//line web/callback.qtpl:15
+//line web/callback.qtpl:23
 	}
-//line web/callback.qtpl:15
+//line web/callback.qtpl:23
 	qw422016.N().S(` `)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 }
 
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 func (p *CallbackPage) writebody(qq422016 qtio422016.Writer) {
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	p.streambody(qw422016)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	qt422016.ReleaseWriter(qw422016)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 }
 
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 func (p *CallbackPage) body() string {
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	qb422016 := qt422016.AcquireByteBuffer()
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	p.writebody(qb422016)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	qs422016 := string(qb422016.B)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	qt422016.ReleaseByteBuffer(qb422016)
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 	return qs422016
-//line web/callback.qtpl:16
+//line web/callback.qtpl:24
 }
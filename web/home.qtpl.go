@@ -87,7 +87,7 @@ func (p *HomePage) streambody(qw422016 *qt422016.Writer) {
 //line web/home.qtpl:22
 		qw422016.N().S(` <img class="u-logo" src="`)
 //line web/home.qtpl:24
-		qw422016.E().S(p.Client.Logo.String())
+		p.streamimg(qw422016, p.Client.Logo, 140, 140)
 //line web/home.qtpl:24
 		qw422016.N().S(`" alt="`)
 //line web/home.qtpl:25
@@ -105,7 +105,11 @@ func (p *HomePage) streambody(qw422016 *qt422016.Writer) {
 //line web/home.qtpl:38
 	qw422016.E().S(p.Client.Name)
 //line web/home.qtpl:38
-	qw422016.N().S(` </a> </h1> </header> <main> <form class="" method="get" action="/authorize" enctype="application/x-www-form-urlencoded" accept-charset="utf-8" target="_self"> `)
+	qw422016.N().S(` </a> </h1> </header> <main> <form class="" method="get" action="`)
+//line web/home.qtpl:46
+	p.streamurl(qw422016, "/authorize")
+//line web/home.qtpl:46
+	qw422016.N().S(`" enctype="application/x-www-form-urlencoded" accept-charset="utf-8" target="_self"> `)
 //line web/home.qtpl:51
 	for name, value := range map[string]string{
 		"client_id":     p.Client.ID.String(),
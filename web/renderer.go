@@ -0,0 +1,24 @@
+package web
+
+import "io"
+
+type (
+	// Renderer writes the page of the web interface. Custom renderer can
+	// wrap or replace built-in templates by the type of the page.
+	Renderer interface {
+		Render(w io.Writer, page Page)
+	}
+
+	// RendererFunc is an adapter to use ordinary function as a Renderer.
+	RendererFunc func(w io.Writer, page Page)
+)
+
+// DefaultRenderer writes pages by built-in templates.
+//
+//nolint:gochecknoglobals // interfaces cannot be constants
+var DefaultRenderer Renderer = RendererFunc(func(w io.Writer, page Page) { WriteTemplate(w, page) })
+
+// Render calls f(w, page).
+func (f RendererFunc) Render(w io.Writer, page Page) {
+	f(w, page)
+}
@@ -0,0 +1,9 @@
+package web
+
+import "embed"
+
+// Static contains assets of the web interface, like favicons and manifest,
+// stored in the static directory.
+//
+//go:embed static/*
+var Static embed.FS
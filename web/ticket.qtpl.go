@@ -30,7 +30,11 @@ func (p *TicketPage) streambody(qw422016 *qt422016.Writer) {
 //line web/ticket.qtpl:9
 	p.streamt(qw422016, "TicketAuth")
 //line web/ticket.qtpl:9
-	qw422016.N().S(`</h1> </header> <main> <form class="" accept-charset="utf-8" action="/ticket/send" autocomplete="off" enctype="application/x-www-form-urlencoded" method="post" target="_self"> `)
+	qw422016.N().S(`</h1> </header> <main> <form class="" accept-charset="utf-8" action="`)
+//line web/ticket.qtpl:15
+	p.streamurl(qw422016, "/ticket/send")
+//line web/ticket.qtpl:15
+	qw422016.N().S(`" autocomplete="off" enctype="application/x-www-form-urlencoded" method="post" target="_self"> `)
 //line web/ticket.qtpl:21
 	if p.CSRF != nil {
 //line web/ticket.qtpl:21